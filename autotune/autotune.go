@@ -0,0 +1,158 @@
+// Package autotune adjusts the number of jobs that run at the same time and
+// the concurrency of every job to the load of the system.
+package autotune
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// The thresholds of the signals, in percent. Above the high ones the
+// limits are halved, below the low ones they grow by one.
+const (
+	highCPU       = 85
+	lowCPU        = 60
+	highMemory    = 85
+	lowMemory     = 70
+	highErrorRate = 20
+	lowErrorRate  = 5
+)
+
+// Interval is how often the controller samples the system.
+var Interval = 10 * time.Second
+
+// Bounds are the minimum and the maximum of a limit.
+type Bounds struct {
+	Min int
+	Max int
+}
+
+func (b Bounds) clamp(v int) int {
+	return min(max(v, b.Min), b.Max)
+}
+
+// Signals are the load of the system, in percent.
+type Signals struct {
+	CPU       float64
+	Memory    float64
+	ErrorRate float64
+}
+
+// Controller increases the limits by one while the system has spare
+// resources and halves them when it is overloaded or the error rate of
+// the searches is high, e.g. when Google throttles the scraper.
+type Controller struct {
+	jobsBounds        Bounds
+	concurrencyBounds Bounds
+
+	mu          sync.Mutex
+	jobs        int
+	concurrency int
+	errors      int
+	total       int
+}
+
+// New returns a controller that starts with the minimum number of jobs and
+// the maximum concurrency, the scraper used to run one job at a time with
+// the configured concurrency.
+func New(jobs, concurrency Bounds) *Controller {
+	return &Controller{
+		jobsBounds:        jobs,
+		concurrencyBounds: concurrency,
+		jobs:              jobs.Min,
+		concurrency:       concurrency.Max,
+	}
+}
+
+// Jobs returns the number of jobs that may run at the same time.
+func (c *Controller) Jobs() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.jobs
+}
+
+// Concurrency returns the concurrency of a job that starts.
+func (c *Controller) Concurrency() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.concurrency
+}
+
+// Observe records the number of failed searches of a job out of total.
+func (c *Controller) Observe(failed, total int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errors += failed
+	c.total += total
+}
+
+// Adjust changes the limits to the signals. The concurrency of the jobs
+// decreases first and increases last, so that the jobs keep a useful
+// concurrency.
+func (c *Controller) Adjust(s Signals) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case s.CPU > highCPU || s.Memory > highMemory || s.ErrorRate > highErrorRate:
+		if c.concurrency > c.concurrencyBounds.Min {
+			c.concurrency = c.concurrencyBounds.clamp(c.concurrency / 2)
+		} else {
+			c.jobs = c.jobsBounds.clamp(c.jobs / 2)
+		}
+	case s.CPU < lowCPU && s.Memory < lowMemory && s.ErrorRate < lowErrorRate:
+		if c.jobs < c.jobsBounds.Max {
+			c.jobs = c.jobsBounds.clamp(c.jobs + 1)
+		} else {
+			c.concurrency = c.concurrencyBounds.clamp(c.concurrency + 1)
+		}
+	}
+}
+
+// errorRate returns the error rate since the last call, in percent.
+func (c *Controller) errorRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var rate float64
+	if c.total > 0 {
+		rate = float64(c.errors) * 100 / float64(c.total)
+	}
+
+	c.errors, c.total = 0, 0
+
+	return rate
+}
+
+// Run samples the system every Interval and adjusts the limits until ctx
+// is done.
+func (c *Controller) Run(ctx context.Context) {
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s := Signals{ErrorRate: c.errorRate()}
+
+			if percent, err := cpu.PercentWithContext(ctx, 0, false); err == nil && len(percent) > 0 {
+				s.CPU = percent[0]
+			}
+
+			if vm, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+				s.Memory = vm.UsedPercent
+			}
+
+			c.Adjust(s)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package autotune_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/autotune"
+)
+
+func Test_Controller(t *testing.T) {
+	c := autotune.New(autotune.Bounds{Min: 1, Max: 3}, autotune.Bounds{Min: 2, Max: 8})
+
+	require.Equal(t, 1, c.Jobs())
+	require.Equal(t, 8, c.Concurrency())
+
+	idle := autotune.Signals{CPU: 20, Memory: 30}
+
+	// the jobs grow first, then the concurrency which is at its maximum
+	for range 5 {
+		c.Adjust(idle)
+	}
+
+	require.Equal(t, 3, c.Jobs())
+	require.Equal(t, 8, c.Concurrency())
+
+	// the concurrency shrinks first
+	c.Adjust(autotune.Signals{CPU: 95, Memory: 30})
+	require.Equal(t, 3, c.Jobs())
+	require.Equal(t, 4, c.Concurrency())
+
+	c.Adjust(autotune.Signals{CPU: 20, Memory: 30, ErrorRate: 50})
+	require.Equal(t, 2, c.Concurrency())
+
+	c.Adjust(autotune.Signals{CPU: 20, Memory: 90})
+	require.Equal(t, 1, c.Jobs())
+	require.Equal(t, 2, c.Concurrency())
+
+	c.Adjust(autotune.Signals{CPU: 99, Memory: 99})
+	require.Equal(t, 1, c.Jobs())
+	require.Equal(t, 2, c.Concurrency())
+
+	// between the thresholds nothing changes
+	c.Adjust(autotune.Signals{CPU: 70, Memory: 30})
+	require.Equal(t, 1, c.Jobs())
+	require.Equal(t, 2, c.Concurrency())
+}
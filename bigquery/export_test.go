@@ -0,0 +1,9 @@
+package bigquery
+
+import "github.com/gosom/scrapemate"
+
+// SetEndpoint sends the requests of the writer to endpoint instead of the
+// BigQuery API.
+func SetEndpoint(w scrapemate.ResultWriter, endpoint string) {
+	w.(*resultWriter).endpoint = endpoint
+}
@@ -0,0 +1,322 @@
+package bigquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gcpauth"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	scope            = "https://www.googleapis.com/auth/bigquery"
+	baseURL          = "https://bigquery.googleapis.com/bigquery/v2"
+	defaultBatchSize = 50
+	flushInterval    = 10 * time.Second
+)
+
+type Config struct {
+	// CredentialsFile is the path to the service account JSON key.
+	// When empty GOOGLE_APPLICATION_CREDENTIALS is used.
+	CredentialsFile string
+	// ProjectID defaults to the project of the service account.
+	ProjectID string
+	DatasetID string
+	TableID   string
+}
+
+// NewResultWriter creates a writer that streams the entries into a BigQuery
+// table using the insertAll API. The table is created from the
+// gmaps.Entry schema if it does not exist, and the columns of the fields
+// added to gmaps.Entry since are added to an existing table.
+func NewResultWriter(cfg Config) (scrapemate.ResultWriter, error) {
+	if cfg.DatasetID == "" || cfg.TableID == "" {
+		return nil, errors.New("bigquery dataset and table are required")
+	}
+
+	ts, err := gcpauth.FromFile(cfg.CredentialsFile, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ProjectID == "" {
+		cfg.ProjectID = ts.ProjectID()
+	}
+
+	if cfg.ProjectID == "" {
+		return nil, errors.New("bigquery project id is required")
+	}
+
+	ans := resultWriter{
+		cfg:      cfg,
+		ts:       ts,
+		client:   &http.Client{Timeout: time.Minute},
+		schema:   entrySchema(),
+		endpoint: baseURL,
+	}
+
+	return &ans, nil
+}
+
+type resultWriter struct {
+	cfg      Config
+	ts       *gcpauth.TokenSource
+	client   *http.Client
+	schema   []field
+	endpoint string
+}
+
+func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	if err := r.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	buff := make([]*gmaps.Entry, 0, defaultBatchSize)
+	lastSave := time.Now().UTC()
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			buff = append(buff, data)
+		case []*gmaps.Entry:
+			buff = append(buff, data...)
+		default:
+			return errors.New("invalid data type")
+		}
+
+		if len(buff) >= defaultBatchSize || time.Now().UTC().Sub(lastSave) >= flushInterval {
+			if err := r.insertAll(ctx, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastSave = time.Now().UTC()
+		}
+	}
+
+	if len(buff) > 0 {
+		if err := r.insertAll(ctx, buff); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *resultWriter) tablePath() string {
+	return fmt.Sprintf("/projects/%s/datasets/%s/tables", r.cfg.ProjectID, r.cfg.DatasetID)
+}
+
+func (r *resultWriter) ensureTable(ctx context.Context) error {
+	payload := map[string]any{
+		"tableReference": map[string]string{
+			"projectId": r.cfg.ProjectID,
+			"datasetId": r.cfg.DatasetID,
+			"tableId":   r.cfg.TableID,
+		},
+		"schema": map[string]any{
+			"fields": r.schema,
+		},
+	}
+
+	resp, err := r.do(ctx, http.MethodPost, r.tablePath(), payload)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusConflict:
+		return r.addColumns(ctx)
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+		return fmt.Errorf("failed to create bigquery table with status %d: %s", resp.StatusCode, string(msg))
+	}
+}
+
+// addColumns adds the fields of the schema that the existing table lacks.
+// The columns of the table are sent back unchanged, BigQuery only accepts
+// new NULLABLE or REPEATED columns in a patch.
+func (r *resultWriter) addColumns(ctx context.Context) error {
+	path := r.tablePath() + "/" + r.cfg.TableID
+
+	resp, err := r.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+		return fmt.Errorf("failed to get bigquery table with status %d: %s", resp.StatusCode, string(msg))
+	}
+
+	var table struct {
+		Schema struct {
+			Fields []json.RawMessage `json:"fields"`
+		} `json:"schema"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&table); err != nil {
+		return fmt.Errorf("failed to decode bigquery table: %w", err)
+	}
+
+	existing := make(map[string]bool, len(table.Schema.Fields))
+
+	for _, raw := range table.Schema.Fields {
+		var f field
+
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return fmt.Errorf("failed to decode bigquery table: %w", err)
+		}
+
+		existing[strings.ToLower(f.Name)] = true
+	}
+
+	fields := table.Schema.Fields
+
+	for _, f := range r.schema {
+		if existing[strings.ToLower(f.Name)] {
+			continue
+		}
+
+		raw, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+
+		fields = append(fields, raw)
+	}
+
+	if len(fields) == len(table.Schema.Fields) {
+		return nil
+	}
+
+	payload := map[string]any{
+		"schema": map[string]any{
+			"fields": fields,
+		},
+	}
+
+	patch, err := r.do(ctx, http.MethodPatch, path, payload)
+	if err != nil {
+		return err
+	}
+
+	defer patch.Body.Close()
+
+	if patch.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(patch.Body, 4096))
+
+		return fmt.Errorf("failed to add the new columns to the bigquery table with status %d: %s", patch.StatusCode, string(msg))
+	}
+
+	return nil
+}
+
+func (r *resultWriter) insertAll(ctx context.Context, entries []*gmaps.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	type row struct {
+		InsertID string         `json:"insertId,omitempty"`
+		JSON     map[string]any `json:"json"`
+	}
+
+	rows := make([]row, 0, len(entries))
+
+	for _, entry := range entries {
+		data, err := entryToRow(r.schema, entry)
+		if err != nil {
+			return err
+		}
+
+		rows = append(rows, row{InsertID: entry.Cid, JSON: data})
+	}
+
+	// the values of the columns the table lacks, e.g. dropped by hand,
+	// are ignored instead of failing the rows
+	payload := map[string]any{
+		"rows":                rows,
+		"ignoreUnknownValues": true,
+	}
+
+	resp, err := r.do(ctx, http.MethodPost, r.tablePath()+"/"+r.cfg.TableID+"/insertAll", payload)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+		return fmt.Errorf("bigquery insertAll failed with status %d: %s", resp.StatusCode, string(msg))
+	}
+
+	var insertResp struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Reason  string `json:"reason"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&insertResp); err != nil {
+		return fmt.Errorf("failed to decode insertAll response: %w", err)
+	}
+
+	for _, ie := range insertResp.InsertErrors {
+		for _, e := range ie.Errors {
+			return fmt.Errorf("bigquery insert failed for row %d: %s: %s", ie.Index, e.Reason, e.Message)
+		}
+	}
+
+	return nil
+}
+
+func (r *resultWriter) do(ctx context.Context, method, path string, payload any) (*http.Response, error) {
+	var body io.Reader = http.NoBody
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, r.endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if err := r.ts.Authorize(req); err != nil {
+		return nil, err
+	}
+
+	return r.client.Do(req)
+}
@@ -0,0 +1,131 @@
+package bigquery_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/bigquery"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func credentialsFile(t *testing.T, tokenURI string) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "project",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"client_email": "scraper@project.iam.gserviceaccount.com",
+		"token_uri":    tokenURI,
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func Test_ResultWriter(t *testing.T) {
+	const table = "/projects/project/datasets/dataset/tables/places"
+
+	var (
+		mu       sync.Mutex
+		patched  []map[string]any
+		inserted map[string]any
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /token":
+			_, _ = io.WriteString(w, `{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`)
+		case "POST /projects/project/datasets/dataset/tables":
+			// the table was created by an older version
+			w.WriteHeader(http.StatusConflict)
+		case "GET " + table:
+			_, _ = io.WriteString(w, `{"schema": {"fields": [
+				{"name": "cid", "type": "STRING", "mode": "NULLABLE", "description": "kept"},
+				{"name": "title", "type": "STRING", "mode": "NULLABLE"}
+			]}}`)
+		case "PATCH " + table:
+			var payload struct {
+				Schema struct {
+					Fields []map[string]any `json:"fields"`
+				} `json:"schema"`
+			}
+
+			require.NoError(t, json.Unmarshal(body, &payload))
+
+			patched = payload.Schema.Fields
+
+			_, _ = io.WriteString(w, `{}`)
+		case "POST " + table + "/insertAll":
+			require.NoError(t, json.Unmarshal(body, &inserted))
+
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	w, err := bigquery.NewResultWriter(bigquery.Config{
+		CredentialsFile: credentialsFile(t, srv.URL+"/token"),
+		DatasetID:       "dataset",
+		TableID:         "places",
+	})
+	require.NoError(t, err)
+
+	bigquery.SetEndpoint(w, srv.URL)
+
+	in := make(chan scrapemate.Result, 1)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Cid: "1", Title: "Cafe", SerpPosition: 2}}
+
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// the existing columns are sent back unchanged, the new ones appended
+	require.Equal(t, "cid", patched[0]["name"])
+	require.Equal(t, "kept", patched[0]["description"])
+	require.Equal(t, "title", patched[1]["name"])
+
+	names := map[string]int{}
+	for _, f := range patched {
+		names[f["name"].(string)]++
+	}
+
+	require.Equal(t, 1, names["cid"])
+	require.Equal(t, 1, names["serp_position"])
+	require.Equal(t, 1, names["provenance"])
+
+	require.Equal(t, true, inserted["ignoreUnknownValues"])
+	require.Len(t, inserted["rows"], 1)
+}
@@ -0,0 +1,95 @@
+package bigquery
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+type field struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Mode  string `json:"mode,omitempty"`
+	index int
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// entrySchema derives the table schema from the json tags of gmaps.Entry.
+// Scalar fields map to their BigQuery counterparts, string slices become
+// repeated strings and everything else is stored in a JSON column.
+func entrySchema() []field {
+	t := reflect.TypeOf(gmaps.Entry{})
+
+	fields := make([]field, 0, t.NumField())
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !sf.IsExported() {
+			continue
+		}
+
+		f := field{Name: name, Mode: "NULLABLE", index: i}
+
+		switch {
+		case sf.Type == timeType:
+			f.Type = "TIMESTAMP"
+		case sf.Type.Kind() == reflect.String:
+			f.Type = "STRING"
+		case sf.Type.Kind() == reflect.Bool:
+			f.Type = "BOOLEAN"
+		case sf.Type.Kind() >= reflect.Int && sf.Type.Kind() <= reflect.Int64:
+			f.Type = "INTEGER"
+		case sf.Type.Kind() == reflect.Float32 || sf.Type.Kind() == reflect.Float64:
+			f.Type = "FLOAT"
+		case sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.String:
+			f.Type = "STRING"
+			f.Mode = "REPEATED"
+		default:
+			f.Type = "JSON"
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+func entryToRow(schema []field, entry *gmaps.Entry) (map[string]any, error) {
+	v := reflect.ValueOf(entry).Elem()
+	row := make(map[string]any, len(schema))
+
+	for _, f := range schema {
+		val := v.Field(f.index).Interface()
+
+		switch f.Type {
+		case "JSON":
+			data, err := json.Marshal(val)
+			if err != nil {
+				return nil, err
+			}
+
+			row[f.Name] = string(data)
+		case "TIMESTAMP":
+			ts, _ := val.(time.Time)
+			if !ts.IsZero() {
+				row[f.Name] = ts.UTC().Format(time.RFC3339Nano)
+			}
+		default:
+			if f.Mode == "REPEATED" && v.Field(f.index).IsNil() {
+				row[f.Name] = []string{}
+
+				continue
+			}
+
+			row[f.Name] = val
+		}
+	}
+
+	return row, nil
+}
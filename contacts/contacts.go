@@ -0,0 +1,405 @@
+// Package contacts exports the places as contacts that can be imported in
+// address books: vCard 4.0 files and the contact CSV files of Google
+// Contacts and Outlook.
+package contacts
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// The export formats.
+const (
+	FormatVCard   = "vcard"
+	FormatGoogle  = "google-csv"
+	FormatOutlook = "outlook-csv"
+)
+
+// Formats are the supported formats.
+var Formats = []string{FormatVCard, FormatGoogle, FormatOutlook}
+
+// Contact is the part of a place that goes to an address book.
+type Contact struct {
+	Name       string
+	Category   string
+	Phone      string
+	Website    string
+	Emails     []string
+	Address    string
+	Street     string
+	City       string
+	PostalCode string
+	State      string
+	Country    string
+	Latitude   float64
+	Longitude  float64
+	// Link is the Google Maps url of the place.
+	Link string
+}
+
+// FromEntry returns the contact of a place.
+func FromEntry(e *gmaps.Entry) Contact {
+	return Contact{
+		Name:       e.Title,
+		Category:   e.Category,
+		Phone:      e.Phone,
+		Website:    e.WebSite,
+		Emails:     e.Emails,
+		Address:    e.Address,
+		Street:     e.CompleteAddress.Street,
+		City:       e.CompleteAddress.City,
+		PostalCode: e.CompleteAddress.PostalCode,
+		State:      e.CompleteAddress.State,
+		Country:    e.CompleteAddress.Country,
+		Latitude:   e.Latitude,
+		Longitude:  e.Longitude,
+		Link:       e.Link,
+	}
+}
+
+// FromRecord returns the contact of a row of a results CSV file, keyed by
+// the column names.
+func FromRecord(record map[string]string) Contact {
+	ans := Contact{
+		Name:       record["title"],
+		Category:   record["category"],
+		Phone:      record["phone"],
+		Website:    record["website"],
+		Address:    record["address"],
+		Street:     record["street"],
+		City:       record["city"],
+		PostalCode: record["postal_code"],
+		State:      record["state"],
+		Country:    record["country"],
+		Link:       record["link"],
+	}
+
+	for _, email := range strings.Split(record["emails"], ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			ans.Emails = append(ans.Emails, email)
+		}
+	}
+
+	ans.Latitude, _ = strconv.ParseFloat(record["latitude"], 64)
+	ans.Longitude, _ = strconv.ParseFloat(record["longitude"], 64)
+
+	return ans
+}
+
+// NewResultWriter returns a writer of the results as contacts in the
+// format, one of Formats.
+func NewResultWriter(w io.Writer, format string) (scrapemate.ResultWriter, error) {
+	cw, err := NewWriter(w, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resultWriter{w: cw}, nil
+}
+
+type resultWriter struct {
+	w *Writer
+}
+
+func (r *resultWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = append(entries, data)
+		case []*gmaps.Entry:
+			entries = data
+		default:
+			return errors.New("invalid data type")
+		}
+
+		for i := range entries {
+			c := FromEntry(entries[i])
+
+			if err := r.w.Write(&c); err != nil {
+				return err
+			}
+		}
+	}
+
+	return r.w.Flush()
+}
+
+// Writer writes contacts in a format.
+type Writer struct {
+	format string
+	w      io.Writer
+	csv    *csv.Writer
+	header bool
+}
+
+// NewWriter returns a writer of the format, one of Formats.
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	ans := Writer{format: format, w: w}
+
+	switch format {
+	case FormatVCard:
+	case FormatGoogle, FormatOutlook:
+		ans.csv = csv.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown contacts format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	return &ans, nil
+}
+
+// ContentType returns the media type of the files of the format.
+func ContentType(format string) string {
+	if format == FormatVCard {
+		return "text/vcard"
+	}
+
+	return "text/csv"
+}
+
+// Extension returns the file extension of the format.
+func Extension(format string) string {
+	if format == FormatVCard {
+		return ".vcf"
+	}
+
+	return ".csv"
+}
+
+// Write writes a contact. The contacts without a name are skipped.
+func (w *Writer) Write(c *Contact) error {
+	if c.Name == "" {
+		return nil
+	}
+
+	switch w.format {
+	case FormatVCard:
+		return writeVCard(w.w, c)
+	case FormatGoogle:
+		return w.writeCSV(googleHeader, googleRow(c))
+	default:
+		return w.writeCSV(outlookHeader, outlookRow(c))
+	}
+}
+
+// Flush writes the header of the CSV formats when no contact was written,
+// and the buffered data.
+func (w *Writer) Flush() error {
+	if w.csv == nil {
+		return nil
+	}
+
+	if !w.header {
+		header := googleHeader
+		if w.format == FormatOutlook {
+			header = outlookHeader
+		}
+
+		if err := w.writeCSV(header, nil); err != nil {
+			return err
+		}
+	}
+
+	w.csv.Flush()
+
+	return w.csv.Error()
+}
+
+func (w *Writer) writeCSV(header, row []string) error {
+	if !w.header {
+		if err := w.csv.Write(header); err != nil {
+			return err
+		}
+
+		w.header = true
+	}
+
+	if row == nil {
+		return nil
+	}
+
+	return w.csv.Write(row)
+}
+
+// the columns of the Google Contacts import
+var googleHeader = []string{
+	"Name", "Organization 1 - Name",
+	"E-mail 1 - Type", "E-mail 1 - Value",
+	"Phone 1 - Type", "Phone 1 - Value",
+	"Address 1 - Type", "Address 1 - Formatted", "Address 1 - Street", "Address 1 - City",
+	"Address 1 - Region", "Address 1 - Postal Code", "Address 1 - Country",
+	"Website 1 - Type", "Website 1 - Value",
+	"Notes",
+}
+
+func googleRow(c *Contact) []string {
+	return []string{
+		c.Name, c.Name,
+		label(len(c.Emails) > 0), strings.Join(c.Emails, " ::: "),
+		label(c.Phone != ""), c.Phone,
+		label(c.Address != "" || c.Street != ""), c.Address, c.Street, c.City,
+		c.State, c.PostalCode, c.Country,
+		label(c.Website != ""), c.Website,
+		notes(c),
+	}
+}
+
+func label(ok bool) string {
+	if ok {
+		return "Work"
+	}
+
+	return ""
+}
+
+// the columns of the Outlook contacts import
+var outlookHeader = []string{
+	"First Name", "Company",
+	"E-mail Address", "E-mail 2 Address", "E-mail 3 Address",
+	"Business Phone",
+	"Business Street", "Business City", "Business State", "Business Postal Code", "Business Country/Region",
+	"Web Page", "Notes",
+}
+
+func outlookRow(c *Contact) []string {
+	emails := make([]string, 3)
+	copy(emails, c.Emails)
+
+	return []string{
+		c.Name, c.Name,
+		emails[0], emails[1], emails[2],
+		c.Phone,
+		c.Street, c.City, c.State, c.PostalCode, c.Country,
+		c.Website, notes(c),
+	}
+}
+
+// notes returns the category and the Google Maps url of the place, for the
+// formats without a field for them.
+func notes(c *Contact) string {
+	var lines []string
+
+	if c.Category != "" {
+		lines = append(lines, "Category: "+c.Category)
+	}
+
+	if c.Link != "" {
+		lines = append(lines, "Google Maps: "+c.Link)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func writeVCard(w io.Writer, c *Contact) error {
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"KIND:org",
+		"FN:" + escape(c.Name),
+		"ORG:" + escape(c.Name),
+	}
+
+	if c.Category != "" {
+		lines = append(lines, "CATEGORIES:"+escape(c.Category))
+	}
+
+	if c.Phone != "" {
+		lines = append(lines, "TEL;TYPE=work;VALUE=text:"+escape(c.Phone))
+	}
+
+	for _, email := range c.Emails {
+		lines = append(lines, "EMAIL;TYPE=work:"+escape(email))
+	}
+
+	if c.Website != "" {
+		lines = append(lines, "URL:"+c.Website)
+	}
+
+	if c.Address != "" || c.Street != "" || c.City != "" || c.PostalCode != "" || c.Country != "" {
+		adr := "ADR;TYPE=work"
+
+		if c.Address != "" {
+			adr += `;LABEL="` + paramValue(c.Address) + `"`
+		}
+
+		// post office box; extended address; street; locality; region;
+		// postal code; country
+		components := []string{"", "", c.Street, c.City, c.State, c.PostalCode, c.Country}
+
+		for i := range components {
+			components[i] = escape(components[i])
+		}
+
+		lines = append(lines, adr+":"+strings.Join(components, ";"))
+	}
+
+	if c.Latitude != 0 || c.Longitude != 0 {
+		lines = append(lines, fmt.Sprintf("GEO:geo:%s,%s",
+			strconv.FormatFloat(c.Latitude, 'f', -1, 64), strconv.FormatFloat(c.Longitude, 'f', -1, 64)))
+	}
+
+	if c.Link != "" {
+		lines = append(lines, "NOTE:"+escape("Google Maps: "+c.Link))
+	}
+
+	lines = append(lines, "END:VCARD")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, fold(line)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`)
+
+// escape escapes a text value of a vCard.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// paramValue returns s without the characters that a quoted parameter
+// value cannot have.
+func paramValue(s string) string {
+	return strings.NewReplacer(`"`, "'", "\r", " ", "\n", " ").Replace(s)
+}
+
+// fold splits a line of a vCard in lines of at most 75 octets, without
+// splitting a character, and ends it with CRLF.
+func fold(line string) string {
+	const limit = 75
+
+	var b strings.Builder
+
+	width := 0
+
+	for _, r := range line {
+		size := len(string(r))
+
+		if width+size > limit {
+			b.WriteString("\r\n ")
+
+			width = 1
+		}
+
+		b.WriteRune(r)
+
+		width += size
+	}
+
+	b.WriteString("\r\n")
+
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package contacts_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/contacts"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func testContact() contacts.Contact {
+	return contacts.FromEntry(&gmaps.Entry{
+		Title:    "Joe's Diner, Downtown",
+		Category: "Diner",
+		Phone:    "+1 555-0100",
+		WebSite:  "https://joes.example.com",
+		Emails:   []string{"info@joes.example.com", "events@joes.example.com"},
+		Address:  "1 Main St, Springfield, IL 62701",
+		CompleteAddress: gmaps.Address{
+			Street:     "1 Main St",
+			City:       "Springfield",
+			PostalCode: "62701",
+			State:      "IL",
+			Country:    "US",
+		},
+		Latitude:  39.7817,
+		Longitude: -89.6501,
+		Link:      "https://www.google.com/maps/place/joes",
+	})
+}
+
+func Test_VCard(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := contacts.NewWriter(&buf, contacts.FormatVCard)
+	require.NoError(t, err)
+
+	c := testContact()
+
+	require.NoError(t, w.Write(&c))
+	require.NoError(t, w.Flush())
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		require.LessOrEqual(t, len(line), 75)
+	}
+
+	// the long lines are folded
+	out := strings.ReplaceAll(buf.String(), "\r\n ", "")
+
+	require.True(t, strings.HasPrefix(out, "BEGIN:VCARD\r\nVERSION:4.0\r\n"))
+	require.True(t, strings.HasSuffix(out, "END:VCARD\r\n"))
+	require.Contains(t, out, "FN:Joe's Diner\\, Downtown\r\n")
+	require.Contains(t, out, "TEL;TYPE=work;VALUE=text:+1 555-0100\r\n")
+	require.Contains(t, out, "EMAIL;TYPE=work:events@joes.example.com\r\n")
+	require.Contains(t, out, ":;;1 Main St;Springfield;IL;62701;US\r\n")
+	require.Contains(t, out, "GEO:geo:39.7817,-89.6501\r\n")
+}
+
+func Test_CSV(t *testing.T) {
+	for _, format := range []string{contacts.FormatGoogle, contacts.FormatOutlook} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w, err := contacts.NewWriter(&buf, format)
+			require.NoError(t, err)
+
+			c := testContact()
+
+			require.NoError(t, w.Write(&c))
+			require.NoError(t, w.Write(&contacts.Contact{}))
+			require.NoError(t, w.Flush())
+
+			rows, err := csv.NewReader(&buf).ReadAll()
+			require.NoError(t, err)
+			require.Len(t, rows, 2)
+			require.Len(t, rows[1], len(rows[0]))
+			require.Equal(t, "Joe's Diner, Downtown", rows[1][0])
+			require.Contains(t, rows[1], "+1 555-0100")
+		})
+	}
+}
+
+func Test_FromRecord(t *testing.T) {
+	c := contacts.FromRecord(map[string]string{
+		"title":     "Cafe",
+		"emails":    "a@example.com, b@example.com",
+		"latitude":  "37.983810",
+		"longitude": "23.727539",
+	})
+
+	require.Equal(t, []string{"a@example.com", "b@example.com"}, c.Emails)
+	require.InDelta(t, 37.98381, c.Latitude, 1e-6)
+}
+
+func Test_UnknownFormat(t *testing.T) {
+	_, err := contacts.NewWriter(&bytes.Buffer{}, "ldif")
+	require.Error(t, err)
+}
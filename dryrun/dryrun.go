@@ -0,0 +1,106 @@
+package dryrun
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+const (
+	// google maps shows at most 120 places per search
+	maxPlacesPerSearch = 120
+	// a search shows about 8 places and every scroll loads about 8 more
+	placesPerScroll = 8
+	// fast mode returns at most 21 places per search
+	fastModePlaces = 21
+	// throughput of a single concurrent browser, see the Performance
+	// section of the README (120 jobs per minute with concurrency 8)
+	jobsPerMinute = 15
+)
+
+type Params struct {
+	FastMode    bool
+	Depth       int
+	Email       bool
+	MaxResults  int
+	Concurrency int
+}
+
+type Seed struct {
+	ID              string `json:"id"`
+	URL             string `json:"url"`
+	EstimatedPlaces int    `json:"estimated_places"`
+}
+
+// Plan describes the seed jobs of a scrape and an estimation of its size,
+// without scraping anything.
+type Plan struct {
+	Seeds            []Seed `json:"seeds"`
+	EstimatedPlaces  int    `json:"estimated_places"`
+	EstimatedJobs    int    `json:"estimated_jobs"`
+	EstimatedSeconds int    `json:"estimated_seconds"`
+}
+
+func New(seedJobs []scrapemate.IJob, params Params) *Plan {
+	perSeed := fastModePlaces
+
+	if !params.FastMode {
+		perSeed = min(maxPlacesPerSearch, placesPerScroll*(max(params.Depth, 1)+1))
+	}
+
+	ans := Plan{
+		Seeds: make([]Seed, 0, len(seedJobs)),
+	}
+
+	for _, job := range seedJobs {
+		ans.Seeds = append(ans.Seeds, Seed{
+			ID:              job.GetID(),
+			URL:             job.GetFullURL(),
+			EstimatedPlaces: perSeed,
+		})
+
+		ans.EstimatedPlaces += perSeed
+	}
+
+	if params.MaxResults > 0 {
+		ans.EstimatedPlaces = min(ans.EstimatedPlaces, params.MaxResults)
+	}
+
+	ans.EstimatedJobs = len(seedJobs)
+
+	// in fast mode the places are part of the search response
+	if !params.FastMode {
+		ans.EstimatedJobs += ans.EstimatedPlaces
+	}
+
+	if params.Email {
+		ans.EstimatedJobs += ans.EstimatedPlaces
+	}
+
+	rate := jobsPerMinute * max(params.Concurrency, 1)
+
+	ans.EstimatedSeconds = ans.EstimatedJobs * 60 / rate
+
+	return &ans
+}
+
+func (p *Plan) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tESTIMATED PLACES\tURL")
+
+	for i := range p.Seeds {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", p.Seeds[i].ID, p.Seeds[i].EstimatedPlaces, p.Seeds[i].URL)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "seed jobs:\t%d\n", len(p.Seeds))
+	fmt.Fprintf(tw, "estimated places:\t%d\n", p.EstimatedPlaces)
+	fmt.Fprintf(tw, "estimated jobs:\t%d\n", p.EstimatedJobs)
+	fmt.Fprintf(tw, "estimated duration:\t%s\n", time.Duration(p.EstimatedSeconds)*time.Second)
+
+	return tw.Flush()
+}
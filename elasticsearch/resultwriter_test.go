@@ -0,0 +1,72 @@
+package elasticsearch_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/elasticsearch"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_ResultWriterLocation(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		docs []map[string]any
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			// the index is created
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+
+		// the lines alternate between the action and the document
+		for i := 0; scanner.Scan(); i++ {
+			if i%2 == 0 {
+				continue
+			}
+
+			var doc map[string]any
+
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &doc))
+
+			docs = append(docs, doc)
+		}
+
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer srv.Close()
+
+	writer, err := elasticsearch.NewResultWriter(srv.URL, elasticsearch.WithIndex("places"))
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Cid: "1", Title: "Cafe", Latitude: 52.52, Longitude: 13.405}}
+	in <- scrapemate.Result{Data: &gmaps.Entry{Cid: "2", Title: "Nowhere"}}
+	close(in)
+
+	require.NoError(t, writer.Run(context.Background(), in))
+	require.Len(t, docs, 2)
+
+	require.Equal(t, "Cafe", docs[0]["title"])
+	require.Equal(t, map[string]any{"lat": 52.52, "lon": 13.405}, docs[0]["location"])
+
+	require.Equal(t, "Nowhere", docs[1]["title"])
+	require.NotContains(t, docs[1], "location")
+}
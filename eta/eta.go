@@ -0,0 +1,144 @@
+// Package eta estimates the duration of the jobs from the timings of the
+// jobs that completed before them.
+package eta
+
+import "time"
+
+// Window is the number of completed jobs the model averages.
+const Window = 20
+
+// The model of a scraper without completed jobs: a keyword takes three
+// minutes, a search of one minute and 20 places of 6 seconds each at the
+// default depth of 10.
+const (
+	defaultSecondsPerSeed        = 60
+	defaultSecondsPerPlace       = 6
+	defaultSeedsPerKeyword       = 1
+	defaultPlacesPerSeedPerDepth = 2
+)
+
+// Timing is the breakdown of the duration of a completed job. The seeds
+// are the searches of the keywords, split into tiles or fast mode areas,
+// and the search ends when the last seed completes. Depth is the scroll
+// depth of the searches, the places a search finds grow with it.
+type Timing struct {
+	Keywords        int     `json:"keywords"`
+	Seeds           int     `json:"seeds"`
+	Depth           int     `json:"depth,omitempty"`
+	Places          int     `json:"places"`
+	SearchSeconds   float64 `json:"search_seconds"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	SecondsPerSeed  float64 `json:"seconds_per_seed"`
+	SecondsPerPlace float64 `json:"seconds_per_place"`
+}
+
+// NewTiming returns the timing of a job whose search took search and the
+// whole job duration. The seconds of the places are the ones after the
+// search, the places found while it runs are scraped in parallel.
+func NewTiming(keywords, seeds, depth, places int, search, duration time.Duration) Timing {
+	ans := Timing{
+		Keywords:        keywords,
+		Seeds:           seeds,
+		Depth:           depth,
+		Places:          places,
+		SearchSeconds:   search.Seconds(),
+		DurationSeconds: duration.Seconds(),
+	}
+
+	if seeds > 0 {
+		ans.SecondsPerSeed = ans.SearchSeconds / float64(seeds)
+	}
+
+	if places > 0 {
+		ans.SecondsPerPlace = max(ans.DurationSeconds-ans.SearchSeconds, 0) / float64(places)
+	}
+
+	return ans
+}
+
+// Model is the rolling average of the timings of the last completed jobs.
+type Model struct {
+	SecondsPerSeed  float64 `json:"seconds_per_seed"`
+	SecondsPerPlace float64 `json:"seconds_per_place"`
+	SeedsPerKeyword float64 `json:"seeds_per_keyword"`
+	// PlacesPerSeedPerDepth are the places a search finds per unit of
+	// scroll depth.
+	PlacesPerSeedPerDepth float64 `json:"places_per_seed_per_depth"`
+	// Jobs is the number of jobs averaged, the defaults are used without
+	// them.
+	Jobs int `json:"jobs"`
+}
+
+// New returns the model of the timings, the most recent first. Only the
+// first Window timings are averaged.
+func New(timings []Timing) Model {
+	var seed, place, seedsPerKeyword, placesPerSeedPerDepth average
+
+	timings = timings[:min(len(timings), Window)]
+
+	for i := range timings {
+		t := &timings[i]
+
+		if t.Seeds > 0 {
+			seed.add(t.SecondsPerSeed)
+		}
+
+		// the timings stored before the depth was recorded are skipped
+		if t.Seeds > 0 && t.Depth > 0 {
+			placesPerSeedPerDepth.add(float64(t.Places) / float64(t.Seeds*t.Depth))
+		}
+
+		if t.Places > 0 {
+			place.add(t.SecondsPerPlace)
+		}
+
+		if t.Keywords > 0 {
+			seedsPerKeyword.add(float64(t.Seeds) / float64(t.Keywords))
+		}
+	}
+
+	return Model{
+		SecondsPerSeed:        seed.or(defaultSecondsPerSeed),
+		SecondsPerPlace:       place.or(defaultSecondsPerPlace),
+		SeedsPerKeyword:       seedsPerKeyword.or(defaultSeedsPerKeyword),
+		PlacesPerSeedPerDepth: placesPerSeedPerDepth.or(defaultPlacesPerSeedPerDepth),
+		Jobs:                  len(timings),
+	}
+}
+
+// Estimate returns the duration of a job with the keywords searched at the
+// depth and the places to refresh. maxPlaces caps the places the keywords
+// find, unless 0.
+func (m Model) Estimate(keywords, depth, places, maxPlaces int) time.Duration {
+	return m.EstimateSeeds(float64(keywords)*m.SeedsPerKeyword, depth, places, maxPlaces)
+}
+
+// EstimateSeeds is Estimate for a job whose seeds are known.
+func (m Model) EstimateSeeds(seeds float64, depth, places, maxPlaces int) time.Duration {
+	found := seeds * float64(max(depth, 1)) * m.PlacesPerSeedPerDepth
+	if maxPlaces > 0 {
+		found = min(found, float64(maxPlaces))
+	}
+
+	seconds := seeds*m.SecondsPerSeed + (found+float64(places))*m.SecondsPerPlace
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+type average struct {
+	sum   float64
+	count int
+}
+
+func (a *average) add(v float64) {
+	a.sum += v
+	a.count++
+}
+
+func (a *average) or(fallback float64) float64 {
+	if a.count == 0 {
+		return fallback
+	}
+
+	return a.sum / float64(a.count)
+}
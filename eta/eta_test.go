@@ -0,0 +1,39 @@
+package eta_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/eta"
+)
+
+func Test_Model(t *testing.T) {
+	// without completed jobs a keyword takes three minutes
+	m := eta.New(nil)
+	require.Zero(t, m.Jobs)
+	require.Equal(t, 3*time.Minute, m.Estimate(1, 10, 0, 0))
+
+	timing := eta.NewTiming(2, 4, 5, 40, 2*time.Minute, 4*time.Minute)
+	require.InDelta(t, 30, timing.SecondsPerSeed, 0.001)
+	require.InDelta(t, 3, timing.SecondsPerPlace, 0.001)
+
+	// a refresh has no seeds
+	refresh := eta.NewTiming(0, 0, 0, 10, 0, 10*time.Second)
+	require.InDelta(t, 1, refresh.SecondsPerPlace, 0.001)
+
+	m = eta.New([]eta.Timing{timing, refresh})
+	require.Equal(t, 2, m.Jobs)
+	require.InDelta(t, 30, m.SecondsPerSeed, 0.001)
+	require.InDelta(t, 2, m.SecondsPerPlace, 0.001)
+	require.InDelta(t, 2, m.SeedsPerKeyword, 0.001)
+	require.InDelta(t, 2, m.PlacesPerSeedPerDepth, 0.001)
+
+	// 2 seeds of 30 seconds and 20 places of 2 seconds
+	require.Equal(t, 100*time.Second, m.Estimate(1, 5, 0, 0))
+	// twice the depth finds twice the places
+	require.Equal(t, 140*time.Second, m.Estimate(1, 10, 0, 0))
+	// the places are capped by the max results, the refreshed ones added
+	require.Equal(t, 60*time.Second+15*2*time.Second, m.Estimate(1, 5, 5, 10))
+}
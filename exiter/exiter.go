@@ -9,17 +9,26 @@ import (
 type Exiter interface {
 	SetSeedCount(int)
 	SetCancelFunc(context.CancelFunc)
+	SetMaxPlaces(int)
 	IncrSeedCompleted(int)
+	IncrSeedRetried(int)
+	IncrSeedFailed(int)
+	SeedRetries() (retried, failed int)
 	IncrPlacesFound(int)
 	IncrPlacesCompleted(int)
+	SearchDoneAt() time.Time
 	Run(context.Context)
 }
 
 type exiter struct {
 	seedCount       int
 	seedCompleted   int
+	seedRetried     int
+	seedFailed      int
 	placesFound     int
 	placesCompleted int
+	maxPlaces       int
+	searchDoneAt    time.Time
 
 	mu         *sync.Mutex
 	cancelFunc context.CancelFunc
@@ -45,11 +54,65 @@ func (e *exiter) SetCancelFunc(fn context.CancelFunc) {
 	e.cancelFunc = fn
 }
 
+// SetMaxPlaces stops the scraping once val places are completed.
+// Zero means no limit.
+func (e *exiter) SetMaxPlaces(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.maxPlaces = val
+}
+
 func (e *exiter) IncrSeedCompleted(val int) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	e.seedCompleted += val
+	e.searchDone()
+}
+
+// IncrSeedRetried counts the seeds that are enqueued again after failing.
+func (e *exiter) IncrSeedRetried(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.seedRetried += val
+}
+
+// IncrSeedFailed counts the seeds that failed without retries left, they
+// are done like the completed ones.
+func (e *exiter) IncrSeedFailed(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.seedFailed += val
+	e.searchDone()
+}
+
+// searchDone records when the last seed is done. It must be called with
+// the lock held.
+func (e *exiter) searchDone() {
+	if e.searchDoneAt.IsZero() && e.seedCount > 0 && e.seedCompleted+e.seedFailed >= e.seedCount {
+		e.searchDoneAt = time.Now()
+	}
+}
+
+// SearchDoneAt returns when the last seed was done, zero while the seeds
+// run.
+func (e *exiter) SearchDoneAt() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.searchDoneAt
+}
+
+// SeedRetries returns the number of retries of the seeds and of the seeds
+// that failed.
+func (e *exiter) SeedRetries() (retried, failed int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.seedRetried, e.seedFailed
 }
 
 func (e *exiter) IncrPlacesFound(val int) {
@@ -88,7 +151,11 @@ func (e *exiter) isDone() bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if e.seedCompleted != e.seedCount {
+	if e.maxPlaces > 0 && e.placesCompleted >= e.maxPlaces {
+		return true
+	}
+
+	if e.seedCompleted+e.seedFailed != e.seedCount {
 		return false
 	}
 
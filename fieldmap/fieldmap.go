@@ -0,0 +1,308 @@
+// Package fieldmap maps the fields of a place to the columns or properties
+// of a destination, with simple transforms, as configured by the user for
+// every integration.
+package fieldmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// The integrations that use a mapping, by the name of their writer.
+var integrations = []string{"sheets", "kafka", "nats"}
+
+// Field maps a field of the place to a column of a destination.
+type Field struct {
+	// Column is the name of the column or property in the destination.
+	Column string `json:"column"`
+	// Source is the JSON name of the field of the place, e.g. phone, with
+	// dots for the nested fields, e.g. complete_address.city.
+	Source string `json:"source"`
+	// Transforms are applied in order: strip_plus, trim, lower, upper and
+	// join, which joins the values of a list with ", " or with the
+	// separator of join:<separator>.
+	Transforms []string `json:"transforms,omitempty"`
+}
+
+// Mapping is the ordered list of the columns of a destination.
+type Mapping []Field
+
+// Config is the mapping of every integration, keyed by its name.
+type Config map[string]Mapping
+
+// Load reads the configuration of a JSON file.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ans, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return ans, nil
+}
+
+// Parse reads a JSON configuration like
+//
+//	{"sheets": [{"column": "Phone", "source": "phone", "transforms": ["strip_plus"]}]}
+//
+// The integrations, sources and transforms are validated.
+func Parse(data []byte) (Config, error) {
+	var ans Config
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&ans); err != nil {
+		return nil, fmt.Errorf("invalid field mapping: %w", err)
+	}
+
+	sources := sourceFields()
+
+	for integration, mapping := range ans {
+		if !slices.Contains(integrations, integration) {
+			return nil, fmt.Errorf("unknown integration %q, the mappings apply to %s", integration, strings.Join(integrations, ", "))
+		}
+
+		if err := mapping.validate(sources); err != nil {
+			return nil, fmt.Errorf("%s: %w", integration, err)
+		}
+	}
+
+	return ans, nil
+}
+
+func (m Mapping) validate(sources map[string]bool) error {
+	if len(m) == 0 {
+		return errors.New("the mapping has no fields")
+	}
+
+	columns := make(map[string]bool, len(m))
+
+	for i, f := range m {
+		if f.Column == "" {
+			return fmt.Errorf("field %d has no column", i)
+		}
+
+		if columns[f.Column] {
+			return fmt.Errorf("column %q is mapped twice", f.Column)
+		}
+
+		columns[f.Column] = true
+
+		root, _, _ := strings.Cut(f.Source, ".")
+		if !sources[root] {
+			return fmt.Errorf("column %q: unknown source %q", f.Column, f.Source)
+		}
+
+		for _, t := range f.Transforms {
+			name, _, _ := strings.Cut(t, ":")
+
+			switch name {
+			case "strip_plus", "trim", "lower", "upper", "join":
+			default:
+				return fmt.Errorf("column %q: unknown transform %q", f.Column, t)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Columns returns the names of the columns.
+func (m Mapping) Columns() []string {
+	ans := make([]string, len(m))
+
+	for i := range m {
+		ans[i] = m[i].Column
+	}
+
+	return ans
+}
+
+// Values returns the values of the columns for the entry. A missing field is
+// nil.
+func (m Mapping) Values(entry *gmaps.Entry) ([]any, error) {
+	doc, err := document(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]any, len(m))
+
+	for i := range m {
+		ans[i] = m[i].value(doc)
+	}
+
+	return ans, nil
+}
+
+// Strings returns the values of the columns as text, for the destinations
+// with cells: lists and objects are JSON encoded and a missing field is
+// empty.
+func (m Mapping) Strings(entry *gmaps.Entry) ([]string, error) {
+	values, err := m.Values(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]string, len(values))
+
+	for i, v := range values {
+		ans[i] = text(v)
+	}
+
+	return ans, nil
+}
+
+// Object returns the entry as an object with the columns as properties.
+func (m Mapping) Object(entry *gmaps.Entry) (map[string]any, error) {
+	values, err := m.Values(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make(map[string]any, len(values))
+
+	for i, v := range values {
+		ans[m[i].Column] = v
+	}
+
+	return ans, nil
+}
+
+func (f *Field) value(doc map[string]any) any {
+	var v any = doc
+
+	for _, key := range strings.Split(f.Source, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		v = obj[key]
+	}
+
+	for _, t := range f.Transforms {
+		v = transform(t, v)
+	}
+
+	return v
+}
+
+func transform(t string, v any) any {
+	name, arg, hasArg := strings.Cut(t, ":")
+
+	if name == "join" {
+		list, ok := v.([]any)
+		if !ok {
+			return v
+		}
+
+		sep := ", "
+		if hasArg {
+			sep = arg
+		}
+
+		parts := make([]string, 0, len(list))
+
+		for _, item := range list {
+			parts = append(parts, text(item))
+		}
+
+		return strings.Join(parts, sep)
+	}
+
+	switch s := v.(type) {
+	case string:
+		return transformString(name, s)
+	case []any:
+		ans := make([]any, len(s))
+
+		for i := range s {
+			ans[i] = transform(t, s[i])
+		}
+
+		return ans
+	default:
+		return v
+	}
+}
+
+func transformString(name, s string) string {
+	switch name {
+	case "strip_plus":
+		return strings.ReplaceAll(s, "+", "")
+	case "trim":
+		return strings.TrimSpace(s)
+	case "lower":
+		return strings.ToLower(s)
+	case "upper":
+		return strings.ToUpper(s)
+	default:
+		return s
+	}
+}
+
+func text(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(s)
+	default:
+		data, err := json.Marshal(s)
+		if err != nil {
+			return fmt.Sprint(s)
+		}
+
+		return string(data)
+	}
+}
+
+// document returns the entry as decoded JSON, so that the sources are the
+// names of its JSON fields.
+func document(entry *gmaps.Entry) (map[string]any, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var ans map[string]any
+
+	if err := json.Unmarshal(data, &ans); err != nil {
+		return nil, err
+	}
+
+	return ans, nil
+}
+
+func sourceFields() map[string]bool {
+	doc, err := document(&gmaps.Entry{})
+	if err != nil {
+		return nil
+	}
+
+	ans := make(map[string]bool, len(doc))
+
+	for k := range doc {
+		ans[k] = true
+	}
+
+	return ans
+}
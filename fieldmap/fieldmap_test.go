@@ -0,0 +1,66 @@
+package fieldmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/fieldmap"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_Mapping(t *testing.T) {
+	cfg, err := fieldmap.Parse([]byte(`{
+		"sheets": [
+			{"column": "Name", "source": "title", "transforms": ["upper"]},
+			{"column": "Phone", "source": "phone", "transforms": ["strip_plus"]},
+			{"column": "Categories", "source": "categories", "transforms": ["join:; "]},
+			{"column": "City", "source": "complete_address.city"},
+			{"column": "Rating", "source": "review_rating"},
+			{"column": "Emails", "source": "emails"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	entry := gmaps.Entry{
+		Title:           "Dentist",
+		Phone:           "+30 2310 123456",
+		Categories:      []string{"Dentist", "Orthodontist"},
+		CompleteAddress: gmaps.Address{City: "Thessaloniki"},
+		ReviewRating:    4.5,
+		Emails:          []string{"info@example.com"},
+	}
+
+	mapping := cfg["sheets"]
+
+	require.Equal(t, []string{"Name", "Phone", "Categories", "City", "Rating", "Emails"}, mapping.Columns())
+
+	values, err := mapping.Strings(&entry)
+	require.NoError(t, err)
+	require.Equal(t, []string{"DENTIST", "30 2310 123456", "Dentist; Orthodontist", "Thessaloniki", "4.5", `["info@example.com"]`}, values)
+
+	obj, err := mapping.Object(&entry)
+	require.NoError(t, err)
+	require.Equal(t, "Dentist; Orthodontist", obj["Categories"])
+	require.InDelta(t, 4.5, obj["Rating"], 0.001)
+}
+
+func Test_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown integration", `{"airtable": [{"column": "Name", "source": "title"}]}`},
+		{"unknown source", `{"sheets": [{"column": "Name", "source": "name"}]}`},
+		{"unknown transform", `{"kafka": [{"column": "Name", "source": "title", "transforms": ["reverse"]}]}`},
+		{"duplicate column", `{"nats": [{"column": "Name", "source": "title"}, {"column": "Name", "source": "phone"}]}`},
+		{"unknown property", `{"sheets": [{"column": "Name", "field": "title"}]}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fieldmap.Parse([]byte(tc.data))
+			require.Error(t, err)
+		})
+	}
+}
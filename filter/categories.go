@@ -0,0 +1,79 @@
+package filter
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+)
+
+// Categories matches the categories of the places against allow and deny
+// lists. The patterns are case insensitive and * matches any text, e.g.
+// "gym", "*therap*" or "supplement*".
+type Categories struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+func NewCategories(include, exclude []string) *Categories {
+	return &Categories{
+		include: compilePatterns(include),
+		exclude: compilePatterns(exclude),
+	}
+}
+
+// Match reports whether the place has a category of the allow list, when
+// there is one, and none of the deny list.
+func (c *Categories) Match(entry *gmaps.Entry) bool {
+	categories := entry.Categories
+	if len(categories) == 0 && entry.Category != "" {
+		categories = []string{entry.Category}
+	}
+
+	if len(c.include) > 0 && !anyMatch(c.include, categories) {
+		return false
+	}
+
+	return !anyMatch(c.exclude, categories)
+}
+
+// EntryFunc skips the places that do not match.
+func (c *Categories) EntryFunc() scraper.EntryFunc {
+	return func(_ context.Context, entry *gmaps.Entry) error {
+		if !c.Match(entry) {
+			return scraper.ErrSkip
+		}
+
+		return nil
+	}
+}
+
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	ans := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		expr := strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*")
+		ans = append(ans, regexp.MustCompile(`(?i)^`+expr+`$`))
+	}
+
+	return ans
+}
+
+func anyMatch(patterns []*regexp.Regexp, categories []string) bool {
+	for _, re := range patterns {
+		for _, category := range categories {
+			if re.MatchString(strings.TrimSpace(category)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
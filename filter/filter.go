@@ -0,0 +1,180 @@
+// Package filter drops the places that do not match the filter
+// expression of a job before they are written.
+//
+// The expressions are written in CEL (https://cel.dev) and the variables
+// are the csv columns of a place, e.g.
+//
+//	review_count >= 10 && rating < 4.0 && website == ""
+package filter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/gosom/google-maps-scraper/geocode"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+)
+
+type variable struct {
+	name  string
+	typ   *cel.Type
+	value func(*gmaps.Entry) any
+}
+
+var variables = []variable{
+	{"title", cel.StringType, func(e *gmaps.Entry) any { return e.Title }},
+	{"category", cel.StringType, func(e *gmaps.Entry) any { return e.Category }},
+	{"categories", cel.ListType(cel.StringType), func(e *gmaps.Entry) any { return e.Categories }},
+	{"address", cel.StringType, func(e *gmaps.Entry) any { return e.Address }},
+	{"website", cel.StringType, func(e *gmaps.Entry) any { return e.WebSite }},
+	{"phone", cel.StringType, func(e *gmaps.Entry) any { return e.Phone }},
+	{"plus_code", cel.StringType, func(e *gmaps.Entry) any { return e.PlusCode }},
+	{"review_count", cel.IntType, func(e *gmaps.Entry) any { return e.ReviewCount }},
+	{"review_rating", cel.DoubleType, func(e *gmaps.Entry) any { return e.ReviewRating }},
+	{"rating", cel.DoubleType, func(e *gmaps.Entry) any { return e.ReviewRating }},
+	{"latitude", cel.DoubleType, func(e *gmaps.Entry) any { return e.Latitude }},
+	{"longitude", cel.DoubleType, func(e *gmaps.Entry) any { return e.Longitude }},
+	{"cid", cel.StringType, func(e *gmaps.Entry) any { return e.Cid }},
+	{"status", cel.StringType, func(e *gmaps.Entry) any { return e.Status }},
+	{"timezone", cel.StringType, func(e *gmaps.Entry) any { return e.Timezone }},
+	{"price_range", cel.StringType, func(e *gmaps.Entry) any { return e.PriceRange }},
+	{"emails", cel.ListType(cel.StringType), func(e *gmaps.Entry) any { return e.Emails }},
+	{"open_now", cel.BoolType, func(e *gmaps.Entry) any { return e.OpenNow }},
+	{"business_status", cel.StringType, func(e *gmaps.Entry) any { return e.BusinessStatus }},
+	{"claimed", cel.BoolType, func(e *gmaps.Entry) any { return e.Claimed }},
+	{"owner_badges", cel.ListType(cel.StringType), func(e *gmaps.Entry) any { return e.OwnerBadges }},
+	{"is_sponsored", cel.BoolType, func(e *gmaps.Entry) any { return e.IsSponsored }},
+	{"serp_position", cel.IntType, func(e *gmaps.Entry) any { return e.SerpPosition }},
+	{"input_keyword", cel.StringType, func(e *gmaps.Entry) any { return e.InputKeyword }},
+}
+
+var env *cel.Env
+
+func init() {
+	opts := []cel.EnvOption{
+		// rating < 4 compares a double with an int
+		cel.CrossTypeNumericComparisons(true),
+	}
+
+	for _, v := range variables {
+		opts = append(opts, cel.Variable(v.name, v.typ))
+	}
+
+	var err error
+
+	env, err = cel.NewEnv(opts...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+type Filter struct {
+	prg cel.Program
+}
+
+// New compiles expr, which must evaluate to a bool.
+func New(expr string) (*Filter, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("invalid filter: %w", iss.Err())
+	}
+
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("invalid filter: it returns %s instead of bool", ast.OutputType())
+	}
+
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	return &Filter{prg: prg}, nil
+}
+
+// Match reports whether the entry matches the filter.
+func (f *Filter) Match(entry *gmaps.Entry) (bool, error) {
+	activation := make(map[string]any, len(variables))
+
+	for _, v := range variables {
+		activation[v.name] = v.value(entry)
+	}
+
+	out, _, err := f.prg.Eval(activation)
+	if err != nil {
+		return false, err
+	}
+
+	ans, ok := out.Value().(bool)
+	if !ok {
+		return false, errors.New("filter did not return a bool")
+	}
+
+	return ans, nil
+}
+
+// EntryFunc skips the places that do not match the filter. A place the
+// filter fails to evaluate is skipped too.
+func (f *Filter) EntryFunc() scraper.EntryFunc {
+	return func(_ context.Context, entry *gmaps.Entry) error {
+		ok, err := f.Match(entry)
+		if err != nil || !ok {
+			return scraper.ErrSkip
+		}
+
+		return nil
+	}
+}
+
+// Options are the filtering options of a job.
+type Options struct {
+	Expr string
+	// IncludeCategories keeps the places with a category that matches one
+	// of the patterns and ExcludeCategories drops them. See Categories.
+	IncludeCategories []string
+	ExcludeCategories []string
+	Quality
+	// VerifyAddress checks the address of the places that pass the
+	// filters against their coordinates with the reverse geocoding service
+	// at GeocodeURL, geocode.DefaultURL when empty.
+	VerifyAddress bool
+	GeocodeURL    string
+	// MaxResults is the maximum number of places that are written, zero
+	// means no limit. The places are counted after the filters.
+	MaxResults int
+}
+
+// EntryFunc returns the function that skips the places that do not pass
+// the options, or nil when there is nothing to filter or verify.
+func (o *Options) EntryFunc() (scraper.EntryFunc, error) {
+	var fns []scraper.EntryFunc
+
+	if o.Quality != (Quality{}) {
+		fns = append(fns, o.Quality.EntryFunc())
+	}
+
+	if len(o.IncludeCategories) > 0 || len(o.ExcludeCategories) > 0 {
+		fns = append(fns, NewCategories(o.IncludeCategories, o.ExcludeCategories).EntryFunc())
+	}
+
+	if o.Expr != "" {
+		f, err := New(o.Expr)
+		if err != nil {
+			return nil, err
+		}
+
+		fns = append(fns, f.EntryFunc())
+	}
+
+	// the places over the limit are not verified
+	fns = append(fns, scraper.Limit(o.MaxResults))
+
+	if o.VerifyAddress {
+		fns = append(fns, geocode.NewVerifier(geocode.WithURL(o.GeocodeURL)).EntryFunc())
+	}
+
+	return scraper.Chain(fns...), nil
+}
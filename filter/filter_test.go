@@ -0,0 +1,72 @@
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/filter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_Filter(t *testing.T) {
+	entry := gmaps.Entry{
+		Title:        "Dentist",
+		Categories:   []string{"Dentist", "Orthodontist"},
+		ReviewCount:  25,
+		ReviewRating: 3.8,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`review_count >= 10 && rating < 4.0 && website == ""`, true},
+		{`review_count >= 10 && rating < 4`, true},
+		{`rating >= 4.0`, false},
+		{`"Orthodontist" in categories && size(emails) == 0`, true},
+		{`title.contains("Clinic") || claimed`, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			f, err := filter.New(tc.expr)
+			require.NoError(t, err)
+
+			got, err := f.Match(&entry)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	for _, expr := range []string{`rating >`, `review_count + 1`, `unknown == 1`} {
+		_, err := filter.New(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func Test_Categories(t *testing.T) {
+	gym := &gmaps.Entry{Categories: []string{"Gym", "Fitness center"}}
+	therapist := &gmaps.Entry{Categories: []string{"Physical therapist"}}
+	shop := &gmaps.Entry{Category: "Supplement store"}
+
+	c := filter.NewCategories([]string{"gym", "*FITNESS*", "physical*"}, []string{"*therapist"})
+
+	require.True(t, c.Match(gym))
+	require.False(t, c.Match(therapist))
+	require.False(t, c.Match(shop))
+
+	c = filter.NewCategories(nil, []string{"supplement*"})
+
+	require.True(t, c.Match(gym))
+	require.False(t, c.Match(shop))
+}
+
+func Test_Quality(t *testing.T) {
+	entry := &gmaps.Entry{Phone: "+351 21 000 0000", ReviewCount: 12, ReviewRating: 4.2}
+
+	require.True(t, (&filter.Quality{RequirePhone: true, MinRating: 4, MinReviews: 10}).Match(entry))
+	require.False(t, (&filter.Quality{RequireWebsite: true}).Match(entry))
+	require.False(t, (&filter.Quality{MinRating: 4.5}).Match(entry))
+	require.False(t, (&filter.Quality{MinReviews: 20}).Match(entry))
+}
@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+)
+
+// Quality are the minimum data a place must have to be written.
+type Quality struct {
+	RequirePhone   bool
+	RequireWebsite bool
+	MinRating      float64
+	MinReviews     int
+}
+
+// Match reports whether the place passes the thresholds.
+func (q *Quality) Match(entry *gmaps.Entry) bool {
+	switch {
+	case q.RequirePhone && entry.Phone == "":
+		return false
+	case q.RequireWebsite && entry.WebSite == "":
+		return false
+	case entry.ReviewRating < q.MinRating:
+		return false
+	case entry.ReviewCount < q.MinReviews:
+		return false
+	default:
+		return true
+	}
+}
+
+// EntryFunc skips the places that do not pass the thresholds.
+func (q *Quality) EntryFunc() scraper.EntryFunc {
+	return func(_ context.Context, entry *gmaps.Entry) error {
+		if !q.Match(entry) {
+			return scraper.ErrSkip
+		}
+
+		return nil
+	}
+}
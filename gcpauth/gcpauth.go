@@ -0,0 +1,157 @@
+package gcpauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const httpTimeout = 30 * time.Second
+
+// TokenSource fetches and caches OAuth2 access tokens with the credentials
+// of a JSON key file, or the application default credentials, e.g. the
+// metadata server when running on Google Cloud (Cloud Run, GCE, GKE).
+type TokenSource struct {
+	projectID string
+	// newSource returns a source with an empty cache
+	newSource func() (oauth2.TokenSource, error)
+
+	mu     sync.Mutex
+	source oauth2.TokenSource
+}
+
+// FromFile reads a credentials file, usually a service account key. If path
+// is empty the GOOGLE_APPLICATION_CREDENTIALS environment variable is used.
+func FromFile(path string, scopes ...string) (*TokenSource, error) {
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+
+	if path == "" {
+		return nil, errors.New("missing service account credentials file")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(data, scopes...)
+}
+
+// Default uses the credentials file if path is set and the application
+// default credentials otherwise.
+func Default(path string, scopes ...string) (*TokenSource, error) {
+	if path != "" {
+		return FromFile(path, scopes...)
+	}
+
+	find := func() (*google.Credentials, error) {
+		return google.FindDefaultCredentials(httpContext(), scopes...)
+	}
+
+	return newTokenSource(find)
+}
+
+// New returns a token source for the credentials JSON.
+func New(credentialsJSON []byte, scopes ...string) (*TokenSource, error) {
+	var sa struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+
+	if err := json.Unmarshal(credentialsJSON, &sa); err != nil {
+		return nil, fmt.Errorf("invalid credentials json: %w", err)
+	}
+
+	// the key is only parsed when the first token is requested
+	if sa.Type == "service_account" && (sa.ClientEmail == "" || sa.PrivateKey == "") {
+		return nil, errors.New("service account json must contain client_email and private_key")
+	}
+
+	parse := func() (*google.Credentials, error) {
+		return google.CredentialsFromJSON(httpContext(), credentialsJSON, scopes...)
+	}
+
+	return newTokenSource(parse)
+}
+
+func newTokenSource(credentials func() (*google.Credentials, error)) (*TokenSource, error) {
+	creds, err := credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	ans := TokenSource{
+		projectID: creds.ProjectID,
+		source:    creds.TokenSource,
+		newSource: func() (oauth2.TokenSource, error) {
+			creds, err := credentials()
+			if err != nil {
+				return nil, err
+			}
+
+			return creds.TokenSource, nil
+		},
+	}
+
+	return &ans, nil
+}
+
+// httpContext makes the token requests time out.
+func httpContext() context.Context {
+	return context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Timeout: httpTimeout})
+}
+
+// ProjectID returns the project of the credentials, empty when they
+// do not have one.
+func (ts *TokenSource) ProjectID() string {
+	return ts.projectID
+}
+
+// Token returns a valid access token, refreshing it when it is about to expire.
+func (ts *TokenSource) Token() (*oauth2.Token, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.source == nil {
+		source, err := ts.newSource()
+		if err != nil {
+			return nil, err
+		}
+
+		ts.source = source
+	}
+
+	return ts.source.Token()
+}
+
+// Invalidate drops the cached token, so that the next request fetches a
+// new one. It is used when an API rejects a token before its expiry.
+func (ts *TokenSource) Invalidate() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.source = nil
+}
+
+// Authorize sets the Authorization header of the request.
+func (ts *TokenSource) Authorize(req *http.Request) error {
+	token, err := ts.Token()
+	if err != nil {
+		return err
+	}
+
+	token.SetAuthHeader(req)
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package gcpauth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gcpauth"
+)
+
+func serviceAccount(t *testing.T, tokenURI string) []byte {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "my-project",
+		"private_key_id": "key-id",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"client_email":   "scraper@my-project.iam.gserviceaccount.com",
+		"token_uri":      tokenURI,
+	})
+	require.NoError(t, err)
+
+	return data
+}
+
+func Test_TokenSource(t *testing.T) {
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+		require.NotEmpty(t, r.Form.Get("assertion"))
+
+		n := requests.Add(1)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 3600}`, n)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, serviceAccount(t, srv.URL), 0o600))
+
+	ts, err := gcpauth.FromFile(path, "https://www.googleapis.com/auth/bigquery")
+	require.NoError(t, err)
+	require.Equal(t, "my-project", ts.ProjectID())
+
+	authorization := func() string {
+		req := httptest.NewRequest(http.MethodGet, "https://example.com", http.NoBody)
+		require.NoError(t, ts.Authorize(req))
+
+		return req.Header.Get("Authorization")
+	}
+
+	// the token is cached until it expires
+	require.Equal(t, "Bearer token-1", authorization())
+	require.Equal(t, "Bearer token-1", authorization())
+	require.EqualValues(t, 1, requests.Load())
+
+	ts.Invalidate()
+
+	require.Equal(t, "Bearer token-2", authorization())
+	require.EqualValues(t, 2, requests.Load())
+}
+
+func Test_FromFile(t *testing.T) {
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+
+	_, err := gcpauth.FromFile("")
+	require.Error(t, err)
+
+	_, err = gcpauth.New([]byte(`{"type": "service_account"}`))
+	require.Error(t, err)
+}
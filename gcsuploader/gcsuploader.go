@@ -0,0 +1,69 @@
+package gcsuploader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gcpauth"
+)
+
+const (
+	uploadURL    = "https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s"
+	storageScope = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+// Uploader uploads objects to Google Cloud Storage.
+type Uploader struct {
+	ts     *gcpauth.TokenSource
+	client *http.Client
+}
+
+// New creates an uploader using the service account at credentialsFile,
+// or the metadata server when it is empty (e.g. in Cloud Run).
+func New(credentialsFile string) (*Uploader, error) {
+	ts, err := gcpauth.Default(credentialsFile, storageScope)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := Uploader{
+		ts:     ts,
+		client: &http.Client{Timeout: 10 * time.Minute},
+	}
+
+	return &ans, nil
+}
+
+func (u *Uploader) Upload(ctx context.Context, bucketName, key string, body io.Reader) error {
+	endpoint := fmt.Sprintf(uploadURL, url.PathEscape(bucketName), url.QueryEscape(key))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/csv")
+
+	if err := u.ts.Authorize(req); err != nil {
+		return err
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+		return fmt.Errorf("gcs upload of %s failed with status %d: %s", key, resp.StatusCode, string(msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+// Package geocode verifies the address of the places against their
+// coordinates with a reverse geocoding service that implements the
+// Nominatim API (https://nominatim.org/release-docs/develop/api/Reverse/).
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+)
+
+// DefaultURL is the public Nominatim instance. Its usage policy allows
+// one request per second.
+const DefaultURL = "https://nominatim.openstreetmap.org"
+
+const userAgent = "google-maps-scraper (https://github.com/gosom/google-maps-scraper)"
+
+type Option func(*Verifier)
+
+// WithURL sets the base url of the reverse geocoding service.
+func WithURL(u string) Option {
+	return func(v *Verifier) {
+		if u != "" {
+			v.url = strings.TrimSuffix(u, "/")
+		}
+	}
+}
+
+// WithInterval sets the minimum time between two requests. It is one
+// second for DefaultURL and none for the other services by default.
+func WithInterval(d time.Duration) Option {
+	return func(v *Verifier) {
+		v.interval = d
+	}
+}
+
+func WithHTTPClient(c *http.Client) Option {
+	return func(v *Verifier) {
+		v.client = c
+	}
+}
+
+type Verifier struct {
+	url      string
+	interval time.Duration
+	client   *http.Client
+	throttle *throttle
+}
+
+type throttle struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// throttles are shared by the verifiers of the same service, so that the
+// concurrent jobs do not exceed its rate limit together.
+var throttles sync.Map
+
+func NewVerifier(opts ...Option) *Verifier {
+	v := Verifier{
+		url:      DefaultURL,
+		interval: -1,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(&v)
+	}
+
+	if v.interval < 0 {
+		v.interval = 0
+		if v.url == DefaultURL {
+			v.interval = time.Second
+		}
+	}
+
+	t, _ := throttles.LoadOrStore(v.url, &throttle{})
+	v.throttle = t.(*throttle) //nolint:errcheck // the map only holds throttles
+
+	return &v
+}
+
+type address struct {
+	CountryCode  string `json:"country_code"`
+	Postcode     string `json:"postcode"`
+	City         string `json:"city"`
+	Town         string `json:"town"`
+	Village      string `json:"village"`
+	Municipality string `json:"municipality"`
+}
+
+// Verify reports whether the country, postal code and city of the place
+// match the ones of its coordinates. The parts that one of the two does
+// not have are not compared.
+func (v *Verifier) Verify(ctx context.Context, entry *gmaps.Entry) (bool, error) {
+	addr, err := v.reverse(ctx, entry.Latitude, entry.Longitude, entry.Lang)
+	if err != nil {
+		return false, err
+	}
+
+	ca := entry.CompleteAddress
+
+	if !matches(ca.Country, addr.CountryCode) || !matches(normalizePostcode(ca.PostalCode), normalizePostcode(addr.Postcode)) {
+		return false, nil
+	}
+
+	if ca.City == "" {
+		return true, nil
+	}
+
+	for _, city := range []string{addr.City, addr.Town, addr.Village, addr.Municipality} {
+		if city != "" && strings.EqualFold(city, ca.City) {
+			return true, nil
+		}
+	}
+
+	return addr.City == "" && addr.Town == "" && addr.Village == "" && addr.Municipality == "", nil
+}
+
+// EntryFunc sets the AddressCheck of the places that have coordinates.
+// A failed lookup leaves it empty and does not drop the place.
+func (v *Verifier) EntryFunc() scraper.EntryFunc {
+	return func(ctx context.Context, entry *gmaps.Entry) error {
+		if entry.Latitude == 0 && entry.Longitude == 0 {
+			return nil
+		}
+
+		ok, err := v.Verify(ctx, entry)
+		if err != nil {
+			return nil
+		}
+
+		entry.AddressCheck = gmaps.AddressMismatch
+		if ok {
+			entry.AddressCheck = gmaps.AddressMatch
+		}
+
+		return nil
+	}
+}
+
+func (v *Verifier) reverse(ctx context.Context, lat, lon float64, lang string) (*address, error) {
+	if err := v.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("format", "jsonv2")
+	params.Set("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	params.Set("lon", strconv.FormatFloat(lon, 'f', -1, 64))
+	params.Set("zoom", "18")
+	params.Set("addressdetails", "1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url+"/reverse?"+params.Encode(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	// the names are compared with the ones of the place
+	if lang != "" {
+		req.Header.Set("Accept-Language", lang)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reverse geocoding failed with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Address address `json:"address"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return &body.Address, nil
+}
+
+// wait blocks until interval has passed since the previous request.
+func (v *Verifier) wait(ctx context.Context) error {
+	v.throttle.mu.Lock()
+	defer v.throttle.mu.Unlock()
+
+	if d := time.Until(v.throttle.last.Add(v.interval)); d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+
+	v.throttle.last = time.Now()
+
+	return nil
+}
+
+func matches(a, b string) bool {
+	return a == "" || b == "" || strings.EqualFold(a, b)
+}
+
+func normalizePostcode(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, " ", ""), "-", "")
+}
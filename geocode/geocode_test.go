@@ -0,0 +1,45 @@
+package geocode_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/geocode"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_Verifier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/reverse", r.URL.Path)
+		require.Equal(t, "38.03", r.URL.Query().Get("lat"))
+
+		_, _ = w.Write([]byte(`{"address":{"town":"Ilion","postcode":"131 22","country_code":"gr"}}`))
+	}))
+	defer srv.Close()
+
+	v := geocode.NewVerifier(geocode.WithURL(srv.URL), geocode.WithInterval(0))
+	fn := v.EntryFunc()
+
+	entry := &gmaps.Entry{
+		Latitude:        38.03,
+		Longitude:       23.7,
+		CompleteAddress: gmaps.Address{City: "Ilion", PostalCode: "13122", Country: "GR"},
+	}
+
+	require.NoError(t, fn(context.Background(), entry))
+	require.Equal(t, gmaps.AddressMatch, entry.AddressCheck)
+
+	entry.CompleteAddress.PostalCode = "12137"
+
+	require.NoError(t, fn(context.Background(), entry))
+	require.Equal(t, gmaps.AddressMismatch, entry.AddressCheck)
+
+	noCoordinates := &gmaps.Entry{CompleteAddress: gmaps.Address{City: "Ilion"}}
+
+	require.NoError(t, fn(context.Background(), noCoordinates))
+	require.Empty(t, noCoordinates.AddressCheck)
+}
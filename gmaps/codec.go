@@ -0,0 +1,68 @@
+package gmaps
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/gosom/scrapemate"
+)
+
+// EncodeJob serializes a job so it can be stored in an external queue.
+// It returns the payload type that is needed to decode it.
+func EncodeJob(job scrapemate.IJob) (payloadType string, payload []byte, err error) {
+	var buf bytes.Buffer
+
+	enc := gob.NewEncoder(&buf)
+
+	switch j := job.(type) {
+	case *GmapJob:
+		payloadType = "search"
+		err = enc.Encode(j)
+	case *PlaceJob:
+		payloadType = "place"
+		err = enc.Encode(j)
+	case *EmailExtractJob:
+		payloadType = "email"
+		err = enc.Encode(j)
+	default:
+		return "", nil, fmt.Errorf("invalid job type %T", job)
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return payloadType, buf.Bytes(), nil
+}
+
+// DecodeJob is the inverse of EncodeJob.
+func DecodeJob(payloadType string, payload []byte) (scrapemate.IJob, error) {
+	dec := gob.NewDecoder(bytes.NewReader(payload))
+
+	switch payloadType {
+	case "search":
+		j := new(GmapJob)
+		if err := dec.Decode(j); err != nil {
+			return nil, fmt.Errorf("failed to decode search job: %w", err)
+		}
+
+		return j, nil
+	case "place":
+		j := new(PlaceJob)
+		if err := dec.Decode(j); err != nil {
+			return nil, fmt.Errorf("failed to decode place job: %w", err)
+		}
+
+		return j, nil
+	case "email":
+		j := new(EmailExtractJob)
+		if err := dec.Decode(j); err != nil {
+			return nil, fmt.Errorf("failed to decode email job: %w", err)
+		}
+
+		return j, nil
+	default:
+		return nil, fmt.Errorf("invalid payload type: %s", payloadType)
+	}
+}
@@ -2,6 +2,7 @@ package gmaps
 
 import (
 	"context"
+	"slices"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -18,6 +19,9 @@ type EmailExtractJob struct {
 
 	Entry       *Entry
 	ExitMonitor exiter.Exiter
+	// Sitemap also extracts the emails of the contact pages found in the
+	// sitemap of the website.
+	Sitemap SitemapOptions
 }
 
 func NewEmailJob(parentID string, entry *Entry, opts ...EmailExtractJobOptions) *EmailExtractJob {
@@ -52,6 +56,12 @@ func WithEmailJobExitMonitor(exitMonitor exiter.Exiter) EmailExtractJobOptions {
 	}
 }
 
+func WithEmailJobSitemap(opts SitemapOptions) EmailExtractJobOptions {
+	return func(j *EmailExtractJob) {
+		j.Sitemap = opts
+	}
+}
+
 func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
@@ -78,13 +88,38 @@ func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response
 		return j.Entry, nil, nil
 	}
 
+	method := MethodMailto
+
 	emails := docEmailExtractor(doc)
 	if len(emails) == 0 {
+		method = MethodRegex
 		emails = regexEmailExtractor(resp.Body)
 	}
 
+	if j.Sitemap.MaxPages > 0 {
+		site := resp.URL
+		if site == "" {
+			site = j.URL
+		}
+
+		found := crawlSitemap(ctx, site, j.Sitemap)
+		if len(emails) == 0 && len(found) > 0 {
+			method = MethodSitemap
+		}
+
+		for _, email := range found {
+			if !slices.Contains(emails, email) {
+				emails = append(emails, email)
+			}
+		}
+	}
+
 	j.Entry.Emails = emails
 
+	if len(emails) > 0 {
+		j.Entry.setMethod("emails", method)
+	}
+
 	return j.Entry, nil, nil
 }
 
@@ -9,11 +9,25 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Image struct {
 	Title string `json:"title"`
 	Image string `json:"image"`
+	// Author is the name of the user that uploaded the photo, empty for
+	// the photos of the owner or when google does not show it.
+	Author string `json:"author,omitempty"`
+	// UploadedAt is the upload date of the photo as YYYY-MM-DD.
+	UploadedAt   string `json:"uploaded_at,omitempty"`
+	IsOwnerPhoto bool   `json:"is_owner_photo"`
+}
+
+// StreetView is the street view panorama of a place, the Image is a
+// static thumbnail that can be embedded without the viewer.
+type StreetView struct {
+	PanoID string `json:"pano_id"`
+	Image  string `json:"image"`
 }
 
 type LinkSource struct {
@@ -21,6 +35,10 @@ type LinkSource struct {
 	Source string `json:"source"`
 }
 
+// fromTheBusiness is the about section with the attributes declared by
+// the owner of a claimed listing.
+const fromTheBusiness = "from_the_business"
+
 type Owner struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -36,6 +54,13 @@ type Address struct {
 	Country    string `json:"country"`
 }
 
+// Values of Entry.AddressCheck. It is empty when the address was not
+// checked against the coordinates of the place.
+const (
+	AddressMatch    = "match"
+	AddressMismatch = "mismatch"
+)
+
 type Option struct {
 	Name    string `json:"name"`
 	Enabled bool   `json:"enabled"`
@@ -48,12 +73,22 @@ type About struct {
 }
 
 type Review struct {
+	ID             string
 	Name           string
 	ProfilePicture string
 	Rating         int
 	Description    string
 	Images         []string
 	When           string
+	OwnerReply     string
+	// LocalGuide is set for the reviews of Local Guides, LocalGuideLevel
+	// is their level (1-10).
+	LocalGuide      bool
+	LocalGuideLevel int
+	// AuthorReviews and AuthorPhotos are the totals of the author on
+	// google maps, when they are shown.
+	AuthorReviews int
+	AuthorPhotos  int
 }
 
 type Entry struct {
@@ -71,11 +106,12 @@ type Entry struct {
 	WebSite          string                 `json:"web_site"`
 	Phone            string                 `json:"phone"`
 	PlusCode         string                 `json:"plus_code"`
+	GeoConfidence    string                 `json:"geo_confidence"`
 	ReviewCount      int                    `json:"review_count"`
 	ReviewRating     float64                `json:"review_rating"`
 	ReviewsPerRating map[int]int            `json:"reviews_per_rating"`
 	Latitude         float64                `json:"latitude"`
-	Longtitude       float64                `json:"longtitude"`
+	Longitude        float64                `json:"longitude"`
 	Status           string                 `json:"status"`
 	Description      string                 `json:"description"`
 	ReviewsLink      string                 `json:"reviews_link"`
@@ -83,15 +119,89 @@ type Entry struct {
 	Timezone         string                 `json:"timezone"`
 	PriceRange       string                 `json:"price_range"`
 	DataID           string                 `json:"data_id"`
-	Images           []Image                `json:"images"`
-	Reservations     []LinkSource           `json:"reservations"`
-	OrderOnline      []LinkSource           `json:"order_online"`
-	Menu             LinkSource             `json:"menu"`
-	Owner            Owner                  `json:"owner"`
-	CompleteAddress  Address                `json:"complete_address"`
-	About            []About                `json:"about"`
-	UserReviews      []Review               `json:"user_reviews"`
-	Emails           []string               `json:"emails"`
+	// MapsURL, KnowledgePanelURL and ReviewsURL are derived from DataID.
+	MapsURL           string       `json:"maps_url"`
+	KnowledgePanelURL string       `json:"knowledge_panel_url"`
+	ReviewsURL        string       `json:"reviews_url"`
+	Images            []Image      `json:"images"`
+	StreetView        StreetView   `json:"street_view"`
+	Reservations      []LinkSource `json:"reservations"`
+	OrderOnline       []LinkSource `json:"order_online"`
+	Menu              LinkSource   `json:"menu"`
+	Owner             Owner        `json:"owner"`
+	CompleteAddress   Address      `json:"complete_address"`
+	AddressCheck      string       `json:"address_check"`
+	About             []About      `json:"about"`
+	UserReviews       []Review     `json:"user_reviews"`
+	Emails            []string     `json:"emails"`
+	// Lang is the language the details were requested in. The english
+	// variants are set when they were requested for another language.
+	Lang            string `json:"lang"`
+	AddressEnglish  string `json:"address_en"`
+	CategoryEnglish string `json:"category_en"`
+	// Hours are the OpenHours in 24h format and OpenNow is computed
+	// with the timezone of the place at ScrapedAt. LocalTimeAtScrape is
+	// ScrapedAt in the timezone of the place, empty when it is unknown.
+	Hours             map[string][]TimeRange `json:"hours"`
+	OpenNow           bool                   `json:"open_now"`
+	ScrapedAt         time.Time              `json:"scraped_at"`
+	LocalTimeAtScrape string                 `json:"local_time_at_scrape"`
+	// BusinessStatus is Status mapped to one of the Status constants.
+	BusinessStatus string `json:"business_status"`
+	// Claimed is true when the listing is managed by its owner and
+	// OwnerBadges are the attributes the owner declares for the business,
+	// e.g. "Identifies as women-owned".
+	Claimed     bool     `json:"claimed"`
+	OwnerBadges []string `json:"owner_badges"`
+	IsSponsored bool     `json:"is_sponsored"`
+	// SerpPosition is the 1-based position of the place in the results
+	// of SerpKeyword, the query that was searched.
+	SerpPosition int    `json:"serp_position"`
+	SerpKeyword  string `json:"serp_keyword"`
+	// InputKeyword is the line of the input that produced the entry and
+	// InputIndex its 1-based position among the queries of the input.
+	InputKeyword string `json:"input_keyword"`
+	InputIndex   int    `json:"input_index"`
+	// Provenance is nil for entries that were not scraped, e.g. the ones
+	// read back from a file.
+	Provenance *Provenance `json:"provenance"`
+}
+
+type plainEntry Entry
+
+// LegacyEntry is the json of an entry that also contains the longitude as
+// longtitude, the misspelled name of the field before schema version 10.
+//
+// Deprecated: it will be removed, read longitude instead.
+type LegacyEntry struct {
+	*plainEntry
+	Longtitude float64 `json:"longtitude"`
+}
+
+// NewLegacyEntry returns the legacy json of e.
+//
+// Deprecated: it will be removed, read longitude instead.
+func NewLegacyEntry(e *Entry) LegacyEntry {
+	return LegacyEntry{plainEntry: (*plainEntry)(e), Longtitude: e.Longitude}
+}
+
+// UnmarshalJSON accepts the legacy longtitude field, so that the entries
+// stored before schema version 10 are read with their longitude.
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*plainEntry
+		Longtitude *float64 `json:"longtitude"`
+	}{plainEntry: (*plainEntry)(e)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Longtitude != nil && e.Longitude == 0 {
+		e.Longitude = *aux.Longtitude
+	}
+
+	return nil
 }
 
 func (e *Entry) haversineDistance(lat, lon float64) float64 {
@@ -101,7 +211,7 @@ func (e *Entry) haversineDistance(lat, lon float64) float64 {
 	clon := lon * math.Pi / 180
 
 	elat := e.Latitude * math.Pi / 180
-	elon := e.Longtitude * math.Pi / 180
+	elon := e.Longitude * math.Pi / 180
 
 	dlat := elat - clat
 	dlon := elon - clon
@@ -121,6 +231,17 @@ func (e *Entry) isWithinRadius(lat, lon, radius float64) bool {
 	return distance <= radius
 }
 
+// InCountry reports whether the entry is located in the country with the
+// given ISO 3166-1 alpha-2 code. Entries without a country and an empty
+// code always match.
+func (e *Entry) InCountry(country string) bool {
+	if country == "" || e.CompleteAddress.Country == "" {
+		return true
+	}
+
+	return strings.EqualFold(e.CompleteAddress.Country, country)
+}
+
 func (e *Entry) IsWebsiteValidForEmail() bool {
 	if e.WebSite == "" {
 		return false
@@ -187,6 +308,33 @@ func (e *Entry) CsvHeaders() []string {
 		"about",
 		"user_reviews",
 		"emails",
+		"lang",
+		"address_en",
+		"category_en",
+		"hours",
+		"open_now",
+		"business_status",
+		"claimed",
+		"owner_badges",
+		"is_sponsored",
+		"serp_position",
+		"serp_keyword",
+		"input_keyword",
+		"input_index",
+		"provenance",
+		"street",
+		"city",
+		"postal_code",
+		"state",
+		"country",
+		"address_check",
+		"geo_confidence",
+		"scraped_at",
+		"local_time_at_scrape",
+		"street_view",
+		"maps_url",
+		"knowledge_panel_url",
+		"reviews_url",
 	}
 }
 
@@ -206,7 +354,7 @@ func (e *Entry) CsvRow() []string {
 		stringify(e.ReviewRating),
 		stringify(e.ReviewsPerRating),
 		stringify(e.Latitude),
-		stringify(e.Longtitude),
+		stringify(e.Longitude),
 		e.Cid,
 		e.Status,
 		e.Description,
@@ -224,6 +372,33 @@ func (e *Entry) CsvRow() []string {
 		stringify(e.About),
 		stringify(e.UserReviews),
 		stringSliceToString(e.Emails),
+		e.Lang,
+		e.AddressEnglish,
+		e.CategoryEnglish,
+		stringify(e.Hours),
+		stringify(e.OpenNow),
+		e.BusinessStatus,
+		stringify(e.Claimed),
+		stringSliceToString(e.OwnerBadges),
+		stringify(e.IsSponsored),
+		strconv.Itoa(e.SerpPosition),
+		e.SerpKeyword,
+		e.InputKeyword,
+		strconv.Itoa(e.InputIndex),
+		e.Provenance.String(),
+		e.CompleteAddress.Street,
+		e.CompleteAddress.City,
+		e.CompleteAddress.PostalCode,
+		e.CompleteAddress.State,
+		e.CompleteAddress.Country,
+		e.AddressCheck,
+		e.GeoConfidence,
+		formatTime(e.ScrapedAt),
+		e.LocalTimeAtScrape,
+		stringify(e.StreetView),
+		e.MapsURL,
+		e.KnowledgePanelURL,
+		e.ReviewsURL,
 	}
 }
 
@@ -276,30 +451,22 @@ func EntryFromJSON(raw []byte) (entry Entry, err error) {
 	entry.ReviewCount = int(getNthElementAndCast[float64](darray, 4, 8))
 	entry.ReviewRating = getNthElementAndCast[float64](darray, 4, 7)
 	entry.Latitude = getNthElementAndCast[float64](darray, 9, 2)
-	entry.Longtitude = getNthElementAndCast[float64](darray, 9, 3)
+	entry.Longitude = getNthElementAndCast[float64](darray, 9, 3)
 	entry.Cid = getNthElementAndCast[string](jd, 25, 3, 0, 13, 0, 0, 1)
 	entry.Status = getNthElementAndCast[string](darray, 34, 4, 4)
+	entry.BusinessStatus = ParseBusinessStatus(entry.Status)
 	entry.Description = getNthElementAndCast[string](darray, 32, 1, 1)
 	entry.ReviewsLink = getNthElementAndCast[string](darray, 4, 3, 0)
 	entry.Thumbnail = getNthElementAndCast[string](darray, 72, 0, 1, 6, 0)
 	entry.Timezone = getNthElementAndCast[string](darray, 30)
 	entry.PriceRange = getNthElementAndCast[string](darray, 4, 2)
 	entry.DataID = getNthElementAndCast[string](darray, 10)
+	entry.setLinks()
+	entry.Hours = getStructuredHours(darray)
+	entry.setScrapedAt(time.Now())
 
-	items := getLinkSource(getLinkSourceParams{
-		arr:    getNthElementAndCast[[]any](darray, 171, 0),
-		link:   []int{3, 0, 6, 0},
-		source: []int{2},
-	})
-
-	entry.Images = make([]Image, len(items))
-
-	for i := range items {
-		entry.Images[i] = Image{
-			Title: items[i].Source,
-			Image: items[i].Link,
-		}
-	}
+	entry.Images = getImages(darray)
+	entry.StreetView = getStreetView(darray)
 
 	entry.Reservations = getLinkSource(getLinkSourceParams{
 		arr:    getNthElementAndCast[[]any](darray, 46),
@@ -329,6 +496,9 @@ func EntryFromJSON(raw []byte) (entry Entry, err error) {
 		Name: getNthElementAndCast[string](darray, 57, 1),
 	}
 
+	// only claimed listings have an owner account
+	entry.Claimed = entry.Owner.ID != ""
+
 	if entry.Owner.ID != "" {
 		entry.Owner.Link = fmt.Sprintf("https://www.google.com/maps/contrib/%s", entry.Owner.ID)
 	}
@@ -365,6 +535,14 @@ func EntryFromJSON(raw []byte) (entry Entry, err error) {
 		}
 
 		entry.About = append(entry.About, about)
+
+		if about.ID == fromTheBusiness {
+			for j := range about.Options {
+				if about.Options[j].Enabled {
+					entry.OwnerBadges = append(entry.OwnerBadges, about.Options[j].Name)
+				}
+			}
+		}
 	}
 
 	entry.ReviewsPerRating = map[int]int{
@@ -387,6 +565,7 @@ func EntryFromJSON(raw []byte) (entry Entry, err error) {
 		}
 
 		review := Review{
+			ID:             getNthElementAndCast[string](el, 0),
 			Name:           getNthElementAndCast[string](el, 1, 4, 5, 0),
 			ProfilePicture: profilePic,
 			When: func() string {
@@ -398,8 +577,11 @@ func EntryFromJSON(raw []byte) (entry Entry, err error) {
 			}(),
 			Rating:      int(getNthElementAndCast[float64](el, 2, 0, 0)),
 			Description: getNthElementAndCast[string](el, 2, 15, 0, 0),
+			OwnerReply:  getNthElementAndCast[string](el, 3, 14, 0, 0),
 		}
 
+		setReviewAuthor(&review, getNthElementAndCast[[]any](el, 1, 4, 0))
+
 		if review.Name == "" {
 			continue
 		}
@@ -416,9 +598,94 @@ func EntryFromJSON(raw []byte) (entry Entry, err error) {
 		entry.UserReviews = append(entry.UserReviews, review)
 	}
 
+	entry.CheckPlusCode()
+
 	return entry, nil
 }
 
+// setReviewAuthor reads the details of the author of a review. The name
+// and picture are only used when the review does not have them already.
+func setReviewAuthor(review *Review, author []any) {
+	if review.Name == "" {
+		review.Name = getNthElementAndCast[string](author, 4)
+	}
+
+	if review.ProfilePicture == "" {
+		review.ProfilePicture = getNthElementAndCast[string](author, 3)
+	}
+
+	review.LocalGuideLevel = int(getNthElementAndCast[float64](author, 0, 0))
+	review.LocalGuide = review.LocalGuideLevel > 0
+	review.AuthorReviews = int(getNthElementAndCast[float64](author, 1))
+	review.AuthorPhotos = int(getNthElementAndCast[float64](author, 2))
+}
+
+//nolint:gomnd // it's ok, I need the indexes
+func getImages(darray []any) []Image {
+	items := getNthElementAndCast[[]any](darray, 171, 0)
+	images := make([]Image, 0, len(items))
+
+	for i := range items {
+		item := getNthElementAndCast[[]any](items, i)
+
+		image := Image{
+			Title: getNthElementAndCast[string](item, 2),
+			Image: getNthElementAndCast[string](item, 3, 0, 6, 0),
+		}
+
+		if image.Title == "" || image.Image == "" {
+			continue
+		}
+
+		meta := getNthElementAndCast[[]any](item, 3, 0, 21)
+
+		// 1 for the photos of the owner and 2 for the ones of the users
+		image.IsOwnerPhoto = getNthElementAndCast[float64](meta, 6, 0) == 1 ||
+			strings.HasPrefix(getNthElementAndCast[string](meta, 6, 5, 2), "bizbuilder")
+
+		if !image.IsOwnerPhoto {
+			image.Author = getNthElementAndCast[string](meta, 4, 1, 0, 0, 0)
+		}
+
+		year := int(getNthElementAndCast[float64](meta, 6, 8, 0))
+		month := int(getNthElementAndCast[float64](meta, 6, 8, 1))
+		day := int(getNthElementAndCast[float64](meta, 6, 8, 2))
+
+		if year > 0 && month > 0 && day > 0 {
+			image.UploadedAt = fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+		}
+
+		images = append(images, image)
+	}
+
+	return images
+}
+
+// getStreetView returns the panorama of the street view category of the
+// photos. The category is found by the source of its photo because the
+// title is translated.
+//
+//nolint:gomnd // it's ok, I need the indexes
+func getStreetView(darray []any) StreetView {
+	items := getNthElementAndCast[[]any](darray, 171, 0)
+
+	for i := range items {
+		photo := getNthElementAndCast[[]any](items, i, 3, 0)
+
+		source := getNthElementAndCast[string](photo, 21, 6, 5, 2)
+		if !strings.HasPrefix(source, "photos:street_view") {
+			continue
+		}
+
+		return StreetView{
+			PanoID: getNthElementAndCast[string](photo, 21, 1, 1),
+			Image:  getNthElementAndCast[string](photo, 6, 0),
+		}
+	}
+
+	return StreetView{}
+}
+
 type getLinkSourceParams struct {
 	arr    []any
 	source []int
@@ -557,6 +824,14 @@ func getNthElementAndCast[T any](arr []any, indexes ...int) T {
 	return ans
 }
 
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
 func stringSliceToString(s []string) string {
 	return strings.Join(s, ", ")
 }
@@ -1,9 +1,12 @@
 package gmaps_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/stretchr/testify/require"
@@ -41,62 +44,83 @@ func Test_EntryFromJSON(t *testing.T) {
 			"Saturday":  {"12:30–10 pm"},
 			"Sunday":    {"12:30–10 pm"},
 		},
-		WebSite:      "",
-		Phone:        "25 101555",
-		PlusCode:     "M2CR+6X Limassol",
-		ReviewCount:  396,
-		ReviewRating: 4.2,
-		Latitude:     34.670595399999996,
-		Longtitude:   33.042456699999995,
-		Cid:          "16519582940102929223",
-		Status:       "Closed ⋅ Opens 12:30\u202fpm Tue",
-		ReviewsLink:  "https://search.google.com/local/reviews?placeid=ChIJDdnwdv0y5xQRRytw1ihZQeU&q=Kipriakon&authuser=0&hl=en&gl=CY",
-		Thumbnail:    "https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w408-h408-k-no",
-		Timezone:     "Asia/Nicosia",
-		PriceRange:   "€€",
-		DataID:       "0x14e732fd76f0d90d:0xe5415928d6702b47",
+		WebSite:           "",
+		Phone:             "25 101555",
+		PlusCode:          "M2CR+6X Limassol",
+		GeoConfidence:     gmaps.GeoConfidenceHigh,
+		ReviewCount:       396,
+		ReviewRating:      4.2,
+		Latitude:          34.670595399999996,
+		Longitude:         33.042456699999995,
+		Cid:               "16519582940102929223",
+		Status:            "Closed ⋅ Opens 12:30\u202fpm Tue",
+		ReviewsLink:       "https://search.google.com/local/reviews?placeid=ChIJDdnwdv0y5xQRRytw1ihZQeU&q=Kipriakon&authuser=0&hl=en&gl=CY",
+		Thumbnail:         "https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w408-h408-k-no",
+		Timezone:          "Asia/Nicosia",
+		PriceRange:        "€€",
+		DataID:            "0x14e732fd76f0d90d:0xe5415928d6702b47",
+		MapsURL:           "https://www.google.com/maps?cid=16519582940102929223",
+		KnowledgePanelURL: "https://www.google.com/search?ludocid=16519582940102929223&q=Kipriakon",
+		ReviewsURL:        "https://www.google.com/maps/place/data=!4m4!3m3!1s0x14e732fd76f0d90d:0xe5415928d6702b47!9m1!1b1",
 		Images: []gmaps.Image{
 			{
-				Title: "All",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w298-h298-k-no",
+				Title:        "All",
+				Image:        "https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w298-h298-k-no",
+				UploadedAt:   "2017-05-21",
+				IsOwnerPhoto: true,
 			},
 			{
-				Title: "Latest",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipNgMqyaQs2MqH1oiGC44eDcvudurxQfNb2RuDsd=w224-h298-k-no",
+				Title:      "Latest",
+				Image:      "https://lh5.googleusercontent.com/p/AF1QipNgMqyaQs2MqH1oiGC44eDcvudurxQfNb2RuDsd=w224-h298-k-no",
+				UploadedAt: "2023-08-16",
 			},
 			{
-				Title: "Videos",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipPZbq8v8K8RZfvL6gZ_4Dw6qwNJ_MUxxOOfBo7h=w224-h398-k-no",
+				Title:      "Videos",
+				Image:      "https://lh5.googleusercontent.com/p/AF1QipPZbq8v8K8RZfvL6gZ_4Dw6qwNJ_MUxxOOfBo7h=w224-h398-k-no",
+				UploadedAt: "2023-07-10",
 			},
 			{
-				Title: "Menu",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipNhoFtPcaLCIhdN3GhlJ6sQIvdhaESnRG8nyeC8=w397-h298-k-no",
+				Title:      "Menu",
+				Image:      "https://lh5.googleusercontent.com/p/AF1QipNhoFtPcaLCIhdN3GhlJ6sQIvdhaESnRG8nyeC8=w397-h298-k-no",
+				UploadedAt: "2023-01-18",
 			},
 			{
-				Title: "Food & drink",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipMbu-iiWkE4DsXx3aI7nGaqyXJKbBYCrBXvzOnu=w298-h298-k-no",
+				Title:        "Food & drink",
+				Image:        "https://lh5.googleusercontent.com/p/AF1QipMbu-iiWkE4DsXx3aI7nGaqyXJKbBYCrBXvzOnu=w298-h298-k-no",
+				UploadedAt:   "2017-05-21",
+				IsOwnerPhoto: true,
 			},
 			{
-				Title: "Vibe",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipOGg_vrD4bzkOre5Ly6CFXuO3YCOGfFxQ-EiEkW=w224-h398-k-no",
+				Title:      "Vibe",
+				Image:      "https://lh5.googleusercontent.com/p/AF1QipOGg_vrD4bzkOre5Ly6CFXuO3YCOGfFxQ-EiEkW=w224-h398-k-no",
+				UploadedAt: "2023-06-19",
 			},
 			{
-				Title: "Fried green tomatoes",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipOziHd2hqM1jnK9KfCGf1zVhcOrx8Bj7VdJXj0=w397-h298-k-no",
+				Title:      "Fried green tomatoes",
+				Image:      "https://lh5.googleusercontent.com/p/AF1QipOziHd2hqM1jnK9KfCGf1zVhcOrx8Bj7VdJXj0=w397-h298-k-no",
+				UploadedAt: "2019-08-02",
 			},
 			{
-				Title: "French fries",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipNJyq7nAlKtsxxbNy4PHUZOhJ0k7HPP8tTAlwcV=w397-h298-k-no",
+				Title:      "French fries",
+				Image:      "https://lh5.googleusercontent.com/p/AF1QipNJyq7nAlKtsxxbNy4PHUZOhJ0k7HPP8tTAlwcV=w397-h298-k-no",
+				UploadedAt: "2023-05-01",
 			},
 			{
-				Title: "By owner",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipNRE2R5k13zT-0WG4b6XOD_BES9-nMK04hlCMVV=w298-h298-k-no",
+				Title:        "By owner",
+				Image:        "https://lh5.googleusercontent.com/p/AF1QipNRE2R5k13zT-0WG4b6XOD_BES9-nMK04hlCMVV=w298-h298-k-no",
+				UploadedAt:   "2017-05-21",
+				IsOwnerPhoto: true,
 			},
 			{
-				Title: "Street View & 360°",
-				Image: "https://lh5.googleusercontent.com/p/AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL=w224-h298-k-no-pi-23.425545-ya289.20517-ro-8.658787-fo100",
+				Title:      "Street View & 360°",
+				Image:      "https://lh5.googleusercontent.com/p/AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL=w224-h298-k-no-pi-23.425545-ya289.20517-ro-8.658787-fo100",
+				UploadedAt: "2017-09-24",
 			},
 		},
+		StreetView: gmaps.StreetView{
+			PanoID: "AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL",
+			Image:  "https://lh5.googleusercontent.com/p/AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL=w224-h298-k-no-pi-23.425545-ya289.20517-ro-8.658787-fo100",
+		},
 		OrderOnline: []gmaps.LinkSource{
 			{
 				Link:   "https://foody.com.cy/delivery/lemesos/to-kypriakon?utm_source=google&utm_medium=organic&utm_campaign=google_reserve_place_order_action",
@@ -170,11 +194,82 @@ func Test_EntryFromJSON(t *testing.T) {
 	require.Equal(t, 100, monday[20])
 
 	entry.PopularTimes = nil
+
+	require.Len(t, entry.UserReviews, 8)
+
+	review := entry.UserReviews[1]
+	require.Equal(t, "Yossi Konijn", review.Name)
+	require.True(t, review.LocalGuide)
+	require.Equal(t, 7, review.LocalGuideLevel)
+	require.Equal(t, 458, review.AuthorReviews)
+	require.Equal(t, 619, review.AuthorPhotos)
+
+	review = entry.UserReviews[2]
+	require.Equal(t, "Andreas Althammer", review.Name)
+	require.False(t, review.LocalGuide)
+	require.Equal(t, 5, review.AuthorReviews)
+
 	entry.UserReviews = nil
 
+	require.Len(t, entry.Hours, 7)
+	require.Equal(t, []gmaps.TimeRange{{Open: "12:30", Close: "22:00"}}, entry.Hours["Monday"])
+
+	entry.Hours = nil
+	entry.OpenNow = false
+
+	local, err := time.Parse(time.RFC3339, entry.LocalTimeAtScrape)
+	require.NoError(t, err)
+	require.WithinDuration(t, entry.ScrapedAt, local, time.Second)
+	require.NotEqual(t, "Z", entry.LocalTimeAtScrape[len(entry.LocalTimeAtScrape)-1:])
+
+	entry.ScrapedAt = time.Time{}
+	entry.LocalTimeAtScrape = ""
+
+	require.Equal(t, gmaps.StatusOpen, entry.BusinessStatus)
+
+	entry.BusinessStatus = ""
+
+	require.True(t, entry.Claimed)
+	require.Empty(t, entry.OwnerBadges)
+
+	entry.Claimed = false
+
 	require.Equal(t, expected, entry)
 }
 
+func Test_EntryOpenAt(t *testing.T) {
+	entry := gmaps.Entry{
+		Timezone: "Asia/Nicosia",
+		Hours: map[string][]gmaps.TimeRange{
+			"Monday":   {{Open: "12:30", Close: "22:00"}},
+			"Friday":   {{Open: "09:00", Close: "13:00"}, {Open: "18:00", Close: "02:00"}},
+			"Saturday": {},
+		},
+	}
+
+	tests := []struct {
+		name string
+		at   string
+		want bool
+	}{
+		{"before opening", "2024-09-02T09:00:00Z", false},
+		{"open in place timezone", "2024-09-02T10:00:00Z", true},
+		{"closed in place timezone", "2024-09-02T19:30:00Z", false},
+		{"between periods", "2024-09-06T12:00:00Z", false},
+		{"second period", "2024-09-06T16:00:00Z", true},
+		{"after midnight of previous day", "2024-09-06T22:30:00Z", true},
+		{"closed day", "2024-09-07T08:00:00Z", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tc.at)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, entry.OpenAt(at))
+		})
+	}
+}
+
 func Test_EntryFromJSON2(t *testing.T) {
 	fnames := []string{
 		"../testdata/panic.json",
@@ -216,3 +311,113 @@ func Test_EntryFromJsonC(t *testing.T) {
 		fmt.Printf("%+v\n", entry)
 	}
 }
+
+func Test_ParseBusinessStatus(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", gmaps.StatusOpen},
+		{"Closed ⋅ Opens 12:30\u202fpm Tue", gmaps.StatusOpen},
+		{"Permanently closed", gmaps.StatusPermanentlyClosed},
+		{"Dauerhaft geschlossen", gmaps.StatusPermanentlyClosed},
+		{"Temporarily closed", gmaps.StatusTemporarilyClosed},
+		{"Cerrado temporalmente", gmaps.StatusTemporarilyClosed},
+		{"Opening soon", gmaps.StatusOpeningSoon},
+	}
+
+	for _, tc := range tests {
+		require.Equal(t, tc.want, gmaps.ParseBusinessStatus(tc.raw), tc.raw)
+	}
+}
+
+func Test_EntryProvenance(t *testing.T) {
+	var entry gmaps.Entry
+
+	col := slices.Index(entry.CsvHeaders(), "provenance")
+
+	row := entry.CsvRow()
+	require.Len(t, row, len(entry.CsvHeaders()))
+	require.Empty(t, row[col])
+
+	entry.Provenance = &gmaps.Provenance{
+		SourceURL: "https://www.google.com/maps/place/x",
+		Lang:      "en",
+		Methods:   map[string]string{"emails": gmaps.MethodMailto},
+	}
+
+	row = entry.CsvRow()
+	require.Contains(t, row[col], `"methods":{"emails":"mailto"}`)
+	require.NotContains(t, row[col], "proxy_country")
+}
+
+func Test_Schema(t *testing.T) {
+	var entry gmaps.Entry
+
+	var csvNames, jsonNames []string
+
+	for _, f := range gmaps.Schema() {
+		if f.CSV != "" {
+			csvNames = append(csvNames, f.CSV)
+		}
+
+		if f.JSON != "" {
+			jsonNames = append(jsonNames, f.JSON)
+		}
+	}
+
+	require.Equal(t, entry.CsvHeaders(), csvNames)
+
+	data, err := json.Marshal(&entry)
+	require.NoError(t, err)
+
+	var keys map[string]any
+
+	require.NoError(t, json.Unmarshal(data, &keys))
+	require.Len(t, jsonNames, len(keys))
+
+	for _, name := range jsonNames {
+		require.Contains(t, keys, name)
+	}
+
+	require.Equal(t, gmaps.SchemaVersion, gmaps.SchemaChangelog[0].Version)
+}
+
+func Test_EntryLegacyLongtitude(t *testing.T) {
+	var entry gmaps.Entry
+
+	require.NoError(t, json.Unmarshal([]byte(`{"title":"a","latitude":37.9,"longtitude":23.7}`), &entry))
+	require.Equal(t, "a", entry.Title)
+	require.InDelta(t, 23.7, entry.Longitude, 0.0001)
+
+	data, err := json.Marshal(&entry)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"longitude":23.7`)
+	require.NotContains(t, string(data), "longtitude")
+
+	data, err = json.Marshal(gmaps.NewLegacyEntry(&entry))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"longitude":23.7`)
+	require.Contains(t, string(data), `"longtitude":23.7`)
+}
+
+func Test_EntryPlusCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		entry      gmaps.Entry
+		confidence string
+	}{
+		{"full code without coordinates", gmaps.Entry{PlusCode: "8G5FM2CR+6X"}, gmaps.GeoConfidenceFilled},
+		{"short code near the coordinates", gmaps.Entry{PlusCode: "M2CR+6X Limassol", Latitude: 34.6706, Longitude: 33.0424}, gmaps.GeoConfidenceHigh},
+		{"full code far from the coordinates", gmaps.Entry{PlusCode: "8G5FM2CR+6X", Latitude: 34.69, Longitude: 33.04}, gmaps.GeoConfidenceLow},
+		{"short code without coordinates", gmaps.Entry{PlusCode: "M2CR+6X Limassol"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := tc.entry
+			entry.CheckPlusCode()
+			require.Equal(t, tc.confidence, entry.GeoConfidence)
+		})
+	}
+}
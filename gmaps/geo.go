@@ -0,0 +1,72 @@
+package gmaps
+
+import (
+	"strings"
+
+	olc "github.com/google/open-location-code/go"
+)
+
+// Values of Entry.GeoConfidence. It is empty when the place has no plus
+// code to compare its coordinates with.
+const (
+	// GeoConfidenceHigh is set when the coordinates are in the area of
+	// the plus code.
+	GeoConfidenceHigh = "high"
+	// GeoConfidenceLow is set when the coordinates are more than
+	// maxPlusCodeDistance away from the plus code.
+	GeoConfidenceLow = "low"
+	// GeoConfidenceFilled is set when the place had no coordinates and
+	// they were decoded from the plus code.
+	GeoConfidenceFilled = "filled"
+)
+
+// maxPlusCodeDistance is the distance in meters between the coordinates
+// and the center of the plus code above which they disagree. A 10 digit
+// code is an area of about 14x14 meters.
+const maxPlusCodeDistance = 250
+
+// CheckPlusCode compares the coordinates of the entry with its plus code
+// and fills the ones that are missing. Full codes are decoded as they are,
+// short codes (e.g. "M2CR+6X Limassol") only with the coordinates as the
+// reference location.
+func (e *Entry) CheckPlusCode() {
+	hasCoordinates := e.Latitude != 0 || e.Longitude != 0
+
+	code, _, _ := strings.Cut(strings.TrimSpace(e.PlusCode), " ")
+
+	switch {
+	case code == "":
+		if hasCoordinates {
+			e.PlusCode = olc.Encode(e.Latitude, e.Longitude, 10)
+		}
+
+		return
+	case olc.CheckFull(code) == nil:
+	case olc.CheckShort(code) == nil && hasCoordinates:
+		full, err := olc.RecoverNearest(code, e.Latitude, e.Longitude)
+		if err != nil {
+			return
+		}
+
+		code = full
+	default:
+		return
+	}
+
+	area, err := olc.Decode(code)
+	if err != nil {
+		return
+	}
+
+	lat, lon := area.Center()
+
+	switch {
+	case !hasCoordinates:
+		e.Latitude, e.Longitude = lat, lon
+		e.GeoConfidence = GeoConfidenceFilled
+	case e.haversineDistance(lat, lon) > maxPlusCodeDistance:
+		e.GeoConfidence = GeoConfidenceLow
+	default:
+		e.GeoConfidence = GeoConfidenceHigh
+	}
+}
@@ -0,0 +1,123 @@
+package gmaps
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // the place timezones must resolve in minimal images too
+)
+
+const minutesPerDay = 24 * 60
+
+// TimeRange is an opening period of a day in 24h "HH:MM" format, in the
+// timezone of the place. A period that closes after midnight has a Close
+// before its Open, e.g. {"18:00", "02:00"}.
+type TimeRange struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// getStructuredHours reads the opening periods google maps sends next to
+// the localized strings of OpenHours. The days are keyed by their english
+// name, derived from the date of each day, so they do not depend on the
+// language of the results. Closed days have no periods.
+//
+//nolint:gomnd // it's ok, I need the indexes
+func getStructuredHours(darray []any) map[string][]TimeRange {
+	items := getNthElementAndCast[[]any](darray, 34, 1)
+	if len(items) == 0 {
+		return nil
+	}
+
+	hours := make(map[string][]TimeRange, len(items))
+
+	for _, item := range items {
+		arr, ok := item.([]any)
+		if !ok {
+			continue
+		}
+
+		day := getNthElementAndCast[string](arr, 0)
+
+		if d, err := time.Parse(time.DateOnly, getNthElementAndCast[string](arr, 4)); err == nil {
+			day = d.Weekday().String()
+		}
+
+		periods := getNthElementAndCast[[]any](arr, 6)
+		ranges := make([]TimeRange, 0, len(periods))
+
+		for i := range periods {
+			p, ok := periods[i].([]any)
+			if !ok {
+				continue
+			}
+
+			ranges = append(ranges, TimeRange{
+				Open:  hhmm(getNthElementAndCast[float64](p, 0), getNthElementAndCast[float64](p, 1)),
+				Close: hhmm(getNthElementAndCast[float64](p, 2), getNthElementAndCast[float64](p, 3)),
+			})
+		}
+
+		hours[day] = ranges
+	}
+
+	return hours
+}
+
+// setScrapedAt sets the time the place was scraped, the local time of
+// the place at that time and whether it was open then.
+func (e *Entry) setScrapedAt(t time.Time) {
+	e.ScrapedAt = t.UTC()
+	e.OpenNow = e.OpenAt(t)
+
+	if loc, err := time.LoadLocation(e.Timezone); err == nil && e.Timezone != "" {
+		e.LocalTimeAtScrape = t.In(loc).Format(time.RFC3339)
+	}
+}
+
+// OpenAt reports whether the place is open at t, according to Hours and
+// the timezone of the place. It is false when the hours are not known.
+func (e *Entry) OpenAt(t time.Time) bool {
+	if len(e.Hours) == 0 {
+		return false
+	}
+
+	if loc, err := time.LoadLocation(e.Timezone); err == nil && e.Timezone != "" {
+		t = t.In(loc)
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	yesterday := (t.Weekday() + 6) % 7
+
+	for _, r := range e.Hours[t.Weekday().String()] {
+		open, closing := minutes(r.Open), minutes(r.Close)
+
+		if now >= open && (closing <= open || now < closing) {
+			return true
+		}
+	}
+
+	// periods of the previous day that close after midnight
+	for _, r := range e.Hours[yesterday.String()] {
+		open, closing := minutes(r.Open), minutes(r.Close)
+
+		if closing <= open && now < closing {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hhmm(hour, minute float64) string {
+	return fmt.Sprintf("%02d:%02d", int(hour), int(minute))
+}
+
+func minutes(s string) int {
+	var h, m int
+
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil {
+		return 0
+	}
+
+	return min(h*60+m, minutesPerDay)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,6 +25,34 @@ type GmapJob struct {
 	MaxDepth     int
 	LangCode     string
 	ExtractEmail bool
+	// Query is the search term, its results are ranked by their position.
+	Query string
+	// InputKeyword and InputIndex identify the line of the input
+	// that created the job.
+	InputKeyword string
+	InputIndex   int
+	// ResultsLang is the language of the place details. Defaults to LangCode.
+	ResultsLang    string
+	ResultsEnglish bool
+	// Country is the ISO 3166-1 alpha-2 code of the country the results
+	// are restricted to.
+	Country string
+	// ExcludeSponsored skips the sponsored places of the results feed.
+	ExcludeSponsored bool
+	// Center drops the places that are farther than Center.Radius meters
+	// from Center, if Radius is set.
+	Center MapLocation
+	// EmailSitemap crawls the contact pages of the sitemap of the websites
+	// when the emails are extracted.
+	EmailSitemap SitemapOptions
+	// Session keeps the cookies of the browser across the jobs of a user.
+	Session *Session
+	// Retries is the number of times the search is enqueued again when
+	// it fails, Attempt the number of retries so far.
+	Retries int
+	Attempt int
+	// backoff delays the push of a retry, see NewRetryProvider.
+	backoff time.Duration
 
 	Deduper     deduper.Deduper
 	ExitMonitor exiter.Exiter
@@ -37,6 +66,7 @@ func NewGmapJob(
 	zoom int,
 	opts ...GmapJobOptions,
 ) *GmapJob {
+	keyword := query
 	query = url.QueryEscape(query)
 
 	const (
@@ -68,6 +98,7 @@ func NewGmapJob(
 		MaxDepth:     maxDepth,
 		LangCode:     langCode,
 		ExtractEmail: extractEmail,
+		Query:        keyword,
 	}
 
 	for _, opt := range opts {
@@ -77,12 +108,104 @@ func NewGmapJob(
 	return &job
 }
 
+// NewTiledGmapJobs returns one job per tile of the area (see Tiles), so
+// that the searches stay within area.Radius meters of the center. The
+// places farther away are dropped like with WithRadius. The ids of the
+// jobs after the first one are suffixed with their index.
+func NewTiledGmapJobs(
+	id, langCode, query string,
+	maxDepth int,
+	extractEmail bool,
+	area MapLocation,
+	opts ...GmapJobOptions,
+) []*GmapJob {
+	opts = append(opts, WithRadius(area.Lat, area.Lon, area.Radius))
+
+	tiles := Tiles(area, BrowserViewportW, BrowserViewportH)
+	jobs := make([]*GmapJob, 0, len(tiles))
+
+	for i, tile := range tiles {
+		jobID := id
+		if id != "" && i > 0 {
+			jobID = fmt.Sprintf("%s-%d", id, i)
+		}
+
+		geo := fmt.Sprintf("%.6f,%.6f", tile.Lat, tile.Lon)
+
+		jobs = append(jobs, NewGmapJob(jobID, langCode, query, maxDepth, extractEmail, geo, int(tile.ZoomLvl), opts...))
+	}
+
+	return jobs
+}
+
 func WithDeduper(d deduper.Deduper) GmapJobOptions {
 	return func(j *GmapJob) {
 		j.Deduper = d
 	}
 }
 
+// WithResultsLang requests the place details in lang instead of the
+// language of the search. With english the address and the category
+// are fetched in english too.
+func WithResultsLang(lang string, english bool) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ResultsLang = lang
+		j.ResultsEnglish = english
+	}
+}
+
+// WithCountry sets the region of the search and drops the places
+// that are not located in country.
+func WithCountry(country string) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Country = strings.ToUpper(country)
+		j.URLParams["gl"] = strings.ToLower(country)
+	}
+}
+
+func WithInput(keyword string, index int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.InputKeyword = keyword
+		j.InputIndex = index
+	}
+}
+
+func WithExcludeSponsored() GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ExcludeSponsored = true
+	}
+}
+
+// WithRadius drops the places that are farther than radius meters from
+// lat, lon, like the radius of the fast mode.
+func WithRadius(lat, lon, radius float64) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Center = MapLocation{Lat: lat, Lon: lon, Radius: radius}
+	}
+}
+
+func WithEmailSitemap(opts SitemapOptions) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.EmailSitemap = opts
+	}
+}
+
+// WithSession starts the browser contexts of the job and of its places
+// with the cookies of the session, and keeps their cookies in it.
+func WithSession(s *Session) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Session = s
+	}
+}
+
+// WithRetries enqueues the search again up to n times when it fails,
+// with an exponential backoff (see SeedRetryBackoff and NewRetryProvider).
+func WithRetries(n int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Retries = n
+	}
+}
+
 func WithExitMonitor(e exiter.Exiter) GmapJobOptions {
 	return func(j *GmapJob) {
 		j.ExitMonitor = e
@@ -93,6 +216,40 @@ func (j *GmapJob) UseInResults() bool {
 	return false
 }
 
+// ProcessOnFetchError lets Process retry the failed searches.
+func (j *GmapJob) ProcessOnFetchError() bool {
+	return true
+}
+
+// Backoff is the delay before the job is pushed when it is a retry.
+func (j *GmapJob) Backoff() time.Duration {
+	return j.backoff
+}
+
+// retry returns the job again when it has retries left, to be pushed
+// after the backoff. Otherwise the seed counts as failed and err is returned.
+func (j *GmapJob) retry(ctx context.Context, err error) (any, []scrapemate.IJob, error) {
+	if j.Attempt >= j.Retries || ctx.Err() != nil {
+		if j.ExitMonitor != nil {
+			j.ExitMonitor.IncrSeedFailed(1)
+		}
+
+		return nil, nil, err
+	}
+
+	scrapemate.GetLoggerFromContext(ctx).Info("retrying search", "query", j.Query, "attempt", j.Attempt+1, "error", err)
+
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrSeedRetried(1)
+	}
+
+	next := *j
+	next.Attempt++
+	next.backoff = retryBackoff(next.Attempt)
+
+	return nil, []scrapemate.IJob{&next}, nil
+}
+
 func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
@@ -101,31 +258,77 @@ func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any,
 
 	log := scrapemate.GetLoggerFromContext(ctx)
 
+	if resp.Error != nil {
+		return j.retry(ctx, resp.Error)
+	}
+
 	doc, ok := resp.Document.(*goquery.Document)
 	if !ok {
-		return nil, nil, fmt.Errorf("could not convert to goquery document")
+		return j.retry(ctx, fmt.Errorf("could not convert to goquery document"))
 	}
 
 	var next []scrapemate.IJob
 
-	if strings.Contains(resp.URL, "/maps/place/") {
-		jopts := []PlaceJobOptions{}
-		if j.ExitMonitor != nil {
-			jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
-		}
+	jopts := []PlaceJobOptions{}
+	if j.ExitMonitor != nil {
+		jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
+	}
+
+	if j.ResultsEnglish {
+		jopts = append(jopts, WithPlaceJobEnglish())
+	}
 
-		placeJob := NewPlaceJob(j.ID, j.LangCode, resp.URL, j.ExtractEmail, jopts...)
+	if j.Country != "" {
+		jopts = append(jopts, WithPlaceJobCountry(j.Country))
+	}
+
+	if j.InputKeyword != "" {
+		jopts = append(jopts, WithPlaceJobInput(j.InputKeyword, j.InputIndex))
+	}
+
+	if j.Center.Radius > 0 {
+		jopts = append(jopts, WithPlaceJobRadius(j.Center.Lat, j.Center.Lon, j.Center.Radius))
+	}
+
+	if j.EmailSitemap.MaxPages > 0 {
+		jopts = append(jopts, WithPlaceJobEmailSitemap(j.EmailSitemap))
+	}
+
+	if j.Session != nil {
+		jopts = append(jopts, WithPlaceJobSession(j.Session))
+	}
+
+	placeLang := j.LangCode
+	if j.ResultsLang != "" {
+		placeLang = j.ResultsLang
+	}
+
+	if strings.Contains(resp.URL, "/maps/place/") {
+		opts := append(slices.Clip(jopts), WithPlaceJobSerp(j.Query, 1))
+		placeJob := NewPlaceJob(j.ID, placeLang, resp.URL, j.ExtractEmail, opts...)
 
 		next = append(next, placeJob)
 	} else {
+		position := 0
+
 		doc.Find(`div[role=feed] div[jsaction]>a`).Each(func(_ int, s *goquery.Selection) {
 			if href := s.AttrOr("href", ""); href != "" {
-				jopts := []PlaceJobOptions{}
-				if j.ExitMonitor != nil {
-					jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
+				opts := slices.Clip(jopts)
+
+				// ads do not take a position of the organic ranking
+				if isSponsored(s) {
+					if j.ExcludeSponsored {
+						return
+					}
+
+					opts = append(opts, WithPlaceJobSponsored(), WithPlaceJobSerp(j.Query, 0))
+				} else {
+					position++
+
+					opts = append(opts, WithPlaceJobSerp(j.Query, position))
 				}
 
-				nextJob := NewPlaceJob(j.ID, j.LangCode, href, j.ExtractEmail, jopts...)
+				nextJob := NewPlaceJob(j.ID, placeLang, href, j.ExtractEmail, opts...)
 
 				if j.Deduper == nil || j.Deduper.AddIfNotExists(ctx, href) {
 					next = append(next, nextJob)
@@ -147,6 +350,12 @@ func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any,
 func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scrapemate.Response {
 	var resp scrapemate.Response
 
+	if err := j.Session.apply(page); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
 	pageResponse, err := page.Goto(j.GetFullURL(), playwright.PageGotoOptions{
 		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
 	})
@@ -163,6 +372,8 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scra
 		return resp
 	}
 
+	j.Session.update(page)
+
 	const defaultTimeout = 5000
 
 	err = page.WaitForURL(page.URL(), playwright.PageWaitForURLOptions{
@@ -0,0 +1,147 @@
+package gmaps_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// the options of the normal mode behave the same in fast mode
+
+func Test_SearchJobEmailAndDedup(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/output.json")
+	require.NoError(t, err)
+
+	dedup := deduper.New()
+
+	newJob := func() *gmaps.SearchJob {
+		return gmaps.NewSearchJob(&gmaps.MapSearchParams{
+			Location: gmaps.MapLocation{Lat: 38.03, Lon: 23.70, ZoomLvl: 15, Radius: 10000},
+			Query:    "coffee",
+			Hl:       "en",
+		}, gmaps.WithSearchJobExtractEmail(), gmaps.WithSearchJobDeduper(dedup))
+	}
+
+	process := func(job *gmaps.SearchJob) ([]*gmaps.Entry, []scrapemate.IJob) {
+		data, next, err := job.Process(context.Background(), &scrapemate.Response{Body: append([]byte(")]}'\n"), raw...)})
+		require.NoError(t, err)
+
+		entries, ok := data.([]*gmaps.Entry)
+		require.True(t, ok)
+
+		return entries, next
+	}
+
+	entries, next := process(newJob())
+	require.NotEmpty(t, entries)
+	require.NotEmpty(t, next)
+
+	for _, entry := range entries {
+		require.False(t, entry.IsWebsiteValidForEmail(), entry.WebSite)
+	}
+
+	for _, job := range next {
+		emailJob, ok := job.(*gmaps.EmailExtractJob)
+		require.True(t, ok)
+		require.Equal(t, emailJob.Entry.WebSite, emailJob.GetURL())
+	}
+
+	// the same places found by another query are dropped
+	entries, next = process(newJob())
+	require.Empty(t, entries)
+	require.Empty(t, next)
+}
+
+func Test_PlaceJobRadius(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/raw.json")
+	require.NoError(t, err)
+
+	process := func(lat, lon, radius float64) any {
+		job := gmaps.NewPlaceJob("", "en", "https://www.google.com/maps/place/Kipriakon", false,
+			gmaps.WithPlaceJobRadius(lat, lon, radius))
+
+		data, _, err := job.Process(context.Background(), &scrapemate.Response{Meta: map[string]any{"json": raw}})
+		require.NoError(t, err)
+
+		return data
+	}
+
+	// the place is in Limassol
+	require.NotNil(t, process(34.67, 33.04, 1000))
+	require.Nil(t, process(38.03, 23.70, 10000))
+}
+
+func Test_GmapJobRetry(t *testing.T) {
+	monitor := exiter.New()
+	monitor.SetSeedCount(1)
+
+	job := gmaps.NewGmapJob("", "en", "coffee", 1, false, "", 0,
+		gmaps.WithRetries(1), gmaps.WithExitMonitor(monitor))
+
+	fetchErr := errors.New("timeout")
+
+	_, next, err := job.Process(context.Background(), &scrapemate.Response{Error: fetchErr})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+
+	retry, ok := next[0].(*gmaps.GmapJob)
+	require.True(t, ok)
+	require.Equal(t, 1, retry.Attempt)
+	require.Equal(t, gmaps.SeedRetryBackoff, retry.Backoff())
+	require.Equal(t, job.GetURL(), retry.GetURL())
+
+	_, next, err = retry.Process(context.Background(), &scrapemate.Response{Error: fetchErr})
+	require.ErrorIs(t, err, fetchErr)
+	require.Empty(t, next)
+
+	retried, failed := monitor.SeedRetries()
+	require.Equal(t, 1, retried)
+	require.Equal(t, 1, failed)
+}
+
+type pushRecorder struct {
+	scrapemate.JobProvider
+	pushed chan scrapemate.IJob
+}
+
+func (p *pushRecorder) Push(_ context.Context, job scrapemate.IJob) error {
+	p.pushed <- job
+
+	return nil
+}
+
+func Test_RetryProvider(t *testing.T) {
+	backoff := gmaps.SeedRetryBackoff
+	gmaps.SeedRetryBackoff = 50 * time.Millisecond
+
+	t.Cleanup(func() { gmaps.SeedRetryBackoff = backoff })
+
+	recorder := &pushRecorder{pushed: make(chan scrapemate.IJob, 2)}
+	provider := gmaps.NewRetryProvider(recorder)
+
+	job := gmaps.NewGmapJob("", "en", "coffee", 1, false, "", 0, gmaps.WithRetries(1))
+
+	require.NoError(t, provider.Push(context.Background(), job))
+	require.Same(t, job, <-recorder.pushed)
+
+	_, next, err := job.Process(context.Background(), &scrapemate.Response{Error: errors.New("timeout")})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+
+	start := time.Now()
+
+	// the push returns at once, the retry is pushed after its backoff
+	require.NoError(t, provider.Push(context.Background(), next[0]))
+	require.Empty(t, recorder.pushed)
+	require.Same(t, next[0], <-recorder.pushed)
+	require.GreaterOrEqual(t, time.Since(start), gmaps.SeedRetryBackoff)
+}
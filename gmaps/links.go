@@ -0,0 +1,76 @@
+package gmaps
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CidFromDataID returns the cid of a place, the decimal value of the
+// second part of its data id, e.g. 16519582940102929223 for
+// 0x14e732fd76f0d90d:0xe5415928d6702b47. It returns "" for an invalid id.
+func CidFromDataID(dataID string) string {
+	_, feature, ok := strings.Cut(dataID, ":")
+	if !ok || !strings.HasPrefix(feature, "0x") {
+		return ""
+	}
+
+	cid, err := strconv.ParseUint(feature[2:], 16, 64)
+	if err != nil {
+		return ""
+	}
+
+	return strconv.FormatUint(cid, 10)
+}
+
+// setLinks sets the links derived from the data id, and the cid when the
+// place data has none.
+func (e *Entry) setLinks() {
+	cid := CidFromDataID(e.DataID)
+	if cid == "" {
+		return
+	}
+
+	if e.Cid == "" {
+		e.Cid = cid
+	}
+
+	e.MapsURL = "https://www.google.com/maps?cid=" + cid
+
+	query := url.Values{"ludocid": {cid}}
+	if e.Title != "" {
+		query.Set("q", e.Title)
+	}
+
+	e.KnowledgePanelURL = "https://www.google.com/search?" + query.Encode()
+
+	// !9m1!1b1 opens the reviews tab of the place
+	e.ReviewsURL = "https://www.google.com/maps/place/data=!4m4!3m3!1s" + e.DataID + "!9m1!1b1"
+}
+
+// PlaceURL returns the url of the page of a place given its cid or its url
+// on google maps.
+func PlaceURL(place string) (string, error) {
+	place = strings.TrimSpace(place)
+
+	if _, err := strconv.ParseUint(place, 10, 64); err == nil {
+		return "https://www.google.com/maps?cid=" + place, nil
+	}
+
+	u, err := url.Parse(place)
+	if err != nil {
+		return "", err
+	}
+
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+
+	switch {
+	case u.Scheme != "https" && u.Scheme != "http":
+		return "", fmt.Errorf("invalid place %q: not a cid or a url", place)
+	case host == "maps.app.goo.gl", strings.HasPrefix(host, "google."), strings.HasPrefix(host, "maps.google."):
+		return u.String(), nil
+	default:
+		return "", fmt.Errorf("invalid place %q: not a google maps url", place)
+	}
+}
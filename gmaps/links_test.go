@@ -0,0 +1,30 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_CidFromDataID(t *testing.T) {
+	require.Equal(t, "16519582940102929223", gmaps.CidFromDataID("0x14e732fd76f0d90d:0xe5415928d6702b47"))
+	require.Empty(t, gmaps.CidFromDataID("0x14e732fd76f0d90d"))
+	require.Empty(t, gmaps.CidFromDataID("0x14e732fd76f0d90d:0xzz"))
+}
+
+func Test_PlaceURL(t *testing.T) {
+	u, err := gmaps.PlaceURL("16519582940102929223")
+	require.NoError(t, err)
+	require.Equal(t, "https://www.google.com/maps?cid=16519582940102929223", u)
+
+	u, err = gmaps.PlaceURL(" https://www.google.de/maps/place/Kipriakon/@35.1,33.3,17z ")
+	require.NoError(t, err)
+	require.Equal(t, "https://www.google.de/maps/place/Kipriakon/@35.1,33.3,17z", u)
+
+	for _, place := range []string{"", "kipriakon", "ftp://www.google.com/maps", "https://example.com/maps?cid=1"} {
+		_, err := gmaps.PlaceURL(place)
+		require.Error(t, err, place)
+	}
+}
@@ -0,0 +1,54 @@
+package gmaps
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// The orders of the results, see SortEntries.
+const (
+	OrderDistance    = "distance"
+	OrderRating      = "rating"
+	OrderReviewCount = "review_count"
+	OrderTitle       = "title"
+)
+
+// Orders are the valid values of the order_by option.
+var Orders = []string{OrderDistance, OrderRating, OrderReviewCount, OrderTitle}
+
+// SortEntries sorts entries by orderBy: the distance from lat, lon
+// ascending, the rating or the review count descending, or the title
+// alphabetically. The order of the equal entries is kept.
+func SortEntries(entries []*Entry, orderBy string, lat, lon float64) {
+	var compare func(a, b *Entry) int
+
+	switch orderBy {
+	case OrderDistance:
+		compare = func(a, b *Entry) int {
+			return cmp.Compare(a.haversineDistance(lat, lon), b.haversineDistance(lat, lon))
+		}
+	case OrderRating:
+		compare = func(a, b *Entry) int {
+			return cmp.Or(
+				cmp.Compare(b.ReviewRating, a.ReviewRating),
+				cmp.Compare(b.ReviewCount, a.ReviewCount),
+			)
+		}
+	case OrderReviewCount:
+		compare = func(a, b *Entry) int {
+			return cmp.Or(
+				cmp.Compare(b.ReviewCount, a.ReviewCount),
+				cmp.Compare(b.ReviewRating, a.ReviewRating),
+			)
+		}
+	case OrderTitle:
+		compare = func(a, b *Entry) int {
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		}
+	default:
+		return
+	}
+
+	slices.SortStableFunc(entries, compare)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/google/uuid"
@@ -20,6 +21,27 @@ type PlaceJob struct {
 	UsageInResultststs bool
 	ExtractEmail       bool
 	ExitMonitor        exiter.Exiter
+	// English fetches the address and the category in english too.
+	English bool
+	// Base is set for the job that fetches the english variant of Base.
+	Base *Entry
+	// Country drops the place when it is not located in this country.
+	Country string
+	// Center drops the place when it is farther than Center.Radius meters
+	// from Center, if Radius is set.
+	Center MapLocation
+	// Sponsored is set for the places that are ads in the results feed.
+	Sponsored bool
+	// SerpPosition is the 1-based position of the place in the results
+	// of SerpKeyword.
+	SerpPosition int
+	SerpKeyword  string
+	InputKeyword string
+	InputIndex   int
+	// EmailSitemap is passed to the email job of the place.
+	EmailSitemap SitemapOptions
+	// Session keeps the cookies of the browser across the jobs of a user.
+	Session *Session
 }
 
 func NewPlaceJob(parentID, langCode, u string, extractEmail bool, opts ...PlaceJobOptions) *PlaceJob {
@@ -56,6 +78,62 @@ func WithPlaceJobExitMonitor(exitMonitor exiter.Exiter) PlaceJobOptions {
 	}
 }
 
+func WithPlaceJobCountry(country string) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Country = country
+	}
+}
+
+// WithPlaceJobRadius drops the place when it is farther than radius
+// meters from lat, lon, like the radius of the fast mode.
+func WithPlaceJobRadius(lat, lon, radius float64) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Center = MapLocation{Lat: lat, Lon: lon, Radius: radius}
+	}
+}
+
+func WithPlaceJobSponsored() PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Sponsored = true
+	}
+}
+
+func WithPlaceJobSerp(keyword string, position int) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.SerpKeyword = keyword
+		j.SerpPosition = position
+	}
+}
+
+func WithPlaceJobInput(keyword string, index int) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.InputKeyword = keyword
+		j.InputIndex = index
+	}
+}
+
+func WithPlaceJobEnglish() PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.English = true
+	}
+}
+
+// WithPlaceJobEmailSitemap crawls the contact pages of the sitemap of the
+// website when the email is extracted.
+func WithPlaceJobEmailSitemap(opts SitemapOptions) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.EmailSitemap = opts
+	}
+}
+
+// WithPlaceJobSession starts the browser context of the job with the
+// cookies of the session, and keeps its cookies in it.
+func WithPlaceJobSession(s *Session) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Session = s
+	}
+}
+
 func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
@@ -73,14 +151,68 @@ func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, [
 		return nil, nil, err
 	}
 
-	entry.ID = j.ParentID
+	entry.Lang = j.URLParams["hl"]
+
+	if !entry.InCountry(j.Country) || !j.inRadius(&entry) {
+		if j.ExitMonitor != nil {
+			j.ExitMonitor.IncrPlacesCompleted(1)
+		}
+
+		j.UsageInResultststs = false
+
+		return nil, nil, nil
+	}
+
+	if j.Base != nil {
+		j.Base.AddressEnglish = entry.Address
+		j.Base.CategoryEnglish = entry.Category
+
+		entry = *j.Base
+	} else {
+		entry.ID = j.ParentID
+		entry.IsSponsored = j.Sponsored
+		entry.SerpPosition = j.SerpPosition
+		entry.SerpKeyword = j.SerpKeyword
+		entry.InputKeyword = j.InputKeyword
+		entry.InputIndex = j.InputIndex
+
+		if entry.Link == "" {
+			entry.Link = j.GetURL()
+		}
+
+		sourceURL := resp.URL
+		if sourceURL == "" {
+			sourceURL = j.GetURL()
+		}
 
-	if entry.Link == "" {
-		entry.Link = j.GetURL()
+		entry.Provenance = newProvenance(sourceURL, entry.Lang, entry.ScrapedAt)
+		entry.setMethod("details", MethodAppState)
+
+		if len(entry.Images) > 0 {
+			entry.setMethod("images", MethodAppState)
+		}
+
+		if j.English && entry.Lang != "en" {
+			opts := []PlaceJobOptions{
+				WithPlaceJobCountry(j.Country),
+				WithPlaceJobEmailSitemap(j.EmailSitemap),
+				WithPlaceJobSession(j.Session),
+			}
+			if j.ExitMonitor != nil {
+				opts = append(opts, WithPlaceJobExitMonitor(j.ExitMonitor))
+			}
+
+			englishJob := NewPlaceJob(j.ParentID, "en", j.GetURL(), j.ExtractEmail, opts...)
+			englishJob.Base = &entry
+
+			j.UsageInResultststs = false
+
+			return nil, []scrapemate.IJob{englishJob}, nil
+		}
 	}
 
 	if j.ExtractEmail && entry.IsWebsiteValidForEmail() {
-		opts := []EmailExtractJobOptions{}
+		opts := []EmailExtractJobOptions{WithEmailJobSitemap(j.EmailSitemap)}
 		if j.ExitMonitor != nil {
 			opts = append(opts, WithEmailJobExitMonitor(j.ExitMonitor))
 		}
@@ -100,7 +232,13 @@ func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, [
 func (j *PlaceJob) BrowserActions(_ context.Context, page playwright.Page) scrapemate.Response {
 	var resp scrapemate.Response
 
-	pageResponse, err := page.Goto(j.GetURL(), playwright.PageGotoOptions{
+	if err := j.Session.apply(page); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	pageResponse, err := page.Goto(withLang(j.GetURL(), j.URLParams["hl"]), playwright.PageGotoOptions{
 		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
 	})
 
@@ -116,6 +254,8 @@ func (j *PlaceJob) BrowserActions(_ context.Context, page playwright.Page) scrap
 		return resp
 	}
 
+	j.Session.update(page)
+
 	const defaultTimeout = 5000
 
 	err = page.WaitForURL(page.URL(), playwright.PageWaitForURLOptions{
@@ -167,9 +307,36 @@ func (j *PlaceJob) UseInResults() bool {
 	return j.UsageInResultststs
 }
 
+// withLang sets the hl parameter of a place url, so the details are
+// returned in lang.
+func withLang(u, lang string) string {
+	if lang == "" {
+		return u
+	}
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+
+	q := parsed.Query()
+	q.Set("hl", lang)
+	parsed.RawQuery = q.Encode()
+
+	return parsed.String()
+}
+
 const js = `
 function parse() {
   const inputString = window.APP_INITIALIZATION_STATE[3][6]
   return inputString
 }
 `
+
+func (j *PlaceJob) inRadius(entry *Entry) bool {
+	if j.Center.Radius <= 0 || (entry.Latitude == 0 && entry.Longitude == 0) {
+		return true
+	}
+
+	return entry.haversineDistance(j.Center.Lat, j.Center.Lon) <= j.Center.Radius
+}
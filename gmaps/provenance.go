@@ -0,0 +1,80 @@
+package gmaps
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Extraction methods recorded in Provenance.Methods.
+const (
+	MethodAppState  = "app_state"
+	MethodSearchAPI = "search_api"
+	MethodMailto    = "mailto"
+	MethodRegex     = "regex"
+	MethodSitemap   = "sitemap"
+)
+
+// Version is the version of the scraper recorded in the provenance of the
+// entries. It can be set at build time with
+//
+//	-ldflags "-X github.com/gosom/google-maps-scraper/gmaps.Version=v1.2.3"
+//
+// and defaults to the module version of the binary.
+var Version = ""
+
+var buildVersion = sync.OnceValue(func() string {
+	if Version != "" {
+		return Version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+})
+
+// Provenance describes where, when and how an entry was scraped.
+type Provenance struct {
+	ScrapedAt time.Time `json:"scraped_at"`
+	SourceURL string    `json:"source_url"`
+	Lang      string    `json:"lang"`
+	// ProxyCountry is the country of the proxy that fetched the place,
+	// when it is known.
+	ProxyCountry string `json:"proxy_country,omitempty"`
+	// Methods maps the parts of the entry to the method that extracted
+	// them, e.g. "emails": "mailto".
+	Methods        map[string]string `json:"methods,omitempty"`
+	ScraperVersion string            `json:"scraper_version"`
+}
+
+// String returns the provenance as json, or an empty string when it is nil.
+func (p *Provenance) String() string {
+	if p == nil {
+		return ""
+	}
+
+	return stringify(p)
+}
+
+func newProvenance(sourceURL, lang string, scrapedAt time.Time) *Provenance {
+	return &Provenance{
+		ScrapedAt:      scrapedAt,
+		SourceURL:      sourceURL,
+		Lang:           lang,
+		ScraperVersion: buildVersion(),
+	}
+}
+
+func (e *Entry) setMethod(part, method string) {
+	if e.Provenance == nil {
+		return
+	}
+
+	if e.Provenance.Methods == nil {
+		e.Provenance.Methods = map[string]string{}
+	}
+
+	e.Provenance.Methods[part] = method
+}
@@ -0,0 +1,70 @@
+package gmaps
+
+import (
+	"context"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	memprovider "github.com/gosom/scrapemate/adapters/providers/memory"
+)
+
+// SeedRetryBackoff is the delay before the first retry of a failed seed
+// job. It doubles on every retry, up to a minute.
+var SeedRetryBackoff = 5 * time.Second
+
+const maxSeedRetryBackoff = time.Minute
+
+// retryBackoff returns the backoff of the attempt-th retry.
+func retryBackoff(attempt int) time.Duration {
+	delay := SeedRetryBackoff
+	for i := 1; i < attempt && delay < maxSeedRetryBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxSeedRetryBackoff)
+}
+
+// backoffJob is a retry that is pushed to the provider after its backoff.
+type backoffJob interface {
+	Backoff() time.Duration
+}
+
+type retryProvider struct {
+	scrapemate.JobProvider
+}
+
+// NewRetryProvider wraps p, the in memory provider if it is nil, so that
+// the retries of the failed searches are pushed to it after their backoff.
+// The worker that ran the search is free in the meantime. The pending
+// retries are only kept in memory and are dropped when ctx is done.
+func NewRetryProvider(p scrapemate.JobProvider) scrapemate.JobProvider {
+	if p == nil {
+		p = memprovider.New()
+	}
+
+	return &retryProvider{JobProvider: p}
+}
+
+func (p *retryProvider) Push(ctx context.Context, job scrapemate.IJob) error {
+	j, ok := job.(backoffJob)
+	if !ok || j.Backoff() <= 0 {
+		return p.JobProvider.Push(ctx, job)
+	}
+
+	go func() {
+		timer := time.NewTimer(j.Backoff())
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if err := p.JobProvider.Push(ctx, job); err != nil {
+			scrapemate.GetLoggerFromContext(ctx).Error("could not push retry", "job", job.GetID(), "error", err)
+		}
+	}()
+
+	return nil
+}
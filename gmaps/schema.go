@@ -0,0 +1,132 @@
+package gmaps
+
+// SchemaVersion is the version of the fields of an Entry in the csv and
+// json outputs. It is increased on every change of the fields, see
+// SchemaChangelog.
+const SchemaVersion = 18
+
+// Field types of the schema. A json field is a json encoded value in the
+// csv output and a nested value in the json outputs. A list is a comma
+// separated value in the csv output and an array in the json outputs. A
+// time is in RFC 3339 format.
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+	TypeList   = "list"
+	TypeJSON   = "json"
+	TypeTime   = "time"
+)
+
+// Field is a field of an Entry. CSV is empty for the fields that are only
+// in the json outputs and JSON for the ones that are only in the csv.
+type Field struct {
+	CSV  string `json:"csv,omitempty"`
+	JSON string `json:"json,omitempty"`
+	Type string `json:"type"`
+}
+
+// SchemaChange describes the changes of a schema version.
+type SchemaChange struct {
+	Version int      `json:"version"`
+	Changes []string `json:"changes"`
+	// Breaking is true when a field was removed, renamed or changed format.
+	Breaking bool `json:"breaking"`
+}
+
+// Schema returns the fields of an Entry in the order of the csv columns.
+func Schema() []Field {
+	return []Field{
+		{"input_id", "input_id", TypeString},
+		{"link", "link", TypeString},
+		{"title", "title", TypeString},
+		{"category", "category", TypeString},
+		{"", "categories", TypeList},
+		{"address", "address", TypeString},
+		{"open_hours", "open_hours", TypeJSON},
+		{"popular_times", "popular_times", TypeJSON},
+		{"website", "web_site", TypeString},
+		{"phone", "phone", TypeString},
+		{"plus_code", "plus_code", TypeString},
+		{"", "geo_confidence", TypeString},
+		{"review_count", "review_count", TypeInt},
+		{"review_rating", "review_rating", TypeFloat},
+		{"reviews_per_rating", "reviews_per_rating", TypeJSON},
+		{"latitude", "latitude", TypeFloat},
+		{"longitude", "longitude", TypeFloat},
+		{"cid", "cid", TypeString},
+		{"status", "status", TypeString},
+		{"descriptions", "description", TypeString},
+		{"reviews_link", "reviews_link", TypeString},
+		{"thumbnail", "thumbnail", TypeString},
+		{"timezone", "timezone", TypeString},
+		{"price_range", "price_range", TypeString},
+		{"data_id", "data_id", TypeString},
+		{"", "maps_url", TypeString},
+		{"", "knowledge_panel_url", TypeString},
+		{"", "reviews_url", TypeString},
+		{"images", "images", TypeJSON},
+		{"", "street_view", TypeJSON},
+		{"reservations", "reservations", TypeJSON},
+		{"order_online", "order_online", TypeJSON},
+		{"menu", "menu", TypeJSON},
+		{"owner", "owner", TypeJSON},
+		{"complete_address", "complete_address", TypeJSON},
+		{"", "address_check", TypeString},
+		{"about", "about", TypeJSON},
+		{"user_reviews", "user_reviews", TypeJSON},
+		{"emails", "emails", TypeList},
+		{"lang", "lang", TypeString},
+		{"address_en", "address_en", TypeString},
+		{"category_en", "category_en", TypeString},
+		{"hours", "hours", TypeJSON},
+		{"open_now", "open_now", TypeBool},
+		{"", "scraped_at", TypeTime},
+		{"", "local_time_at_scrape", TypeTime},
+		{"business_status", "business_status", TypeString},
+		{"claimed", "claimed", TypeBool},
+		{"owner_badges", "owner_badges", TypeList},
+		{"is_sponsored", "is_sponsored", TypeBool},
+		{"serp_position", "serp_position", TypeInt},
+		{"serp_keyword", "serp_keyword", TypeString},
+		{"input_keyword", "input_keyword", TypeString},
+		{"input_index", "input_index", TypeInt},
+		{"provenance", "provenance", TypeJSON},
+		{"street", "", TypeString},
+		{"city", "", TypeString},
+		{"postal_code", "", TypeString},
+		{"state", "", TypeString},
+		{"country", "", TypeString},
+		{"address_check", "", TypeString},
+		{"geo_confidence", "", TypeString},
+		{"scraped_at", "", TypeTime},
+		{"local_time_at_scrape", "", TypeTime},
+		{"street_view", "", TypeJSON},
+		{"maps_url", "", TypeString},
+		{"knowledge_panel_url", "", TypeString},
+		{"reviews_url", "", TypeString},
+	}
+}
+
+// SchemaChangelog lists the changes of the schema, newest first.
+var SchemaChangelog = []SchemaChange{
+	{Version: 18, Changes: []string{"add maps_url, knowledge_panel_url and reviews_url"}},
+	{Version: 17, Changes: []string{"add street_view"}},
+	{Version: 16, Changes: []string{"add author, uploaded_at and is_owner_photo to the images"}},
+	{Version: 15, Changes: []string{"add LocalGuide, LocalGuideLevel, AuthorReviews and AuthorPhotos to the user_reviews"}},
+	{Version: 14, Changes: []string{"add ID and OwnerReply to the user_reviews"}},
+	{Version: 13, Changes: []string{"add scraped_at and local_time_at_scrape"}},
+	{Version: 12, Changes: []string{"add geo_confidence, fill the coordinates or plus_code when one of them is missing"}},
+	{Version: 11, Changes: []string{"add the street, city, postal_code, state and country of complete_address as csv columns", "add address_check"}},
+	{Version: 10, Changes: []string{"rename the json field longtitude to longitude, -legacy-longtitude keeps both"}, Breaking: true},
+	{Version: 9, Changes: []string{"add provenance"}},
+	{Version: 8, Changes: []string{"add input_keyword and input_index"}},
+	{Version: 7, Changes: []string{"add serp_position and serp_keyword"}},
+	{Version: 6, Changes: []string{"add is_sponsored"}},
+	{Version: 5, Changes: []string{"add claimed and owner_badges"}},
+	{Version: 4, Changes: []string{"add business_status"}},
+	{Version: 3, Changes: []string{"add hours in 24h format and open_now, open_hours is unchanged"}},
+	{Version: 2, Changes: []string{"add lang, address_en and category_en"}},
+	{Version: 1, Changes: []string{"initial fields"}},
+}
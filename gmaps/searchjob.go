@@ -5,8 +5,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
 	"github.com/gosom/scrapemate"
 )
@@ -21,18 +25,35 @@ type MapLocation struct {
 }
 
 type MapSearchParams struct {
-	Location  MapLocation
+	// Location is the center and the radius of the search area, the
+	// places outside of it are dropped.
+	Location MapLocation
+	// Tile is the viewport of the request when the area is split into
+	// several searches (see Tiles). Location is the viewport otherwise.
+	Tile      *MapLocation
 	Query     string
 	ViewportW int
 	ViewportH int
 	Hl        string
+	// Country restricts the results to the country with this
+	// ISO 3166-1 alpha-2 code
+	Country string
 }
 
 type SearchJob struct {
 	scrapemate.Job
 
-	params      *MapSearchParams
-	ExitMonitor exiter.Exiter
+	params       *MapSearchParams
+	ExtractEmail bool
+	Deduper      deduper.Deduper
+	ExitMonitor  exiter.Exiter
+	InputKeyword string
+	InputIndex   int
+	EmailSitemap SitemapOptions
+	// Retries and Attempt are like the ones of GmapJob.
+	Retries int
+	Attempt int
+	backoff time.Duration
 }
 
 func NewSearchJob(params *MapSearchParams, opts ...SearchJobOptions) *SearchJob {
@@ -62,27 +83,109 @@ func NewSearchJob(params *MapSearchParams, opts ...SearchJobOptions) *SearchJob
 	return &job
 }
 
+func WithSearchJobInput(keyword string, index int) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.InputKeyword = keyword
+		j.InputIndex = index
+	}
+}
+
+// WithSearchJobExtractEmail visits the website of the places to extract
+// their emails, like the email option of the normal mode.
+func WithSearchJobExtractEmail() SearchJobOptions {
+	return func(j *SearchJob) {
+		j.ExtractEmail = true
+	}
+}
+
+// WithSearchJobEmailSitemap crawls the contact pages of the sitemap of
+// the websites when the emails are extracted.
+func WithSearchJobEmailSitemap(opts SitemapOptions) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.EmailSitemap = opts
+	}
+}
+
+// WithSearchJobDeduper drops the places that another job already found.
+func WithSearchJobDeduper(d deduper.Deduper) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.Deduper = d
+	}
+}
+
 func WithSearchJobExitMonitor(exitMonitor exiter.Exiter) SearchJobOptions {
 	return func(j *SearchJob) {
 		j.ExitMonitor = exitMonitor
 	}
 }
 
-func (j *SearchJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+// WithSearchJobRetries enqueues the search again up to n times when it
+// fails, like WithRetries.
+func WithSearchJobRetries(n int) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.Retries = n
+	}
+}
+
+func (j *SearchJob) ProcessOnFetchError() bool {
+	return true
+}
+
+// Backoff is like the one of GmapJob.
+func (j *SearchJob) Backoff() time.Duration {
+	return j.backoff
+}
+
+func (j *SearchJob) retry(ctx context.Context, err error) (any, []scrapemate.IJob, error) {
+	if j.Attempt >= j.Retries || ctx.Err() != nil {
+		if j.ExitMonitor != nil {
+			j.ExitMonitor.IncrSeedFailed(1)
+		}
+
+		return nil, nil, err
+	}
+
+	scrapemate.GetLoggerFromContext(ctx).Info("retrying search", "query", j.params.Query, "attempt", j.Attempt+1, "error", err)
+
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrSeedRetried(1)
+	}
+
+	next := *j
+	next.Attempt++
+	next.backoff = retryBackoff(next.Attempt)
+
+	return nil, []scrapemate.IJob{&next}, nil
+}
+
+func (j *SearchJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
 		resp.Body = nil
 		resp.Meta = nil
 	}()
 
+	if resp.Error != nil {
+		return j.retry(ctx, resp.Error)
+	}
+
 	body := removeFirstLine(resp.Body)
 	if len(body) == 0 {
-		return nil, nil, fmt.Errorf("empty response body")
+		return j.retry(ctx, fmt.Errorf("empty response body"))
 	}
 
 	entries, err := ParseSearchResults(body)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse search results: %w", err)
+		return j.retry(ctx, fmt.Errorf("failed to parse search results: %w", err))
+	}
+
+	// the position is the order of the response, before the entries
+	// are sorted by distance
+	for i := range entries {
+		entries[i].SerpPosition = i + 1
+		entries[i].SerpKeyword = j.params.Query
+		entries[i].InputKeyword = j.InputKeyword
+		entries[i].InputIndex = j.InputIndex
 	}
 
 	entries = filterAndSortEntriesWithinRadius(entries,
@@ -91,13 +194,58 @@ func (j *SearchJob) Process(_ context.Context, resp *scrapemate.Response) (any,
 		j.params.Location.Radius,
 	)
 
+	entries = slices.DeleteFunc(entries, func(e *Entry) bool {
+		return !e.InCountry(j.params.Country) ||
+			(j.Deduper != nil && !j.Deduper.AddIfNotExists(ctx, e.dedupKey()))
+	})
+
+	for i := range entries {
+		entries[i].Lang = j.params.Hl
+		entries[i].Provenance = newProvenance(j.GetURL(), j.params.Hl, entries[i].ScrapedAt)
+		entries[i].setMethod("details", MethodSearchAPI)
+	}
+
+	// the places with a website are completed by their email job
+	var (
+		next []scrapemate.IJob
+		done = entries
+	)
+
+	if j.ExtractEmail {
+		done = make([]*Entry, 0, len(entries))
+
+		for _, entry := range entries {
+			if !entry.IsWebsiteValidForEmail() {
+				done = append(done, entry)
+
+				continue
+			}
+
+			opts := []EmailExtractJobOptions{WithEmailJobSitemap(j.EmailSitemap)}
+			if j.ExitMonitor != nil {
+				opts = append(opts, WithEmailJobExitMonitor(j.ExitMonitor))
+			}
+
+			next = append(next, NewEmailJob(j.ID, entry, opts...))
+		}
+	}
+
 	if j.ExitMonitor != nil {
 		j.ExitMonitor.IncrSeedCompleted(1)
 		j.ExitMonitor.IncrPlacesFound(len(entries))
-		j.ExitMonitor.IncrPlacesCompleted(len(entries))
+		j.ExitMonitor.IncrPlacesCompleted(len(done))
+	}
+
+	return done, next, nil
+}
+
+// dedupKey identifies the place of a fast mode entry, which has no link.
+func (e *Entry) dedupKey() string {
+	if e.DataID != "" {
+		return "data_id:" + e.DataID
 	}
 
-	return entries, nil, nil
+	return fmt.Sprintf("place:%s@%f,%f", e.Title, e.Latitude, e.Longitude)
 }
 
 func removeFirstLine(data []byte) []byte {
@@ -113,9 +261,41 @@ func removeFirstLine(data []byte) []byte {
 	return data[index+1:]
 }
 
+// The size in pixels of the viewport of the search requests.
+const (
+	searchViewportW = 600
+	searchViewportH = 800
+)
+
+// Split returns one search per tile of the area (see Tiles), so that the
+// results of each request stay close to the center. The places farther
+// than the radius are still dropped.
+func (p *MapSearchParams) Split() []*MapSearchParams {
+	if p.Location.Radius <= 0 {
+		return []*MapSearchParams{p}
+	}
+
+	tiles := Tiles(p.Location, searchViewportW, searchViewportH)
+	ans := make([]*MapSearchParams, 0, len(tiles))
+
+	for i := range tiles {
+		params := *p
+		params.Tile = &tiles[i]
+
+		ans = append(ans, &params)
+	}
+
+	return ans
+}
+
 func buildGoogleMapsParams(params *MapSearchParams) map[string]string {
-	params.ViewportH = 800
-	params.ViewportW = 600
+	params.ViewportH = searchViewportH
+	params.ViewportW = searchViewportW
+
+	viewport := params.Location
+	if params.Tile != nil {
+		viewport = *params.Tile
+	}
 
 	ans := map[string]string{
 		"tbm":      "map",
@@ -127,14 +307,18 @@ func buildGoogleMapsParams(params *MapSearchParams) map[string]string {
 	pb := fmt.Sprintf("!4m12!1m3!1d3826.902183192154!2d%.4f!3d%.4f!2m3!1f0!2f0!3f0!3m2!1i%d!2i%d!4f%.1f!7i20!8i0"+
 		"!10b1!12m22!1m3!18b1!30b1!34e1!2m3!5m1!6e2!20e3!4b0!10b1!12b1!13b1!16b1!17m1!3e1!20m3!5e2!6b1!14b1!46m1!1b0"+
 		"!96b1!19m4!2m3!1i360!2i120!4i8",
-		params.Location.Lon,
-		params.Location.Lat,
+		viewport.Lon,
+		viewport.Lat,
 		params.ViewportW,
 		params.ViewportH,
-		params.Location.ZoomLvl,
+		viewport.ZoomLvl,
 	)
 
 	ans["pb"] = pb
 
+	if params.Country != "" {
+		ans["gl"] = strings.ToLower(params.Country)
+	}
+
 	return ans
 }
@@ -0,0 +1,86 @@
+package gmaps
+
+import (
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Session keeps the cookies of the browser, e.g. the answer to the consent
+// form and the preferences of Google, so that the jobs of a user do not
+// answer the consent form on every new browser context.
+type Session struct {
+	mu      sync.Mutex
+	cookies []playwright.Cookie
+	changed bool
+}
+
+func NewSession(cookies []playwright.Cookie) *Session {
+	return &Session{cookies: cookies}
+}
+
+// Cookies returns the cookies of the session.
+func (s *Session) Cookies() []playwright.Cookie {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]playwright.Cookie(nil), s.cookies...)
+}
+
+// Changed reports whether the browser changed the cookies since the session
+// was created.
+func (s *Session) Changed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.changed
+}
+
+// apply adds the cookies of the session to the context of the page.
+func (s *Session) apply(page playwright.Page) error {
+	if s == nil {
+		return nil
+	}
+
+	cookies := s.Cookies()
+	if len(cookies) == 0 {
+		return nil
+	}
+
+	optional := make([]playwright.OptionalCookie, 0, len(cookies))
+
+	for i := range cookies {
+		c := cookies[i]
+
+		optional = append(optional, playwright.OptionalCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   &c.Domain,
+			Path:     &c.Path,
+			Expires:  &c.Expires,
+			HttpOnly: &c.HttpOnly,
+			Secure:   &c.Secure,
+			SameSite: c.SameSite,
+		})
+	}
+
+	return page.Context().AddCookies(optional)
+}
+
+// update keeps the cookies of the context of the page.
+func (s *Session) update(page playwright.Page) {
+	if s == nil {
+		return
+	}
+
+	cookies, err := page.Context().Cookies()
+	if err != nil || len(cookies) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cookies = cookies
+	s.changed = true
+}
@@ -0,0 +1,272 @@
+package gmaps
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SitemapOptions enables the crawl of the contact pages of a website,
+// found in its sitemap, when the emails of a place are extracted.
+type SitemapOptions struct {
+	// MaxPages is the number of pages fetched besides the home page. The
+	// crawl is disabled when it is 0.
+	MaxPages int
+	// MaxSize is the number of bytes read of a sitemap or a page.
+	MaxSize int64
+}
+
+// DefaultSitemapMaxSize is the MaxSize used when it is not set.
+const DefaultSitemapMaxSize = 1 << 20
+
+const (
+	sitemapUserAgent = "Mozilla/5.0 (compatible; google-maps-scraper)"
+	sitemapTimeout   = 10 * time.Second
+	// the number of sitemaps read, including the sitemap indexes
+	maxSitemaps = 3
+)
+
+// contactKeywords rank the pages of a sitemap by their path, the first
+// keywords rank higher.
+var contactKeywords = []string{
+	"contact", "kontakt", "contacto", "contatti", "contato",
+	"impressum", "imprint", "legal",
+	"about", "uber-uns", "ueber-uns", "quienes-somos", "chi-siamo",
+	"team", "staff", "company",
+}
+
+var sitemapClient = &http.Client{Timeout: sitemapTimeout}
+
+// crawlSitemap returns the emails of the contact pages of the website.
+// The sitemaps are the ones of its robots.txt, or /sitemap.xml, and the
+// paths that robots.txt disallows are not fetched.
+func crawlSitemap(ctx context.Context, site string, opts SitemapOptions) []string {
+	base, err := url.Parse(site)
+	if err != nil || base.Host == "" {
+		return nil
+	}
+
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultSitemapMaxSize
+	}
+
+	robots := readRobots(ctx, base, opts.MaxSize)
+
+	sitemaps := robots.sitemaps
+	if len(sitemaps) == 0 {
+		sitemaps = []string{base.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String()}
+	}
+
+	var pages []string
+
+	for i := 0; i < len(sitemaps) && i < maxSitemaps; i++ {
+		locs, index := readSitemap(ctx, sitemaps[i], opts.MaxSize)
+		if index {
+			sitemaps = append(sitemaps, locs...)
+
+			continue
+		}
+
+		pages = append(pages, locs...)
+	}
+
+	var emails []string
+
+	for _, page := range contactPages(base, pages, robots.disallow, opts.MaxPages) {
+		body, err := fetchLimited(ctx, page, opts.MaxSize)
+		if err != nil {
+			continue
+		}
+
+		found := regexEmailExtractor(body)
+		if doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body)); err == nil {
+			found = append(docEmailExtractor(doc), found...)
+		}
+
+		for _, email := range found {
+			if !slices.Contains(emails, email) {
+				emails = append(emails, email)
+			}
+		}
+	}
+
+	return emails
+}
+
+// contactPages returns up to limit pages of the host of base that match
+// a contact keyword. The pages closer to the root rank higher, then the
+// ones of the first keywords.
+func contactPages(base *url.URL, pages, disallow []string, limit int) []string {
+	type ranked struct {
+		page  string
+		depth int
+		rank  int
+	}
+
+	var candidates []ranked
+
+	for _, page := range pages {
+		u, err := url.Parse(page)
+		if err != nil || !sameSite(u.Hostname(), base.Hostname()) {
+			continue
+		}
+
+		if slices.ContainsFunc(disallow, func(prefix string) bool {
+			return strings.HasPrefix(u.Path, prefix)
+		}) {
+			continue
+		}
+
+		path := strings.ToLower(u.Path)
+
+		rank := slices.IndexFunc(contactKeywords, func(keyword string) bool {
+			return strings.Contains(path, keyword)
+		})
+		if rank < 0 || slices.ContainsFunc(candidates, func(c ranked) bool { return c.page == page }) {
+			continue
+		}
+
+		depth := strings.Count(strings.Trim(u.Path, "/"), "/")
+
+		candidates = append(candidates, ranked{page: page, depth: depth, rank: rank})
+	}
+
+	// /contact and /about before /blog/how-to-contact-us
+	slices.SortStableFunc(candidates, func(a, b ranked) int {
+		return cmp.Or(cmp.Compare(a.depth, b.depth), cmp.Compare(a.rank, b.rank), cmp.Compare(len(a.page), len(b.page)))
+	})
+
+	result := make([]string, 0, min(limit, len(candidates)))
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		result = append(result, candidates[i].page)
+	}
+
+	return result
+}
+
+func sameSite(a, b string) bool {
+	return strings.TrimPrefix(a, "www.") == strings.TrimPrefix(b, "www.")
+}
+
+type robotsRules struct {
+	sitemaps []string
+	disallow []string
+}
+
+// readRobots returns the sitemaps of robots.txt and the paths disallowed
+// for all the user agents.
+func readRobots(ctx context.Context, base *url.URL, maxSize int64) robotsRules {
+	var rules robotsRules
+
+	body, err := fetchLimited(ctx, base.ResolveReference(&url.URL{Path: "/robots.txt"}).String(), maxSize)
+	if err != nil {
+		return rules
+	}
+
+	var (
+		allAgents bool
+		inRules   bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "user-agent":
+			// a group starts with its user agents
+			if inRules {
+				allAgents, inRules = false, false
+			}
+
+			allAgents = allAgents || value == "*"
+		case "disallow":
+			inRules = true
+
+			if allAgents && value != "" {
+				rules.disallow = append(rules.disallow, value)
+			}
+		case "allow":
+			inRules = true
+		case "sitemap":
+			if u, err := url.Parse(value); err == nil && u.IsAbs() {
+				rules.sitemaps = append(rules.sitemaps, value)
+			}
+		}
+	}
+
+	return rules
+}
+
+// readSitemap returns the locations of a sitemap, and whether they are
+// sitemaps of a sitemap index.
+func readSitemap(ctx context.Context, u string, maxSize int64) ([]string, bool) {
+	body, err := fetchLimited(ctx, u, maxSize)
+	if err != nil {
+		return nil, false
+	}
+
+	var sitemap struct {
+		XMLName  xml.Name
+		URLs     []string `xml:"url>loc"`
+		Sitemaps []string `xml:"sitemap>loc"`
+	}
+
+	// a truncated sitemap keeps the locations read before the error
+	_ = xml.Unmarshal(body, &sitemap)
+
+	if sitemap.XMLName.Local == "sitemapindex" {
+		return trimAll(sitemap.Sitemaps), true
+	}
+
+	return trimAll(sitemap.URLs), false
+}
+
+func trimAll(items []string) []string {
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+
+	return items
+}
+
+// fetchLimited returns at most maxSize bytes of the body of u.
+func fetchLimited(ctx context.Context, u string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", sitemapUserAgent)
+
+	resp, err := sitemapClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, u)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxSize))
+}
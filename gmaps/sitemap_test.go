@@ -0,0 +1,67 @@
+package gmaps_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_EmailJobSitemap(t *testing.T) {
+	var fetched []string
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+
+	defer srv.Close()
+
+	page := func(email string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fetched = append(fetched, r.URL.Path)
+
+			fmt.Fprintf(w, `<html><body><a href="mailto:%s">mail us</a></body></html>`, email)
+		}
+	}
+
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, "User-agent: *\nDisallow: /private/\n\nSitemap: %s/sitemap_index.xml\n", srv.URL)
+	})
+	mux.HandleFunc("/sitemap_index.xml", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0"?><sitemapindex><sitemap><loc>%s/pages.xml</loc></sitemap></sitemapindex>`, srv.URL)
+	})
+	mux.HandleFunc("/pages.xml", func(w http.ResponseWriter, _ *http.Request) {
+		var locs []string
+		for _, path := range []string{"/menu", "/private/contact", "/blog/how-to-contact-us", "/about", "/contact"} {
+			locs = append(locs, fmt.Sprintf("<url><loc>%s%s</loc></url>", srv.URL, path))
+		}
+
+		locs = append(locs, "<url><loc>https://example.com/contact</loc></url>")
+
+		fmt.Fprintf(w, `<?xml version="1.0"?><urlset>%s</urlset>`, strings.Join(locs, ""))
+	})
+	mux.HandleFunc("/contact", page("info@example.com"))
+	mux.HandleFunc("/about", page("team@example.com"))
+	mux.HandleFunc("/blog/how-to-contact-us", page("blog@example.com"))
+	mux.HandleFunc("/private/contact", page("private@example.com"))
+
+	entry := &gmaps.Entry{WebSite: srv.URL, Provenance: &gmaps.Provenance{}}
+	job := gmaps.NewEmailJob("", entry, gmaps.WithEmailJobSitemap(gmaps.SitemapOptions{MaxPages: 2}))
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>home</body></html>"))
+	require.NoError(t, err)
+
+	_, _, err = job.Process(context.Background(), &scrapemate.Response{URL: srv.URL, Document: doc})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"/contact", "/about"}, fetched)
+	require.Equal(t, []string{"info@example.com", "team@example.com"}, entry.Emails)
+	require.Equal(t, gmaps.MethodSitemap, entry.Provenance.Methods["emails"])
+}
@@ -0,0 +1,36 @@
+package gmaps
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// sponsoredLabels are the labels of the ads in the results feed, in the
+// languages of the localize package.
+var sponsoredLabels = map[string]bool{
+	"sponsored":     true,
+	"gesponsert":    true,
+	"sponsorisé":    true,
+	"patrocinado":   true,
+	"sponsorizzato": true,
+	"gesponsord":    true,
+	"χορηγούμενο":   true,
+	"sponsorowane":  true,
+	"sponsorlu":     true,
+}
+
+// isSponsored reports whether the place link of the results feed belongs
+// to an ad. Ads are mixed with the organic results and are only told apart
+// by their label.
+func isSponsored(link *goquery.Selection) bool {
+	sponsored := false
+
+	link.Parent().Find("span").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		sponsored = sponsoredLabels[strings.ToLower(strings.TrimSpace(s.Text()))]
+
+		return !sponsored
+	})
+
+	return sponsored
+}
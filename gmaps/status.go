@@ -0,0 +1,52 @@
+package gmaps
+
+import "strings"
+
+const (
+	StatusOpen              = "open"
+	StatusTemporarilyClosed = "temporarily_closed"
+	StatusPermanentlyClosed = "permanently_closed"
+	StatusOpeningSoon       = "opening_soon"
+)
+
+// statusTerms are the texts google maps shows for the closure states, in the
+// languages of the localize package. Anything else is an operating business.
+var statusTerms = []struct {
+	status string
+	terms  []string
+}{
+	{StatusPermanentlyClosed, []string{
+		"permanently closed", "dauerhaft geschlossen", "définitivement fermé",
+		"fermé définitivement", "cerrado permanentemente", "chiuso definitivamente",
+		"chiuso permanentemente", "fechado permanentemente", "permanent gesloten",
+		"μόνιμα κλειστό", "zamknięte na stałe", "kalıcı olarak kapandı",
+	}},
+	{StatusTemporarilyClosed, []string{
+		"temporarily closed", "vorübergehend geschlossen", "fermé temporairement",
+		"temporairement fermé", "cerrado temporalmente", "chiuso temporaneamente",
+		"fechado temporariamente", "tijdelijk gesloten", "προσωρινά κλειστό",
+		"tymczasowo zamknięte", "geçici olarak kapalı",
+	}},
+	{StatusOpeningSoon, []string{
+		"opening soon", "eröffnet demnächst", "eröffnet bald", "ouverture prochaine",
+		"próxima apertura", "prossima apertura", "abre em breve", "inaugura em breve",
+		"binnenkort geopend", "ανοίγει σύντομα", "wkrótce otwarcie", "yakında açılıyor",
+	}},
+}
+
+// ParseBusinessStatus maps the localized status text of a place to one of
+// the Status constants. The hours of an operating business, such as
+// "Closed ⋅ Opens 9 am", are StatusOpen.
+func ParseBusinessStatus(raw string) string {
+	raw = strings.ToLower(raw)
+
+	for _, st := range statusTerms {
+		for _, term := range st.terms {
+			if strings.Contains(raw, term) {
+				return st.status
+			}
+		}
+	}
+
+	return StatusOpen
+}
@@ -0,0 +1,116 @@
+package gmaps
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Table is a secondary output with normalized rows of the data that is
+// nested in the places, so that it can be loaded without parsing json.
+type Table struct {
+	Name    string
+	Headers []string
+	// Key are the headers that identify a row, the rows without them are
+	// not stored in the databases.
+	Key []string
+	// Rows returns the rows of a place, with a value per header.
+	Rows func(*Entry) [][]any
+}
+
+// Records returns the rows of a place as csv records.
+func (t *Table) Records(e *Entry) [][]string {
+	rows := t.Rows(e)
+	records := make([][]string, 0, len(rows))
+
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = fmt.Sprint(v)
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// PopularTimesTable has a row per day and hour of the popular times of
+// the places.
+var PopularTimesTable = Table{
+	Name:    "popular_times",
+	Headers: []string{"cid", "day", "hour", "busyness"},
+	Key:     []string{"cid", "day", "hour"},
+	Rows: func(e *Entry) [][]any {
+		items := e.PopularTimeRows()
+		rows := make([][]any, 0, len(items))
+
+		for _, item := range items {
+			rows = append(rows, []any{e.Cid, item.Day, item.Hour, item.Busyness})
+		}
+
+		return rows
+	},
+}
+
+// ReviewsTable has a row per user review of the places.
+var ReviewsTable = Table{
+	Name: "reviews",
+	Headers: []string{
+		"cid", "review_id", "author", "rating", "date", "text", "owner_reply",
+		"local_guide", "local_guide_level", "author_reviews", "author_photos",
+	},
+	Key: []string{"cid", "review_id"},
+	Rows: func(e *Entry) [][]any {
+		rows := make([][]any, 0, len(e.UserReviews))
+
+		for _, review := range e.UserReviews {
+			rows = append(rows, []any{
+				e.Cid,
+				review.ID,
+				review.Name,
+				review.Rating,
+				review.When,
+				review.Description,
+				review.OwnerReply,
+				review.LocalGuide,
+				review.LocalGuideLevel,
+				review.AuthorReviews,
+				review.AuthorPhotos,
+			})
+		}
+
+		return rows
+	},
+}
+
+// Tables are the secondary outputs by name.
+var Tables = map[string]Table{
+	PopularTimesTable.Name: PopularTimesTable,
+	ReviewsTable.Name:      ReviewsTable,
+}
+
+var weekdays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// PopularTime is the busyness (0-100) of a place at an hour of a day.
+type PopularTime struct {
+	Day      string
+	Hour     int
+	Busyness int
+}
+
+// PopularTimeRows returns the popular times of the place ordered by day,
+// starting on monday, and hour.
+func (e *Entry) PopularTimeRows() []PopularTime {
+	var rows []PopularTime
+
+	for _, day := range weekdays {
+		hours := e.PopularTimes[day]
+
+		for _, hour := range slices.Sorted(maps.Keys(hours)) {
+			rows = append(rows, PopularTime{Day: day, Hour: hour, Busyness: hours[hour]})
+		}
+	}
+
+	return rows
+}
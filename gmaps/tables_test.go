@@ -0,0 +1,45 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_PopularTimesTable(t *testing.T) {
+	entry := gmaps.Entry{
+		Cid: "123",
+		PopularTimes: map[string]map[int]int{
+			"Sunday": {9: 10},
+			"Monday": {18: 80, 7: 5},
+		},
+	}
+
+	require.Equal(t, [][]string{
+		{"123", "Monday", "7", "5"},
+		{"123", "Monday", "18", "80"},
+		{"123", "Sunday", "9", "10"},
+	}, gmaps.PopularTimesTable.Records(&entry))
+
+	require.Empty(t, gmaps.PopularTimesTable.Records(&gmaps.Entry{Cid: "123"}))
+}
+
+func Test_ReviewsTable(t *testing.T) {
+	entry := gmaps.Entry{
+		Cid: "123",
+		UserReviews: []gmaps.Review{
+			{
+				ID: "a", Name: "Jane", Rating: 5, When: "2023-9-2", Description: "Great, \"really\"\ngreat", OwnerReply: "Thanks",
+				LocalGuide: true, LocalGuideLevel: 6, AuthorReviews: 120, AuthorPhotos: 40,
+			},
+			{ID: "b", Name: "John", Rating: 2, When: "2023-8-1"},
+		},
+	}
+
+	require.Equal(t, [][]string{
+		{"123", "a", "Jane", "5", "2023-9-2", "Great, \"really\"\ngreat", "Thanks", "true", "6", "120", "40"},
+		{"123", "b", "John", "2", "2023-8-1", "", "", "false", "0", "0", "0"},
+	}, gmaps.ReviewsTable.Records(&entry))
+}
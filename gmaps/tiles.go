@@ -0,0 +1,98 @@
+package gmaps
+
+import "math"
+
+// MaxTiles is the maximum number of searches a radius is split into. The
+// zoom is lowered until the tiles of the radius are at most MaxTiles.
+const MaxTiles = 25
+
+// The size in pixels of the viewport of the browser, which the results
+// of the normal mode are searched in.
+const (
+	BrowserViewportW = 1280
+	BrowserViewportH = 720
+)
+
+const maxZoom = 21
+
+// Tiles splits the circle of area.Radius meters around area into map
+// viewports of width x height pixels at zoom area.ZoomLvl, so that each
+// search only covers a part of the circle and the places that google
+// ranks from farther away are not scraped. The viewports that do not
+// intersect the circle are left out.
+//
+// Without a zoom level, the highest one whose viewport covers the whole
+// circle is used. Without a radius, the area is returned as it is.
+func Tiles(area MapLocation, width, height int) []MapLocation {
+	if area.Radius <= 0 || width <= 0 || height <= 0 {
+		return []MapLocation{area}
+	}
+
+	zoom := area.ZoomLvl
+	single := zoom <= 0
+
+	if single || zoom > maxZoom {
+		zoom = maxZoom
+	}
+
+	limit := MaxTiles
+	if single {
+		limit = 1
+	}
+
+	for ; zoom > 1; zoom-- {
+		if tiles := tilesAt(area, zoom, width, height, limit); tiles != nil {
+			return tiles
+		}
+	}
+
+	return tilesAt(area, 1, width, height, math.MaxInt)
+}
+
+// tilesAt returns nil when there are more than limit tiles at zoom.
+func tilesAt(area MapLocation, zoom float64, width, height, limit int) []MapLocation {
+	const (
+		// meters per pixel on the equator at zoom 0
+		equatorResolution = 156543.03392
+		metersPerDegree   = 111320
+	)
+
+	cos := math.Cos(area.Lat * math.Pi / 180)
+
+	resolution := equatorResolution * cos / math.Pow(2, zoom)
+	w := float64(width) * resolution
+	h := float64(height) * resolution
+
+	nx := int(math.Ceil(2 * area.Radius / w))
+	ny := int(math.Ceil(2 * area.Radius / h))
+
+	var tiles []MapLocation
+
+	for i := range nx {
+		x := (float64(i)+0.5)*w - float64(nx)*w/2
+
+		for j := range ny {
+			y := (float64(j)+0.5)*h - float64(ny)*h/2
+
+			// the distance of the closest point of the tile to the center
+			dx := math.Max(math.Abs(x)-w/2, 0)
+			dy := math.Max(math.Abs(y)-h/2, 0)
+
+			if math.Hypot(dx, dy) > area.Radius {
+				continue
+			}
+
+			if len(tiles) == limit {
+				return nil
+			}
+
+			tiles = append(tiles, MapLocation{
+				Lat:     area.Lat + y/metersPerDegree,
+				Lon:     area.Lon + x/(metersPerDegree*cos),
+				ZoomLvl: zoom,
+			})
+		}
+	}
+
+	return tiles
+}
@@ -0,0 +1,46 @@
+package gmaps_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_Tiles(t *testing.T) {
+	area := gmaps.MapLocation{Lat: 38.03, Lon: 23.70, ZoomLvl: 15, Radius: 10000}
+
+	tiles := gmaps.Tiles(area, gmaps.BrowserViewportW, gmaps.BrowserViewportH)
+	require.Greater(t, len(tiles), 1)
+	require.LessOrEqual(t, len(tiles), gmaps.MaxTiles)
+
+	for _, tile := range tiles {
+		require.LessOrEqual(t, tile.ZoomLvl, area.ZoomLvl)
+		// the centers of the tiles are at most a tile away from the circle
+		require.Less(t, math.Abs(tile.Lat-area.Lat), 0.2)
+		require.Less(t, math.Abs(tile.Lon-area.Lon), 0.2)
+	}
+
+	// the tiles are symmetric around the center
+	var lat, lon float64
+
+	for _, tile := range tiles {
+		lat += tile.Lat - area.Lat
+		lon += tile.Lon - area.Lon
+	}
+
+	require.InDelta(t, 0, lat, 1e-9)
+	require.InDelta(t, 0, lon, 1e-9)
+
+	// without a zoom level the whole circle fits a single viewport
+	area.ZoomLvl = 0
+	tiles = gmaps.Tiles(area, gmaps.BrowserViewportW, gmaps.BrowserViewportH)
+	require.Len(t, tiles, 1)
+	require.Greater(t, tiles[0].ZoomLvl, 1.0)
+
+	// a small radius is a single search at the requested zoom
+	area = gmaps.MapLocation{Lat: 38.03, Lon: 23.70, ZoomLvl: 15, Radius: 500}
+	require.Equal(t, []gmaps.MapLocation{{Lat: 38.03, Lon: 23.70, ZoomLvl: 15}}, gmaps.Tiles(area, gmaps.BrowserViewportW, gmaps.BrowserViewportH))
+}
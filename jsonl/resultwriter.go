@@ -0,0 +1,76 @@
+package jsonl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// NewResultWriter creates a writer that writes every entry as a JSON object
+// on its own line. The output is flushed after every result, so it can be
+// consumed while the scraper is running (e.g. piped to jq).
+func NewResultWriter(w io.Writer, opts ...Option) scrapemate.ResultWriter {
+	ans := resultWriter{w: bufio.NewWriter(w)}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	return &ans
+}
+
+type Option func(*resultWriter)
+
+// WithLegacyLongtitude also writes the longitude as longtitude, see
+// gmaps.LegacyEntry.
+func WithLegacyLongtitude(legacy bool) Option {
+	return func(r *resultWriter) {
+		r.legacy = legacy
+	}
+}
+
+type resultWriter struct {
+	w      *bufio.Writer
+	legacy bool
+}
+
+func (r *resultWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	enc := json.NewEncoder(r.w)
+	enc.SetEscapeHTML(false)
+
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = append(entries, data)
+		case []*gmaps.Entry:
+			entries = data
+		default:
+			return errors.New("invalid data type")
+		}
+
+		for i := range entries {
+			var v any = entries[i]
+			if r.legacy {
+				v = gmaps.NewLegacyEntry(entries[i])
+			}
+
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+		}
+
+		if err := r.w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+type avroField struct {
+	Name    string `json:"name"`
+	Type    any    `json:"type"`
+	Default any    `json:"default"`
+	kind    string
+	index   int
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// entryAvroSchema builds an avro record schema from the json tags of gmaps.Entry.
+// Nested structures (hours, reviews, ...) are sent as json encoded strings so
+// the schema stays flat and easy to evolve.
+func entryAvroSchema() (string, []avroField, error) {
+	t := reflect.TypeOf(gmaps.Entry{})
+
+	fields := make([]avroField, 0, t.NumField())
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !sf.IsExported() {
+			continue
+		}
+
+		f := avroField{Name: name, index: i}
+
+		switch {
+		case sf.Type == timeType:
+			f.Type, f.Default, f.kind = "string", "", "time"
+		case sf.Type.Kind() == reflect.String:
+			f.Type, f.Default, f.kind = "string", "", "plain"
+		case sf.Type.Kind() == reflect.Bool:
+			f.Type, f.Default, f.kind = "boolean", false, "plain"
+		case sf.Type.Kind() >= reflect.Int && sf.Type.Kind() <= reflect.Int64:
+			f.Type, f.Default, f.kind = "long", 0, "plain"
+		case sf.Type.Kind() == reflect.Float32 || sf.Type.Kind() == reflect.Float64:
+			f.Type, f.Default, f.kind = "double", 0, "plain"
+		case sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.String:
+			f.Type = map[string]string{"type": "array", "items": "string"}
+			f.Default, f.kind = []string{}, "array"
+		default:
+			f.Type, f.Default, f.kind = "string", "", "json"
+		}
+
+		fields = append(fields, f)
+	}
+
+	schema := map[string]any{
+		"type":      "record",
+		"name":      "Entry",
+		"namespace": "com.github.gosom.gmaps",
+		"fields":    fields,
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(data), fields, nil
+}
+
+// entryToAvro converts the entry to the avro json representation expected
+// by the REST proxy.
+func entryToAvro(fields []avroField, entry *gmaps.Entry) (map[string]any, error) {
+	v := reflect.ValueOf(entry).Elem()
+	ans := make(map[string]any, len(fields))
+
+	for _, f := range fields {
+		fv := v.Field(f.index)
+
+		switch f.kind {
+		case "time":
+			ts, _ := fv.Interface().(time.Time)
+			if ts.IsZero() {
+				ans[f.Name] = ""
+			} else {
+				ans[f.Name] = ts.UTC().Format(time.RFC3339Nano)
+			}
+		case "array":
+			if fv.IsNil() {
+				ans[f.Name] = []string{}
+			} else {
+				ans[f.Name] = fv.Interface()
+			}
+		case "json":
+			data, err := json.Marshal(fv.Interface())
+			if err != nil {
+				return nil, err
+			}
+
+			ans[f.Name] = string(data)
+		default:
+			ans[f.Name] = fv.Interface()
+		}
+	}
+
+	return ans, nil
+}
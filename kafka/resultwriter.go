@@ -0,0 +1,284 @@
+// Package kafka publishes the places to a Kafka topic through a Confluent
+// compatible REST proxy (the v2 API), not the Kafka protocol. The proxy owns
+// the broker connections, so SASL, TLS towards the brokers and retries are
+// configured there, and it serializes the Avro messages with the schema
+// registry. The writer itself only needs net/http: TLS is the one of the
+// https url and the only auth is basic auth from the url.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/fieldmap"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	FormatJSON = "json"
+	FormatAvro = "avro"
+
+	defaultBatchSize = 50
+)
+
+type Option func(*resultWriter)
+
+// WithFormat sets the message format. Supported values are json (default) and avro.
+// Avro messages are serialized by the REST proxy using the schema registry.
+func WithFormat(format string) Option {
+	return func(w *resultWriter) {
+		if format != "" {
+			w.format = format
+		}
+	}
+}
+
+func WithBatchSize(size int) Option {
+	return func(w *resultWriter) {
+		if size > 0 {
+			w.batchSize = size
+		}
+	}
+}
+
+// WithMapping publishes the values of the columns of the mapping instead of
+// the entries. It applies to the json format.
+func WithMapping(mapping fieldmap.Mapping) Option {
+	return func(w *resultWriter) {
+		w.mapping = mapping
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(w *resultWriter) {
+		w.client = client
+	}
+}
+
+// NewResultWriter creates a writer that publishes every entry to a Kafka topic
+// through a Confluent compatible REST proxy. The entry cid is used as message key.
+func NewResultWriter(restURL, topic string, opts ...Option) (scrapemate.ResultWriter, error) {
+	u, err := url.Parse(restURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka rest proxy url: %w", err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid kafka rest proxy url: %s", restURL)
+	}
+
+	if topic == "" {
+		return nil, errors.New("kafka topic is required")
+	}
+
+	ans := resultWriter{
+		topic:     topic,
+		format:    FormatJSON,
+		batchSize: defaultBatchSize,
+		client:    &http.Client{Timeout: time.Minute},
+	}
+
+	if u.User != nil {
+		ans.username = u.User.Username()
+		ans.password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	ans.baseURL = strings.TrimSuffix(u.String(), "/")
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	switch ans.format {
+	case FormatJSON:
+	case FormatAvro:
+		if ans.mapping != nil {
+			return nil, errors.New("the kafka field mapping requires the json format")
+		}
+
+		ans.schema, ans.fields, err = entryAvroSchema()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kafka message format: %s", ans.format)
+	}
+
+	return &ans, nil
+}
+
+type resultWriter struct {
+	baseURL   string
+	username  string
+	password  string
+	topic     string
+	format    string
+	batchSize int
+	client    *http.Client
+	mapping   fieldmap.Mapping
+
+	schema   string
+	schemaID int
+	fields   []avroField
+}
+
+type record struct {
+	Key   string `json:"key,omitempty"`
+	Value any    `json:"value"`
+}
+
+type produceRequest struct {
+	KeySchema     string   `json:"key_schema,omitempty"`
+	ValueSchema   string   `json:"value_schema,omitempty"`
+	ValueSchemaID int      `json:"value_schema_id,omitempty"`
+	Records       []record `json:"records"`
+}
+
+func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	buff := make([]*gmaps.Entry, 0, r.batchSize)
+	lastSave := time.Now().UTC()
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			buff = append(buff, data)
+		case []*gmaps.Entry:
+			buff = append(buff, data...)
+		default:
+			return errors.New("invalid data type")
+		}
+
+		if len(buff) >= r.batchSize || time.Now().UTC().Sub(lastSave) >= 10*time.Second {
+			if err := r.publish(ctx, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastSave = time.Now().UTC()
+		}
+	}
+
+	if len(buff) > 0 {
+		if err := r.publish(ctx, buff); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *resultWriter) publish(ctx context.Context, entries []*gmaps.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	payload := produceRequest{
+		Records: make([]record, 0, len(entries)),
+	}
+
+	contentType := "application/vnd.kafka.json.v2+json"
+
+	if r.format == FormatAvro {
+		contentType = "application/vnd.kafka.avro.v2+json"
+
+		payload.KeySchema = `{"type":"string"}`
+
+		if r.schemaID > 0 {
+			payload.ValueSchemaID = r.schemaID
+		} else {
+			payload.ValueSchema = r.schema
+		}
+	}
+
+	for _, entry := range entries {
+		var value any = entry
+
+		if r.mapping != nil {
+			v, err := r.mapping.Object(entry)
+			if err != nil {
+				return err
+			}
+
+			value = v
+		}
+
+		if r.format == FormatAvro {
+			v, err := entryToAvro(r.fields, entry)
+			if err != nil {
+				return err
+			}
+
+			value = v
+		}
+
+		payload.Records = append(payload.Records, record{Key: entry.Cid, Value: value})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	endpoint := r.baseURL + "/topics/" + url.PathEscape(r.topic)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+		return fmt.Errorf("kafka produce failed with status %d: %s", resp.StatusCode, string(msg))
+	}
+
+	var produceResp struct {
+		ValueSchemaID int `json:"value_schema_id"`
+		Offsets       []struct {
+			Partition int    `json:"partition"`
+			ErrorCode int    `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&produceResp); err != nil {
+		return fmt.Errorf("failed to decode kafka produce response: %w", err)
+	}
+
+	if produceResp.ValueSchemaID > 0 {
+		r.schemaID = produceResp.ValueSchemaID
+	}
+
+	for _, o := range produceResp.Offsets {
+		if o.ErrorCode != 0 || o.Error != "" {
+			return fmt.Errorf("kafka produce failed for partition %d: %d %s", o.Partition, o.ErrorCode, o.Error)
+		}
+	}
+
+	return nil
+}
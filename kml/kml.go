@@ -0,0 +1,370 @@
+// Package kml exports the places as KML placemarks, or KMZ archives, to load
+// in Google Earth or Google My Maps. The placemarks are grouped in a folder
+// per category and their icon is colored by rating.
+package kml
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// The export formats.
+const (
+	FormatKML = "kml"
+	FormatKMZ = "kmz"
+)
+
+// Formats are the supported formats.
+var Formats = []string{FormatKML, FormatKMZ}
+
+const (
+	icon          = "https://maps.google.com/mapfiles/kml/paddle/wht-blank.png"
+	uncategorized = "Uncategorized"
+)
+
+// Placemark is the part of a place that goes to a map.
+type Placemark struct {
+	Name        string
+	Category    string
+	Rating      float64
+	ReviewCount int
+	Address     string
+	Phone       string
+	Website     string
+	Latitude    float64
+	Longitude   float64
+	// Link is the Google Maps url of the place.
+	Link string
+}
+
+// FromEntry returns the placemark of a place.
+func FromEntry(e *gmaps.Entry) Placemark {
+	return Placemark{
+		Name:        e.Title,
+		Category:    e.Category,
+		Rating:      e.ReviewRating,
+		ReviewCount: e.ReviewCount,
+		Address:     e.Address,
+		Phone:       e.Phone,
+		Website:     e.WebSite,
+		Latitude:    e.Latitude,
+		Longitude:   e.Longitude,
+		Link:        e.Link,
+	}
+}
+
+// FromRecord returns the placemark of a row of a results CSV file, keyed by
+// the column names.
+func FromRecord(record map[string]string) Placemark {
+	ans := Placemark{
+		Name:     record["title"],
+		Category: record["category"],
+		Address:  record["address"],
+		Phone:    record["phone"],
+		Website:  record["website"],
+		Link:     record["link"],
+	}
+
+	ans.Rating, _ = strconv.ParseFloat(record["review_rating"], 64)
+	ans.ReviewCount, _ = strconv.Atoi(record["review_count"])
+	ans.Latitude, _ = strconv.ParseFloat(record["latitude"], 64)
+	ans.Longitude, _ = strconv.ParseFloat(record["longitude"], 64)
+
+	return ans
+}
+
+// NewResultWriter returns a writer of the results as a KML document in the
+// format, one of Formats. The document is written when the results end.
+func NewResultWriter(w io.Writer, format string) (scrapemate.ResultWriter, error) {
+	kw, err := NewWriter(w, format, "Google Maps places")
+	if err != nil {
+		return nil, err
+	}
+
+	return &resultWriter{w: kw}, nil
+}
+
+type resultWriter struct {
+	w *Writer
+}
+
+func (r *resultWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = append(entries, data)
+		case []*gmaps.Entry:
+			entries = data
+		default:
+			return errors.New("invalid data type")
+		}
+
+		for i := range entries {
+			p := FromEntry(entries[i])
+
+			r.w.Add(&p)
+		}
+	}
+
+	return r.w.Close()
+}
+
+// Writer collects placemarks and writes them as a document, since the
+// placemarks of a category are written together.
+type Writer struct {
+	format  string
+	name    string
+	w       io.Writer
+	folders map[string][]Placemark
+}
+
+// NewWriter returns a writer of a document with the name, in the format,
+// one of Formats.
+func NewWriter(w io.Writer, format, name string) (*Writer, error) {
+	if !slices.Contains(Formats, format) {
+		return nil, fmt.Errorf("unknown kml format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	ans := Writer{
+		format:  format,
+		name:    name,
+		w:       w,
+		folders: make(map[string][]Placemark),
+	}
+
+	return &ans, nil
+}
+
+// ContentType returns the media type of the files of the format.
+func ContentType(format string) string {
+	if format == FormatKMZ {
+		return "application/vnd.google-earth.kmz"
+	}
+
+	return "application/vnd.google-earth.kml+xml"
+}
+
+// Extension returns the file extension of the format.
+func Extension(format string) string {
+	return "." + format
+}
+
+// Add adds a placemark. The places without coordinates are skipped.
+func (w *Writer) Add(p *Placemark) {
+	if p.Latitude == 0 && p.Longitude == 0 {
+		return
+	}
+
+	category := strings.TrimSpace(p.Category)
+	if category == "" {
+		category = uncategorized
+	}
+
+	w.folders[category] = append(w.folders[category], *p)
+}
+
+// Close writes the document.
+func (w *Writer) Close() error {
+	if w.format == FormatKML {
+		return w.writeDocument(w.w)
+	}
+
+	zw := zip.NewWriter(w.w)
+
+	// doc.kml is the document that Google Earth opens in a KMZ archive
+	f, err := zw.Create("doc.kml")
+	if err != nil {
+		return err
+	}
+
+	if err := w.writeDocument(f); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func (w *Writer) writeDocument(out io.Writer) error {
+	doc := document{
+		Name:   w.name,
+		Styles: styles(),
+	}
+
+	categories := make([]string, 0, len(w.folders))
+
+	for category := range w.folders {
+		categories = append(categories, category)
+	}
+
+	slices.Sort(categories)
+
+	for _, category := range categories {
+		f := folder{Name: category}
+
+		for i := range w.folders[category] {
+			f.Placemarks = append(f.Placemarks, newPlacemark(&w.folders[category][i]))
+		}
+
+		doc.Folders = append(doc.Folders, f)
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(kmlRoot{Namespace: "http://www.opengis.net/kml/2.2", Document: doc}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(out, "\n")
+
+	return err
+}
+
+// ratingStyle is the icon color of the places with a rating of at least
+// min, in the aabbggrr order of KML.
+type ratingStyle struct {
+	id    string
+	min   float64
+	color string
+}
+
+// the styles from the best rating, the places without reviews are gray
+var ratingStyles = []ratingStyle{
+	{id: "rating-4.5", min: 4.5, color: "ff00a000"},
+	{id: "rating-4", min: 4, color: "ff00d7a0"},
+	{id: "rating-3", min: 3, color: "ff00c8ff"},
+	{id: "rating-low", min: 0.1, color: "ff0000dc"},
+	{id: "no-rating", color: "ff9e9e9e"},
+}
+
+func styleID(rating float64) string {
+	for _, s := range ratingStyles {
+		if rating >= s.min {
+			return s.id
+		}
+	}
+
+	return ratingStyles[len(ratingStyles)-1].id
+}
+
+func styles() []style {
+	ans := make([]style, len(ratingStyles))
+
+	for i, s := range ratingStyles {
+		ans[i] = style{
+			ID: s.id,
+			IconStyle: iconStyle{
+				Color: s.color,
+				Icon:  iconRef{Href: icon},
+			},
+		}
+	}
+
+	return ans
+}
+
+func newPlacemark(p *Placemark) placemark {
+	ans := placemark{
+		Name:     p.Name,
+		StyleURL: "#" + styleID(p.Rating),
+		Point: point{
+			Coordinates: strconv.FormatFloat(p.Longitude, 'f', -1, 64) + "," + strconv.FormatFloat(p.Latitude, 'f', -1, 64),
+		},
+	}
+
+	var lines []string
+
+	add := func(name, value string) {
+		if value == "" {
+			return
+		}
+
+		lines = append(lines, name+": "+value)
+		ans.ExtendedData.Data = append(ans.ExtendedData.Data, data{Name: name, Value: value})
+	}
+
+	add("Category", p.Category)
+
+	if p.Rating > 0 {
+		add("Rating", strconv.FormatFloat(p.Rating, 'f', -1, 64))
+		add("Reviews", strconv.Itoa(p.ReviewCount))
+	}
+
+	add("Address", p.Address)
+	add("Phone", p.Phone)
+	add("Website", p.Website)
+	add("Google Maps", p.Link)
+
+	ans.Description = strings.Join(lines, "\n")
+
+	return ans
+}
+
+type kmlRoot struct {
+	XMLName   xml.Name `xml:"kml"`
+	Namespace string   `xml:"xmlns,attr"`
+	Document  document `xml:"Document"`
+}
+
+type document struct {
+	Name    string   `xml:"name"`
+	Styles  []style  `xml:"Style"`
+	Folders []folder `xml:"Folder"`
+}
+
+type style struct {
+	ID        string    `xml:"id,attr"`
+	IconStyle iconStyle `xml:"IconStyle"`
+}
+
+type iconStyle struct {
+	Color string  `xml:"color"`
+	Icon  iconRef `xml:"Icon"`
+}
+
+type iconRef struct {
+	Href string `xml:"href"`
+}
+
+type folder struct {
+	Name       string      `xml:"name"`
+	Placemarks []placemark `xml:"Placemark"`
+}
+
+type placemark struct {
+	Name         string       `xml:"name"`
+	Description  string       `xml:"description,omitempty"`
+	StyleURL     string       `xml:"styleUrl"`
+	ExtendedData extendedData `xml:"ExtendedData"`
+	Point        point        `xml:"Point"`
+}
+
+type extendedData struct {
+	Data []data `xml:"Data"`
+}
+
+type data struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type point struct {
+	Coordinates string `xml:"coordinates"`
+}
@@ -0,0 +1,121 @@
+package kml_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/kml"
+)
+
+func testPlacemarks() []kml.Placemark {
+	return []kml.Placemark{
+		kml.FromEntry(&gmaps.Entry{
+			Title:        "Joe's Diner & Bar",
+			Category:     "Diner",
+			ReviewRating: 4.7,
+			ReviewCount:  120,
+			Phone:        "+1 555-0100",
+			Latitude:     39.7817,
+			Longitude:    -89.6501,
+			Link:         "https://www.google.com/maps/place/joes",
+		}),
+		kml.FromRecord(map[string]string{
+			"title":         "Bob's Cafe",
+			"category":      "Cafe",
+			"review_rating": "2.5",
+			"review_count":  "8",
+			"latitude":      "39.8",
+			"longitude":     "-89.6",
+		}),
+		kml.FromEntry(&gmaps.Entry{Title: "Nowhere"}),
+		kml.FromEntry(&gmaps.Entry{Title: "New Place", Latitude: 1, Longitude: 2}),
+	}
+}
+
+func write(t *testing.T, format string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w, err := kml.NewWriter(&buf, format, "Leads")
+	require.NoError(t, err)
+
+	placemarks := testPlacemarks()
+
+	for i := range placemarks {
+		w.Add(&placemarks[i])
+	}
+
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func Test_KML(t *testing.T) {
+	out := write(t, kml.FormatKML)
+
+	var doc struct {
+		Document struct {
+			Name    string `xml:"name"`
+			Folders []struct {
+				Name       string `xml:"name"`
+				Placemarks []struct {
+					Name        string `xml:"name"`
+					StyleURL    string `xml:"styleUrl"`
+					Coordinates string `xml:"Point>coordinates"`
+				} `xml:"Placemark"`
+			} `xml:"Folder"`
+		} `xml:"Document"`
+	}
+
+	require.NoError(t, xml.Unmarshal(out, &doc))
+	require.Equal(t, "Leads", doc.Document.Name)
+
+	// a folder per category in order, without the places with no coordinates
+	require.Len(t, doc.Document.Folders, 3)
+	require.Equal(t, "Cafe", doc.Document.Folders[0].Name)
+	require.Equal(t, "Diner", doc.Document.Folders[1].Name)
+	require.Equal(t, "Uncategorized", doc.Document.Folders[2].Name)
+
+	diner := doc.Document.Folders[1].Placemarks[0]
+	require.Equal(t, "Joe's Diner & Bar", diner.Name)
+	require.Equal(t, "#rating-4.5", diner.StyleURL)
+	require.Equal(t, "-89.6501,39.7817", diner.Coordinates)
+
+	require.Equal(t, "#rating-low", doc.Document.Folders[0].Placemarks[0].StyleURL)
+	require.Equal(t, "#no-rating", doc.Document.Folders[2].Placemarks[0].StyleURL)
+
+	require.Contains(t, string(out), `<Data name="Rating">`)
+	require.Contains(t, string(out), "Google Maps: https://www.google.com/maps/place/joes")
+}
+
+func Test_KMZ(t *testing.T) {
+	out := write(t, kml.FormatKMZ)
+
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	require.Equal(t, "doc.kml", zr.File[0].Name)
+
+	f, err := zr.File[0].Open()
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	doc, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Equal(t, write(t, kml.FormatKML), doc)
+}
+
+func Test_UnknownFormat(t *testing.T) {
+	_, err := kml.NewWriter(io.Discard, "gpx", "Leads")
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "kml, kmz"))
+}
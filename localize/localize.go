@@ -0,0 +1,110 @@
+// Package localize translates the category term of a search query to the
+// language of the search, using a bundled table of common categories.
+// Google Maps matches places by the category names of the local language,
+// so "plumber in Berlin" finds fewer places than "Klempner in Berlin".
+package localize
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed categories.csv
+var categoriesCSV string
+
+var (
+	loadOnce sync.Once
+	termRe   *regexp.Regexp
+	// translations maps the lower case english terms and their plurals
+	// to the translations per language
+	translations map[string]map[string]string
+	languages    map[string]bool
+)
+
+// Query replaces the english category terms of query with their translation
+// to lang (e.g. "de" or "de-AT"). The rest of the query is kept as is.
+// Queries are returned unchanged when the language is not supported.
+func Query(query, lang string) string {
+	loadOnce.Do(load)
+
+	lang, _, _ = strings.Cut(strings.ToLower(lang), "-")
+
+	if !languages[lang] {
+		return query
+	}
+
+	return termRe.ReplaceAllStringFunc(query, func(term string) string {
+		if tr := translations[strings.ToLower(term)][lang]; tr != "" {
+			return tr
+		}
+
+		return term
+	})
+}
+
+// Supported reports whether there are translations for lang.
+func Supported(lang string) bool {
+	loadOnce.Do(load)
+
+	lang, _, _ = strings.Cut(strings.ToLower(lang), "-")
+
+	return languages[lang]
+}
+
+func load() {
+	rows, err := csv.NewReader(strings.NewReader(categoriesCSV)).ReadAll()
+	if err != nil {
+		panic("localize: invalid categories table: " + err.Error())
+	}
+
+	header := rows[0]
+
+	translations = make(map[string]map[string]string)
+	languages = make(map[string]bool, len(header)-1)
+
+	for _, lang := range header[1:] {
+		languages[lang] = true
+	}
+
+	var terms []string
+
+	for _, row := range rows[1:] {
+		tr := make(map[string]string, len(header)-1)
+
+		for i, lang := range header[1:] {
+			tr[lang] = row[i+1]
+		}
+
+		for _, term := range []string{row[0], plural(row[0])} {
+			translations[term] = tr
+			terms = append(terms, term)
+		}
+	}
+
+	// longer terms first, so that "coffee shop" wins over a shorter match
+	sort.Slice(terms, func(i, j int) bool {
+		return len(terms[i]) > len(terms[j])
+	})
+
+	for i := range terms {
+		terms[i] = regexp.QuoteMeta(terms[i])
+	}
+
+	termRe = regexp.MustCompile(`(?i)\b(?:` + strings.Join(terms, "|") + `)\b`)
+}
+
+func plural(term string) string {
+	switch {
+	case strings.HasSuffix(term, "y") && !strings.ContainsAny(term[len(term)-2:len(term)-1], "aeiou"):
+		return term[:len(term)-1] + "ies"
+	case strings.HasSuffix(term, "s"), strings.HasSuffix(term, "sh"),
+		strings.HasSuffix(term, "ch"), strings.HasSuffix(term, "x"):
+		return term + "es"
+	default:
+		return term + "s"
+	}
+}
@@ -0,0 +1,34 @@
+package localize_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/localize"
+)
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		lang  string
+		want  string
+	}{
+		{"translates the category", "plumber in Berlin", "de", "Klempner in Berlin"},
+		{"plural", "Plumbers near Munich", "de", "Klempner near Munich"},
+		{"y plural", "bakeries in Lyon", "fr", "boulangerie in Lyon"},
+		{"longest term wins", "coffee shop Athens", "el", "καφετέρια Athens"},
+		{"region suffix", "dentist Porto", "pt-PT", "dentista Porto"},
+		{"word boundaries", "barbecue in Rome", "it", "barbecue in Rome"},
+		{"unsupported language", "plumber in Tokyo", "ja", "plumber in Tokyo"},
+		{"english", "plumber in London", "en", "plumber in London"},
+		{"unknown term", "zoo in Berlin", "de", "zoo in Berlin"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, localize.Query(tc.query, tc.lang))
+		})
+	}
+}
@@ -8,12 +8,20 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/gosom/kit/logging"
+
+	"github.com/gosom/google-maps-scraper/redact"
 	"github.com/gosom/google-maps-scraper/runner"
 	"github.com/gosom/google-maps-scraper/runner/databaserunner"
+	"github.com/gosom/google-maps-scraper/runner/dbmigrate"
+	"github.com/gosom/google-maps-scraper/runner/doctor"
 	"github.com/gosom/google-maps-scraper/runner/filerunner"
+	"github.com/gosom/google-maps-scraper/runner/gcprunner"
 	"github.com/gosom/google-maps-scraper/runner/installplaywright"
+	"github.com/gosom/google-maps-scraper/runner/k8srunner"
 	"github.com/gosom/google-maps-scraper/runner/lambdaaws"
 	"github.com/gosom/google-maps-scraper/runner/webrunner"
+	"github.com/gosom/google-maps-scraper/service"
 )
 
 func main() {
@@ -21,6 +29,36 @@ func main() {
 
 	runner.Banner()
 
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		if err := service.Command(os.Args[2:]); err != nil {
+			os.Stderr.WriteString(err.Error() + "\n")
+
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "db" {
+		if err := dbmigrate.Command(ctx, os.Args[2:], os.Stdout); err != nil {
+			os.Stderr.WriteString(redact.String(err.Error()) + "\n")
+
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "schema" {
+		if err := runner.SchemaCommand(os.Args[2:], os.Stdout); err != nil {
+			os.Stderr.WriteString(err.Error() + "\n")
+
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
@@ -30,14 +68,32 @@ func main() {
 		log.Println("Received signal, shutting down...")
 
 		cancel()
+
+		<-sigChan
+
+		log.Println("Received second signal, exiting now")
+
+		os.Exit(1)
 	}()
 
 	cfg := runner.ParseConfig()
 
+	redactor := redact.New(cfg.Secrets()...)
+	redacted := redact.NewWriter(os.Stderr, redactor)
+
+	// scrapemate logs with the kit logger, its errors can hold the
+	// credentials of the urls too
+	log.SetOutput(redacted)
+	logging.SetDefault(logging.New("zerolog", logging.INFO, redacted))
+
+	// a failed runner exits without reporting the stop, so that the
+	// service manager restarts it
+	stopped := service.Run(cancel)
+
 	runnerInstance, err := runnerFactory(cfg)
 	if err != nil {
 		cancel()
-		os.Stderr.WriteString(err.Error() + "\n")
+		os.Stderr.WriteString(redactor.String(err.Error()) + "\n")
 
 		runner.Telemetry().Close()
 
@@ -45,7 +101,7 @@ func main() {
 	}
 
 	if err := runnerInstance.Run(ctx); err != nil {
-		os.Stderr.WriteString(err.Error() + "\n")
+		os.Stderr.WriteString(redactor.String(err.Error()) + "\n")
 
 		_ = runnerInstance.Close(ctx)
 		runner.Telemetry().Close()
@@ -59,15 +115,20 @@ func main() {
 	runner.Telemetry().Close()
 
 	cancel()
+	stopped()
 
 	os.Exit(0)
 }
 
 func runnerFactory(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.Doctor {
+		return doctor.New(cfg)
+	}
+
 	switch cfg.RunMode {
 	case runner.RunModeFile:
 		return filerunner.New(cfg)
-	case runner.RunModeDatabase, runner.RunModeDatabaseProduce:
+	case runner.RunModeDatabase, runner.RunModeDatabaseProduce, runner.RunModeWorker:
 		return databaserunner.New(cfg)
 	case runner.RunModeInstallPlaywright:
 		return installplaywright.New(cfg)
@@ -77,6 +138,12 @@ func runnerFactory(cfg *runner.Config) (runner.Runner, error) {
 		return lambdaaws.New(cfg)
 	case runner.RunModeAwsLambdaInvoker:
 		return lambdaaws.NewInvoker(cfg)
+	case runner.RunModeK8sDispatcher:
+		return k8srunner.New(cfg)
+	case runner.RunModeGcpCloudRun:
+		return gcprunner.New(cfg)
+	case runner.RunModeGcpInvoker:
+		return gcprunner.NewInvoker(cfg)
 	default:
 		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
 	}
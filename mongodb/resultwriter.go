@@ -0,0 +1,251 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	defaultDatabase   = "gmaps"
+	defaultCollection = "results"
+	defaultBatchSize  = 50
+)
+
+type Option func(*resultWriter)
+
+func WithDatabase(name string) Option {
+	return func(w *resultWriter) {
+		if name != "" {
+			w.database = name
+		}
+	}
+}
+
+// WithCollection sets the collection name template.
+// The {job_id} placeholder is replaced by the web job id, so each job
+// can be stored in its own collection.
+func WithCollection(name string) Option {
+	return func(w *resultWriter) {
+		if name != "" {
+			w.collection = name
+		}
+	}
+}
+
+// WithJobID sets the value used for the {job_id} placeholder.
+func WithJobID(id string) Option {
+	return func(w *resultWriter) {
+		w.jobID = id
+	}
+}
+
+// NewResultWriter creates a writer that stores the full entry document in
+// MongoDB. Documents are upserted by cid and a 2dsphere index is created
+// on the location field.
+func NewResultWriter(uri string, opts ...Option) (scrapemate.ResultWriter, error) {
+	if !strings.HasPrefix(uri, "mongodb://") && !strings.HasPrefix(uri, "mongodb+srv://") {
+		return nil, errors.New("invalid mongodb uri")
+	}
+
+	ans := resultWriter{
+		uri:        uri,
+		database:   defaultDatabase,
+		collection: defaultCollection,
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	ans.collection = strings.ReplaceAll(ans.collection, "{job_id}", ans.jobID)
+
+	return &ans, nil
+}
+
+type resultWriter struct {
+	uri        string
+	database   string
+	collection string
+	jobID      string
+
+	// mu guards the client of Deliver
+	mu     sync.Mutex
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+// connect returns a client and the collection, whose indexes exist.
+func (r *resultWriter) connect(ctx context.Context) (*mongo.Client, *mongo.Collection, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(r.uri))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coll := client.Database(r.database).Collection(r.collection)
+
+	if err := ensureIndexes(ctx, coll); err != nil {
+		disconnect(client)
+
+		return nil, nil, err
+	}
+
+	return client, coll, nil
+}
+
+func disconnect(client *mongo.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_ = client.Disconnect(ctx)
+}
+
+// Deliver saves the entries with a client that is kept open until Close.
+// The indexes are ensured once, when the client connects.
+func (r *resultWriter) Deliver(ctx context.Context, entries []*gmaps.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.client == nil {
+		client, coll, err := r.connect(ctx)
+		if err != nil {
+			return err
+		}
+
+		r.client, r.coll = client, coll
+	}
+
+	return batchSave(ctx, r.coll, entries)
+}
+
+// Close disconnects the client of Deliver.
+func (r *resultWriter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.client != nil {
+		disconnect(r.client)
+
+		r.client, r.coll = nil, nil
+	}
+
+	return nil
+}
+
+func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	client, coll, err := r.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer disconnect(client)
+
+	buff := make([]*gmaps.Entry, 0, defaultBatchSize)
+	lastSave := time.Now().UTC()
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			buff = append(buff, data)
+		case []*gmaps.Entry:
+			buff = append(buff, data...)
+		default:
+			return errors.New("invalid data type")
+		}
+
+		if len(buff) >= defaultBatchSize || time.Now().UTC().Sub(lastSave) >= time.Minute {
+			if err := batchSave(ctx, coll, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastSave = time.Now().UTC()
+		}
+	}
+
+	if len(buff) > 0 {
+		if err := batchSave(ctx, coll, buff); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ensureIndexes(ctx context.Context, coll *mongo.Collection) error {
+	_, err := coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "cid", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"cid": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+		},
+	})
+
+	return err
+}
+
+func batchSave(ctx context.Context, coll *mongo.Collection, entries []*gmaps.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(entries))
+
+	for _, entry := range entries {
+		doc, err := toDocument(entry)
+		if err != nil {
+			return err
+		}
+
+		if entry.Cid == "" {
+			models = append(models, mongo.NewInsertOneModel().SetDocument(doc))
+
+			continue
+		}
+
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"cid": entry.Cid}).
+			SetReplacement(doc).
+			SetUpsert(true),
+		)
+	}
+
+	_, err := coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+
+	return err
+}
+
+// toDocument uses the json representation of the entry so that the
+// documents have the same field names as the JSON output.
+func toDocument(entry *gmaps.Entry) (bson.M, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bson.M
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if entry.Latitude != 0 || entry.Longitude != 0 {
+		doc["location"] = bson.M{
+			"type":        "Point",
+			"coordinates": []float64{entry.Longitude, entry.Latitude},
+		}
+	}
+
+	return doc, nil
+}
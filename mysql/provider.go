@@ -0,0 +1,245 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	statusNew    = "new"
+	statusQueued = "queued"
+	batchSize    = 10
+)
+
+var _ scrapemate.JobProvider = (*provider)(nil)
+
+type provider struct {
+	db        *sql.DB
+	mu        *sync.Mutex
+	jobc      chan scrapemate.IJob
+	errc      chan error
+	started   bool
+	batchSize int
+}
+
+func NewProvider(db *sql.DB, opts ...ProviderOption) scrapemate.JobProvider {
+	prov := provider{
+		db:        db,
+		mu:        &sync.Mutex{},
+		errc:      make(chan error, 1),
+		batchSize: batchSize,
+	}
+
+	for _, opt := range opts {
+		opt(&prov)
+	}
+
+	prov.jobc = make(chan scrapemate.IJob, 2*prov.batchSize)
+
+	return &prov
+}
+
+// ProviderOption allows configuring the provider
+type ProviderOption func(*provider)
+
+// WithBatchSize sets custom batch size
+func WithBatchSize(size int) ProviderOption {
+	return func(p *provider) {
+		if size > 0 {
+			p.batchSize = size
+		}
+	}
+}
+
+//nolint:gocritic // it contains about unnamed results
+func (p *provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	outc := make(chan scrapemate.IJob)
+	errc := make(chan error, 1)
+
+	p.mu.Lock()
+	if !p.started {
+		go p.fetchJobs(ctx)
+
+		p.started = true
+	}
+	p.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-p.errc:
+				errc <- err
+
+				return
+			case job, ok := <-p.jobc:
+				if !ok {
+					return
+				}
+
+				if job == nil || job.GetID() == "" {
+					continue
+				}
+
+				select {
+				case outc <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return outc, errc
+}
+
+// Push pushes a job to the job provider
+func (p *provider) Push(ctx context.Context, job scrapemate.IJob) error {
+	q := `INSERT IGNORE INTO gmaps_jobs
+		(id, priority, payload_type, payload, created_at, status)
+		VALUES
+		(?, ?, ?, ?, ?, ?)`
+
+	payloadType, payload, err := gmaps.EncodeJob(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, q,
+		job.GetID(), job.GetPriority(), payloadType, payload, time.Now().UTC(), statusNew,
+	)
+
+	return err
+}
+
+func (p *provider) fetchJobs(ctx context.Context) {
+	defer close(p.jobc)
+	defer close(p.errc)
+
+	baseDelay := time.Millisecond * 50
+	maxDelay := time.Millisecond * 300
+	factor := 2
+	currentDelay := baseDelay
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		jobs, err := p.claimJobs(ctx)
+		if err != nil {
+			p.errc <- err
+
+			return
+		}
+
+		if len(jobs) > 0 {
+			for _, job := range jobs {
+				select {
+				case p.jobc <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		} else {
+			select {
+			case <-time.After(currentDelay):
+				currentDelay = time.Duration(float64(currentDelay) * float64(factor))
+				if currentDelay > maxDelay {
+					currentDelay = maxDelay
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// claimJobs marks a batch of new jobs as queued. MySQL has no UPDATE ... RETURNING
+// so the rows are locked with SELECT ... FOR UPDATE SKIP LOCKED (MySQL 8+, MariaDB 10.6+)
+// and updated in the same transaction.
+func (p *provider) claimJobs(ctx context.Context) ([]scrapemate.IJob, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	q := `SELECT id, payload_type, payload FROM gmaps_jobs
+		WHERE status = ?
+		ORDER BY priority ASC, created_at ASC
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.QueryContext(ctx, q, statusNew, p.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var (
+		ids  []any
+		jobs []scrapemate.IJob
+	)
+
+	for rows.Next() {
+		var (
+			id          string
+			payloadType string
+			payload     []byte
+		)
+
+		if err := rows.Scan(&id, &payloadType, &payload); err != nil {
+			return nil, err
+		}
+
+		job, err := gmaps.DecodeJob(payloadType, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := append([]any{statusQueued}, ids...)
+
+	//nolint:gosec // only placeholders are concatenated
+	_, err = tx.ExecContext(ctx, `UPDATE gmaps_jobs SET status = ? WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,206 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+type ResultWriterOption func(*resultWriter)
+
+// WithTables also writes the rows of the tables (e.g. popular_times) to
+// the database tables of the same name. The rows are upserted by the key
+// of the table.
+func WithTables(tables ...gmaps.Table) ResultWriterOption {
+	return func(r *resultWriter) {
+		r.tables = append(r.tables, tables...)
+	}
+}
+
+func NewResultWriter(db *sql.DB, opts ...ResultWriterOption) scrapemate.ResultWriter {
+	ans := resultWriter{db: db}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	return &ans
+}
+
+type resultWriter struct {
+	db     *sql.DB
+	tables []gmaps.Table
+}
+
+func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	const maxBatchSize = 50
+
+	buff := make([]*gmaps.Entry, 0, 50)
+	lastSave := time.Now().UTC()
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			buff = append(buff, data)
+		case []*gmaps.Entry:
+			buff = append(buff, data...)
+		default:
+			return errors.New("invalid data type")
+		}
+
+		if len(buff) >= maxBatchSize || time.Now().UTC().Sub(lastSave) >= time.Minute {
+			err := r.batchSave(ctx, buff)
+			if err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastSave = time.Now().UTC()
+		}
+	}
+
+	if len(buff) > 0 {
+		err := r.batchSave(ctx, buff)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *resultWriter) batchSave(ctx context.Context, entries []*gmaps.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	q := `INSERT INTO results
+		(data, scraped_at)
+		VALUES
+		`
+	elements := make([]string, 0, len(entries))
+	args := make([]interface{}, 0, 2*len(entries))
+
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		elements = append(elements, "(?, ?)")
+		args = append(args, string(data), scrapedAt(entry))
+	}
+
+	q += strings.Join(elements, ", ")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range r.tables {
+		if err := saveTable(ctx, tx, table, entries); err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+
+	return err
+}
+
+// scrapedAt is the time the place was scraped, or the time it is written
+// for the entries that do not have one.
+func scrapedAt(entry *gmaps.Entry) time.Time {
+	if entry.ScrapedAt.IsZero() {
+		return time.Now().UTC()
+	}
+
+	return entry.ScrapedAt
+}
+
+func saveTable(ctx context.Context, tx *sql.Tx, table gmaps.Table, entries []*gmaps.Entry) error {
+	// the maximum number of placeholders of a prepared statement
+	const maxParams = 65535
+
+	rows := tableRows(table, entries)
+	cols := len(table.Headers)
+
+	updates := make([]string, 0, cols)
+
+	for _, h := range table.Headers {
+		if !slices.Contains(table.Key, h) {
+			updates = append(updates, h+" = VALUES("+h+")")
+		}
+	}
+
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", cols), ", ") + ")"
+
+	for batch := range slices.Chunk(rows, maxParams/cols) {
+		elements := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*cols)
+
+		for _, row := range batch {
+			elements = append(elements, placeholders)
+
+			args = append(args, row...)
+		}
+
+		q := `INSERT INTO ` + table.Name + `
+		(` + strings.Join(table.Headers, ", ") + `)
+		VALUES
+		` + strings.Join(elements, ", ") + `
+		ON DUPLICATE KEY UPDATE ` + strings.Join(updates, ", ")
+
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tableRows returns the rows of the table that have a key.
+func tableRows(table gmaps.Table, entries []*gmaps.Entry) [][]any {
+	key := make([]int, 0, len(table.Key))
+
+	for i, h := range table.Headers {
+		if slices.Contains(table.Key, h) {
+			key = append(key, i)
+		}
+	}
+
+	var rows [][]any
+
+	for _, entry := range entries {
+	next:
+		for _, row := range table.Rows(entry) {
+			for _, i := range key {
+				if row[i] == "" || row[i] == nil {
+					continue next
+				}
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
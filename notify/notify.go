@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+// Event describes a change of the status of a job: it started, finished
+// or failed.
+type Event struct {
+	Job web.Job
+	// DownloadURL is the link to the results, empty when the public url
+	// of the web server is not configured or the job failed.
+	DownloadURL string
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, evt Event) error
+}
+
+// Multi sends the event to all the notifiers. A failing notifier does not
+// stop the others.
+func Multi(notifiers ...Notifier) Notifier {
+	return multi(notifiers)
+}
+
+type multi []Notifier
+
+func (m multi) Notify(ctx context.Context, evt Event) error {
+	var errs []error
+
+	for _, n := range m {
+		if err := n.Notify(ctx, evt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Summary is the plain text description of the event that is shared by
+// the notifiers.
+func Summary(evt Event) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Job %q (%s) %s.\n", evt.Job.Name, evt.Job.ID, statusText(evt.Job.Status))
+
+	if s := evt.Job.Stats; s != nil {
+		fmt.Fprintf(&b, "\nPlaces: %d (%d unique)\n", s.PlacesFound, s.UniquePlaces)
+		fmt.Fprintf(&b, "Emails: %d\n", s.EmailsFound)
+		fmt.Fprintf(&b, "Reviews: %d\n", s.ReviewsFetched)
+		fmt.Fprintf(&b, "Average rating: %.2f\n", s.AverageRating)
+		fmt.Fprintf(&b, "Duration: %.0fs\n", s.DurationSeconds)
+
+		if s.FilteredOut > 0 {
+			fmt.Fprintf(&b, "Filtered out: %d\n", s.FilteredOut)
+		}
+
+		if s.SeedsFailed > 0 {
+			fmt.Fprintf(&b, "Failed searches: %d\n", s.SeedsFailed)
+		}
+	}
+
+	if evt.DownloadURL != "" {
+		fmt.Fprintf(&b, "\nDownload: %s\n", evt.DownloadURL)
+	}
+
+	return b.String()
+}
+
+// Done reports whether the job of the event finished or failed.
+func (e Event) Done() bool {
+	return e.Job.Status == web.StatusOK || e.Job.Status == web.StatusFailed
+}
+
+func statusText(status string) string {
+	switch status {
+	case web.StatusOK:
+		return "finished"
+	case web.StatusWorking:
+		return "started"
+	default:
+		return status
+	}
+}
@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+const (
+	discordGreen = 0x2eb67d
+	discordRed   = 0xe01e5a
+	discordBlue  = 0x36c5f0
+)
+
+// NewSlack creates a notifier that posts the job lifecycle to a Slack
+// incoming webhook. With summary the message of a finished job contains
+// its statistics.
+func NewSlack(webhookURL string, summary bool) (Notifier, error) {
+	if err := validateWebhook(webhookURL); err != nil {
+		return nil, err
+	}
+
+	return &slack{webhook: newWebhook(webhookURL), summary: summary}, nil
+}
+
+// NewDiscord creates a notifier that posts the job lifecycle to a Discord
+// webhook. With summary the message of a finished job contains its
+// statistics.
+func NewDiscord(webhookURL string, summary bool) (Notifier, error) {
+	if err := validateWebhook(webhookURL); err != nil {
+		return nil, err
+	}
+
+	return &discord{webhook: newWebhook(webhookURL), summary: summary}, nil
+}
+
+type webhook struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhook(u string) webhook {
+	return webhook{url: u, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func validateWebhook(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook url, it must be an https url")
+	}
+
+	return nil
+}
+
+func (w webhook) post(ctx context.Context, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+		return fmt.Errorf("webhook failed with status %d: %s", resp.StatusCode, string(msg))
+	}
+
+	return nil
+}
+
+type field struct {
+	name  string
+	value string
+}
+
+// fields are the statistics of the summary card.
+func fields(s *web.JobStats) []field {
+	return []field{
+		{"Places", fmt.Sprintf("%d (%d unique)", s.PlacesFound, s.UniquePlaces)},
+		{"Emails", strconv.Itoa(s.EmailsFound)},
+		{"Reviews", strconv.Itoa(s.ReviewsFetched)},
+		{"Average rating", fmt.Sprintf("%.2f", s.AverageRating)},
+		{"Duration", (time.Duration(s.DurationSeconds) * time.Second).String()},
+	}
+}
+
+func headline(evt Event) string {
+	return fmt.Sprintf("Job %q %s", evt.Job.Name, statusText(evt.Job.Status))
+}
+
+type slack struct {
+	webhook
+	summary bool
+}
+
+func (s *slack) Notify(ctx context.Context, evt Event) error {
+	text := headline(evt)
+	if evt.DownloadURL != "" {
+		text += " - <" + evt.DownloadURL + "|download>"
+	}
+
+	blocks := []map[string]any{
+		{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": text}},
+	}
+
+	if s.summary && evt.Job.Stats != nil {
+		var items []map[string]string
+
+		for _, f := range fields(evt.Job.Stats) {
+			items = append(items, map[string]string{"type": "mrkdwn", "text": "*" + f.name + "*\n" + f.value})
+		}
+
+		blocks = append(blocks, map[string]any{"type": "section", "fields": items})
+	}
+
+	if err := s.post(ctx, map[string]any{"text": text, "blocks": blocks}); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+
+	return nil
+}
+
+type discord struct {
+	webhook
+	summary bool
+}
+
+func (d *discord) Notify(ctx context.Context, evt Event) error {
+	embed := map[string]any{
+		"title": headline(evt),
+		"color": discordColor(evt.Job.Status),
+	}
+
+	if evt.DownloadURL != "" {
+		embed["url"] = evt.DownloadURL
+	}
+
+	if d.summary && evt.Job.Stats != nil {
+		var items []map[string]any
+
+		for _, f := range fields(evt.Job.Stats) {
+			items = append(items, map[string]any{"name": f.name, "value": f.value, "inline": true})
+		}
+
+		embed["fields"] = items
+	}
+
+	if err := d.post(ctx, map[string]any{"embeds": []map[string]any{embed}}); err != nil {
+		return fmt.Errorf("discord: %w", err)
+	}
+
+	return nil
+}
+
+func discordColor(status string) int {
+	switch status {
+	case web.StatusOK:
+		return discordGreen
+	case web.StatusFailed:
+		return discordRed
+	default:
+		return discordBlue
+	}
+}
@@ -0,0 +1,307 @@
+// Package client is a Go client for the REST API of the web runner.
+// It uses the request and response types of the web package so it
+// stays in sync with the server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/dryrun"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+// APIError is returned when the API responds with an error status.
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.Code, e.Message)
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *Client) {
+		if c != nil {
+			cl.http = c
+		}
+	}
+}
+
+// WithAdminToken sets the token used for the admin endpoints.
+func WithAdminToken(token string) Option {
+	return func(cl *Client) {
+		cl.adminToken = token
+	}
+}
+
+type Client struct {
+	baseURL    string
+	adminToken string
+	http       *http.Client
+}
+
+// New creates a client for the server at baseURL (e.g. http://localhost:8080).
+func New(baseURL string, opts ...Option) *Client {
+	ans := Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: 5 * time.Minute},
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	return &ans
+}
+
+// CreateJob creates a scraping job and returns its id.
+func (c *Client) CreateJob(ctx context.Context, name string, data *web.JobData) (string, error) {
+	body := struct {
+		Name string `json:"name"`
+		web.JobData
+	}{
+		Name:    name,
+		JobData: *data,
+	}
+
+	// the API expects the max time in seconds
+	body.MaxTime /= time.Second
+
+	var ans struct {
+		ID string `json:"id"`
+	}
+
+	if err := c.do(ctx, http.MethodPost, "/api/v1/jobs", body, &ans, false); err != nil {
+		return "", err
+	}
+
+	return ans.ID, nil
+}
+
+// PlanJob returns the seed jobs and the estimated size of a job without creating it.
+func (c *Client) PlanJob(ctx context.Context, data *web.JobData) (*dryrun.Plan, error) {
+	body := *data
+
+	body.MaxTime /= time.Second
+
+	var ans dryrun.Plan
+
+	if err := c.do(ctx, http.MethodPost, "/api/v1/jobs?dry_run=true", body, &ans, false); err != nil {
+		return nil, err
+	}
+
+	return &ans, nil
+}
+
+func (c *Client) ListJobs(ctx context.Context) ([]web.Job, error) {
+	var ans []web.Job
+
+	if err := c.do(ctx, http.MethodGet, "/api/v1/jobs", nil, &ans, false); err != nil {
+		return nil, err
+	}
+
+	return ans, nil
+}
+
+func (c *Client) GetJob(ctx context.Context, id string) (web.Job, error) {
+	var ans web.Job
+
+	if err := c.do(ctx, http.MethodGet, "/api/v1/jobs/"+url.PathEscape(id), nil, &ans, false); err != nil {
+		return web.Job{}, err
+	}
+
+	return ans, nil
+}
+
+func (c *Client) DeleteJob(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/jobs/"+url.PathEscape(id), nil, nil, false)
+}
+
+// Download writes the CSV results of the job to w. Presigned redirects
+// are followed.
+func (c *Client) Download(ctx context.Context, id string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/jobs/"+url.PathEscape(id)+"/download", http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errorFromResponse(resp)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+
+	return err
+}
+
+// ResultsQuery selects a page of results. An empty Cursor selects the first page.
+type ResultsQuery struct {
+	Cursor string
+	Limit  int
+	Fields []string
+}
+
+func (q *ResultsQuery) encode() string {
+	v := url.Values{}
+
+	if q.Cursor != "" {
+		v.Set("cursor", q.Cursor)
+	}
+
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+
+	if len(q.Fields) > 0 {
+		v.Set("fields", strings.Join(q.Fields, ","))
+	}
+
+	if len(v) == 0 {
+		return ""
+	}
+
+	return "?" + v.Encode()
+}
+
+// JobResults returns a page of the results of the job.
+func (c *Client) JobResults(ctx context.Context, id string, q ResultsQuery) (web.ResultsPage, error) {
+	var ans web.ResultsPage
+
+	if err := c.do(ctx, http.MethodGet, "/api/v1/jobs/"+url.PathEscape(id)+"/results"+q.encode(), nil, &ans, false); err != nil {
+		return web.ResultsPage{}, err
+	}
+
+	return ans, nil
+}
+
+// Results returns a page of the results of all jobs.
+func (c *Client) Results(ctx context.Context, q ResultsQuery) (web.ResultsPage, error) {
+	var ans web.ResultsPage
+
+	if err := c.do(ctx, http.MethodGet, "/api/v1/results"+q.encode(), nil, &ans, false); err != nil {
+		return web.ResultsPage{}, err
+	}
+
+	return ans, nil
+}
+
+// PurgePlace removes the place with the given cid from the results of all jobs.
+func (c *Client) PurgePlace(ctx context.Context, cid string) (web.PurgeResult, error) {
+	var ans web.PurgeResult
+
+	if err := c.do(ctx, http.MethodDelete, "/api/v1/places/"+url.PathEscape(cid), nil, &ans, false); err != nil {
+		return web.PurgeResult{}, err
+	}
+
+	return ans, nil
+}
+
+func (c *Client) AdminStats(ctx context.Context, days int) (web.Stats, error) {
+	var ans web.Stats
+
+	path := fmt.Sprintf("/api/v1/admin/stats?days=%d", days)
+
+	if err := c.do(ctx, http.MethodGet, path, nil, &ans, true); err != nil {
+		return web.Stats{}, err
+	}
+
+	return ans, nil
+}
+
+func (c *Client) AdminRequeueJob(ctx context.Context, id string) (web.Job, error) {
+	var ans web.Job
+
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/jobs/"+url.PathEscape(id)+"/requeue", nil, &ans, true); err != nil {
+		return web.Job{}, err
+	}
+
+	return ans, nil
+}
+
+func (c *Client) AdminFailJob(ctx context.Context, id string) (web.Job, error) {
+	var ans web.Job
+
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/jobs/"+url.PathEscape(id)+"/fail", nil, &ans, true); err != nil {
+		return web.Job{}, err
+	}
+
+	return ans, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any, admin bool) error {
+	var reader io.Reader = http.NoBody
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if admin && c.adminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.adminToken)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errorFromResponse(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func errorFromResponse(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	ans := APIError{Code: resp.StatusCode}
+
+	if err := json.Unmarshal(data, &ans); err != nil || ans.Message == "" {
+		ans.Message = strings.TrimSpace(string(data))
+	}
+
+	ans.Code = resp.StatusCode
+
+	return &ans
+}
@@ -0,0 +1,207 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gosom/scrapemate"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var (
+	// ErrSkip is returned by an EntryFunc to drop the place.
+	ErrSkip = errors.New("skip entry")
+	// ErrStop is returned by an EntryFunc to drop the place and stop the
+	// search. The places that are already written are kept.
+	ErrStop = errors.New("stop search")
+)
+
+// EntryFunc is called for every place as soon as it is scraped, before
+// the writers. Returning ErrSkip drops the place and ErrStop stops the
+// search; any other error stops the search too and is reported.
+type EntryFunc func(ctx context.Context, entry *gmaps.Entry) error
+
+// NewHookWriter returns a writer that calls fn for the places and passes
+// the ones it keeps to writers. stop is called when fn stops the search.
+func NewHookWriter(fn EntryFunc, stop func(), writers ...scrapemate.ResultWriter) *HookWriter {
+	return &HookWriter{fn: fn, stop: stop, writers: writers}
+}
+
+var _ scrapemate.ResultWriter = (*HookWriter)(nil)
+
+type HookWriter struct {
+	fn      EntryFunc
+	stop    func()
+	writers []scrapemate.ResultWriter
+
+	mu      sync.Mutex
+	stopped bool
+	err     error
+	skipped int
+}
+
+func (h *HookWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	// failed is only canceled when a writer fails, the writers keep
+	// receiving the results after the scraping is canceled
+	egroup, failed := errgroup.WithContext(context.WithoutCancel(ctx))
+
+	outs := make([]chan scrapemate.Result, len(h.writers))
+
+	for i, w := range h.writers {
+		outs[i] = make(chan scrapemate.Result)
+
+		egroup.Go(func() error {
+			return w.Run(ctx, outs[i])
+		})
+	}
+
+	for result := range in {
+		// once stopped the results are drained until the scraper exits
+		if h.isStopped() {
+			continue
+		}
+
+		result, ok := h.filter(ctx, result)
+		if !ok {
+			continue
+		}
+
+		for _, out := range outs {
+			select {
+			case out <- result:
+			case <-failed.Done():
+			}
+		}
+	}
+
+	for _, out := range outs {
+		close(out)
+	}
+
+	return egroup.Wait()
+}
+
+// Err returns the error of the EntryFunc that stopped the search, if any.
+func (h *HookWriter) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.err
+}
+
+// Skipped returns the number of places the EntryFunc dropped.
+func (h *HookWriter) Skipped() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.skipped
+}
+
+func (h *HookWriter) filter(ctx context.Context, result scrapemate.Result) (scrapemate.Result, bool) {
+	switch v := result.Data.(type) {
+	case *gmaps.Entry:
+		return result, h.keep(ctx, v)
+	case []*gmaps.Entry:
+		kept := make([]*gmaps.Entry, 0, len(v))
+
+		for _, entry := range v {
+			if h.keep(ctx, entry) {
+				kept = append(kept, entry)
+			}
+		}
+
+		return scrapemate.Result{Job: result.Job, Data: kept}, len(kept) > 0
+	default:
+		return result, true
+	}
+}
+
+func (h *HookWriter) keep(ctx context.Context, entry *gmaps.Entry) bool {
+	if h.isStopped() {
+		return false
+	}
+
+	err := h.fn(ctx, entry)
+
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, ErrSkip):
+		h.mu.Lock()
+		h.skipped++
+		h.mu.Unlock()
+
+		return false
+	case errors.Is(err, ErrStop):
+		err = nil
+	}
+
+	h.mu.Lock()
+	h.stopped = true
+	h.err = err
+	h.mu.Unlock()
+
+	if h.stop != nil {
+		h.stop()
+	}
+
+	return false
+}
+
+func (h *HookWriter) isStopped() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.stopped
+}
+
+// Chain returns an EntryFunc that calls the non nil fns in order until one
+// returns an error. It returns nil when all fns are nil.
+func Chain(fns ...EntryFunc) EntryFunc {
+	var chain []EntryFunc
+
+	for _, fn := range fns {
+		if fn != nil {
+			chain = append(chain, fn)
+		}
+	}
+
+	switch len(chain) {
+	case 0:
+		return nil
+	case 1:
+		return chain[0]
+	}
+
+	return func(ctx context.Context, entry *gmaps.Entry) error {
+		for _, fn := range chain {
+			if err := fn(ctx, entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// Limit returns an EntryFunc that keeps the first n places and stops the
+// search on the next one. It returns nil when n is not positive.
+func Limit(n int) EntryFunc {
+	if n <= 0 {
+		return nil
+	}
+
+	var count atomic.Int64
+
+	return func(context.Context, *gmaps.Entry) error {
+		if count.Add(1) > int64(n) {
+			return ErrStop
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package scraper
+
+import (
+	"context"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// NewLegacyWriter returns a writer that passes the places to w as
+// gmaps.LegacyEntry, so that their json also contains the longtitude
+// field. It is for the writers that encode any result, e.g. the json
+// writer of scrapemate.
+//
+// Deprecated: it will be removed with gmaps.LegacyEntry.
+func NewLegacyWriter(w scrapemate.ResultWriter) scrapemate.ResultWriter {
+	return &legacyWriter{w: w}
+}
+
+type legacyWriter struct {
+	w scrapemate.ResultWriter
+}
+
+func (l *legacyWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- l.w.Run(ctx, out)
+	}()
+
+	var (
+		exited bool
+		err    error
+	)
+
+	// once w exits the results are drained
+	for result := range in {
+		if exited {
+			continue
+		}
+
+		switch v := result.Data.(type) {
+		case *gmaps.Entry:
+			result.Data = gmaps.NewLegacyEntry(v)
+		case []*gmaps.Entry:
+			entries := make([]gmaps.LegacyEntry, len(v))
+			for i := range v {
+				entries[i] = gmaps.NewLegacyEntry(v[i])
+			}
+
+			result.Data = entries
+		}
+
+		select {
+		case out <- result:
+		case err = <-done:
+			exited = true
+		}
+	}
+
+	if exited {
+		return err
+	}
+
+	close(out)
+
+	return <-done
+}
@@ -0,0 +1,32 @@
+package scraper_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/adapters/writers/jsonwriter"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+)
+
+func Test_LegacyWriter(t *testing.T) {
+	in := make(chan scrapemate.Result, 2)
+
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "a", Longitude: 23.7}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "b", Longitude: 33.04}}}
+
+	close(in)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, scraper.NewLegacyWriter(jsonwriter.NewJSONWriter(&buf)).Run(context.Background(), in))
+
+	out := buf.String()
+	require.Contains(t, out, `"longitude":23.7,`)
+	require.Contains(t, out, `"longtitude":23.7}`)
+	require.Contains(t, out, `"longtitude":33.04}]`)
+}
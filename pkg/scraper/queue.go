@@ -0,0 +1,168 @@
+package scraper
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+// QueueStats are the metrics of the queue of a writer.
+type QueueStats struct {
+	Name     string `json:"name"`
+	Depth    int    `json:"depth"`
+	Capacity int    `json:"capacity"`
+	// Enqueued is the number of results that entered the queue.
+	Enqueued int `json:"enqueued"`
+	// BlockedSeconds is the time the scraper waited for the queue to have
+	// room.
+	BlockedSeconds float64 `json:"blocked_seconds"`
+}
+
+// Full returns how full the queue is, from 0 to 1.
+func (s QueueStats) Full() float64 {
+	if s.Capacity == 0 {
+		return 0
+	}
+
+	return float64(s.Depth) / float64(s.Capacity)
+}
+
+// Queues tracks the queues of the running writers.
+type Queues struct {
+	mu      sync.Mutex
+	running []*QueueWriter
+}
+
+// Wrap returns a writer that passes the results to w through a queue of
+// size results. A slow writer buffers at most size results: when its queue
+// is full the results wait, which slows down the scraping instead of
+// dropping them or growing the memory.
+func (q *Queues) Wrap(name string, size int, w scrapemate.ResultWriter) *QueueWriter {
+	return &QueueWriter{name: name, w: w, queue: make(chan scrapemate.Result, max(size, 1)), queues: q}
+}
+
+// Stats returns the metrics of the queues of the running writers.
+func (q *Queues) Stats() []QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ans := make([]QueueStats, 0, len(q.running))
+
+	for _, w := range q.running {
+		ans = append(ans, w.Stats())
+	}
+
+	return ans
+}
+
+// Full returns how full the fullest queue is, from 0 to 1.
+func (q *Queues) Full() float64 {
+	var ans float64
+
+	for _, s := range q.Stats() {
+		ans = max(ans, s.Full())
+	}
+
+	return ans
+}
+
+func (q *Queues) add(w *QueueWriter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.running = append(q.running, w)
+}
+
+func (q *Queues) remove(w *QueueWriter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.running = slices.DeleteFunc(q.running, func(r *QueueWriter) bool { return r == w })
+}
+
+var _ scrapemate.ResultWriter = (*QueueWriter)(nil)
+
+// QueueWriter is a writer with a bounded queue, see Queues.Wrap.
+type QueueWriter struct {
+	name   string
+	w      scrapemate.ResultWriter
+	queue  chan scrapemate.Result
+	queues *Queues
+
+	mu       sync.Mutex
+	enqueued int
+	blocked  time.Duration
+}
+
+func (q *QueueWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	q.queues.add(q)
+	defer q.queues.remove(q)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- q.w.Run(ctx, q.queue)
+	}()
+
+	var (
+		exited bool
+		err    error
+	)
+
+	// once the writer exits the results are drained
+	for result := range in {
+		if exited {
+			continue
+		}
+
+		select {
+		case q.queue <- result:
+			q.add(0)
+
+			continue
+		default:
+		}
+
+		started := time.Now()
+
+		select {
+		case q.queue <- result:
+			q.add(time.Since(started))
+		case err = <-done:
+			exited = true
+		}
+	}
+
+	close(q.queue)
+
+	if !exited {
+		err = <-done
+	}
+
+	return err
+}
+
+// Stats returns the metrics of the queue.
+func (q *QueueWriter) Stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return QueueStats{
+		Name:           q.name,
+		Depth:          len(q.queue),
+		Capacity:       cap(q.queue),
+		Enqueued:       q.enqueued,
+		BlockedSeconds: q.blocked.Seconds(),
+	}
+}
+
+func (q *QueueWriter) add(blocked time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.enqueued++
+	q.blocked += blocked
+}
@@ -0,0 +1,91 @@
+package scraper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+)
+
+// gateWriter does not read the results until release is closed.
+type gateWriter struct {
+	release chan struct{}
+	out     collectWriter
+}
+
+func (w *gateWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	<-w.release
+
+	return w.out.Run(ctx, in)
+}
+
+type failWriter struct{}
+
+func (failWriter) Run(context.Context, <-chan scrapemate.Result) error {
+	return errors.New("unavailable")
+}
+
+func Test_QueueWriter(t *testing.T) {
+	var queues scraper.Queues
+
+	slow := &gateWriter{release: make(chan struct{})}
+	q := queues.Wrap("slow", 2, slow)
+
+	in := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- q.Run(context.Background(), in)
+	}()
+
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "a"}}
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "b"}}
+
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "c"}}
+
+	// the queue is full and c waits for room, the next result waits for
+	// the writer
+	require.Eventually(t, func() bool { return queues.Full() == 1 }, time.Second, 10*time.Millisecond)
+
+	select {
+	case in <- scrapemate.Result{Data: &gmaps.Entry{Title: "d"}}:
+		t.Fatal("the full queue accepted a result")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stats := queues.Stats()
+	require.Len(t, stats, 1)
+	require.Equal(t, "slow", stats[0].Name)
+	require.Equal(t, 2, stats[0].Depth)
+
+	close(slow.release)
+
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "d"}}
+
+	close(in)
+
+	require.NoError(t, <-done)
+	require.Equal(t, []string{"a", "b", "c", "d"}, slow.out.titles)
+	require.Empty(t, queues.Stats())
+
+	// a failed writer does not block the scraping
+	in = make(chan scrapemate.Result)
+
+	go func() {
+		done <- queues.Wrap("failed", 1, failWriter{}).Run(context.Background(), in)
+	}()
+
+	for range 5 {
+		in <- scrapemate.Result{Data: &gmaps.Entry{}}
+	}
+
+	close(in)
+
+	require.Error(t, <-done)
+}
@@ -0,0 +1,93 @@
+package scraper_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+)
+
+func Test_SearchValidation(t *testing.T) {
+	s := scraper.New()
+
+	tests := []struct {
+		name  string
+		query scraper.Query
+	}{
+		{"no keywords", scraper.Query{Keywords: []string{" "}}},
+		{"fast mode without coordinates", scraper.Query{Keywords: []string{"cafe"}, FastMode: true}},
+		{"invalid coordinates", scraper.Query{Keywords: []string{"cafe"}, FastMode: true, GeoCoordinates: "91,0"}},
+		{"invalid zoom", scraper.Query{Keywords: []string{"cafe"}, FastMode: true, GeoCoordinates: "38.7,-9.1", Zoom: 22}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := s.Search(context.Background(), tc.query)
+			require.Error(t, err)
+			require.Nil(t, results)
+		})
+	}
+}
+
+type collectWriter struct {
+	titles []string
+}
+
+func (w *collectWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		switch v := result.Data.(type) {
+		case *gmaps.Entry:
+			w.titles = append(w.titles, v.Title)
+		case []*gmaps.Entry:
+			for _, entry := range v {
+				w.titles = append(w.titles, entry.Title)
+			}
+		}
+	}
+
+	return nil
+}
+
+func Test_HookWriter(t *testing.T) {
+	var (
+		out     collectWriter
+		stopped bool
+	)
+
+	hook := scraper.NewHookWriter(func(_ context.Context, entry *gmaps.Entry) error {
+		switch entry.Title {
+		case "skip":
+			return scraper.ErrSkip
+		case "stop":
+			return scraper.ErrStop
+		default:
+			return nil
+		}
+	}, func() { stopped = true }, &out)
+
+	in := make(chan scrapemate.Result, 4)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "a"}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "skip"}, {Title: "b"}}}
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "stop"}}
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "c"}}
+	close(in)
+
+	require.NoError(t, hook.Run(context.Background(), in))
+	require.NoError(t, hook.Err())
+	require.True(t, stopped)
+	require.Equal(t, []string{"a", "b"}, out.titles)
+}
+
+func Test_Limit(t *testing.T) {
+	require.Nil(t, scraper.Limit(0))
+
+	fn := scraper.Limit(2)
+
+	require.NoError(t, fn(context.Background(), &gmaps.Entry{}))
+	require.NoError(t, fn(context.Background(), &gmaps.Entry{}))
+	require.ErrorIs(t, fn(context.Background(), &gmaps.Entry{}), scraper.ErrStop)
+}
@@ -0,0 +1,85 @@
+package scraper
+
+import (
+	"context"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// NewSortWriter returns a writer that keeps the places until the search
+// ends and passes them to w sorted by orderBy (see gmaps.SortEntries).
+// The other results are passed as they arrive. It returns w when orderBy
+// is empty.
+func NewSortWriter(orderBy string, lat, lon float64, w scrapemate.ResultWriter) scrapemate.ResultWriter {
+	if orderBy == "" {
+		return w
+	}
+
+	return &sortWriter{orderBy: orderBy, lat: lat, lon: lon, w: w}
+}
+
+type sortWriter struct {
+	orderBy  string
+	lat, lon float64
+	w        scrapemate.ResultWriter
+}
+
+func (s *sortWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.w.Run(ctx, out)
+	}()
+
+	var (
+		entries []*gmaps.Entry
+		jobs    = map[*gmaps.Entry]scrapemate.IJob{}
+		exited  bool
+		err     error
+	)
+
+	// once w exits the results are drained
+	send := func(result scrapemate.Result) {
+		if exited {
+			return
+		}
+
+		select {
+		case out <- result:
+		case err = <-done:
+			exited = true
+		}
+	}
+
+	for result := range in {
+		switch v := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = append(entries, v)
+			jobs[v] = result.Job
+		case []*gmaps.Entry:
+			for _, entry := range v {
+				entries = append(entries, entry)
+				jobs[entry] = result.Job
+			}
+		default:
+			send(result)
+		}
+	}
+
+	gmaps.SortEntries(entries, s.orderBy, s.lat, s.lon)
+
+	for _, entry := range entries {
+		send(scrapemate.Result{Job: jobs[entry], Data: entry})
+	}
+
+	if exited {
+		return err
+	}
+
+	close(out)
+
+	return <-done
+}
@@ -0,0 +1,47 @@
+package scraper_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+)
+
+func Test_SortWriter(t *testing.T) {
+	entries := []*gmaps.Entry{
+		{Title: "far", Latitude: 38.1, Longitude: 23.7, ReviewRating: 4.8, ReviewCount: 10},
+		{Title: "Near", Latitude: 38.0, Longitude: 23.7, ReviewRating: 4.2, ReviewCount: 300},
+		{Title: "middle", Latitude: 38.05, Longitude: 23.7, ReviewRating: 4.8, ReviewCount: 50},
+	}
+
+	tests := []struct {
+		orderBy string
+		want    []string
+	}{
+		{"", []string{"far", "Near", "middle"}},
+		{gmaps.OrderDistance, []string{"Near", "middle", "far"}},
+		{gmaps.OrderRating, []string{"middle", "far", "Near"}},
+		{gmaps.OrderReviewCount, []string{"Near", "middle", "far"}},
+		{gmaps.OrderTitle, []string{"far", "middle", "Near"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.orderBy, func(t *testing.T) {
+			in := make(chan scrapemate.Result, len(entries))
+
+			in <- scrapemate.Result{Data: entries[0]}
+			in <- scrapemate.Result{Data: entries[1:]}
+
+			close(in)
+
+			var w collectWriter
+
+			require.NoError(t, scraper.NewSortWriter(tc.orderBy, 38.0, 23.7, &w).Run(context.Background(), in))
+			require.Equal(t, tc.want, w.titles)
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// The statuses of the outbox rows. The delivered rows are deleted.
+const (
+	OutboxPending    = "pending"
+	OutboxDelivering = "delivering"
+	OutboxDead       = "dead"
+)
+
+const (
+	defaultOutboxMaxAttempts = 10
+	outboxBatchSize          = 10
+	outboxPollInterval       = 5 * time.Second
+	outboxMinBackoff         = 30 * time.Second
+	outboxMaxBackoff         = time.Hour
+	// outboxClaimTimeout is how long a relay holds the rows it claimed, a
+	// relay that stopped while delivering releases them when it expires.
+	outboxClaimTimeout = 10 * time.Minute
+)
+
+// Deliverer is implemented by the writers that deliver the entries on a
+// connection kept open between the calls. The relay delivers with it,
+// instead of running the writer, and a new connection, for every row.
+type Deliverer interface {
+	Deliver(ctx context.Context, entries []*gmaps.Entry) error
+	Close() error
+}
+
+// WithOutbox writes the results to the outbox table for every destination,
+// in the transaction of the results, instead of sending them to the
+// destinations directly. The OutboxRelay delivers them.
+func WithOutbox(destinations ...string) ResultWriterOption {
+	return func(r *resultWriter) {
+		r.outbox = append(r.outbox, destinations...)
+	}
+}
+
+// saveOutbox adds a row with the entries for every destination.
+func saveOutbox(ctx context.Context, exec execFunc, destinations []string, entries []*gmaps.Entry) error {
+	if len(destinations) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	for _, destination := range destinations {
+		const q = `INSERT INTO outbox (destination, payload) VALUES ($1, $2)`
+
+		if err := exec(ctx, q, destination, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OutboxRelay delivers the rows of the outbox table to their destinations.
+// A row is deleted once delivered and retried with an exponential backoff
+// otherwise. After the max attempts it is set to dead and kept for
+// inspection. Several relays can run at once, a relay claims the rows it
+// delivers until the claim expires.
+type OutboxRelay struct {
+	db           *sql.DB
+	destinations map[string]scrapemate.ResultWriter
+	maxAttempts  int
+}
+
+// NewOutboxRelay returns a relay that delivers the rows with the writers of
+// their destinations. maxAttempts defaults to 10 when it is not positive.
+func NewOutboxRelay(db *sql.DB, destinations map[string]scrapemate.ResultWriter, maxAttempts int) *OutboxRelay {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultOutboxMaxAttempts
+	}
+
+	return &OutboxRelay{db: db, destinations: destinations, maxAttempts: maxAttempts}
+}
+
+// Run delivers the rows until ctx is canceled.
+func (o *OutboxRelay) Run(ctx context.Context) error {
+	defer o.close()
+
+	for {
+		n, err := o.DeliverBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("outbox: %v", err)
+		}
+
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(outboxPollInterval):
+		}
+	}
+}
+
+type outboxItem struct {
+	id          int64
+	destination string
+	payload     []byte
+	attempts    int
+}
+
+// DeliverBatch delivers the rows that are due and returns how many it
+// handled. The rows are claimed in a short transaction and delivered
+// outside of it, so no row lock is held during the deliveries.
+func (o *OutboxRelay) DeliverBatch(ctx context.Context) (int, error) {
+	items, err := o.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, it := range items {
+		deliverErr := o.deliver(ctx, it.destination, it.payload)
+		if deliverErr == nil {
+			if _, err := o.db.ExecContext(ctx, `DELETE FROM outbox WHERE id = $1`, it.id); err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		if ctx.Err() != nil {
+			// the claim expires and the row is delivered again
+			return 0, ctx.Err()
+		}
+
+		attempts := it.attempts + 1
+
+		status := OutboxPending
+		if attempts >= o.maxAttempts {
+			status = OutboxDead
+
+			log.Printf("outbox: giving up on row %d for %s after %d attempts: %v", it.id, it.destination, attempts, deliverErr)
+		}
+
+		const update = `UPDATE outbox SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, locked_until = NULL
+			WHERE id = $5`
+
+		next := time.Now().UTC().Add(outboxBackoff(attempts))
+
+		if _, err := o.db.ExecContext(ctx, update, status, attempts, deliverErr.Error(), next, it.id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(items), nil
+}
+
+// claim sets the rows that are due, and the ones whose claim expired, to
+// delivering until the claim timeout.
+func (o *OutboxRelay) claim(ctx context.Context) ([]outboxItem, error) {
+	const q = `UPDATE outbox SET status = $1, locked_until = $2
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE (status = $3 AND next_attempt_at <= NOW()) OR (status = $1 AND locked_until < NOW())
+			ORDER BY id
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, destination, payload, attempts`
+
+	lockedUntil := time.Now().UTC().Add(outboxClaimTimeout)
+
+	rows, err := o.db.QueryContext(ctx, q, OutboxDelivering, lockedUntil, OutboxPending, outboxBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var items []outboxItem
+
+	for rows.Next() {
+		var it outboxItem
+
+		if err := rows.Scan(&it.id, &it.destination, &it.payload, &it.attempts); err != nil {
+			return nil, err
+		}
+
+		items = append(items, it)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery
+	slices.SortFunc(items, func(a, b outboxItem) int {
+		return cmp.Compare(a.id, b.id)
+	})
+
+	return items, nil
+}
+
+// deliver sends the entries of the payload to the destination, with its
+// Deliverer or by running its writer. The writers return the error of the
+// delivery.
+func (o *OutboxRelay) deliver(ctx context.Context, destination string, payload []byte) error {
+	w, ok := o.destinations[destination]
+	if !ok {
+		return fmt.Errorf("unknown destination %q", destination)
+	}
+
+	var entries []*gmaps.Entry
+
+	if err := json.Unmarshal(payload, &entries); err != nil {
+		return err
+	}
+
+	if d, ok := w.(Deliverer); ok {
+		return d.Deliver(ctx, entries)
+	}
+
+	in := make(chan scrapemate.Result, 1)
+	in <- scrapemate.Result{Data: entries}
+
+	close(in)
+
+	return w.Run(ctx, in)
+}
+
+// close closes the connections of the destinations.
+func (o *OutboxRelay) close() {
+	for name, w := range o.destinations {
+		if d, ok := w.(Deliverer); ok {
+			if err := d.Close(); err != nil {
+				log.Printf("outbox: failed to close %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// outboxBackoff returns the delay before the next attempt: 30 seconds
+// doubled after every attempt, at most an hour.
+func outboxBackoff(attempts int) time.Duration {
+	ans := outboxMinBackoff
+
+	for range attempts - 1 {
+		ans *= 2
+
+		if ans >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+
+	return ans
+}
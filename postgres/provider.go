@@ -1,10 +1,8 @@
 package postgres
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
-	"encoding/gob"
 	"fmt"
 	"sync"
 	"time"
@@ -17,6 +15,8 @@ import (
 const (
 	statusNew    = "new"
 	statusQueued = "queued"
+	statusDone   = "done"
+	statusFailed = "failed"
 	batchSize    = 10
 )
 
@@ -29,6 +29,11 @@ type provider struct {
 	errc      chan error
 	started   bool
 	batchSize int
+
+	workerID     string
+	leaseTimeout time.Duration
+	maxAttempts  int
+	leases       *leases
 }
 
 func NewProvider(db *sql.DB, opts ...ProviderOption) scrapemate.JobProvider {
@@ -45,6 +50,10 @@ func NewProvider(db *sql.DB, opts ...ProviderOption) scrapemate.JobProvider {
 
 	prov.jobc = make(chan scrapemate.IJob, 2*prov.batchSize)
 
+	if prov.workerID != "" && prov.leases == nil {
+		prov.leases = newLeases()
+	}
+
 	return &prov
 }
 
@@ -60,6 +69,18 @@ func WithBatchSize(size int) ProviderOption {
 	}
 }
 
+// WithLease enables job leasing for the given worker.
+// Fetched jobs are leased for timeout and become visible to other workers
+// again if the lease is not renewed (see Worker) or the job is not completed.
+// A job is marked as failed after maxAttempts expired leases.
+func WithLease(workerID string, timeout time.Duration, maxAttempts int) ProviderOption {
+	return func(p *provider) {
+		p.workerID = workerID
+		p.leaseTimeout = timeout
+		p.maxAttempts = maxAttempts
+	}
+}
+
 //nolint:gocritic // it contains about unnamed results
 func (p *provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
 	outc := make(chan scrapemate.IJob)
@@ -110,36 +131,13 @@ func (p *provider) Push(ctx context.Context, job scrapemate.IJob) error {
 		VALUES
 		($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`
 
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-
-	var payloadType string
-
-	switch j := job.(type) {
-	case *gmaps.GmapJob:
-		payloadType = "search"
-
-		if err := enc.Encode(j); err != nil {
-			return err
-		}
-	case *gmaps.PlaceJob:
-		payloadType = "place"
-
-		if err := enc.Encode(j); err != nil {
-			return err
-		}
-	case *gmaps.EmailExtractJob:
-		payloadType = "email"
-
-		if err := enc.Encode(j); err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("invalid job type %T", job)
+	payloadType, payload, err := gmaps.EncodeJob(job)
+	if err != nil {
+		return err
 	}
 
-	_, err := p.db.ExecContext(ctx, q,
-		job.GetID(), job.GetPriority(), payloadType, buf.Bytes(), time.Now().UTC(), statusNew,
+	_, err = p.db.ExecContext(ctx, q,
+		job.GetID(), job.GetPriority(), payloadType, payload, time.Now().UTC(), statusNew,
 	)
 
 	return err
@@ -161,9 +159,31 @@ func (p *provider) fetchJobs(ctx context.Context) {
 		)
 		RETURNING *
 	)
-	SELECT payload_type, payload from updated ORDER by priority ASC, created_at ASC
+	SELECT id, payload_type, payload from updated ORDER by priority ASC, created_at ASC
 	`
 
+	args := []any{statusQueued, statusNew, p.batchSize}
+
+	if p.workerID != "" {
+		q = `
+		WITH updated AS (
+			UPDATE gmaps_jobs
+			SET status = $1, worker_id = $4, lease_expires_at = NOW() + $5::interval, attempts = attempts + 1
+			WHERE id IN (
+				SELECT id from gmaps_jobs
+				WHERE status = $2
+				OR (status = $1 AND lease_expires_at < NOW() AND attempts < $6)
+				ORDER BY priority ASC, created_at ASC FOR UPDATE SKIP LOCKED
+			LIMIT $3
+			)
+			RETURNING *
+		)
+		SELECT id, payload_type, payload from updated ORDER by priority ASC, created_at ASC
+		`
+
+		args = append(args, p.workerID, fmt.Sprintf("%d milliseconds", p.leaseTimeout.Milliseconds()), p.maxAttempts)
+	}
+
 	baseDelay := time.Millisecond * 50
 	maxDelay := time.Millisecond * 300
 	factor := 2
@@ -178,7 +198,7 @@ func (p *provider) fetchJobs(ctx context.Context) {
 		default:
 		}
 
-		rows, err := p.db.QueryContext(ctx, q, statusQueued, statusNew, p.batchSize)
+		rows, err := p.db.QueryContext(ctx, q, args...)
 		if err != nil {
 			p.errc <- err
 
@@ -187,23 +207,30 @@ func (p *provider) fetchJobs(ctx context.Context) {
 
 		for rows.Next() {
 			var (
+				id          string
 				payloadType string
 				payload     []byte
 			)
 
-			if err := rows.Scan(&payloadType, &payload); err != nil {
+			if err := rows.Scan(&id, &payloadType, &payload); err != nil {
 				p.errc <- err
 
 				return
 			}
 
-			job, err := decodeJob(payloadType, payload)
+			job, err := gmaps.DecodeJob(payloadType, payload)
 			if err != nil {
 				p.errc <- err
 
 				return
 			}
 
+			if p.workerID != "" {
+				p.leases.add(id)
+
+				job = &leasedJob{IJob: job, provider: p, id: id}
+			}
+
 			jobs = append(jobs, job)
 		}
 
@@ -243,38 +270,93 @@ func (p *provider) fetchJobs(ctx context.Context) {
 	}
 }
 
-type encjob struct {
-	Type string
-	Data scrapemate.IJob
+// leasedJob completes the lease of the job once it has been processed,
+// so it is not renewed anymore. The job is processed on fetch errors too,
+// otherwise scrapemate skips Process and the lease is never completed.
+type leasedJob struct {
+	scrapemate.IJob
+	provider *provider
+	id       string
 }
 
-func decodeJob(payloadType string, payload []byte) (scrapemate.IJob, error) {
-	buf := bytes.NewBuffer(payload)
-	dec := gob.NewDecoder(buf)
+func (j *leasedJob) ProcessOnFetchError() bool {
+	return true
+}
 
-	switch payloadType {
-	case "search":
-		j := new(gmaps.GmapJob)
-		if err := dec.Decode(j); err != nil {
-			return nil, fmt.Errorf("failed to decode search job: %w", err)
-		}
+func (j *leasedJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	ctx = context.WithoutCancel(ctx)
 
-		return j, nil
-	case "place":
-		j := new(gmaps.PlaceJob)
-		if err := dec.Decode(j); err != nil {
-			return nil, fmt.Errorf("failed to decode place job: %w", err)
-		}
+	if resp.Error != nil && !j.IJob.ProcessOnFetchError() {
+		j.provider.release(ctx, j.id)
 
-		return j, nil
-	case "email":
-		j := new(gmaps.EmailExtractJob)
-		if err := dec.Decode(j); err != nil {
-			return nil, fmt.Errorf("failed to decode email job: %w", err)
-		}
+		return nil, nil, resp.Error
+	}
+
+	data, next, err := j.IJob.Process(ctx, resp)
+	if err != nil {
+		j.provider.complete(ctx, j.id, statusFailed)
 
-		return j, nil
-	default:
-		return nil, fmt.Errorf("invalid payload type: %s", payloadType)
+		return nil, nil, err
 	}
+
+	j.provider.complete(ctx, j.id, statusDone)
+
+	return data, next, nil
+}
+
+// complete ends the lease of the job with the given status.
+func (p *provider) complete(ctx context.Context, id, status string) {
+	defer p.leases.remove(id)
+
+	q := `UPDATE gmaps_jobs SET status = $1, lease_expires_at = NULL WHERE id = $2 AND worker_id = $3`
+
+	_, _ = p.db.ExecContext(ctx, q, status, id, p.workerID)
+}
+
+// release puts a job whose fetch failed back in the queue, so that another
+// attempt is made until maxAttempts.
+func (p *provider) release(ctx context.Context, id string) {
+	defer p.leases.remove(id)
+
+	q := `UPDATE gmaps_jobs
+		SET status = CASE WHEN attempts < $1 THEN $2 ELSE $3 END, worker_id = NULL, lease_expires_at = NULL
+		WHERE id = $4 AND worker_id = $5`
+
+	_, _ = p.db.ExecContext(ctx, q, p.maxAttempts, statusNew, statusFailed, id, p.workerID)
+}
+
+// leases are the ids of the jobs a worker has fetched and not completed yet.
+type leases struct {
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+func newLeases() *leases {
+	return &leases{ids: map[string]struct{}{}}
+}
+
+func (l *leases) add(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.ids[id] = struct{}{}
+}
+
+func (l *leases) remove(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.ids, id)
+}
+
+func (l *leases) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ans := make([]string, 0, len(l.ids))
+	for id := range l.ids {
+		ans = append(ans, id)
+	}
+
+	return ans
 }
@@ -6,55 +6,128 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/gosom/scrapemate"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/gosom/google-maps-scraper/gmaps"
 )
 
-func NewResultWriter(db *sql.DB) scrapemate.ResultWriter {
-	return &resultWriter{db: db}
+const (
+	defaultBatchSize     = 50
+	defaultFlushInterval = time.Minute
+	// the maximum number of parameters of a statement
+	maxParams = 65535
+)
+
+type ResultWriterOption func(*resultWriter)
+
+// WithTables also writes the rows of the tables (e.g. popular_times) to
+// the database tables of the same name. The rows are upserted by the key
+// of the table.
+func WithTables(tables ...gmaps.Table) ResultWriterOption {
+	return func(r *resultWriter) {
+		r.tables = append(r.tables, tables...)
+	}
+}
+
+// WithResultBatch sets the number of results written at once, 50 by
+// default, and the longest time they wait for their batch to fill before
+// they are written, a minute by default.
+func WithResultBatch(size int, flushInterval time.Duration) ResultWriterOption {
+	return func(r *resultWriter) {
+		if size > 0 {
+			r.batchSize = size
+		}
+
+		if flushInterval > 0 {
+			r.flushInterval = flushInterval
+		}
+	}
+}
+
+// WithCopy writes the results with COPY FROM instead of multi-row inserts,
+// which sustains a higher throughput with large batches. It requires the
+// pgx driver. The rows of the tables are still upserted.
+func WithCopy() ResultWriterOption {
+	return func(r *resultWriter) {
+		r.copy = true
+	}
+}
+
+func NewResultWriter(db *sql.DB, opts ...ResultWriterOption) scrapemate.ResultWriter {
+	ans := resultWriter{
+		db:            db,
+		batchSize:     defaultBatchSize,
+		flushInterval: defaultFlushInterval,
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	return &ans
 }
 
 type resultWriter struct {
-	db *sql.DB
+	db            *sql.DB
+	tables        []gmaps.Table
+	batchSize     int
+	flushInterval time.Duration
+	copy          bool
+	outbox        []string
 }
 
-func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
-	const maxBatchSize = 50
+// execFunc runs a statement in the transaction of a batch.
+type execFunc func(ctx context.Context, q string, args ...any) error
 
-	buff := make([]*gmaps.Entry, 0, 50)
-	lastSave := time.Now().UTC()
+func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	buff := make([]*gmaps.Entry, 0, r.batchSize)
 
-	for result := range in {
-		entry, ok := result.Data.(*gmaps.Entry)
+	// the results of a slow job are written even when the batch does not
+	// fill up
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
 
-		if !ok {
-			return errors.New("invalid data type")
+	flush := func() error {
+		if err := r.batchSave(ctx, buff); err != nil {
+			return err
 		}
 
-		buff = append(buff, entry)
+		buff = buff[:0]
 
-		if len(buff) >= maxBatchSize || time.Now().UTC().Sub(lastSave) >= time.Minute {
-			err := r.batchSave(ctx, buff)
-			if err != nil {
-				return err
+		return nil
+	}
+
+	for {
+		select {
+		case result, ok := <-in:
+			if !ok {
+				return flush()
 			}
 
-			buff = buff[:0]
-		}
-	}
+			entry, ok := result.Data.(*gmaps.Entry)
+			if !ok {
+				return errors.New("invalid data type")
+			}
 
-	if len(buff) > 0 {
-		err := r.batchSave(ctx, buff)
-		if err != nil {
-			return err
+			buff = append(buff, entry)
+
+			if len(buff) >= r.batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return err
+			}
 		}
 	}
-
-	return nil
 }
 
 func (r *resultWriter) batchSave(ctx context.Context, entries []*gmaps.Entry) error {
@@ -62,41 +135,217 @@ func (r *resultWriter) batchSave(ctx context.Context, entries []*gmaps.Entry) er
 		return nil
 	}
 
-	q := `INSERT INTO results
-		(data)
+	if r.copy {
+		return r.copySave(ctx, entries)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	exec := func(ctx context.Context, q string, args ...any) error {
+		_, err := tx.ExecContext(ctx, q, args...)
+
+		return err
+	}
+
+	for batch := range slices.Chunk(entries, maxParams/2) {
+		q := `INSERT INTO results
+		(data, scraped_at)
 		VALUES
 		`
-	elements := make([]string, 0, len(entries))
-	args := make([]interface{}, 0, len(entries))
+		elements := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, 2*len(batch))
+
+		for i, entry := range batch {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+
+			elements = append(elements, fmt.Sprintf("($%d, $%d)", 2*i+1, 2*i+2))
+			args = append(args, data, scrapedAt(entry))
+		}
+
+		q += strings.Join(elements, ", ")
+		q += " ON CONFLICT DO NOTHING"
+
+		if err := exec(ctx, q, args...); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range r.tables {
+		if err := saveTable(ctx, exec, table, entries); err != nil {
+			return err
+		}
+	}
+
+	if err := saveOutbox(ctx, exec, r.outbox, entries); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
 
-	for i, entry := range entries {
+	return err
+}
+
+// copySave writes the results with COPY FROM on the pgx connection, in a
+// transaction with the upserts of the tables.
+func (r *resultWriter) copySave(ctx context.Context, entries []*gmaps.Entry) error {
+	rows := make([][]any, 0, len(entries))
+
+	for _, entry := range entries {
 		data, err := json.Marshal(entry)
 		if err != nil {
 			return err
 		}
 
-		elements = append(elements, fmt.Sprintf("($%d)", i+1))
-		args = append(args, data)
+		rows = append(rows, []any{data, scrapedAt(entry)})
 	}
 
-	q += strings.Join(elements, ", ")
-	q += " ON CONFLICT DO NOTHING"
-
-	tx, err := r.db.BeginTx(ctx, nil)
+	conn, err := r.db.Conn(ctx)
 	if err != nil {
 		return err
 	}
 
-	defer func() {
-		_ = tx.Rollback()
-	}()
+	defer conn.Close()
 
-	_, err = tx.ExecContext(ctx, q, args...)
-	if err != nil {
-		return err
+	return conn.Raw(func(driverConn any) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("COPY requires the pgx driver")
+		}
+
+		tx, err := pgxConn.Conn().Begin(ctx)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{"results"}, []string{"data", "scraped_at"}, pgx.CopyFromRows(rows))
+		if err != nil {
+			return err
+		}
+
+		exec := func(ctx context.Context, q string, args ...any) error {
+			_, err := tx.Exec(ctx, q, args...)
+
+			return err
+		}
+
+		for _, table := range r.tables {
+			if err := saveTable(ctx, exec, table, entries); err != nil {
+				return err
+			}
+		}
+
+		if err := saveOutbox(ctx, exec, r.outbox, entries); err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	})
+}
+
+// scrapedAt is the time the place was scraped, or the time it is written
+// for the entries that do not have one.
+func scrapedAt(entry *gmaps.Entry) time.Time {
+	if entry.ScrapedAt.IsZero() {
+		return time.Now().UTC()
 	}
 
-	err = tx.Commit()
+	return entry.ScrapedAt
+}
 
-	return err
+func saveTable(ctx context.Context, exec execFunc, table gmaps.Table, entries []*gmaps.Entry) error {
+	rows := tableRows(table, entries)
+	cols := len(table.Headers)
+
+	updates := make([]string, 0, cols)
+
+	for _, h := range table.Headers {
+		if !slices.Contains(table.Key, h) {
+			updates = append(updates, h+" = EXCLUDED."+h)
+		}
+	}
+
+	for batch := range slices.Chunk(rows, maxParams/cols) {
+		elements := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*cols)
+
+		for _, row := range batch {
+			placeholders := make([]string, cols)
+			for i := range row {
+				placeholders[i] = fmt.Sprintf("$%d", len(args)+i+1)
+			}
+
+			elements = append(elements, "("+strings.Join(placeholders, ", ")+")")
+
+			args = append(args, row...)
+		}
+
+		q := `INSERT INTO ` + table.Name + `
+		(` + strings.Join(table.Headers, ", ") + `)
+		VALUES
+		` + strings.Join(elements, ", ") + `
+		ON CONFLICT (` + strings.Join(table.Key, ", ") + `) DO UPDATE SET ` + strings.Join(updates, ", ")
+
+		if err := exec(ctx, q, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tableRows returns the rows of the table that have a key, once per key
+// since a row can only be upserted once per statement.
+func tableRows(table gmaps.Table, entries []*gmaps.Entry) [][]any {
+	key := make([]int, 0, len(table.Key))
+
+	for i, h := range table.Headers {
+		if slices.Contains(table.Key, h) {
+			key = append(key, i)
+		}
+	}
+
+	var (
+		rows [][]any
+		seen = map[string]bool{}
+	)
+
+	for _, entry := range entries {
+	next:
+		for _, row := range table.Rows(entry) {
+			parts := make([]string, 0, len(key))
+
+			for _, i := range key {
+				if row[i] == "" || row[i] == nil {
+					continue next
+				}
+
+				parts = append(parts, fmt.Sprint(row[i]))
+			}
+
+			id := strings.Join(parts, "\x00")
+			if seen[id] {
+				continue
+			}
+
+			seen[id] = true
+
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
 }
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Worker represents a scraper node that consumes jobs from the gmaps_jobs queue.
+// It registers itself in gmaps_workers and periodically renews the
+// heartbeat and the leases of the jobs it holds.
+type Worker struct {
+	db           *sql.DB
+	id           string
+	hostname     string
+	leaseTimeout time.Duration
+	maxAttempts  int
+	leases       *leases
+}
+
+func NewWorker(db *sql.DB, leaseTimeout time.Duration, maxAttempts int) *Worker {
+	hostname, _ := os.Hostname()
+
+	return &Worker{
+		db:           db,
+		id:           uuid.New().String(),
+		hostname:     hostname,
+		leaseTimeout: leaseTimeout,
+		maxAttempts:  maxAttempts,
+		leases:       newLeases(),
+	}
+}
+
+func (w *Worker) ID() string {
+	return w.id
+}
+
+// ProviderOption returns the option that enables job leasing for this worker.
+func (w *Worker) ProviderOption() ProviderOption {
+	return func(p *provider) {
+		WithLease(w.id, w.leaseTimeout, w.maxAttempts)(p)
+
+		p.leases = w.leases
+	}
+}
+
+func (w *Worker) Register(ctx context.Context) error {
+	q := `INSERT INTO gmaps_workers
+		(id, hostname, started_at, last_heartbeat)
+		VALUES
+		($1, $2, NOW(), NOW())`
+
+	_, err := w.db.ExecContext(ctx, q, w.id, w.hostname)
+
+	return err
+}
+
+// Heartbeat renews the worker heartbeat and its job leases until ctx is canceled.
+func (w *Worker) Heartbeat(ctx context.Context) error {
+	ticker := time.NewTicker(w.leaseTimeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.beat(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *Worker) beat(ctx context.Context) error {
+	lease := fmt.Sprintf("%d milliseconds", w.leaseTimeout.Milliseconds())
+
+	res, err := w.db.ExecContext(ctx, `UPDATE gmaps_workers SET last_heartbeat = NOW() WHERE id = $1`, w.id)
+	if err != nil {
+		return err
+	}
+
+	// another worker removed this one after missed heartbeats
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		if err := w.Register(ctx); err != nil {
+			return err
+		}
+	}
+
+	// only the jobs the worker has fetched and not completed are renewed
+	if _, err := w.db.ExecContext(ctx,
+		`UPDATE gmaps_jobs SET lease_expires_at = NOW() + $1::interval WHERE worker_id = $2 AND status = $3 AND id = ANY($4)`,
+		lease, w.id, statusQueued, w.leases.list(),
+	); err != nil {
+		return err
+	}
+
+	// the workers that missed two heartbeats are removed and their jobs
+	// are visible again without waiting for the leases to expire
+	stale := fmt.Sprintf("%d milliseconds", (2 * w.leaseTimeout / 3).Milliseconds())
+
+	if _, err := w.db.ExecContext(ctx,
+		`DELETE FROM gmaps_workers WHERE last_heartbeat < NOW() - $1::interval`, stale,
+	); err != nil {
+		return err
+	}
+
+	if _, err := w.db.ExecContext(ctx,
+		`UPDATE gmaps_jobs SET lease_expires_at = NOW() WHERE status = $1 AND lease_expires_at > NOW()
+		AND worker_id IS NOT NULL AND worker_id NOT IN (SELECT id FROM gmaps_workers)`,
+		statusQueued,
+	); err != nil {
+		return err
+	}
+
+	// jobs that keep losing their lease (e.g. they crash the worker) are not retried forever
+	_, err = w.db.ExecContext(ctx,
+		`UPDATE gmaps_jobs SET status = $1 WHERE status = $2 AND lease_expires_at < NOW() AND attempts >= $3`,
+		statusFailed, statusQueued, w.maxAttempts,
+	)
+
+	return err
+}
+
+// Unregister releases the jobs still leased by the worker so other
+// workers can pick them up immediately and removes the worker.
+func (w *Worker) Unregister(ctx context.Context) error {
+	if _, err := w.db.ExecContext(ctx,
+		`UPDATE gmaps_jobs SET status = $1, worker_id = NULL, lease_expires_at = NULL, attempts = GREATEST(attempts - 1, 0)
+		WHERE worker_id = $2 AND status = $3`,
+		statusNew, w.id, statusQueued,
+	); err != nil {
+		return err
+	}
+
+	_, err := w.db.ExecContext(ctx, `DELETE FROM gmaps_workers WHERE id = $1`, w.id)
+
+	return err
+}
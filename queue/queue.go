@@ -0,0 +1,21 @@
+package queue
+
+import "context"
+
+// Message is a job id received from a Provider.
+type Message struct {
+	// ID is the backend specific id used to acknowledge the message.
+	ID    string
+	JobID string
+}
+
+// Provider dispatches pending jobs to the runners. Only the job ids go
+// through the queue, the job repository stays the source of truth for
+// the job metadata.
+type Provider interface {
+	Publish(ctx context.Context, jobID string) error
+	// Receive blocks until a message is available or ctx is canceled.
+	Receive(ctx context.Context) (Message, error)
+	Ack(ctx context.Context, msg Message) error
+	Close() error
+}
@@ -0,0 +1,163 @@
+package redisqueue
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	defaultJobStream = "gmaps:jobs"
+	batchSize        = 10
+)
+
+var _ scrapemate.JobProvider = (*JobProvider)(nil)
+
+// JobProvider is a scrapemate.JobProvider that fans out the scrape jobs
+// to several scraper instances using a Redis stream and a consumer group.
+// A job is acknowledged once it has been processed, or its fetch failed.
+// Jobs of crashed instances are delivered again after the claim idle time,
+// up to the max deliveries.
+type JobProvider struct {
+	s       *stream
+	mu      sync.Mutex
+	started bool
+	jobc    chan scrapemate.IJob
+	errc    chan error
+}
+
+func NewJobProvider(redisURL string, opts ...Option) (*JobProvider, error) {
+	s, err := newStream(redisURL, defaultJobStream, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := JobProvider{
+		s:    s,
+		jobc: make(chan scrapemate.IJob, 2*batchSize),
+		errc: make(chan error, 1),
+	}
+
+	return &ans, nil
+}
+
+//nolint:gocritic // it contains about unnamed results
+func (p *JobProvider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	outc := make(chan scrapemate.IJob)
+	errc := make(chan error, 1)
+
+	p.mu.Lock()
+	if !p.started {
+		go p.fetchJobs(ctx)
+
+		p.started = true
+	}
+	p.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-p.errc:
+				errc <- err
+
+				return
+			case job, ok := <-p.jobc:
+				if !ok {
+					return
+				}
+
+				select {
+				case outc <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return outc, errc
+}
+
+// Push pushes a job to the job provider
+func (p *JobProvider) Push(ctx context.Context, job scrapemate.IJob) error {
+	payloadType, payload, err := gmaps.EncodeJob(job)
+	if err != nil {
+		return err
+	}
+
+	return p.s.add(ctx, map[string]any{
+		"id":           job.GetID(),
+		"payload_type": payloadType,
+		"payload":      payload,
+	})
+}
+
+func (p *JobProvider) Close() error {
+	return p.s.close()
+}
+
+func (p *JobProvider) fetchJobs(ctx context.Context) {
+	defer close(p.jobc)
+	defer close(p.errc)
+
+	for {
+		msgs, err := p.s.read(ctx, batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				p.errc <- err
+			}
+
+			return
+		}
+
+		for i := range msgs {
+			job, err := gmaps.DecodeJob(
+				stringValue(msgs[i].Values, "payload_type"),
+				[]byte(stringValue(msgs[i].Values, "payload")),
+			)
+			if err != nil {
+				p.errc <- err
+
+				return
+			}
+
+			select {
+			case p.jobc <- &ackJob{IJob: job, s: p.s, msgID: msgs[i].ID}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// ackJob acknowledges the stream message once the job has been processed.
+// The job is processed on fetch errors too, otherwise scrapemate skips
+// Process and the message is delivered again forever.
+type ackJob struct {
+	scrapemate.IJob
+	s     *stream
+	msgID string
+}
+
+func (j *ackJob) ProcessOnFetchError() bool {
+	return true
+}
+
+func (j *ackJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	if resp.Error != nil && !j.IJob.ProcessOnFetchError() {
+		_ = j.s.ack(context.WithoutCancel(ctx), j.msgID)
+
+		return nil, nil, resp.Error
+	}
+
+	data, next, err := j.IJob.Process(ctx, resp)
+
+	_ = j.s.ack(context.WithoutCancel(ctx), j.msgID)
+
+	return data, next, err
+}
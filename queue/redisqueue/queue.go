@@ -0,0 +1,59 @@
+package redisqueue
+
+import (
+	"context"
+
+	"github.com/gosom/google-maps-scraper/queue"
+)
+
+const defaultQueueStream = "gmaps:pending-jobs"
+
+var _ queue.Provider = (*Queue)(nil)
+
+// Queue is a queue.Provider backed by a Redis stream.
+type Queue struct {
+	s *stream
+}
+
+func New(redisURL string, opts ...Option) (*Queue, error) {
+	s, err := newStream(redisURL, defaultQueueStream, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Queue{s: s}, nil
+}
+
+func (q *Queue) Publish(ctx context.Context, jobID string) error {
+	return q.s.add(ctx, map[string]any{"job_id": jobID})
+}
+
+func (q *Queue) Receive(ctx context.Context) (queue.Message, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return queue.Message{}, err
+		}
+
+		msgs, err := q.s.read(ctx, 1)
+		if err != nil {
+			return queue.Message{}, err
+		}
+
+		if len(msgs) == 0 {
+			continue
+		}
+
+		return queue.Message{
+			ID:    msgs[0].ID,
+			JobID: stringValue(msgs[0].Values, "job_id"),
+		}, nil
+	}
+}
+
+func (q *Queue) Ack(ctx context.Context, msg queue.Message) error {
+	return q.s.ack(ctx, msg.ID)
+}
+
+func (q *Queue) Close() error {
+	return q.s.close()
+}
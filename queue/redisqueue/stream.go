@@ -0,0 +1,219 @@
+package redisqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultGroup         = "gmaps-workers"
+	defaultClaimIdle     = 30 * time.Minute
+	defaultMaxDeliveries = 5
+	blockTimeout         = 5 * time.Second
+)
+
+type Option func(*stream)
+
+func WithStream(name string) Option {
+	return func(s *stream) {
+		if name != "" {
+			s.name = name
+		}
+	}
+}
+
+func WithGroup(name string) Option {
+	return func(s *stream) {
+		if name != "" {
+			s.group = name
+		}
+	}
+}
+
+// WithClaimIdle sets the time after which a message that was delivered
+// but not acknowledged is handed to another consumer.
+func WithClaimIdle(d time.Duration) Option {
+	return func(s *stream) {
+		if d > 0 {
+			s.claimIdle = d
+		}
+	}
+}
+
+// WithMaxDeliveries sets the number of times a message is delivered
+// before it is moved to the dead letter stream, <stream>:dead.
+func WithMaxDeliveries(n int64) Option {
+	return func(s *stream) {
+		if n > 0 {
+			s.maxDeliveries = n
+		}
+	}
+}
+
+// stream wraps a redis stream consumed by a consumer group.
+type stream struct {
+	client        *redis.Client
+	name          string
+	group         string
+	consumer      string
+	claimIdle     time.Duration
+	maxDeliveries int64
+}
+
+func newStream(redisURL, name string, opts ...Option) (*stream, error) {
+	ropts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+
+	s := stream{
+		client:        redis.NewClient(ropts),
+		name:          name,
+		group:         defaultGroup,
+		consumer:      hostname + "-" + uuid.New().String(),
+		claimIdle:     defaultClaimIdle,
+		maxDeliveries: defaultMaxDeliveries,
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = s.client.XGroupCreateMkStream(ctx, s.name, s.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		_ = s.client.Close()
+
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (s *stream) add(ctx context.Context, values map[string]any) error {
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.name,
+		Values: values,
+	}).Err()
+}
+
+// read returns up to count messages. Messages whose consumer did not
+// acknowledge them within claimIdle are returned first.
+func (s *stream) read(ctx context.Context, count int64) ([]redis.XMessage, error) {
+	claimed, _, err := s.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   s.name,
+		Group:    s.group,
+		Consumer: s.consumer,
+		MinIdle:  s.claimIdle,
+		Start:    "0-0",
+		Count:    count,
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	if len(claimed) > 0 {
+		return s.deadLetter(ctx, claimed)
+	}
+
+	streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.group,
+		Consumer: s.consumer,
+		Streams:  []string{s.name, ">"},
+		Count:    count,
+		Block:    blockTimeout,
+	}).Result()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var msgs []redis.XMessage
+
+	for i := range streams {
+		msgs = append(msgs, streams[i].Messages...)
+	}
+
+	return msgs, nil
+}
+
+// deadLetter moves the claimed messages that were delivered more than
+// maxDeliveries times to the dead letter stream and returns the others.
+func (s *stream) deadLetter(ctx context.Context, claimed []redis.XMessage) ([]redis.XMessage, error) {
+	pending, err := s.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream:   s.name,
+		Group:    s.group,
+		Start:    claimed[0].ID,
+		End:      claimed[len(claimed)-1].ID,
+		Count:    int64(len(claimed)),
+		Consumer: s.consumer,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make(map[string]int64, len(pending))
+	for i := range pending {
+		deliveries[pending[i].ID] = pending[i].RetryCount
+	}
+
+	ans := claimed[:0]
+
+	for i := range claimed {
+		if deliveries[claimed[i].ID] <= s.maxDeliveries {
+			ans = append(ans, claimed[i])
+
+			continue
+		}
+
+		values := make(map[string]any, len(claimed[i].Values)+2)
+		for k, v := range claimed[i].Values {
+			values[k] = v
+		}
+
+		values["message_id"] = claimed[i].ID
+		values["deliveries"] = deliveries[claimed[i].ID]
+
+		if err := s.client.XAdd(ctx, &redis.XAddArgs{Stream: s.name + ":dead", Values: values}).Err(); err != nil {
+			return nil, err
+		}
+
+		if err := s.ack(ctx, claimed[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return ans, nil
+}
+
+func (s *stream) ack(ctx context.Context, id string) error {
+	if err := s.client.XAck(ctx, s.name, s.group, id).Err(); err != nil {
+		return err
+	}
+
+	return s.client.XDel(ctx, s.name, id).Err()
+}
+
+func (s *stream) close() error {
+	return s.client.Close()
+}
+
+func stringValue(values map[string]any, key string) string {
+	v, _ := values[key].(string)
+
+	return v
+}
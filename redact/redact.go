@@ -0,0 +1,118 @@
+// Package redact removes credentials from the log lines: the passwords of
+// proxy urls and DSNs, API keys and tokens.
+package redact
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mask replaces the redacted values.
+const Mask = "[REDACTED]"
+
+type rule struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+var rules = []rule{
+	// passwords of urls: proxies, postgres, redis, smtp, mongodb...
+	{regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]*:)[^\s/@]+@`), "${1}" + Mask + "@"},
+	// go-sql-driver/mysql DSNs: user:password@tcp(host:port)/db
+	{regexp.MustCompile(`([^\s:/@]+:)[^\s/@]+@(tcp|unix)\(`), "${1}" + Mask + "@${2}("},
+	// key=value DSNs and query parameters
+	{regexp.MustCompile(`(?i)\b(password|passwd|pwd|api[_-]?key|access[_-]?token|secret|token)=[^\s&"']+`), "${1}=" + Mask},
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[a-zA-Z0-9._~+/=-]{8,}`), "${1} " + Mask},
+	// Stripe and Clerk secret and restricted keys, webhook secrets
+	{regexp.MustCompile(`\b(sk|rk)_(live|test)_[a-zA-Z0-9]{8,}`), "${1}_${2}_" + Mask},
+	{regexp.MustCompile(`\bwhsec_[a-zA-Z0-9+/=]{8,}`), "whsec_" + Mask},
+	// SendGrid and Google API keys
+	{regexp.MustCompile(`\bSG\.[a-zA-Z0-9_-]{16,}\.[a-zA-Z0-9_-]{16,}`), Mask},
+	{regexp.MustCompile(`\bAIza[a-zA-Z0-9_-]{35}\b`), Mask},
+	// Telegram bot tokens in the urls of the bot API
+	{regexp.MustCompile(`\bbot[0-9]{6,}:[a-zA-Z0-9_-]{30,}`), "bot" + Mask},
+	// Slack and Discord webhooks
+	{regexp.MustCompile(`(hooks\.slack\.com/services/)[^\s"']+`), "${1}" + Mask},
+	{regexp.MustCompile(`(discord(?:app)?\.com/api/webhooks/)[^\s"']+`), "${1}" + Mask},
+}
+
+// Redactor redacts the rules and a list of known secrets, like the tokens
+// of the configuration.
+type Redactor struct {
+	secrets []string
+}
+
+// New returns a redactor that also replaces the secrets. Secrets shorter
+// than 6 characters are ignored, they would mask unrelated text.
+func New(secrets ...string) *Redactor {
+	var ans Redactor
+
+	for _, s := range secrets {
+		if len(s) >= 6 {
+			ans.secrets = append(ans.secrets, s)
+		}
+	}
+
+	// the longest first, in case a secret contains another
+	slices.SortFunc(ans.secrets, func(a, b string) int { return len(b) - len(a) })
+
+	return &ans
+}
+
+// String returns s with the credentials replaced by Mask.
+func (r *Redactor) String(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+
+	for _, rule := range rules {
+		s = rule.re.ReplaceAllString(s, rule.repl)
+	}
+
+	return s
+}
+
+// String redacts s with the rules only.
+func String(s string) string {
+	return New().String(s)
+}
+
+// Writer redacts the lines written to w. The log package writes a line per
+// call; the writes of other writers are buffered until a newline so that a
+// secret split across writes is still redacted.
+type Writer struct {
+	mu  sync.Mutex
+	w   io.Writer
+	r   *Redactor
+	buf []byte
+}
+
+// NewWriter returns a writer that redacts with r before writing to w.
+func NewWriter(w io.Writer, r *Redactor) *Writer {
+	return &Writer{w: w, r: r}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	i := bytes.LastIndexByte(w.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+
+	lines := w.r.String(string(w.buf[:i+1]))
+	w.buf = append(w.buf[:0], w.buf[i+1:]...)
+
+	if _, err := io.WriteString(w.w, lines); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,195 @@
+// Package resource samples the memory used by the scraper and its browsers.
+package resource
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// SampleInterval is how often a Monitor samples the memory.
+var SampleInterval = 5 * time.Second
+
+// Usage is the memory used by the process and its children, the playwright
+// driver and the browsers.
+type Usage struct {
+	RSS      uint64
+	Browsers int
+}
+
+// MiB returns the RSS in MiB.
+func (u Usage) MiB() int {
+	return int(u.RSS >> 20)
+}
+
+// browserNames are the names of the browser processes, e.g. chrome or
+// headless_shell.
+var browserNames = []string{"chrom", "headless_shell", "firefox"}
+
+// Sample returns the memory used by the process and its children.
+func Sample(ctx context.Context) (Usage, error) {
+	p, err := process.NewProcessWithContext(ctx, int32(os.Getpid())) //nolint:gosec // pids fit in an int32
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var ans Usage
+
+	add(ctx, p, &ans, true)
+
+	return ans, nil
+}
+
+func add(ctx context.Context, p *process.Process, u *Usage, self bool) {
+	// the processes that exited while they are walked are skipped
+	if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+		u.RSS += mem.RSS
+	}
+
+	if name, err := p.NameWithContext(ctx); err == nil && !self && isBrowser(name) {
+		u.Browsers++
+	}
+
+	children, err := p.ChildrenWithContext(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, child := range children {
+		add(ctx, child, u, false)
+	}
+}
+
+func isBrowser(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, browser := range browserNames {
+		if strings.Contains(name, browser) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Monitor samples the memory while a job runs, keeps its peak and calls
+// onLimit once when the memory grows by more than limit bytes since the
+// monitor started. A limit of 0 only keeps the peak.
+//
+// The samples cover the whole process, so they are only attributed to the
+// job while it runs alone, see WithAlone.
+type Monitor struct {
+	limit   uint64
+	onLimit func()
+	alone   func() bool
+
+	mu       sync.Mutex
+	peak     Usage
+	exceeded bool
+}
+
+type MonitorOption func(*Monitor)
+
+// WithAlone sets the function that reports whether the job runs alone. The
+// samples taken while other jobs run are ignored and the growth is measured
+// again from the first sample after they finished.
+func WithAlone(alone func() bool) MonitorOption {
+	return func(m *Monitor) {
+		m.alone = alone
+	}
+}
+
+func NewMonitor(limit uint64, onLimit func(), opts ...MonitorOption) *Monitor {
+	ans := Monitor{
+		limit:   limit,
+		onLimit: onLimit,
+		alone:   func() bool { return true },
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	return &ans
+}
+
+// Run samples the memory until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	var (
+		baseline Usage
+		measured bool
+	)
+
+	sample := func() {
+		if !m.alone() {
+			measured = false
+
+			return
+		}
+
+		usage, err := Sample(ctx)
+		if err != nil {
+			return
+		}
+
+		m.update(usage)
+
+		if !measured {
+			baseline, measured = usage, true
+
+			return
+		}
+
+		if m.limit > 0 && usage.RSS > baseline.RSS+m.limit && m.setExceeded() {
+			m.onLimit()
+		}
+	}
+
+	sample()
+
+	ticker := time.NewTicker(SampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sample()
+		}
+	}
+}
+
+func (m *Monitor) update(usage Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.peak.RSS = max(m.peak.RSS, usage.RSS)
+	m.peak.Browsers = max(m.peak.Browsers, usage.Browsers)
+}
+
+// setExceeded returns true the first time it is called.
+func (m *Monitor) setExceeded() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.exceeded {
+		return false
+	}
+
+	m.exceeded = true
+
+	return true
+}
+
+// Peak returns the peak usage and whether the limit was exceeded.
+func (m *Monitor) Peak() (Usage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.peak, m.exceeded
+}
@@ -0,0 +1,267 @@
+package runner
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFlag = "config"
+	envPrefix  = "GMAPS_"
+	redacted   = "******"
+)
+
+var (
+	urlPasswordRe = regexp.MustCompile(`(://[^:/@\s]*:)[^@\s]+@`)
+	dsnPasswordRe = regexp.MustCompile(`(password=)\S+`)
+)
+
+// applyConfig sets the flags that were not given on the command line.
+// A GMAPS_<FLAG> env variable (e.g. GMAPS_S3_BUCKET for -s3-bucket) takes
+// precedence over the value of the YAML config file, whose keys are the
+// flag names. The values of the file are typed by their flag, e.g. the
+// value of a bool flag must be a YAML bool, see readConfigFile.
+func applyConfig(fs *flag.FlagSet, path string) error {
+	values := map[string]string{}
+
+	if path != "" {
+		var err error
+
+		values, err = readConfigFile(fs, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	explicit := map[string]bool{}
+
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	var err error
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] || f.Name == configFlag {
+			return
+		}
+
+		v, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			v, ok = values[f.Name]
+		}
+
+		if !ok {
+			return
+		}
+
+		if serr := fs.Set(f.Name, v); serr != nil {
+			err = fmt.Errorf("invalid value %q for %s: %w", v, f.Name, serr)
+		}
+	})
+
+	return err
+}
+
+// readConfigFile returns the values of the config file in the format of the
+// command line. Every value is decoded into the type of its flag: bool,
+// integer, float and duration flags take YAML values of that type and
+// string flags a scalar or a list, joined with commas.
+func readConfigFile(fs *flag.FlagSet, path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+
+	// an empty file has no document
+	if len(doc.Content) == 0 {
+		return values, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid config file %s: the options must be a mapping", path)
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, node := root.Content[i].Value, root.Content[i+1]
+
+		f := fs.Lookup(k)
+		if f == nil || k == configFlag {
+			return nil, fmt.Errorf("invalid config file %s: unknown option %q", path, k)
+		}
+
+		if node.Tag == "!!null" {
+			continue
+		}
+
+		v, err := decodeOption(f, node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config file %s: option %q: %w", path, k, err)
+		}
+
+		values[k] = v
+	}
+
+	return values, nil
+}
+
+func decodeOption(f *flag.Flag, node *yaml.Node) (string, error) {
+	var value any = f.Value.String()
+
+	if getter, ok := f.Value.(flag.Getter); ok {
+		value = getter.Get()
+	}
+
+	switch value.(type) {
+	case bool:
+		return decodeScalar[bool](node)
+	case int:
+		return decodeScalar[int](node)
+	case int64:
+		return decodeScalar[int64](node)
+	case uint:
+		return decodeScalar[uint](node)
+	case uint64:
+		return decodeScalar[uint64](node)
+	case float64:
+		return decodeScalar[float64](node)
+	case time.Duration:
+		return decodeScalar[time.Duration](node)
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", errors.New("the items of the list must be scalars")
+			}
+
+			items = append(items, item.Value)
+		}
+
+		return strings.Join(items, ","), nil
+	default:
+		return "", errors.New("must be a scalar or a list")
+	}
+}
+
+func decodeScalar[T any](node *yaml.Node) (string, error) {
+	var v T
+
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("must be a %T", v)
+	}
+
+	if err := node.Decode(&v); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(v), nil
+}
+
+// PrintConfig writes the effective configuration as YAML, in the format
+// accepted by -config. Keys, tokens, webhooks and the passwords of urls are
+// redacted.
+func PrintConfig(w io.Writer, fs *flag.FlagSet) error {
+	doc := yaml.Node{Kind: yaml.MappingNode}
+
+	var err error
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || f.Name == configFlag {
+			return
+		}
+
+		var value any = f.Value.String()
+
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value = getter.Get()
+		}
+
+		switch v := value.(type) {
+		case time.Duration:
+			value = v.String()
+		case string:
+			value = redact(f.Name, v)
+		}
+
+		var node yaml.Node
+
+		if err = node.Encode(value); err != nil {
+			return
+		}
+
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.Name}, &node)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+func redact(name, value string) string {
+	if value == "" {
+		return value
+	}
+
+	for _, s := range []string{"key", "token", "secret", "password", "webhook"} {
+		if strings.Contains(name, s) {
+			return redacted
+		}
+	}
+
+	// the variables of the containers hold credentials, their names are kept
+	if name == "k8s-env" {
+		return redactEnv(value)
+	}
+
+	value = urlPasswordRe.ReplaceAllString(value, "${1}"+redacted+"@")
+
+	return dsnPasswordRe.ReplaceAllString(value, "${1}"+redacted)
+}
+
+func redactEnv(value string) string {
+	vars := strings.Split(value, ",")
+
+	for i := range vars {
+		if k, _, ok := strings.Cut(vars[i], "="); ok {
+			vars[i] = k + "=" + redacted
+		}
+	}
+
+	return strings.Join(vars, ",")
+}
+
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
@@ -0,0 +1,147 @@
+package dbmigrate
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gosom/google-maps-scraper/runner/databaserunner"
+	"github.com/gosom/google-maps-scraper/scripts"
+)
+
+const usage = "usage: db migrate [-dsn <dsn>] [-steps <n>] [-dry-run] up|down|status|force <version>"
+
+// Command runs the db subcommand: db migrate up|down|status|force. up
+// applies the pending migrations, down rolls back the last one (or -steps),
+// status lists the applied and pending migrations and force sets the
+// version after a failed migration was fixed by hand.
+func Command(ctx context.Context, args []string, w io.Writer) error {
+	if len(args) == 0 || args[0] != "migrate" {
+		return errors.New(usage)
+	}
+
+	fs := flag.NewFlagSet("db migrate", flag.ContinueOnError)
+	fs.SetOutput(w)
+
+	dsn := fs.String("dsn", "", "database connection string. Use the mysql:// scheme for MySQL/MariaDB")
+	steps := fs.Int("steps", 0, "number of migrations to apply or roll back. Defaults to all pending ones for up and 1 for down")
+	dryRun := fs.Bool("dry-run", false, "print the SQL of the migrations instead of running it")
+
+	// the flags can come before or after the action
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		return errors.New(usage)
+	}
+
+	action := rest[0]
+
+	if err := fs.Parse(rest[1:]); err != nil {
+		return err
+	}
+
+	if *dsn == "" {
+		return errors.New("-dsn is required")
+	}
+
+	if *steps < 0 {
+		return errors.New("-steps must not be negative")
+	}
+
+	fsys, dir := scripts.Postgres, "migrations"
+	if databaserunner.IsMySQL(*dsn) {
+		fsys, dir = scripts.MySQL, "migrations-mysql"
+		// the migrations have several statements
+		*dsn = withParam(*dsn, "multiStatements=true")
+	}
+
+	migrations, err := Load(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	conn, err := databaserunner.Open(*dsn)
+	if err != nil {
+		return fmt.Errorf("could not connect: %w", err)
+	}
+
+	defer conn.Close()
+
+	m := New(conn, migrations)
+
+	switch action {
+	case "up":
+		return m.Up(ctx, *steps, *dryRun, w)
+	case "down":
+		return m.Down(ctx, max(*steps, 1), *dryRun, w)
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		return printStatus(w, &status)
+	case "force":
+		if fs.NArg() != 1 {
+			return errors.New(usage)
+		}
+
+		version, err := strconv.Atoi(fs.Arg(0))
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", fs.Arg(0))
+		}
+
+		if *dryRun {
+			fmt.Fprintf(w, "would set the version to %d\n", version)
+
+			return nil
+		}
+
+		return m.Force(ctx, version)
+	default:
+		return errors.New(usage)
+	}
+}
+
+func printStatus(w io.Writer, status *Status) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	switch {
+	case status.Dirty:
+		fmt.Fprintf(tw, "schema at migration %d, dirty: the migration failed, fix the schema and run force <version>\n\n", status.Version)
+	case status.Version == 0:
+		fmt.Fprint(tw, "no migration applied\n\n")
+	default:
+		fmt.Fprintf(tw, "schema at migration %d\n\n", status.Version)
+	}
+
+	for _, migration := range status.Applied {
+		fmt.Fprintf(tw, "applied\t%04d\t%s\n", migration.Version, migration.Name)
+	}
+
+	for _, migration := range status.Pending {
+		fmt.Fprintf(tw, "pending\t%04d\t%s\n", migration.Version, migration.Name)
+	}
+
+	return tw.Flush()
+}
+
+func withParam(dsn, param string) string {
+	if strings.Contains(dsn, param) {
+		return dsn
+	}
+
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + param
+	}
+
+	return dsn + "?" + param
+}
@@ -0,0 +1,297 @@
+// Package dbmigrate applies the migrations of the database provider that
+// are embedded in the binary. It keeps the schema_migrations table of
+// golang-migrate, so a database can be migrated with either tool.
+package dbmigrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// Migration is a schema change. Down is empty when the migration cannot be
+// rolled back.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// File returns the name of the migration file of the direction.
+func (m *Migration) File(direction string) string {
+	return fmt.Sprintf("%04d_%s.%s.sql", m.Version, m.Name, direction)
+}
+
+var fileRe = regexp.MustCompile(`^([0-9]+)_(.+)\.(up|down)\.sql$`)
+
+// Load reads the migrations of dir, sorted by version. Files that are not
+// named <version>_<name>.<up|down>.sql are an error, so that a typo does not
+// skip a migration.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+
+	for _, entry := range entries {
+		match := fileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	ans := make([]Migration, 0, len(byVersion))
+
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d %s has no up file", m.Version, m.Name)
+		}
+
+		ans = append(ans, *m)
+	}
+
+	slices.SortFunc(ans, func(a, b Migration) int { return a.Version - b.Version })
+
+	return ans, nil
+}
+
+// ErrDirty is returned when a migration failed before. The schema has to be
+// fixed by hand and the version forced.
+var ErrDirty = errors.New("the schema is dirty")
+
+// Status is the migration state of a database.
+type Status struct {
+	// Version is the last applied migration, 0 when none was applied.
+	Version int
+	// Dirty is set when the migration of Version failed half way.
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Migrator applies the migrations to a postgres or MySQL database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New returns a migrator of the migrations, sorted by version.
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Status returns the applied and pending migrations. The schema_migrations
+// table is created when it does not exist.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	const q = `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`
+
+	if _, err := m.db.ExecContext(ctx, q); err != nil {
+		return Status{}, err
+	}
+
+	var ans Status
+
+	err := m.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&ans.Version, &ans.Dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Status{}, err
+	}
+
+	// golang-migrate keeps -1 when the rollback of the first migration
+	// failed
+	ans.Version = max(ans.Version, 0)
+
+	for _, migration := range m.migrations {
+		if migration.Version <= ans.Version {
+			ans.Applied = append(ans.Applied, migration)
+		} else {
+			ans.Pending = append(ans.Pending, migration)
+		}
+	}
+
+	return ans, nil
+}
+
+// Up applies the pending migrations, at most steps unless 0. With dryRun
+// the SQL of the migrations is written to w instead.
+func (m *Migrator) Up(ctx context.Context, steps int, dryRun bool, w io.Writer) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	if status.Dirty {
+		return fmt.Errorf("%w at migration %d", ErrDirty, status.Version)
+	}
+
+	pending := status.Pending
+	if steps > 0 && steps < len(pending) {
+		pending = pending[:steps]
+	}
+
+	for _, migration := range pending {
+		if err := m.apply(ctx, w, dryRun, migration.File("up"), migration.Up, migration.Version); err != nil {
+			return err
+		}
+	}
+
+	if len(pending) == 0 {
+		fmt.Fprintf(w, "no pending migrations, the schema is at migration %d\n", status.Version)
+	}
+
+	return nil
+}
+
+// Down rolls back the last steps applied migrations. With dryRun the SQL
+// of the migrations is written to w instead.
+func (m *Migrator) Down(ctx context.Context, steps int, dryRun bool, w io.Writer) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	if status.Dirty {
+		return fmt.Errorf("%w at migration %d", ErrDirty, status.Version)
+	}
+
+	applied := status.Applied
+	if len(applied) == 0 || applied[len(applied)-1].Version != status.Version {
+		if status.Version == 0 {
+			fmt.Fprintln(w, "no applied migrations")
+
+			return nil
+		}
+
+		return fmt.Errorf("the schema is at migration %d, which this binary does not know", status.Version)
+	}
+
+	rollback := applied[max(len(applied)-steps, 0):]
+
+	// check all of them first, not to stop half way
+	for _, migration := range rollback {
+		if migration.Down == "" {
+			return fmt.Errorf("migration %s cannot be rolled back, it has no down file", migration.File("up"))
+		}
+	}
+
+	for i := len(rollback) - 1; i >= 0; i-- {
+		previous := 0
+		if idx := len(applied) - len(rollback) + i; idx > 0 {
+			previous = applied[idx-1].Version
+		}
+
+		if err := m.apply(ctx, w, dryRun, rollback[i].File("down"), rollback[i].Down, previous); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Force sets the version and clears the dirty flag without running a
+// migration, after a failed migration was fixed by hand. Version 0 means
+// that no migration is applied.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if _, err := m.Status(ctx); err != nil {
+		return err
+	}
+
+	if version != 0 && !slices.ContainsFunc(m.migrations, func(mg Migration) bool { return mg.Version == version }) {
+		return fmt.Errorf("unknown migration %d", version)
+	}
+
+	return m.setVersion(ctx, version, false)
+}
+
+// apply runs the SQL of a migration file and sets the version to version.
+// The version is marked dirty while the SQL runs, so that a failure is
+// detected on the next run.
+func (m *Migrator) apply(ctx context.Context, w io.Writer, dryRun bool, file, query string, version int) error {
+	if dryRun {
+		fmt.Fprintf(w, "-- %s\n%s\n", file, query)
+
+		return nil
+	}
+
+	if err := m.setVersion(ctx, version, true); err != nil {
+		return err
+	}
+
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("%s failed, the schema is dirty: %w", file, err)
+	}
+
+	if err := m.setVersion(ctx, version, false); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "applied %s\n", file)
+
+	return nil
+}
+
+func (m *Migrator) setVersion(ctx context.Context, version int, dirty bool) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+
+	if version > 0 || dirty {
+		if version == 0 {
+			version = -1
+		}
+
+		// the values are numbers, formatted to avoid the placeholders
+		// that differ between postgres and MySQL
+		q := fmt.Sprintf(`INSERT INTO schema_migrations (version, dirty) VALUES (%d, %t)`, version, dirty)
+
+		if _, err := tx.ExecContext(ctx, q); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
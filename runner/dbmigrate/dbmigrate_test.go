@@ -0,0 +1,59 @@
+package dbmigrate_test
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner/dbmigrate"
+	"github.com/gosom/google-maps-scraper/scripts"
+)
+
+func Test_LoadEmbedded(t *testing.T) {
+	tests := []struct {
+		fsys fs.FS
+		dir  string
+	}{
+		{scripts.Postgres, "migrations"},
+		{scripts.MySQL, "migrations-mysql"},
+	}
+
+	for _, tc := range tests {
+		migrations, err := dbmigrate.Load(tc.fsys, tc.dir)
+		require.NoError(t, err, tc.dir)
+
+		latest, err := scripts.LatestVersion(tc.fsys, tc.dir)
+		require.NoError(t, err)
+		require.Len(t, migrations, latest, tc.dir)
+
+		// every migration can be rolled back
+		for i, m := range migrations {
+			require.Equal(t, i+1, m.Version, tc.dir)
+			require.NotEmpty(t, m.Up, m.File("up"))
+			require.NotEmpty(t, m.Down, m.File("down"))
+		}
+	}
+}
+
+func Test_Load(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_b.up.sql":   {Data: []byte("UP 2")},
+		"m/0001_a.up.sql":   {Data: []byte("UP 1")},
+		"m/0001_a.down.sql": {Data: []byte("DOWN 1")},
+	}
+
+	migrations, err := dbmigrate.Load(fsys, "m")
+	require.NoError(t, err)
+	require.Equal(t, []dbmigrate.Migration{
+		{Version: 1, Name: "a", Up: "UP 1", Down: "DOWN 1"},
+		{Version: 2, Name: "b", Up: "UP 2"},
+	}, migrations)
+
+	// a typo must not skip a migration
+	fsys["m/0003_c.dow.sql"] = &fstest.MapFile{Data: []byte("DOWN 3")}
+
+	_, err = dbmigrate.Load(fsys, "m")
+	require.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package doctor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/disk"
+
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/runner/databaserunner"
+	"github.com/gosom/google-maps-scraper/scripts"
+)
+
+// minFreeSpace is the free space of the data folder below which the disk
+// check fails.
+const minFreeSpace = 1 << 30
+
+type bucketChecker interface {
+	HeadBucket(ctx context.Context, bucketName string) error
+}
+
+// missingEnv returns the settings the run mode needs that are not set.
+func (d *doctor) missingEnv() []string {
+	cfg := d.cfg
+
+	var missing []string
+
+	aws := cfg.RunMode == runner.RunModeAwsLambda || cfg.RunMode == runner.RunModeAwsLambdaInvoker || cfg.S3Bucket != ""
+	if aws {
+		if cfg.AwsAccessKey == "" {
+			missing = append(missing, "MY_AWS_ACCESS_KEY (-aws-access-key)")
+		}
+
+		if cfg.AwsSecretKey == "" {
+			missing = append(missing, "MY_AWS_SECRET_KEY (-aws-secret-key)")
+		}
+
+		if cfg.AwsRegion == "" && cfg.S3Endpoint == "" {
+			missing = append(missing, "MY_AWS_REGION (-aws-region) or S3_ENDPOINT (-s3-endpoint)")
+		}
+	}
+
+	if cfg.RunMode == runner.RunModeWeb {
+		if (cfg.SMTPURL != "" || cfg.SendGridAPIKey != "") && cfg.NotifyFrom == "" {
+			missing = append(missing, "-notify-from, the sender of the notification emails")
+		}
+
+		if cfg.TelegramToken != "" && len(cfg.TelegramChats) == 0 {
+			missing = append(missing, "TELEGRAM_CHAT_IDS (-telegram-chats)")
+		}
+	}
+
+	return missing
+}
+
+func (d *doctor) envCheck() check {
+	return check{
+		name: "environment",
+		run: func(context.Context) (string, error) {
+			if missing := d.missingEnv(); len(missing) > 0 {
+				return "", fmt.Errorf("missing %s", strings.Join(missing, ", "))
+			}
+
+			return "", nil
+		},
+		fix: "set the missing env variables or flags, see ./google-maps-scraper -h",
+	}
+}
+
+func (d *doctor) databaseCheck() check {
+	return check{
+		name: "database",
+		run: func(ctx context.Context) (string, error) {
+			conn, err := databaserunner.Open(d.cfg.Dsn)
+			if err != nil {
+				return "", fmt.Errorf("could not connect: %w", err)
+			}
+
+			defer conn.Close()
+
+			return migrationStatus(ctx, conn, databaserunner.IsMySQL(d.cfg.Dsn))
+		},
+		fix: "check that the database of -dsn is reachable and apply the migrations " +
+			"with ./google-maps-scraper db migrate -dsn <dsn> up",
+	}
+}
+
+// migrationStatus compares the version of the schema_migrations table of
+// golang-migrate with the last migration of the binary.
+func migrationStatus(ctx context.Context, conn *sql.DB, isMySQL bool) (string, error) {
+	fsys, dir := scripts.Postgres, "migrations"
+	if isMySQL {
+		fsys, dir = scripts.MySQL, "migrations-mysql"
+	}
+
+	latest, err := scripts.LatestVersion(fsys, dir)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		version int
+		dirty   bool
+	)
+
+	err = conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", errors.New("no migration was applied")
+	case err != nil:
+		return "", fmt.Errorf("could not read the migration version: %w", err)
+	case dirty:
+		return "", fmt.Errorf("the migration %d failed and the schema is dirty, fix it and force the version", version)
+	case version < latest:
+		return "", fmt.Errorf("the schema is at migration %d, the latest is %d", version, latest)
+	}
+
+	return fmt.Sprintf("migration %d", version), nil
+}
+
+func (d *doctor) s3Check() check {
+	return check{
+		name: "s3",
+		run: func(ctx context.Context) (string, error) {
+			store, ok := d.cfg.S3Uploader.(bucketChecker)
+			if !ok {
+				return "", errors.New("no S3 credentials")
+			}
+
+			if err := store.HeadBucket(ctx, d.cfg.S3Bucket); err != nil {
+				return "", err
+			}
+
+			return d.cfg.S3Bucket, nil
+		},
+		fix: "check the AWS credentials, the region or -s3-endpoint, and that the bucket of -s3-bucket exists " +
+			"and the credentials can access it",
+	}
+}
+
+func (d *doctor) proxiesCheck() check {
+	return check{
+		name: "proxies",
+		run: func(ctx context.Context) (string, error) {
+			health, err := runner.CheckProxies(ctx, d.cfg.Proxies)
+			if err != nil {
+				return "", err
+			}
+
+			details := fmt.Sprintf("%d of %d reachable", health.Reachable, health.Total)
+			if len(health.Unreachable) > 0 {
+				details += ", unreachable: " + strings.Join(health.Unreachable, ", ")
+			}
+
+			return details, nil
+		},
+		fix: "check the host and the port of the proxies of -proxies and that the firewall allows the connections",
+	}
+}
+
+// dataFolder returns the folder the run mode writes to, or "" when it does
+// not write files.
+func (d *doctor) dataFolder() string {
+	switch {
+	case d.cfg.RunMode == runner.RunModeWeb:
+		return d.cfg.DataFolder
+	case d.cfg.RunMode == runner.RunModeFile && d.cfg.ResultsFile != "stdout" && d.cfg.ResultsFile != "-":
+		return filepath.Dir(d.cfg.ResultsFile)
+	default:
+		return ""
+	}
+}
+
+func (d *doctor) diskCheck(folder string) check {
+	return check{
+		name: "disk space",
+		run: func(context.Context) (string, error) {
+			// the web runner creates the data folder on startup
+			path := folder
+			for {
+				usage, err := disk.Usage(path)
+				if err == nil {
+					if usage.Free < minFreeSpace {
+						return "", fmt.Errorf("%s has %d MiB free", folder, usage.Free>>20)
+					}
+
+					return fmt.Sprintf("%s has %d MiB free", folder, usage.Free>>20), nil
+				}
+
+				parent := filepath.Dir(path)
+				if parent == path {
+					return "", err
+				}
+
+				path = parent
+			}
+		},
+		fix: "free some space or use a folder of another disk",
+	}
+}
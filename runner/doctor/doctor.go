@@ -0,0 +1,118 @@
+// Package doctor checks the environment of a run mode and prints how to
+// fix the problems it finds.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/runner/installplaywright"
+)
+
+// check is a check of the environment. run returns details printed when
+// it passes, and fix is printed when it fails.
+type check struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+	fix  string
+}
+
+type doctor struct {
+	cfg *runner.Config
+	out io.Writer
+}
+
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if !cfg.Doctor {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	return &doctor{cfg: cfg, out: os.Stdout}, nil
+}
+
+// Run runs all the checks and fails when any of them fails.
+func (d *doctor) Run(ctx context.Context) error {
+	checks := d.checks()
+
+	var failed int
+
+	for _, c := range checks {
+		details, err := c.run(ctx)
+		if err != nil {
+			failed++
+
+			fmt.Fprintf(d.out, "[fail] %s: %v\n       fix: %s\n", c.name, err, c.fix)
+
+			continue
+		}
+
+		fmt.Fprintf(d.out, "[ok]   %s %s\n", c.name, details)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+
+	return nil
+}
+
+func (d *doctor) Close(context.Context) error {
+	return nil
+}
+
+func (d *doctor) checks() []check {
+	checks := []check{d.envCheck()}
+
+	// the fast mode does not use a browser
+	if !d.cfg.FastMode || d.cfg.RunMode == runner.RunModeWeb {
+		checks = append(checks, d.playwrightChecks()...)
+	}
+
+	if d.cfg.Dsn != "" {
+		checks = append(checks, d.databaseCheck())
+	}
+
+	if d.cfg.S3Bucket != "" {
+		checks = append(checks, d.s3Check())
+	}
+
+	if len(d.cfg.Proxies) > 0 {
+		checks = append(checks, d.proxiesCheck())
+	}
+
+	if folder := d.dataFolder(); folder != "" {
+		checks = append(checks, d.diskCheck(folder))
+	}
+
+	return checks
+}
+
+func (d *doctor) playwrightChecks() []check {
+	return []check{
+		{
+			name: "playwright driver",
+			run: func(context.Context) (string, error) {
+				version := installplaywright.DriverVersion()
+				if version == "" {
+					return "", errors.New("unknown driver version")
+				}
+
+				return version, nil
+			},
+			fix: "check that the cache directory of playwright-go is writable, or set PLAYWRIGHT_DRIVER_PATH",
+		},
+		{
+			name: "playwright browsers",
+			run: func(context.Context) (string, error) {
+				return "", installplaywright.Verify()
+			},
+			fix: "install them with PLAYWRIGHT_INSTALL_ONLY=1 ./google-maps-scraper, or start without -browsers-offline " +
+				"so that they are installed on startup. With PLAYWRIGHT_BROWSERS_PATH set, check that it is the path " +
+				"they were installed to",
+		},
+	}
+}
@@ -0,0 +1,6 @@
+package runner
+
+var (
+	ApplyConfig = applyConfig
+	Redact      = redact
+)
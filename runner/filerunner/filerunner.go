@@ -5,14 +5,26 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/gosom/google-maps-scraper/contacts"
 	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/dryrun"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/jsonl"
+	"github.com/gosom/google-maps-scraper/kml"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+	"github.com/gosom/google-maps-scraper/redact"
 	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/runner/installplaywright"
+	"github.com/gosom/google-maps-scraper/seeds"
 	"github.com/gosom/google-maps-scraper/tlmt"
+	"github.com/gosom/google-maps-scraper/tmplwriter"
+	"github.com/gosom/kit/logging"
 	"github.com/gosom/scrapemate"
 	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
 	"github.com/gosom/scrapemate/adapters/writers/jsonwriter"
@@ -20,11 +32,16 @@ import (
 )
 
 type fileRunner struct {
-	cfg     *runner.Config
-	input   io.Reader
-	writers []scrapemate.ResultWriter
-	app     *scrapemateapp.ScrapemateApp
-	outfile *os.File
+	cfg       *runner.Config
+	input     io.Reader
+	writers   []scrapemate.ResultWriter
+	app       *scrapemateapp.ScrapemateApp
+	outfile   *os.File
+	tables    []*os.File
+	logfile   *os.File
+	dashboard *dashboard
+	hook      *scraper.HookWriter
+	cancel    context.CancelFunc
 }
 
 func New(cfg *runner.Config) (runner.Runner, error) {
@@ -40,10 +57,32 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		return nil, err
 	}
 
+	if cfg.DryRun {
+		return ans, nil
+	}
+
 	if err := ans.setWriters(); err != nil {
 		return nil, err
 	}
 
+	if cfg.TUI {
+		if err := ans.setDashboard(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !cfg.FastMode {
+		if err := installplaywright.Ensure(cfg.BrowsersOffline); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.BrowserDataDir != "" {
+		if err := installplaywright.SetupTempDir(cfg.BrowserDataDir); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := ans.setApp(); err != nil {
 		return nil, err
 	}
@@ -67,6 +106,10 @@ func (r *fileRunner) Run(ctx context.Context) (err error) {
 			params["error"] = err.Error()
 		}
 
+		if r.cfg.BrowserDataDir != "" {
+			params["browser_data_size"] = installplaywright.TempDirSize(r.cfg.BrowserDataDir)
+		}
+
 		evt := tlmt.NewEvent("file_runner", params)
 
 		_ = runner.Telemetry().Send(ctx, evt)
@@ -75,37 +118,87 @@ func (r *fileRunner) Run(ctx context.Context) (err error) {
 	dedup := deduper.New()
 	exitMonitor := exiter.New()
 
-	seedJobs, err = runner.CreateSeedJobs(
-		r.cfg.FastMode,
-		r.cfg.LangCode,
-		r.input,
-		r.cfg.MaxDepth,
-		r.cfg.Email,
-		r.cfg.GeoCoordinates,
-		r.cfg.Zoom,
-		r.cfg.Radius,
-		dedup,
-		exitMonitor,
-	)
+	seedOpts := r.cfg.SeedOptions()
+	seedOpts.Dedup = dedup
+	seedOpts.ExitMonitor = exitMonitor
+
+	seedJobs, err = seeds.New(r.input, &seedOpts)
 	if err != nil {
 		return err
 	}
 
+	if r.cfg.DryRun {
+		plan := dryrun.New(seedJobs, dryrun.Params{
+			FastMode:    r.cfg.FastMode,
+			Depth:       r.cfg.MaxDepth,
+			Email:       r.cfg.Email,
+			MaxResults:  r.cfg.MaxResults,
+			Concurrency: r.cfg.Concurrency,
+		})
+
+		return plan.Print(os.Stdout)
+	}
+
+	if r.dashboard != nil {
+		seedJobs = r.dashboard.track(seedJobs)
+
+		tuiCtx, tuiCancel := context.WithCancel(context.Background())
+		tuiDone := make(chan struct{})
+
+		go func() {
+			r.dashboard.Run(tuiCtx)
+			close(tuiDone)
+		}()
+
+		defer func() {
+			tuiCancel()
+			<-tuiDone
+		}()
+	}
+
 	exitMonitor.SetSeedCount(len(seedJobs))
+	exitMonitor.SetMaxPlaces(r.cfg.MaxResults)
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	exitMonitor.SetCancelFunc(cancel)
 
+	r.cancel = cancel
+
 	go exitMonitor.Run(ctx)
 
 	err = r.app.Start(ctx, seedJobs...)
 
+	if r.hook != nil {
+		log.Printf("%d places were filtered out", r.hook.Skipped())
+
+		if r.hook.Err() != nil {
+			return r.hook.Err()
+		}
+	}
+
 	return err
 }
 
 func (r *fileRunner) Close(context.Context) error {
+	if r.logfile != nil {
+		defer r.logfile.Close()
+	}
+
+	// the profiles are removed once the browsers are closed
+	if r.cfg.BrowserDataDir != "" {
+		defer func() {
+			if _, err := installplaywright.CleanTempDir(r.cfg.BrowserDataDir); err != nil {
+				log.Printf("could not clean the browser data: %v", err)
+			}
+		}()
+	}
+
+	for _, f := range r.tables {
+		defer f.Close()
+	}
+
 	if r.app != nil {
 		return r.app.Close()
 	}
@@ -125,7 +218,7 @@ func (r *fileRunner) Close(context.Context) error {
 
 func (r *fileRunner) setInput() error {
 	switch r.cfg.InputFile {
-	case "stdin":
+	case "stdin", "-":
 		r.input = os.Stdin
 	default:
 		f, err := os.Open(r.cfg.InputFile)
@@ -158,7 +251,7 @@ func (r *fileRunner) setWriters() error {
 		var resultsWriter io.Writer
 
 		switch r.cfg.ResultsFile {
-		case "stdout":
+		case "stdout", "-":
 			resultsWriter = os.Stdout
 		default:
 			f, err := os.Create(r.cfg.ResultsFile)
@@ -169,17 +262,111 @@ func (r *fileRunner) setWriters() error {
 			r.outfile = f
 
 			resultsWriter = r.outfile
+
+			if err := runner.WriteSchemaFile(r.cfg.ResultsFile, r.cfg.Format); err != nil {
+				return err
+			}
 		}
 
-		csvWriter := csvwriter.NewCsvWriter(csv.NewWriter(resultsWriter))
+		var writer scrapemate.ResultWriter
 
-		if r.cfg.JSON {
-			r.writers = append(r.writers, jsonwriter.NewJSONWriter(resultsWriter))
-		} else {
-			r.writers = append(r.writers, csvWriter)
+		switch r.cfg.Format {
+		case "json":
+			writer = jsonwriter.NewJSONWriter(resultsWriter)
+			if r.cfg.LegacyLongtitude {
+				writer = scraper.NewLegacyWriter(writer)
+			}
+		case "jsonl":
+			writer = jsonl.NewResultWriter(resultsWriter, jsonl.WithLegacyLongtitude(r.cfg.LegacyLongtitude))
+		case "template":
+			t, err := tmplwriter.Parse(r.cfg.Template)
+			if err != nil {
+				return err
+			}
+
+			writer = tmplwriter.NewResultWriter(resultsWriter, t)
+		case contacts.FormatVCard, contacts.FormatGoogle, contacts.FormatOutlook:
+			var err error
+
+			writer, err = contacts.NewResultWriter(resultsWriter, r.cfg.Format)
+			if err != nil {
+				return err
+			}
+		case kml.FormatKML, kml.FormatKMZ:
+			var err error
+
+			writer, err = kml.NewResultWriter(resultsWriter, r.cfg.Format)
+			if err != nil {
+				return err
+			}
+		default:
+			writer = csvwriter.NewCsvWriter(csv.NewWriter(resultsWriter))
+		}
+
+		r.writers = append(r.writers, r.cfg.SortWriter(writer))
+
+		if err := r.setTables(); err != nil {
+			return err
+		}
+	}
+
+	externalWriters, err := runner.ExternalWriters(r.cfg, "")
+	if err != nil {
+		return err
+	}
+
+	r.writers = append(r.writers, externalWriters...)
+
+	return nil
+}
+
+// setTables writes the enabled secondary outputs to csv files next to
+// the results.
+func (r *fileRunner) setTables() error {
+	tables := r.cfg.Tables()
+	if len(tables) == 0 {
+		return nil
+	}
+
+	if r.outfile == nil {
+		return fmt.Errorf("the %s table requires the results to be written to a file", tables[0].Name)
+	}
+
+	for _, table := range tables {
+		f, err := os.Create(runner.TableFile(r.cfg.ResultsFile, table.Name))
+		if err != nil {
+			return err
 		}
+
+		r.tables = append(r.tables, f)
+		r.writers = append(r.writers, runner.NewTableWriter(f, table))
+	}
+
+	return nil
+}
+
+// setDashboard writes the logs to a file next to the results, so they
+// do not interfere with the dashboard that is drawn on the terminal.
+func (r *fileRunner) setDashboard() error {
+	if r.outfile == nil {
+		return fmt.Errorf("tui requires the results to be written to a file")
+	}
+
+	f, err := os.Create(r.cfg.ResultsFile + ".log")
+	if err != nil {
+		return err
 	}
 
+	r.logfile = f
+	r.dashboard = newDashboard(os.Stderr, len(r.cfg.Proxies))
+
+	// scrapemate logs with the default kit logger, it is set before the
+	// app is created
+	w := redact.NewWriter(f, redact.New(r.cfg.Secrets()...))
+
+	log.SetOutput(w)
+	logging.SetDefault(logging.New("zerolog", logging.INFO, w))
+
 	return nil
 }
 
@@ -187,6 +374,7 @@ func (r *fileRunner) setApp() error {
 	opts := []func(*scrapemateapp.Config) error{
 		// scrapemateapp.WithCache("leveldb", "cache"),
 		scrapemateapp.WithConcurrency(r.cfg.Concurrency),
+		scrapemateapp.WithProvider(gmaps.NewRetryProvider(nil)),
 		scrapemateapp.WithExitOnInactivity(r.cfg.ExitOnInactivityDuration),
 	}
 
@@ -217,8 +405,20 @@ func (r *fileRunner) setApp() error {
 		)
 	}
 
+	writers := r.writers
+
+	filterFn, err := r.cfg.FilterOptions().EntryFunc()
+	if err != nil {
+		return err
+	}
+
+	if fn := scraper.Chain(filterFn, r.cfg.EntryFunc); fn != nil {
+		r.hook = scraper.NewHookWriter(fn, r.stop, r.writers...)
+		writers = []scrapemate.ResultWriter{r.hook}
+	}
+
 	matecfg, err := scrapemateapp.NewConfig(
-		r.writers,
+		writers,
 		opts...,
 	)
 	if err != nil {
@@ -232,3 +432,10 @@ func (r *fileRunner) setApp() error {
 
 	return nil
 }
+
+// stop cancels the run when the EntryFunc stops the search.
+func (r *fileRunner) stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
@@ -0,0 +1,276 @@
+package filerunner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	tuiRefresh   = 500 * time.Millisecond
+	tuiBarWidth  = 30
+	tuiMaxSeeds  = 15
+	tuiNameWidth = 40
+)
+
+type seedProgress struct {
+	name     string
+	found    int
+	done     int
+	errors   int
+	finished bool
+}
+
+// dashboard renders a live view of the scrape progress. It tracks the jobs
+// by wrapping the seed jobs: every job a seed job creates is wrapped too,
+// so results and errors are attributed to their seed.
+type dashboard struct {
+	mu      sync.Mutex
+	out     io.Writer
+	started time.Time
+	seeds   []*seedProgress
+	results int
+	errors  int
+	lastErr string
+	proxies int
+}
+
+func newDashboard(out io.Writer, proxies int) *dashboard {
+	return &dashboard{
+		out:     out,
+		started: time.Now(),
+		proxies: proxies,
+	}
+}
+
+// track wraps the seed jobs so their progress is reported to the dashboard.
+func (d *dashboard) track(seedJobs []scrapemate.IJob) []scrapemate.IJob {
+	ans := make([]scrapemate.IJob, 0, len(seedJobs))
+
+	for _, job := range seedJobs {
+		seed := &seedProgress{name: seedName(job)}
+
+		d.seeds = append(d.seeds, seed)
+
+		ans = append(ans, &trackedJob{IJob: job, d: d, seed: seed, isSeed: true})
+	}
+
+	return ans
+}
+
+func (d *dashboard) Run(ctx context.Context) {
+	ticker := time.NewTicker(tuiRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.render()
+
+			return
+		case <-ticker.C:
+			d.render()
+		}
+	}
+}
+
+func (d *dashboard) processed(job *trackedJob, data any, next int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err != nil {
+		d.errors++
+		d.lastErr = err.Error()
+		job.seed.errors++
+	}
+
+	results := 0
+
+	switch v := data.(type) {
+	case nil:
+	case []*gmaps.Entry:
+		// fast mode returns the places with the search
+		results = len(v)
+		job.seed.found += len(v)
+		job.seed.done += len(v)
+	default:
+		if job.UseInResults() {
+			results = 1
+		}
+	}
+
+	d.results += results
+
+	if job.isSeed {
+		job.seed.found += next
+		job.seed.finished = true
+
+		return
+	}
+
+	// a place job that creates an email job is done when the email job is
+	if next == 0 {
+		job.seed.done++
+	}
+}
+
+func (d *dashboard) render() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var (
+		b                                 strings.Builder
+		seedsDone, found, done, remaining int
+	)
+
+	for _, s := range d.seeds {
+		found += s.found
+		done += s.done
+
+		if s.finished {
+			seedsDone++
+		}
+	}
+
+	elapsed := time.Since(d.started)
+
+	b.WriteString("\033[H\033[J")
+	fmt.Fprintf(&b, "Google Maps Scraper - %s elapsed\n\n", elapsed.Round(time.Second))
+
+	shown := 0
+
+	for _, s := range d.seeds {
+		if s.finished && s.done >= s.found {
+			continue
+		}
+
+		if shown == tuiMaxSeeds {
+			remaining++
+
+			continue
+		}
+
+		shown++
+
+		fmt.Fprintf(&b, "%s %s %d/%d\n", bar(s), truncate(s.name, tuiNameWidth), s.done, s.found)
+	}
+
+	if remaining > 0 {
+		fmt.Fprintf(&b, "... and %d more\n", remaining)
+	}
+
+	fmt.Fprintf(&b, "\nseeds:   %d/%d\n", seedsDone, len(d.seeds))
+	fmt.Fprintf(&b, "places:  %d/%d\n", done, found)
+	fmt.Fprintf(&b, "results: %d\n", d.results)
+	fmt.Fprintf(&b, "errors:  %d\n", d.errors)
+
+	if d.proxies > 0 {
+		fmt.Fprintf(&b, "proxies: %d (rotated)\n", d.proxies)
+	}
+
+	fmt.Fprintf(&b, "eta:     %s\n", eta(elapsed, len(d.seeds), seedsDone, found, done))
+
+	if d.lastErr != "" {
+		fmt.Fprintf(&b, "\nlast error: %s\n", truncate(d.lastErr, 120))
+	}
+
+	_, _ = io.WriteString(d.out, b.String())
+}
+
+// eta estimates the remaining time from the jobs completed so far. The seeds
+// that did not run yet are assumed to find as many places as the average seed.
+func eta(elapsed time.Duration, seeds, seedsDone, found, done int) string {
+	if seedsDone == 0 || done == 0 {
+		return "-"
+	}
+
+	total := seeds + found + (seeds-seedsDone)*found/seedsDone
+	completed := seedsDone + done
+
+	if completed >= total {
+		return "0s"
+	}
+
+	left := time.Duration(float64(elapsed) * float64(total-completed) / float64(completed))
+
+	return left.Round(time.Second).String()
+}
+
+func bar(s *seedProgress) string {
+	filled := 0
+
+	if s.found > 0 {
+		filled = min(tuiBarWidth, tuiBarWidth*s.done/s.found)
+	}
+
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", tuiBarWidth-filled) + "]"
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-1]) + "…"
+}
+
+func seedName(job scrapemate.IJob) string {
+	if q := job.GetURLParams()["q"]; q != "" {
+		return q
+	}
+
+	_, after, ok := strings.Cut(job.GetURL(), "/maps/search/")
+	if !ok {
+		return job.GetID()
+	}
+
+	query, _, _ := strings.Cut(after, "/")
+
+	if unescaped, err := url.QueryUnescape(query); err == nil {
+		return unescaped
+	}
+
+	return query
+}
+
+// trackedJob reports the outcome of a job to the dashboard and tracks the
+// jobs it creates under the same seed. The job is processed on fetch errors
+// too, otherwise scrapemate skips Process and the failed job is never
+// counted.
+type trackedJob struct {
+	scrapemate.IJob
+	d      *dashboard
+	seed   *seedProgress
+	isSeed bool
+}
+
+func (j *trackedJob) ProcessOnFetchError() bool {
+	return true
+}
+
+func (j *trackedJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	if resp.Error != nil && !j.IJob.ProcessOnFetchError() {
+		j.d.processed(j, nil, 0, resp.Error)
+
+		return nil, nil, resp.Error
+	}
+
+	data, next, err := j.IJob.Process(ctx, resp)
+
+	j.d.processed(j, data, len(next), err)
+
+	for i := range next {
+		next[i] = &trackedJob{IJob: next[i], d: j.d, seed: j.seed}
+	}
+
+	return data, next, err
+}
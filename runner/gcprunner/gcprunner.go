@@ -0,0 +1,183 @@
+package gcprunner
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/filter"
+	"github.com/gosom/google-maps-scraper/gcsuploader"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/seeds"
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
+	"github.com/gosom/scrapemate/scrapemateapp"
+)
+
+var _ runner.Runner = (*cloudRunRunner)(nil)
+
+// cloudRunRunner is the task of a Cloud Run Job execution. It scrapes the
+// chunk it receives from the invoker and uploads the results to GCS.
+type cloudRunRunner struct {
+	uploader runner.S3Uploader
+}
+
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeGcpCloudRun {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	uploader, err := gcsuploader.New(cfg.GcpCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := cloudRunRunner{
+		uploader: uploader,
+	}
+
+	return &ans, nil
+}
+
+func (c *cloudRunRunner) Run(ctx context.Context) error {
+	raw := os.Getenv(payloadEnv)
+	if raw == "" {
+		return fmt.Errorf("%s is not set", payloadEnv)
+	}
+
+	var input gInput
+
+	if err := json.Unmarshal([]byte(raw), &input); err != nil {
+		return fmt.Errorf("invalid %s: %w", payloadEnv, err)
+	}
+
+	return c.handler(ctx, input)
+}
+
+func (c *cloudRunRunner) Close(context.Context) error {
+	return nil
+}
+
+//nolint:gocritic // we pass a value to the handler
+func (c *cloudRunRunner) handler(ctx context.Context, input gInput) error {
+	out, err := os.Create(filepath.Join(os.TempDir(), "output.csv"))
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	app, err := c.getApp(ctx, input, out)
+	if err != nil {
+		return err
+	}
+
+	in := strings.NewReader(strings.Join(input.Keywords, "\n"))
+
+	var seedJobs []scrapemate.IJob
+
+	exitMonitor := exiter.New()
+
+	seedJobs, err = seeds.New(in, &seeds.Options{
+		LangCode:         input.Language,
+		MaxDepth:         input.Depth,
+		Localize:         input.Localize,
+		ResultsLang:      input.ResultsLang,
+		ResultsEnglish:   input.ResultsEnglish,
+		Country:          input.Country,
+		ExcludeSponsored: input.ExcludeSponsored,
+		ExitMonitor:      exitMonitor,
+	})
+	if err != nil {
+		return err
+	}
+
+	exitMonitor.SetSeedCount(len(seedJobs))
+
+	bCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	exitMonitor.SetCancelFunc(cancel)
+
+	go exitMonitor.Run(bCtx)
+
+	err = app.Start(bCtx, seedJobs...)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	out.Close()
+
+	key := fmt.Sprintf("%s-%d.csv", input.JobID, input.Part)
+
+	fd, err := os.Open(out.Name())
+	if err != nil {
+		return err
+	}
+
+	defer fd.Close()
+
+	if err := c.uploader.Upload(ctx, input.BucketName, key, fd); err != nil {
+		return err
+	}
+
+	log.Printf("results uploaded to gs://%s/%s", input.BucketName, key)
+
+	return nil
+}
+
+//nolint:gocritic // we pass a value to the handler
+func (c *cloudRunRunner) getApp(_ context.Context, input gInput, out io.Writer) (*scrapemateapp.ScrapemateApp, error) {
+	csvWriter := csvwriter.NewCsvWriter(csv.NewWriter(out))
+
+	writers, err := runner.FilterWriters(&filter.Options{
+		Expr:              input.Filter,
+		IncludeCategories: input.IncludeCategories,
+		ExcludeCategories: input.ExcludeCategories,
+		Quality: filter.Quality{
+			RequirePhone:   input.RequirePhone,
+			RequireWebsite: input.RequireWebsite,
+			MinRating:      input.MinRating,
+			MinReviews:     input.MinReviews,
+		},
+	}, []scrapemate.ResultWriter{csvWriter})
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []func(*scrapemateapp.Config) error{
+		scrapemateapp.WithConcurrency(max(1, input.Concurrency)),
+		scrapemateapp.WithProvider(gmaps.NewRetryProvider(nil)),
+		scrapemateapp.WithExitOnInactivity(time.Minute),
+		scrapemateapp.WithJS(
+			scrapemateapp.DisableImages(),
+		),
+	}
+
+	if !input.DisablePageReuse {
+		opts = append(opts, scrapemateapp.WithPageReuseLimit(2))
+		opts = append(opts, scrapemateapp.WithBrowserReuseLimit(200))
+	}
+
+	mateCfg, err := scrapemateapp.NewConfig(writers, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := scrapemateapp.NewScrapeMateApp(mateCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
@@ -0,0 +1,222 @@
+package gcprunner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gosom/google-maps-scraper/gcpauth"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+const (
+	runURL        = "https://run.googleapis.com/v2/%s:run"
+	platformScope = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+var _ runner.Runner = (*invoker)(nil)
+
+// invoker splits the input in chunks and starts a Cloud Run Job execution
+// per chunk. Each execution uploads its results to the bucket
+// as <job_id>-<part>.csv.
+type invoker struct {
+	ts       *gcpauth.TokenSource
+	client   *http.Client
+	jobName  string
+	payloads []gInput
+}
+
+func NewInvoker(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeGcpInvoker {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	ts, err := gcpauth.Default(cfg.GcpCredentials, platformScope)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := invoker{
+		ts:     ts,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	ans.jobName, err = ans.resolveJobName(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ans.setPayloads(cfg); err != nil {
+		return nil, err
+	}
+
+	return &ans, nil
+}
+
+func (i *invoker) Run(ctx context.Context) error {
+	for j := range i.payloads {
+		if err := i.invoke(ctx, i.payloads[j]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *invoker) Close(context.Context) error {
+	return nil
+}
+
+//nolint:gocritic // let's pass the input as is
+func (i *invoker) invoke(ctx context.Context, input gInput) error {
+	payloadBytes, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]any{
+		"overrides": map[string]any{
+			"taskCount": 1,
+			"containerOverrides": []map[string]any{
+				{
+					"args": []string{"-gcp-cloud-run"},
+					"env": []map[string]string{
+						{"name": payloadEnv, "value": string(payloadBytes)},
+					},
+				},
+			},
+		},
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(runURL, i.jobName), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := i.ts.Authorize(req); err != nil {
+		return err
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+		return fmt.Errorf("cloud run job %s failed to start with status %d: %s", i.jobName, resp.StatusCode, string(msg))
+	}
+
+	log.Printf("Cloud Run job %s executed with JobID %s, Part %d", input.JobName, input.JobID, input.Part)
+
+	return nil
+}
+
+// resolveJobName returns the full resource name of the Cloud Run Job.
+// The job may be given either as projects/<p>/locations/<r>/jobs/<name>
+// or as a plain name together with the region.
+func (i *invoker) resolveJobName(cfg *runner.Config) (string, error) {
+	if strings.HasPrefix(cfg.GcpJob, "projects/") {
+		return cfg.GcpJob, nil
+	}
+
+	project := cfg.GcpProject
+	if project == "" {
+		project = i.ts.ProjectID()
+	}
+
+	if project == "" || cfg.GcpRegion == "" {
+		return "", fmt.Errorf("project and region are required for cloud run job %s", cfg.GcpJob)
+	}
+
+	return fmt.Sprintf("projects/%s/locations/%s/jobs/%s", project, cfg.GcpRegion, cfg.GcpJob), nil
+}
+
+func (i *invoker) setPayloads(cfg *runner.Config) error {
+	f, err := os.Open(cfg.InputFile)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	var currentChunk []string
+
+	jobID := uuid.New().String()
+
+	newPayload := func(part int, keywords []string) gInput {
+		return gInput{
+			JobID:             jobID,
+			Part:              part,
+			BucketName:        cfg.GcsBucket,
+			Keywords:          keywords,
+			Depth:             cfg.MaxDepth,
+			Concurrency:       cfg.Concurrency,
+			Language:          cfg.LangCode,
+			JobName:           cfg.GcpJob,
+			DisablePageReuse:  cfg.DisablePageReuse,
+			Localize:          cfg.Localize,
+			ResultsLang:       cfg.ResultsLang,
+			ResultsEnglish:    cfg.ResultsEnglish,
+			Country:           cfg.Country,
+			ExcludeSponsored:  cfg.ExcludeSponsored,
+			Filter:            cfg.Filter,
+			IncludeCategories: cfg.IncludeCategories,
+			ExcludeCategories: cfg.ExcludeCategories,
+			RequirePhone:      cfg.RequirePhone,
+			RequireWebsite:    cfg.RequireWebsite,
+			MinRating:         cfg.MinRating,
+			MinReviews:        cfg.MinReviews,
+		}
+	}
+
+	for scanner.Scan() {
+		keyword := strings.TrimSpace(scanner.Text())
+		if keyword == "" {
+			continue
+		}
+
+		currentChunk = append(currentChunk, keyword)
+
+		if len(currentChunk) >= cfg.GcpChunkSize {
+			i.payloads = append(i.payloads, newPayload(len(i.payloads), currentChunk))
+			currentChunk = nil
+		}
+	}
+
+	if len(currentChunk) > 0 {
+		i.payloads = append(i.payloads, newPayload(len(i.payloads), currentChunk))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(i.payloads) == 0 {
+		return fmt.Errorf("no keywords found in input file")
+	}
+
+	return nil
+}
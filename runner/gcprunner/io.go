@@ -0,0 +1,29 @@
+package gcprunner
+
+// payloadEnv is the environment variable the invoker uses to pass
+// the chunk of a Cloud Run Job execution to the task.
+const payloadEnv = "GMAPS_PAYLOAD"
+
+type gInput struct {
+	JobID             string   `json:"job_id"`
+	Part              int      `json:"part"`
+	BucketName        string   `json:"bucket_name"`
+	Keywords          []string `json:"keywords"`
+	Depth             int      `json:"depth"`
+	Concurrency       int      `json:"concurrency"`
+	Language          string   `json:"language"`
+	JobName           string   `json:"job_name"`
+	DisablePageReuse  bool     `json:"disable_page_reuse"`
+	Localize          bool     `json:"localize"`
+	ResultsLang       string   `json:"results_lang"`
+	ResultsEnglish    bool     `json:"results_english"`
+	Country           string   `json:"country"`
+	ExcludeSponsored  bool     `json:"exclude_sponsored"`
+	Filter            string   `json:"filter"`
+	IncludeCategories []string `json:"include_categories"`
+	ExcludeCategories []string `json:"exclude_categories"`
+	RequirePhone      bool     `json:"require_phone"`
+	RequireWebsite    bool     `json:"require_website"`
+	MinRating         float64  `json:"min_rating"`
+	MinReviews        int      `json:"min_reviews"`
+}
@@ -2,12 +2,24 @@ package installplaywright
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"os"
 
 	"github.com/gosom/google-maps-scraper/runner"
 	"github.com/playwright-community/playwright-go"
 )
 
+// ErrBrowsersMissing is returned in offline mode when the driver or the
+// browsers are not installed.
+var ErrBrowsersMissing = errors.New("the playwright browsers are not installed")
+
+// browsers are the browsers installed. Their versions are pinned by the
+// version of the playwright driver, which is the one of playwright-go in
+// go.mod.
+var browsers = []string{"chromium"}
+
 type installer struct {
 }
 
@@ -20,15 +32,78 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 }
 
 func (i *installer) Run(context.Context) error {
-	opts := []*playwright.RunOptions{
-		{
-			Browsers: []string{"chromium"},
-		},
+	return Install()
+}
+
+func (i *installer) Close(context.Context) error {
+	return nil
+}
+
+// DriverVersion returns the version of the playwright driver.
+func DriverVersion() string {
+	driver, err := playwright.NewDriver(&playwright.RunOptions{Browsers: browsers})
+	if err != nil {
+		return ""
 	}
 
-	return playwright.Install(opts...)
+	return driver.Version
 }
 
-func (i *installer) Close(context.Context) error {
+// Install downloads the driver and the browsers when they are missing.
+func Install() error {
+	log.Printf("installing the playwright driver %s and %v", DriverVersion(), browsers)
+
+	return playwright.Install(&playwright.RunOptions{Browsers: browsers})
+}
+
+// Ensure installs the driver and the browsers on startup, so that they are
+// not downloaded when the first job runs. Offline, nothing is downloaded
+// and Ensure fails fast when they are missing.
+func Ensure(offline bool) error {
+	if !offline {
+		return Install()
+	}
+
+	if err := Verify(); err != nil {
+		dir := os.Getenv("PLAYWRIGHT_BROWSERS_PATH")
+		if dir == "" {
+			dir = "the default location"
+		}
+
+		return fmt.Errorf("%w (driver %s) in %s, run with PLAYWRIGHT_INSTALL_ONLY=1 on a machine with network access "+
+			"and copy the browsers, or set PLAYWRIGHT_BROWSERS_PATH: %w", ErrBrowsersMissing, DriverVersion(), dir, err)
+	}
+
 	return nil
 }
+
+// Verify launches a browser and opens a context in it, without installing
+// anything.
+func Verify() error {
+	pw, err := playwright.Run(&playwright.RunOptions{Browsers: browsers, SkipInstallBrowsers: true})
+	if err != nil {
+		return fmt.Errorf("could not start playwright: %w", err)
+	}
+
+	defer func() {
+		_ = pw.Stop()
+	}()
+
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+		Headless: playwright.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("could not launch the browser: %w", err)
+	}
+
+	defer func() {
+		_ = browser.Close()
+	}()
+
+	bctx, err := browser.NewContext()
+	if err != nil {
+		return fmt.Errorf("could not create a browser context: %w", err)
+	}
+
+	return bctx.Close()
+}
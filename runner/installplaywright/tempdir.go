@@ -0,0 +1,138 @@
+package installplaywright
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// profilePrefixes are the prefixes of the temporary profiles and downloads
+// of playwright and the browsers.
+var profilePrefixes = []string{
+	"playwright_chromiumdev_profile-",
+	"playwright_firefoxdev_profile-",
+	"playwright-artifacts-",
+	".org.chromium.Chromium.",
+}
+
+// UseTempDir makes playwright and the browsers create their profiles and
+// downloads in dir, which is created when it does not exist.
+func UseTempDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{"TMPDIR"}
+	if runtime.GOOS == "windows" {
+		keys = []string{"TMP", "TEMP"}
+	}
+
+	for _, key := range keys {
+		if err := os.Setenv(key, dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetupTempDir makes the browsers use dir and removes the profiles that
+// were left in it, e.g. by a crash.
+func SetupTempDir(dir string) error {
+	if err := UseTempDir(dir); err != nil {
+		return err
+	}
+
+	size, err := CleanTempDir(dir)
+	if err != nil {
+		return err
+	}
+
+	if size > 0 {
+		log.Printf("removed %d MiB of orphaned browser profiles from %s", size>>20, dir)
+	}
+
+	return nil
+}
+
+// TempDirSize returns the number of bytes the profiles and downloads of dir
+// use.
+func TempDirSize(dir string) int64 {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+
+	var size int64
+
+	for _, entry := range entries {
+		if isProfile(entry.Name()) {
+			size += dirSize(filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	return size
+}
+
+// CleanTempDir removes the profiles and downloads of dir and returns the
+// number of bytes they used. dir must not be shared with a running browser.
+func CleanTempDir(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+
+	for _, entry := range entries {
+		if !isProfile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+
+		size += dirSize(path)
+
+		if err := os.RemoveAll(path); err != nil {
+			return size, err
+		}
+	}
+
+	return size, nil
+}
+
+func isProfile(name string) bool {
+	for _, prefix := range profilePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dirSize(path string) int64 {
+	var size int64
+
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil //nolint:nilerr // the unreadable entries are skipped
+		}
+
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size
+}
@@ -1,128 +1,77 @@
 package runner
 
 import (
-	"bufio"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"plugin"
-	"strconv"
-	"strings"
 
-	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
 	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/seeds"
 	"github.com/gosom/scrapemate"
 )
 
-func CreateSeedJobs(
-	fastmode bool,
+// EmailSitemap returns the options of the crawl of the sitemaps of the
+// websites when the emails are extracted.
+func (c *Config) EmailSitemap() gmaps.SitemapOptions {
+	return gmaps.SitemapOptions{
+		MaxPages: c.EmailSitemapPages,
+		MaxSize:  c.EmailSitemapMaxSize,
+	}
+}
+
+// SeedOptions returns the options of the seed jobs of the configuration.
+func (c *Config) SeedOptions() seeds.Options {
+	return seeds.Options{
+		FastMode:         c.FastMode,
+		LangCode:         c.LangCode,
+		MaxDepth:         c.MaxDepth,
+		Email:            c.Email,
+		EmailSitemap:     c.EmailSitemap(),
+		GeoCoordinates:   c.GeoCoordinates,
+		Zoom:             c.Zoom,
+		Radius:           c.Radius,
+		Tiles:            c.Tiles,
+		Localize:         c.Localize,
+		ResultsLang:      c.ResultsLang,
+		ResultsEnglish:   c.ResultsEnglish,
+		Country:          c.Country,
+		ExcludeSponsored: c.ExcludeSponsored,
+		SeedRetries:      c.SeedRetries,
+	}
+}
+
+// CreatePlaceJobs creates the jobs that scrape the places, given as cids or
+// google maps urls, without searching them.
+func CreatePlaceJobs(
 	langCode string,
-	r io.Reader,
-	maxDepth int,
+	places []string,
 	email bool,
-	geoCoordinates string,
-	zoom int,
-	radius float64,
-	dedup deduper.Deduper,
+	emailSitemap gmaps.SitemapOptions,
 	exitMonitor exiter.Exiter,
-) (jobs []scrapemate.IJob, err error) {
-	var lat, lon float64
-
-	if fastmode {
-		if geoCoordinates == "" {
-			return nil, fmt.Errorf("geo coordinates are required in fast mode")
-		}
-
-		parts := strings.Split(geoCoordinates, ",")
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid geo coordinates: %s", geoCoordinates)
-		}
+) ([]scrapemate.IJob, error) {
+	jobs := make([]scrapemate.IJob, 0, len(places))
 
-		lat, err = strconv.ParseFloat(parts[0], 64)
+	for i, place := range places {
+		u, err := gmaps.PlaceURL(place)
 		if err != nil {
-			return nil, fmt.Errorf("invalid latitude: %w", err)
+			return nil, err
 		}
 
-		lon, err = strconv.ParseFloat(parts[1], 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid longitude: %w", err)
-		}
-
-		if lat < -90 || lat > 90 {
-			return nil, fmt.Errorf("invalid latitude: %f", lat)
-		}
-
-		if lon < -180 || lon > 180 {
-			return nil, fmt.Errorf("invalid longitude: %f", lon)
-		}
-
-		if zoom < 1 || zoom > 21 {
-			return nil, fmt.Errorf("invalid zoom level: %d", zoom)
-		}
-
-		if radius < 0 {
-			return nil, fmt.Errorf("invalid radius: %f", radius)
-		}
-	}
-
-	scanner := bufio.NewScanner(r)
-
-	for scanner.Scan() {
-		query := strings.TrimSpace(scanner.Text())
-		if query == "" {
-			continue
-		}
-
-		var id string
-
-		if before, after, ok := strings.Cut(query, "#!#"); ok {
-			query = strings.TrimSpace(before)
-			id = strings.TrimSpace(after)
+		opts := []gmaps.PlaceJobOptions{
+			gmaps.WithPlaceJobInput(place, i+1),
+			gmaps.WithPlaceJobEmailSitemap(emailSitemap),
 		}
 
-		var job scrapemate.IJob
-
-		if !fastmode {
-			opts := []gmaps.GmapJobOptions{}
-
-			if dedup != nil {
-				opts = append(opts, gmaps.WithDeduper(dedup))
-			}
-
-			if exitMonitor != nil {
-				opts = append(opts, gmaps.WithExitMonitor(exitMonitor))
-			}
-
-			job = gmaps.NewGmapJob(id, langCode, query, maxDepth, email, geoCoordinates, zoom, opts...)
-		} else {
-			jparams := gmaps.MapSearchParams{
-				Location: gmaps.MapLocation{
-					Lat:     lat,
-					Lon:     lon,
-					ZoomLvl: float64(zoom),
-					Radius:  radius,
-				},
-				Query:     query,
-				ViewportW: 1920,
-				ViewportH: 450,
-				Hl:        langCode,
-			}
-
-			opts := []gmaps.SearchJobOptions{}
-
-			if exitMonitor != nil {
-				opts = append(opts, gmaps.WithSearchJobExitMonitor(exitMonitor))
-			}
-
-			job = gmaps.NewSearchJob(&jparams, opts...)
+		if exitMonitor != nil {
+			opts = append(opts, gmaps.WithPlaceJobExitMonitor(exitMonitor))
 		}
 
-		jobs = append(jobs, job)
+		jobs = append(jobs, gmaps.NewPlaceJob("", langCode, u, email, opts...))
 	}
 
-	return jobs, scanner.Err()
+	return jobs, nil
 }
 
 func LoadCustomWriter(pluginDir, pluginName string) (scrapemate.ResultWriter, error) {
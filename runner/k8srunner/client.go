@@ -0,0 +1,184 @@
+package k8srunner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// client is a minimal client for the Kubernetes REST API.
+// When apiURL is empty the in-cluster service account is used,
+// otherwise requests are sent to apiURL (e.g. a `kubectl proxy`).
+type client struct {
+	baseURL   string
+	token     string
+	namespace string
+	http      *http.Client
+}
+
+func newClient(apiURL, namespace string) (*client, error) {
+	ans := client{
+		baseURL:   strings.TrimSuffix(apiURL, "/"),
+		namespace: namespace,
+		http:      &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if ans.baseURL == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("not running in a kubernetes cluster, please set the api url")
+		}
+
+		ans.baseURL = "https://" + host + ":" + port
+
+		token, err := os.ReadFile(serviceAccountDir + "/token")
+		if err != nil {
+			return nil, err
+		}
+
+		ans.token = strings.TrimSpace(string(token))
+
+		ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("invalid kubernetes ca certificate")
+		}
+
+		ans.http.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}
+	} else if token := os.Getenv("KUBE_TOKEN"); token != "" {
+		ans.token = token
+	}
+
+	if ans.namespace == "" {
+		ns, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err == nil {
+			ans.namespace = strings.TrimSpace(string(ns))
+		} else {
+			ans.namespace = "default"
+		}
+	}
+
+	return &ans, nil
+}
+
+func (c *client) createConfigMap(ctx context.Context, cm any) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/namespaces/"+c.namespace+"/configmaps", cm, nil)
+}
+
+func (c *client) deleteConfigMap(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/namespaces/"+c.namespace+"/configmaps/"+name, nil, nil)
+}
+
+func (c *client) createJob(ctx context.Context, job any) error {
+	return c.do(ctx, http.MethodPost, "/apis/batch/v1/namespaces/"+c.namespace+"/jobs", job, nil)
+}
+
+type jobStatus struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Status struct {
+		Active     int `json:"active"`
+		Succeeded  int `json:"succeeded"`
+		Failed     int `json:"failed"`
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+func (j *jobStatus) finished() (done, failed bool) {
+	for _, c := range j.Status.Conditions {
+		if c.Status != "True" {
+			continue
+		}
+
+		switch c.Type {
+		case "Complete":
+			return true, false
+		case "Failed":
+			return true, true
+		}
+	}
+
+	return false, false
+}
+
+func (c *client) listJobs(ctx context.Context, labelSelector string) ([]jobStatus, error) {
+	var resp struct {
+		Items []jobStatus `json:"items"`
+	}
+
+	path := "/apis/batch/v1/namespaces/" + c.namespace + "/jobs?labelSelector=" + labelSelector
+
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Items, nil
+}
+
+func (c *client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+		return fmt.Errorf("kubernetes api %s %s failed with status %d: %s", method, path, resp.StatusCode, string(msg))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
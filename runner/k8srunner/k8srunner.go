@@ -0,0 +1,396 @@
+package k8srunner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/tlmt"
+)
+
+const (
+	dispatchLabel = "gmaps-dispatch"
+	inputDir      = "/input"
+	inputFile     = "queries.txt"
+	pollInterval  = 10 * time.Second
+)
+
+var _ runner.Runner = (*dispatcher)(nil)
+
+// dispatcher splits the input queries in chunks and creates a Kubernetes Job
+// per chunk instead of scraping locally. Each Job pushes the seed jobs of its
+// chunk to the shared database queue (init container) and then consumes the
+// queue until it is idle, so the results end up in the shared database.
+type dispatcher struct {
+	cfg        *runner.Config
+	client     *client
+	dispatchID string
+	chunks     [][]string
+}
+
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeK8sDispatcher {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	c, err := newClient(cfg.K8sAPIURL, cfg.K8sNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := dispatcher{
+		cfg:        cfg,
+		client:     c,
+		dispatchID: uuid.New().String()[:8],
+	}
+
+	if err := ans.setChunks(); err != nil {
+		return nil, err
+	}
+
+	return &ans, nil
+}
+
+func (d *dispatcher) Run(ctx context.Context) error {
+	_ = runner.Telemetry().Send(ctx, tlmt.NewEvent("k8srunner.Run", map[string]any{
+		"job_count": len(d.chunks),
+	}))
+
+	defer d.cleanup()
+
+	for i := range d.chunks {
+		name := fmt.Sprintf("gmaps-%s-%d", d.dispatchID, i)
+
+		if err := d.client.createConfigMap(ctx, d.configMap(name, d.chunks[i])); err != nil {
+			return err
+		}
+
+		if err := d.client.createJob(ctx, d.job(name)); err != nil {
+			return err
+		}
+
+		log.Printf("kubernetes job %s created with %d queries", name, len(d.chunks[i]))
+	}
+
+	return d.wait(ctx)
+}
+
+func (d *dispatcher) Close(context.Context) error {
+	return nil
+}
+
+// wait polls the jobs of this dispatch until all of them are finished.
+func (d *dispatcher) wait(ctx context.Context) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		jobs, err := d.client.listJobs(ctx, dispatchLabel+"%3D"+d.dispatchID)
+		if err != nil {
+			return err
+		}
+
+		var completed, failed int
+
+		for i := range jobs {
+			done, jobFailed := jobs[i].finished()
+
+			switch {
+			case jobFailed:
+				failed++
+			case done:
+				completed++
+			}
+		}
+
+		log.Printf("kubernetes jobs: %d completed, %d failed, %d total", completed, failed, len(d.chunks))
+
+		if completed+failed >= len(d.chunks) {
+			if failed > 0 {
+				return fmt.Errorf("%d of %d kubernetes jobs failed", failed, len(d.chunks))
+			}
+
+			return nil
+		}
+	}
+}
+
+func (d *dispatcher) cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for i := range d.chunks {
+		name := fmt.Sprintf("gmaps-%s-%d", d.dispatchID, i)
+
+		if err := d.client.deleteConfigMap(ctx, name); err != nil {
+			log.Printf("failed to delete configmap %s: %v", name, err)
+		}
+	}
+}
+
+func (d *dispatcher) labels() map[string]string {
+	return map[string]string{
+		"app":         "google-maps-scraper",
+		dispatchLabel: d.dispatchID,
+	}
+}
+
+func (d *dispatcher) configMap(name string, queries []string) map[string]any {
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":   name,
+			"labels": d.labels(),
+		},
+		"data": map[string]string{
+			inputFile: strings.Join(queries, "\n"),
+		},
+	}
+}
+
+func (d *dispatcher) job(name string) map[string]any {
+	env := []map[string]any{d.dsnEnv()}
+
+	for _, kv := range d.cfg.K8sEnv {
+		k, v, _ := strings.Cut(kv, "=")
+
+		env = append(env, map[string]any{"name": k, "value": v})
+	}
+
+	resources := map[string]any{}
+
+	if d.cfg.K8sCPU != "" || d.cfg.K8sMemory != "" {
+		limits := map[string]string{}
+
+		if d.cfg.K8sCPU != "" {
+			limits["cpu"] = d.cfg.K8sCPU
+		}
+
+		if d.cfg.K8sMemory != "" {
+			limits["memory"] = d.cfg.K8sMemory
+		}
+
+		resources["limits"] = limits
+		resources["requests"] = limits
+	}
+
+	produceArgs := append([]string{
+		"-dsn", "$(GMAPS_DSN)",
+		"-produce",
+		"-input", inputDir + "/" + inputFile,
+	}, d.seedArgs()...)
+
+	scrapeArgs := []string{
+		"-dsn", "$(GMAPS_DSN)",
+		"-c", strconv.Itoa(d.cfg.Concurrency),
+		"-exit-on-inactivity", "3m",
+	}
+
+	if d.cfg.FastMode {
+		scrapeArgs = append(scrapeArgs, "-fast-mode")
+	}
+
+	if d.cfg.RedisURL != "" {
+		produceArgs = append(produceArgs, "-redis-url", d.cfg.RedisURL)
+		scrapeArgs = append(scrapeArgs, "-redis-url", d.cfg.RedisURL)
+	}
+
+	volumeMounts := []map[string]any{
+		{"name": "input", "mountPath": inputDir, "readOnly": true},
+	}
+
+	return map[string]any{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]any{
+			"name":   name,
+			"labels": d.labels(),
+		},
+		"spec": map[string]any{
+			"backoffLimit":            2,
+			"ttlSecondsAfterFinished": 86400,
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"labels": d.labels(),
+				},
+				"spec": map[string]any{
+					"restartPolicy": "Never",
+					"initContainers": []map[string]any{
+						{
+							"name":         "produce",
+							"image":        d.cfg.K8sImage,
+							"args":         produceArgs,
+							"env":          env,
+							"volumeMounts": volumeMounts,
+						},
+					},
+					"containers": []map[string]any{
+						{
+							"name":      "scrape",
+							"image":     d.cfg.K8sImage,
+							"args":      scrapeArgs,
+							"env":       env,
+							"resources": resources,
+						},
+					},
+					"volumes": []map[string]any{
+						{
+							"name":      "input",
+							"configMap": map[string]any{"name": name},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dsnEnv passes the dsn from a secret when one is configured,
+// so the credentials are not visible in the job spec.
+func (d *dispatcher) dsnEnv() map[string]any {
+	if secret, key, ok := strings.Cut(d.cfg.K8sDsnSecret, "/"); ok {
+		return map[string]any{
+			"name": "GMAPS_DSN",
+			"valueFrom": map[string]any{
+				"secretKeyRef": map[string]string{"name": secret, "key": key},
+			},
+		}
+	}
+
+	return map[string]any{"name": "GMAPS_DSN", "value": d.cfg.Dsn}
+}
+
+func (d *dispatcher) seedArgs() []string {
+	args := []string{
+		"-lang", d.cfg.LangCode,
+		"-depth", strconv.Itoa(d.cfg.MaxDepth),
+		"-zoom", strconv.Itoa(d.cfg.Zoom),
+		"-radius", strconv.FormatFloat(d.cfg.Radius, 'f', -1, 64),
+	}
+
+	if d.cfg.Email {
+		args = append(args, "-email")
+	}
+
+	if d.cfg.FastMode {
+		args = append(args, "-fast-mode")
+	}
+
+	if d.cfg.Tiles {
+		args = append(args, "-tiles")
+	}
+
+	if d.cfg.Localize {
+		args = append(args, "-localize")
+	}
+
+	if d.cfg.ResultsLang != "" {
+		args = append(args, "-results-lang", d.cfg.ResultsLang)
+	}
+
+	if d.cfg.ResultsEnglish {
+		args = append(args, "-results-english")
+	}
+
+	if d.cfg.Country != "" {
+		args = append(args, "-country", d.cfg.Country)
+	}
+
+	if d.cfg.ExcludeSponsored {
+		args = append(args, "-exclude-sponsored")
+	}
+
+	if d.cfg.Filter != "" {
+		args = append(args, "-filter", d.cfg.Filter)
+	}
+
+	if len(d.cfg.IncludeCategories) > 0 {
+		args = append(args, "-include-categories", strings.Join(d.cfg.IncludeCategories, ","))
+	}
+
+	if len(d.cfg.ExcludeCategories) > 0 {
+		args = append(args, "-exclude-categories", strings.Join(d.cfg.ExcludeCategories, ","))
+	}
+
+	if d.cfg.RequirePhone {
+		args = append(args, "-require-phone")
+	}
+
+	if d.cfg.RequireWebsite {
+		args = append(args, "-require-website")
+	}
+
+	if d.cfg.MinRating > 0 {
+		args = append(args, "-min-rating", strconv.FormatFloat(d.cfg.MinRating, 'f', -1, 64))
+	}
+
+	if d.cfg.MinReviews > 0 {
+		args = append(args, "-min-reviews", strconv.Itoa(d.cfg.MinReviews))
+	}
+
+	if d.cfg.VerifyAddress {
+		args = append(args, "-verify-address", "-geocode-url", d.cfg.GeocodeURL)
+	}
+
+	if d.cfg.GeoCoordinates != "" {
+		args = append(args, "-geo", d.cfg.GeoCoordinates)
+	}
+
+	return args
+}
+
+func (d *dispatcher) setChunks() error {
+	f, err := os.Open(d.cfg.InputFile)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	var current []string
+
+	for scanner.Scan() {
+		keyword := strings.TrimSpace(scanner.Text())
+		if keyword == "" {
+			continue
+		}
+
+		current = append(current, keyword)
+
+		if len(current) >= d.cfg.K8sChunkSize {
+			d.chunks = append(d.chunks, current)
+			current = nil
+		}
+	}
+
+	if len(current) > 0 {
+		d.chunks = append(d.chunks, current)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(d.chunks) == 0 {
+		return fmt.Errorf("no keywords found in input file")
+	}
+
+	return nil
+}
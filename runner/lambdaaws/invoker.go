@@ -120,14 +120,26 @@ func (i *invoker) setPayloads(cfg *runner.Config) error {
 		// When we reach chunkSize or EOF, create a new payload
 		if len(currentChunk) >= chunkSize {
 			payload := lInput{
-				JobID:        jobID,
-				Part:         chunkNumber,
-				BucketName:   cfg.S3Bucket,
-				Keywords:     currentChunk,
-				Depth:        cfg.MaxDepth,
-				Concurrency:  cfg.Concurrency,
-				Language:     cfg.LangCode,
-				FunctionName: cfg.FunctionName,
+				JobID:             jobID,
+				Part:              chunkNumber,
+				BucketName:        cfg.S3Bucket,
+				Keywords:          currentChunk,
+				Depth:             cfg.MaxDepth,
+				Concurrency:       cfg.Concurrency,
+				Language:          cfg.LangCode,
+				FunctionName:      cfg.FunctionName,
+				Localize:          cfg.Localize,
+				ResultsLang:       cfg.ResultsLang,
+				ResultsEnglish:    cfg.ResultsEnglish,
+				Country:           cfg.Country,
+				ExcludeSponsored:  cfg.ExcludeSponsored,
+				Filter:            cfg.Filter,
+				IncludeCategories: cfg.IncludeCategories,
+				ExcludeCategories: cfg.ExcludeCategories,
+				RequirePhone:      cfg.RequirePhone,
+				RequireWebsite:    cfg.RequireWebsite,
+				MinRating:         cfg.MinRating,
+				MinReviews:        cfg.MinReviews,
 			}
 			i.payloads = append(i.payloads, payload)
 
@@ -138,14 +150,26 @@ func (i *invoker) setPayloads(cfg *runner.Config) error {
 
 	if len(currentChunk) > 0 {
 		payload := lInput{
-			JobID:        jobID,
-			Part:         chunkNumber,
-			BucketName:   cfg.S3Bucket,
-			Keywords:     currentChunk,
-			Depth:        cfg.MaxDepth,
-			Concurrency:  cfg.Concurrency,
-			Language:     cfg.LangCode,
-			FunctionName: cfg.FunctionName,
+			JobID:             jobID,
+			Part:              chunkNumber,
+			BucketName:        cfg.S3Bucket,
+			Keywords:          currentChunk,
+			Depth:             cfg.MaxDepth,
+			Concurrency:       cfg.Concurrency,
+			Language:          cfg.LangCode,
+			FunctionName:      cfg.FunctionName,
+			Localize:          cfg.Localize,
+			ResultsLang:       cfg.ResultsLang,
+			ResultsEnglish:    cfg.ResultsEnglish,
+			Country:           cfg.Country,
+			ExcludeSponsored:  cfg.ExcludeSponsored,
+			Filter:            cfg.Filter,
+			IncludeCategories: cfg.IncludeCategories,
+			ExcludeCategories: cfg.ExcludeCategories,
+			RequirePhone:      cfg.RequirePhone,
+			RequireWebsite:    cfg.RequireWebsite,
+			MinRating:         cfg.MinRating,
+			MinReviews:        cfg.MinReviews,
 		}
 		i.payloads = append(i.payloads, payload)
 	}
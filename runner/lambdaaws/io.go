@@ -1,13 +1,25 @@
 package lambdaaws
 
 type lInput struct {
-	JobID            string   `json:"job_id"`
-	Part             int      `json:"part"`
-	BucketName       string   `json:"bucket_name"`
-	Keywords         []string `json:"keywords"`
-	Depth            int      `json:"depth"`
-	Concurrency      int      `json:"concurrency"`
-	Language         string   `json:"language"`
-	FunctionName     string   `json:"function_name"`
-	DisablePageReuse bool     `json:"disable_page_reuse"`
+	JobID             string   `json:"job_id"`
+	Part              int      `json:"part"`
+	BucketName        string   `json:"bucket_name"`
+	Keywords          []string `json:"keywords"`
+	Depth             int      `json:"depth"`
+	Concurrency       int      `json:"concurrency"`
+	Language          string   `json:"language"`
+	FunctionName      string   `json:"function_name"`
+	DisablePageReuse  bool     `json:"disable_page_reuse"`
+	Localize          bool     `json:"localize"`
+	ResultsLang       string   `json:"results_lang"`
+	ResultsEnglish    bool     `json:"results_english"`
+	Country           string   `json:"country"`
+	ExcludeSponsored  bool     `json:"exclude_sponsored"`
+	Filter            string   `json:"filter"`
+	IncludeCategories []string `json:"include_categories"`
+	ExcludeCategories []string `json:"exclude_categories"`
+	RequirePhone      bool     `json:"require_phone"`
+	RequireWebsite    bool     `json:"require_website"`
+	MinRating         float64  `json:"min_rating"`
+	MinReviews        int      `json:"min_reviews"`
 }
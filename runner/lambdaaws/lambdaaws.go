@@ -16,7 +16,10 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/filter"
+	"github.com/gosom/google-maps-scraper/gmaps"
 	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/seeds"
 	"github.com/gosom/scrapemate"
 	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
 	"github.com/gosom/scrapemate/scrapemateapp"
@@ -78,18 +81,17 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 
 	exitMonitor := exiter.New()
 
-	seedJobs, err = runner.CreateSeedJobs(
-		false, // TODO supoort fast mode
-		input.Language,
-		in,
-		input.Depth,
-		false,
-		"",
-		0,
-		10000, // TODO support radius
-		nil,
-		exitMonitor,
-	)
+	seedJobs, err = seeds.New(in, &seeds.Options{
+		// TODO support fast mode and radius
+		LangCode:         input.Language,
+		MaxDepth:         input.Depth,
+		Localize:         input.Localize,
+		ResultsLang:      input.ResultsLang,
+		ResultsEnglish:   input.ResultsEnglish,
+		Country:          input.Country,
+		ExcludeSponsored: input.ExcludeSponsored,
+		ExitMonitor:      exitMonitor,
+	})
 	if err != nil {
 		return err
 	}
@@ -133,10 +135,24 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 func (l *lambdaAwsRunner) getApp(_ context.Context, input lInput, out io.Writer) (*scrapemateapp.ScrapemateApp, error) {
 	csvWriter := csvwriter.NewCsvWriter(csv.NewWriter(out))
 
-	writers := []scrapemate.ResultWriter{csvWriter}
+	writers, err := runner.FilterWriters(&filter.Options{
+		Expr:              input.Filter,
+		IncludeCategories: input.IncludeCategories,
+		ExcludeCategories: input.ExcludeCategories,
+		Quality: filter.Quality{
+			RequirePhone:   input.RequirePhone,
+			RequireWebsite: input.RequireWebsite,
+			MinRating:      input.MinRating,
+			MinReviews:     input.MinReviews,
+		},
+	}, []scrapemate.ResultWriter{csvWriter})
+	if err != nil {
+		return nil, err
+	}
 
 	opts := []func(*scrapemateapp.Config) error{
 		scrapemateapp.WithConcurrency(max(1, input.Concurrency)),
+		scrapemateapp.WithProvider(gmaps.NewRetryProvider(nil)),
 		scrapemateapp.WithExitOnInactivity(time.Minute),
 		scrapemateapp.WithJS(
 			scrapemateapp.DisableImages(),
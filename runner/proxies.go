@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const proxyDialTimeout = 5 * time.Second
+
+// ProxiesHealth are the details of the proxies health check.
+type ProxiesHealth struct {
+	Total       int      `json:"total"`
+	Reachable   int      `json:"reachable"`
+	Unreachable []string `json:"unreachable,omitempty"`
+}
+
+// CheckProxies connects to the proxies. It fails when none of them can be
+// reached.
+func CheckProxies(ctx context.Context, proxies []string) (ProxiesHealth, error) {
+	ans := ProxiesHealth{Total: len(proxies)}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, proxy := range proxies {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := dialProxy(ctx, proxy)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				ans.Unreachable = append(ans.Unreachable, proxyHost(proxy))
+
+				return
+			}
+
+			ans.Reachable++
+		}()
+	}
+
+	wg.Wait()
+
+	if ans.Reachable == 0 {
+		return ans, errors.New("no proxy is reachable")
+	}
+
+	return ans, nil
+}
+
+func dialProxy(ctx context.Context, proxy string) error {
+	dialer := net.Dialer{Timeout: proxyDialTimeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", proxyHost(proxy))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// proxyHost returns the host:port of a proxy url, without the credentials.
+func proxyHost(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err == nil && u.Host != "" {
+		return u.Host
+	}
+
+	return proxy[strings.LastIndex(proxy, "@")+1:]
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// SchemaFile is the metadata written next to a results file.
+type SchemaFile struct {
+	SchemaVersion int           `json:"schema_version"`
+	Format        string        `json:"format"`
+	Fields        []gmaps.Field `json:"fields"`
+}
+
+// WriteSchemaFile writes the schema of the results file to
+// <resultsFile>.schema.json, so that the consumers of the file can
+// detect the changes of its fields.
+func WriteSchemaFile(resultsFile, format string) error {
+	data, err := json.MarshalIndent(SchemaFile{
+		SchemaVersion: gmaps.SchemaVersion,
+		Format:        format,
+		Fields:        gmaps.Schema(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(resultsFile+".schema.json", append(data, '\n'), 0o600)
+}
+
+// SchemaCommand prints the fields of the results with their types and the
+// changelog of the schema.
+func SchemaCommand(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the schema as json")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(struct {
+			SchemaFile
+			Changelog []gmaps.SchemaChange `json:"changelog"`
+		}{
+			SchemaFile: SchemaFile{SchemaVersion: gmaps.SchemaVersion, Fields: gmaps.Schema()},
+			Changelog:  gmaps.SchemaChangelog,
+		})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "schema version %d\n\n", gmaps.SchemaVersion)
+	fmt.Fprintln(tw, "CSV\tJSON\tTYPE")
+
+	for _, f := range gmaps.Schema() {
+		csv := f.CSV
+		if csv == "" {
+			csv = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", csv, f.JSON, f.Type)
+	}
+
+	fmt.Fprintln(tw, "\nchangelog")
+
+	for _, c := range gmaps.SchemaChangelog {
+		for _, change := range c.Changes {
+			if c.Breaking {
+				change += " (breaking)"
+			}
+
+			fmt.Fprintf(tw, "  %d\t%s\n", c.Version, change)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// Tables returns the secondary outputs enabled in the configuration.
+func (c *Config) Tables() []gmaps.Table {
+	var tables []gmaps.Table
+
+	if c.PopularTimesNormalized {
+		tables = append(tables, gmaps.PopularTimesTable)
+	}
+
+	if c.ReviewsNormalized {
+		tables = append(tables, gmaps.ReviewsTable)
+	}
+
+	return tables
+}
+
+// TableFile returns the path of the csv file of the table next to the
+// results file, e.g. results.popular_times.csv for results.csv.
+func TableFile(resultsFile, table string) string {
+	ext := filepath.Ext(resultsFile)
+
+	return strings.TrimSuffix(resultsFile, ext) + "." + table + ".csv"
+}
+
+// NewTableWriter returns a writer of the rows of the table as csv to w.
+func NewTableWriter(w io.Writer, table gmaps.Table) scrapemate.ResultWriter {
+	return &tableWriter{w: csv.NewWriter(w), table: table}
+}
+
+type tableWriter struct {
+	w     *csv.Writer
+	table gmaps.Table
+}
+
+func (t *tableWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	if err := t.w.Write(t.table.Headers); err != nil {
+		return err
+	}
+
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch v := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = []*gmaps.Entry{v}
+		case []*gmaps.Entry:
+			entries = v
+		}
+
+		for _, entry := range entries {
+			if err := t.w.WriteAll(t.table.Records(entry)); err != nil {
+				return err
+			}
+		}
+	}
+
+	t.w.Flush()
+
+	return t.w.Error()
+}
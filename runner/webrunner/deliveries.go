@@ -0,0 +1,177 @@
+package webrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+var _ scrapemate.ResultWriter = (*deliveryWriter)(nil)
+
+// deliveryWindow is the number of places the external writer consumed that
+// are kept until newer ones arrive, since they may still wait in a batch of
+// the writer. It is the largest default batch of the writers.
+const deliveryWindow = 500
+
+var errWriterStopped = errors.New("the writer stopped before the last place")
+
+// deliveryWriter passes the places to an external writer. When the writer
+// fails, the places it had not delivered are stored as failed deliveries
+// that can be replayed, and the job does not fail because of the
+// destination.
+type deliveryWriter struct {
+	jobID       string
+	destination string
+	w           scrapemate.ResultWriter
+	svc         *web.Service
+}
+
+func (d *deliveryWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	// out is not buffered, a result sent was consumed by the writer
+	out := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- d.w.Run(ctx, out)
+	}()
+
+	var (
+		// window are the last places the writer consumed, they may not be
+		// delivered yet
+		window []*gmaps.Entry
+		failed []*gmaps.Entry
+		exited bool
+		err    error
+		kept   int
+	)
+
+	record := func() error {
+		if recordErr := d.svc.RecordDeliveryFailure(context.WithoutCancel(ctx), d.jobID, d.destination, failed, err); recordErr != nil {
+			log.Printf("could not keep the failed delivery of job %s to %s: %v", d.jobID, d.destination, recordErr)
+
+			return err
+		}
+
+		kept += len(failed)
+		failed = nil
+
+		return nil
+	}
+
+	for result := range in {
+		entries := resultEntries(result)
+
+		if !exited {
+			select {
+			case out <- result:
+				window = append(window, entries...)
+				if len(window) > 2*deliveryWindow {
+					window = append(window[:0], window[len(window)-deliveryWindow:]...)
+				}
+
+				continue
+			case err = <-done:
+				if err == nil {
+					err = errWriterStopped
+				}
+
+				exited = true
+				failed = window
+			}
+		}
+
+		// the places that arrive after the writer exited are stored in
+		// chunks, so that they are not all kept in memory
+		failed = append(failed, entries...)
+		if len(failed) >= deliveryWindow {
+			if recordErr := record(); recordErr != nil {
+				return recordErr
+			}
+		}
+	}
+
+	close(out)
+
+	if !exited {
+		err = <-done
+		failed = window
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	if len(failed) > 0 {
+		if recordErr := record(); recordErr != nil {
+			return recordErr
+		}
+	}
+
+	if kept > 0 {
+		log.Printf("delivery of job %s to %s failed, %d places kept for a replay: %v", d.jobID, d.destination, kept, err)
+	}
+
+	return nil
+}
+
+func resultEntries(result scrapemate.Result) []*gmaps.Entry {
+	switch v := result.Data.(type) {
+	case *gmaps.Entry:
+		return []*gmaps.Entry{v}
+	case []*gmaps.Entry:
+		return v
+	}
+
+	return nil
+}
+
+// deliveryWriters wraps the external writers of the job with their queues
+// and keeps their failed deliveries. The queue is in front of the delivery
+// writer, so that it knows which places the external writer consumed.
+func (w *webrunner) deliveryWriters(jobID string, writers []scrapemate.ResultWriter) []scrapemate.ResultWriter {
+	ans := make([]scrapemate.ResultWriter, 0, len(writers))
+
+	for i := range writers {
+		name := runner.WriterName(writers[i])
+
+		ans = append(ans, w.queues.Wrap(name, w.cfg.WriterQueueSize, &deliveryWriter{
+			jobID:       jobID,
+			destination: name,
+			w:           writers[i],
+			svc:         w.svc,
+		}))
+	}
+
+	return ans
+}
+
+// replayDelivery delivers the places of a failed delivery with a new writer
+// of its destination.
+func (w *webrunner) replayDelivery(ctx context.Context, failure *web.DeliveryFailure, entries []*gmaps.Entry) error {
+	writers, err := runner.ExternalWriters(w.cfg, failure.JobID)
+	if err != nil {
+		return err
+	}
+
+	for _, ew := range writers {
+		if runner.WriterName(ew) != failure.Destination {
+			continue
+		}
+
+		in := make(chan scrapemate.Result, 1)
+		in <- scrapemate.Result{Data: entries}
+
+		close(in)
+
+		return ew.Run(ctx, in)
+	}
+
+	return fmt.Errorf("the destination %s is not configured", failure.Destination)
+}
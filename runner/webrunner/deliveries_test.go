@@ -0,0 +1,100 @@
+package webrunner_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner/webrunner"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+type fakeDeliveries struct {
+	failures []web.DeliveryFailure
+}
+
+func (f *fakeDeliveries) CreateDeliveryFailure(_ context.Context, failure *web.DeliveryFailure) error {
+	f.failures = append(f.failures, *failure)
+
+	return nil
+}
+
+func (f *fakeDeliveries) GetDeliveryFailure(context.Context, string) (web.DeliveryFailure, error) {
+	return web.DeliveryFailure{}, sql.ErrNoRows
+}
+
+func (f *fakeDeliveries) SelectDeliveryFailures(context.Context, string) ([]web.DeliveryFailure, error) {
+	return f.failures, nil
+}
+
+func (f *fakeDeliveries) UpdateDeliveryFailure(context.Context, *web.DeliveryFailure) error {
+	return nil
+}
+
+// failingWriter consumes limit results and fails, or consumes all of them
+// when limit is negative.
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	consumed := 0
+
+	for range in {
+		consumed++
+
+		if consumed == w.limit {
+			return errors.New("connection refused")
+		}
+	}
+
+	return nil
+}
+
+func Test_DeliveryWriter(t *testing.T) {
+	const (
+		total    = 3000
+		consumed = 1200
+	)
+
+	run := func(limit int) []web.DeliveryFailure {
+		deliveries := &fakeDeliveries{}
+		svc := web.NewService(nil, t.TempDir(), web.WithDeliveries(deliveries))
+
+		in := make(chan scrapemate.Result)
+
+		go func() {
+			defer close(in)
+
+			for range total {
+				in <- scrapemate.Result{Data: &gmaps.Entry{Title: "a"}}
+			}
+		}()
+
+		w := webrunner.NewDeliveryWriter("job", "kafka", &failingWriter{limit: limit}, svc)
+		require.NoError(t, w.Run(context.Background(), in))
+
+		return deliveries.failures
+	}
+
+	require.Empty(t, run(-1))
+
+	// the places the writer did not consume are kept, with the last ones it
+	// consumed, and not the places of the whole job
+	kept := 0
+
+	for _, f := range run(consumed) {
+		require.Equal(t, "kafka", f.Destination)
+		require.LessOrEqual(t, f.Entries, 2*webrunner.DeliveryWindow)
+
+		kept += f.Entries
+	}
+
+	require.GreaterOrEqual(t, kept, total-consumed+webrunner.DeliveryWindow)
+	require.LessOrEqual(t, kept, total-consumed+2*webrunner.DeliveryWindow)
+}
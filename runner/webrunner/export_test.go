@@ -0,0 +1,15 @@
+package webrunner
+
+import (
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+var CreateSeedJobs = createSeedJobs
+
+const DeliveryWindow = deliveryWindow
+
+func NewDeliveryWriter(jobID, destination string, w scrapemate.ResultWriter, svc *web.Service) scrapemate.ResultWriter {
+	return &deliveryWriter{jobID: jobID, destination: destination, w: w, svc: svc}
+}
@@ -0,0 +1,122 @@
+package webrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/runner/installplaywright"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+// browserCheckInterval is how long the result of the browser check is
+// reused, launching a browser on every probe is too expensive.
+const browserCheckInterval = time.Minute
+
+type bucketChecker interface {
+	HeadBucket(ctx context.Context, bucketName string) error
+}
+
+// healthChecks returns the checks of the dependencies of the runner.
+func (w *webrunner) healthChecks() []web.HealthCheck {
+	checks := []web.HealthCheck{
+		{Name: "browser", Probe: web.ProbeReadiness, Check: w.browsers.check},
+		{
+			Name:  "writers",
+			Probe: web.ProbeReadiness,
+			Check: func(context.Context) (any, error) {
+				return w.queues.Stats(), nil
+			},
+		},
+	}
+
+	if store, ok := w.cfg.S3Uploader.(bucketChecker); ok && w.cfg.S3Bucket != "" {
+		checks = append(checks, web.HealthCheck{
+			Name:  "s3",
+			Probe: web.ProbeReadiness,
+			Check: func(ctx context.Context) (any, error) {
+				return nil, store.HeadBucket(ctx, w.cfg.S3Bucket)
+			},
+		})
+	}
+
+	if len(w.cfg.Proxies) > 0 {
+		checks = append(checks, web.HealthCheck{
+			Name:  "proxies",
+			Probe: web.ProbeReadiness,
+			Check: func(ctx context.Context) (any, error) {
+				return runner.CheckProxies(ctx, w.cfg.Proxies)
+			},
+		})
+	}
+
+	return checks
+}
+
+// browserCheck installs the browsers on startup and checks that they can
+// be launched.
+type browserCheck struct {
+	installed  chan struct{}
+	installErr error
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+func newBrowserCheck() *browserCheck {
+	return &browserCheck{installed: make(chan struct{})}
+}
+
+// install downloads the browsers when they are missing. Offline they were
+// verified when the runner was created.
+func (b *browserCheck) install(offline bool) {
+	defer close(b.installed)
+
+	if offline {
+		return
+	}
+
+	b.installErr = installplaywright.Install()
+	if b.installErr != nil {
+		log.Printf("could not install the browsers: %v", b.installErr)
+	}
+}
+
+// wait blocks until the installation of the browsers ends or ctx is done.
+func (b *browserCheck) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.installed:
+		return nil
+	}
+}
+
+// check launches a browser and opens a context in it.
+func (b *browserCheck) check(context.Context) (any, error) {
+	select {
+	case <-b.installed:
+		if b.installErr != nil {
+			return nil, fmt.Errorf("could not install the browsers: %w", b.installErr)
+		}
+	default:
+		return nil, errors.New("the browsers are being installed")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if time.Since(b.checkedAt) < browserCheckInterval {
+		return nil, b.err
+	}
+
+	b.err = installplaywright.Verify()
+	b.checkedAt = time.Now()
+
+	return nil, b.err
+}
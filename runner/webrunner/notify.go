@@ -0,0 +1,83 @@
+package webrunner
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/notify"
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+const notifyTimeout = time.Minute
+
+// notifier returns the configured notifiers together with extra, or nil
+// when there is none.
+func notifier(cfg *runner.Config, extra ...notify.Notifier) (notify.Notifier, error) {
+	notifiers := extra
+
+	switch {
+	case cfg.SMTPURL != "":
+		sender, err := notify.NewSMTPSender(cfg.SMTPURL, cfg.NotifyFrom)
+		if err != nil {
+			return nil, err
+		}
+
+		notifiers = append(notifiers, notify.NewEmail(sender))
+	case cfg.SendGridAPIKey != "":
+		sender, err := notify.NewSendGridSender(cfg.SendGridAPIKey, cfg.NotifyFrom)
+		if err != nil {
+			return nil, err
+		}
+
+		notifiers = append(notifiers, notify.NewEmail(sender))
+	}
+
+	if cfg.SlackWebhook != "" {
+		n, err := notify.NewSlack(cfg.SlackWebhook, cfg.ChatSummary)
+		if err != nil {
+			return nil, err
+		}
+
+		notifiers = append(notifiers, n)
+	}
+
+	if cfg.DiscordWebhook != "" {
+		n, err := notify.NewDiscord(cfg.DiscordWebhook, cfg.ChatSummary)
+		if err != nil {
+			return nil, err
+		}
+
+		notifiers = append(notifiers, n)
+	}
+
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+
+	return notify.Multi(notifiers...), nil
+}
+
+// notify sends the status of a job to the notifiers. A failure is only
+// logged, it does not affect the job.
+func (w *webrunner) notify(ctx context.Context, job *web.Job) {
+	if w.notifier == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+	defer cancel()
+
+	evt := notify.Event{Job: *job}
+
+	if job.Status == web.StatusOK && w.cfg.PublicURL != "" {
+		evt.DownloadURL = strings.TrimSuffix(w.cfg.PublicURL, "/") + "/download?id=" + url.QueryEscape(job.ID)
+	}
+
+	if err := w.notifier.Notify(ctx, evt); err != nil {
+		log.Printf("failed to notify about job %s: %v", job.ID, err)
+	}
+}
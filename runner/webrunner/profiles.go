@@ -0,0 +1,94 @@
+package webrunner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/playwright-community/playwright-go"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/s3uploader"
+)
+
+type objectStore interface {
+	Upload(ctx context.Context, bucketName, key string, body io.Reader) error
+	Download(ctx context.Context, bucketName, key string) (io.ReadCloser, error)
+}
+
+// profileStore keeps the browser sessions of the profiles of the jobs in
+// the profiles folder of the data folder, or in the S3 bucket so that the
+// instances share them.
+type profileStore struct {
+	dir    string
+	store  objectStore
+	bucket string
+}
+
+func newProfileStore(dataFolder string, store any, bucket string) *profileStore {
+	ans := profileStore{dir: filepath.Join(dataFolder, "profiles")}
+
+	if s, ok := store.(objectStore); ok && bucket != "" {
+		ans.store = s
+		ans.bucket = bucket
+	}
+
+	return &ans
+}
+
+func (p *profileStore) key(name string) string {
+	return "profiles/" + name + ".json"
+}
+
+// load returns the session of the profile, empty for a new profile.
+func (p *profileStore) load(ctx context.Context, name string) (*gmaps.Session, error) {
+	var (
+		body io.ReadCloser
+		err  error
+	)
+
+	if p.store != nil {
+		body, err = p.store.Download(ctx, p.bucket, p.key(name))
+	} else {
+		body, err = os.Open(filepath.Join(p.dir, name+".json"))
+	}
+
+	switch {
+	case errors.Is(err, s3uploader.ErrNotFound), errors.Is(err, os.ErrNotExist):
+		return gmaps.NewSession(nil), nil
+	case err != nil:
+		return nil, err
+	}
+
+	defer body.Close()
+
+	var cookies []playwright.Cookie
+	if err := json.NewDecoder(body).Decode(&cookies); err != nil {
+		return nil, err
+	}
+
+	return gmaps.NewSession(cookies), nil
+}
+
+// save stores the session of the profile.
+func (p *profileStore) save(ctx context.Context, name string, session *gmaps.Session) error {
+	data, err := json.Marshal(session.Cookies())
+	if err != nil {
+		return err
+	}
+
+	if p.store != nil {
+		return p.store.Upload(ctx, p.bucket, p.key(name), bytes.NewReader(data))
+	}
+
+	if err := os.MkdirAll(p.dir, 0o700); err != nil {
+		return err
+	}
+
+	// the cookies are credentials of the browser
+	return os.WriteFile(filepath.Join(p.dir, name+".json"), data, 0o600)
+}
@@ -0,0 +1,89 @@
+package webrunner
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// slots limits the number of jobs that run at the same time. The limit
+// can change while jobs run, e.g. with -autotune.
+type slots struct {
+	limit func() int
+
+	mu      sync.Mutex
+	running map[string]struct{}
+	wg      sync.WaitGroup
+}
+
+func newSlots(limit func() int) *slots {
+	return &slots{limit: limit, running: map[string]struct{}{}}
+}
+
+// acquire waits for a free slot for the job. It returns false when ctx is
+// done or the job already runs.
+func (s *slots) acquire(ctx context.Context, jobID string) bool {
+	for {
+		acquired, running := s.tryAcquire(jobID)
+
+		switch {
+		case running:
+			return false
+		case acquired:
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// tryAcquire takes a slot for the job when one is free. running is true
+// when the job has a slot already.
+func (s *slots) tryAcquire(jobID string) (acquired, running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.running[jobID]; ok {
+		return false, true
+	}
+
+	if len(s.running) >= s.limit() {
+		return false, false
+	}
+
+	s.running[jobID] = struct{}{}
+	s.wg.Add(1)
+
+	return true, false
+}
+
+// release frees the slot of the job. idle is called when no other job
+// runs, before a job can take a slot again.
+func (s *slots) release(jobID string, idle func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.running, jobID)
+	s.wg.Done()
+
+	if len(s.running) == 0 {
+		idle()
+	}
+}
+
+// count returns the number of running jobs.
+func (s *slots) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.running)
+}
+
+// wait waits for the running jobs.
+func (s *slots) wait() {
+	s.wg.Wait()
+}
@@ -0,0 +1,96 @@
+package webrunner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+var _ scrapemate.ResultWriter = (*statsWriter)(nil)
+
+// statsWriter computes the statistics of a job from its results.
+type statsWriter struct {
+	mu        sync.Mutex
+	stats     web.JobStats
+	seen      map[string]bool
+	rated     int
+	ratingSum float64
+	// filter drops the places before they reach the writers, this one
+	// included, when the job has filters.
+	filter *scraper.HookWriter
+}
+
+func newStatsWriter() *statsWriter {
+	return &statsWriter{
+		stats: web.JobStats{KeywordResults: map[string]int{}},
+		seen:  map[string]bool{},
+	}
+}
+
+func (s *statsWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		switch v := result.Data.(type) {
+		case *gmaps.Entry:
+			s.add(v)
+		case []*gmaps.Entry:
+			for i := range v {
+				s.add(v[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *statsWriter) add(entry *gmaps.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.PlacesFound++
+	s.stats.EmailsFound += len(entry.Emails)
+	s.stats.ReviewsFetched += len(entry.UserReviews)
+	s.stats.ImagesFetched += len(entry.Images)
+
+	if entry.InputKeyword != "" {
+		s.stats.KeywordResults[entry.InputKeyword]++
+	}
+
+	key := entry.Cid
+	if key == "" {
+		key = entry.Link
+	}
+
+	if !s.seen[key] {
+		s.seen[key] = true
+		s.stats.UniquePlaces++
+	}
+
+	if entry.ReviewCount > 0 {
+		s.rated++
+		s.ratingSum += entry.ReviewRating
+	}
+}
+
+func (s *statsWriter) result(duration time.Duration) *web.JobStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ans := s.stats
+	ans.DurationSeconds = duration.Round(time.Second).Seconds()
+
+	if s.filter != nil {
+		ans.FilteredOut = s.filter.Skipped()
+	}
+
+	if s.rated > 0 {
+		ans.AverageRating = s.ratingSum / float64(s.rated)
+	}
+
+	return &ans
+}
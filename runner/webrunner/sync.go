@@ -0,0 +1,139 @@
+package webrunner
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/scrapemateapp"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner/installplaywright"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+var _ scrapemate.ResultWriter = (*entriesWriter)(nil)
+
+// entriesWriter keeps the places of a synchronous scrape.
+type entriesWriter struct {
+	mu      sync.Mutex
+	entries []*gmaps.Entry
+}
+
+func (e *entriesWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		switch v := result.Data.(type) {
+		case *gmaps.Entry:
+			e.add(v)
+		case []*gmaps.Entry:
+			for i := range v {
+				e.add(v[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *entriesWriter) add(entry *gmaps.Entry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.entries = append(e.entries, entry)
+}
+
+// scrapeSync scrapes the keywords or the places of data until ctx is done
+// and returns the places found, without a job. It takes a slot like a job,
+// so it waits for the running jobs when there is none free.
+func (w *webrunner) scrapeSync(ctx context.Context, data *web.JobData) ([]*gmaps.Entry, error) {
+	if err := w.browsers.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	id := "sync-" + uuid.New().String()
+
+	if !w.slots.acquire(ctx, id) {
+		return nil, errors.New("no free slot to scrape, try again later")
+	}
+
+	defer w.slots.release(id, func() {
+		if _, err := installplaywright.CleanTempDir(w.cfg.BrowserDataDir); err != nil {
+			log.Printf("could not clean the browser data of %s: %v", id, err)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	exitMonitor := exiter.New()
+
+	seedJobs, err := createSeedJobs(data, w.cfg, deduper.New(), exitMonitor)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &entriesWriter{}
+
+	inactivity := time.Minute
+	if deadline, ok := ctx.Deadline(); ok {
+		inactivity = time.Until(deadline)
+	}
+
+	opts := []func(*scrapemateapp.Config) error{
+		scrapemateapp.WithConcurrency(min(w.concurrency(), max(len(seedJobs), 2))),
+		scrapemateapp.WithProvider(gmaps.NewRetryProvider(nil)),
+		scrapemateapp.WithExitOnInactivity(inactivity),
+	}
+
+	if data.FastMode {
+		opts = append(opts, scrapemateapp.WithStealth("firefox"))
+	} else {
+		opts = append(opts, scrapemateapp.WithJS(scrapemateapp.DisableImages()))
+	}
+
+	if len(w.cfg.Proxies) > 0 {
+		opts = append(opts, scrapemateapp.WithProxies(w.cfg.Proxies))
+	}
+
+	matecfg, err := scrapemateapp.NewConfig([]scrapemate.ResultWriter{writer}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	mate, err := scrapemateapp.NewScrapeMateApp(matecfg)
+	if err != nil {
+		return nil, err
+	}
+
+	defer mate.Close()
+
+	if len(data.Places) > 0 {
+		// the place jobs are the seeds
+		exitMonitor.IncrPlacesFound(len(seedJobs))
+	} else {
+		exitMonitor.SetSeedCount(len(seedJobs))
+	}
+
+	exitMonitor.SetMaxPlaces(data.MaxResults)
+	exitMonitor.SetCancelFunc(cancel)
+
+	go exitMonitor.Run(ctx)
+
+	err = mate.Start(ctx, seedJobs...)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return nil, err
+	}
+
+	mate.Close()
+
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	return writer.entries, nil
+}
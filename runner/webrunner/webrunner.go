@@ -7,16 +7,31 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/gosom/google-maps-scraper/autotune"
 	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/dryrun"
+	"github.com/gosom/google-maps-scraper/eta"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/notify"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+	"github.com/gosom/google-maps-scraper/queue"
+	"github.com/gosom/google-maps-scraper/queue/redisqueue"
+	"github.com/gosom/google-maps-scraper/resource"
 	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/runner/installplaywright"
+	"github.com/gosom/google-maps-scraper/secrets"
+	"github.com/gosom/google-maps-scraper/seeds"
+	"github.com/gosom/google-maps-scraper/telegram"
 	"github.com/gosom/google-maps-scraper/tlmt"
 	"github.com/gosom/google-maps-scraper/web"
+	"github.com/gosom/google-maps-scraper/web/middleware"
 	"github.com/gosom/google-maps-scraper/web/sqlite"
 	"github.com/gosom/scrapemate"
 	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
@@ -25,9 +40,19 @@ import (
 )
 
 type webrunner struct {
-	srv *web.Server
-	svc *web.Service
-	cfg *runner.Config
+	srv      *web.Server
+	svc      *web.Service
+	cfg      *runner.Config
+	queue    queue.Provider
+	notifier notify.Notifier
+	bot      *telegram.Bot
+	browsers *browserCheck
+	tuner    *autotune.Controller
+	slots    *slots
+	profiles *profileStore
+	queues   *scraper.Queues
+	// limits keeps the rate limit buckets, closed with the runner
+	limits middleware.Store
 }
 
 func New(cfg *runner.Config) (runner.Runner, error) {
@@ -43,46 +68,278 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 
 	dbpath := filepath.Join(cfg.DataFolder, dbfname)
 
-	repo, err := sqlite.New(dbpath)
+	var repoOpts []sqlite.Option
+
+	if cfg.EncryptionKeys != "" {
+		keyring, err := secrets.ParseKeyring(cfg.EncryptionKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		repoOpts = append(repoOpts, sqlite.WithKeyring(keyring))
+	}
+
+	repo, err := sqlite.New(dbpath, repoOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	svc := web.NewService(repo, cfg.DataFolder)
+	var svcOpts []web.ServiceOption
 
-	srv, err := web.New(svc, cfg.Addr)
-	if err != nil {
+	if cfg.BrowserDataDir == "" {
+		cfg.BrowserDataDir = filepath.Join(cfg.DataFolder, "browser")
+	}
+
+	if err := installplaywright.SetupTempDir(cfg.BrowserDataDir); err != nil {
 		return nil, err
 	}
 
+	// offline the browsers must be installed already
+	if cfg.BrowsersOffline {
+		if err := installplaywright.Ensure(true); err != nil {
+			return nil, err
+		}
+	}
+
 	ans := webrunner{
-		srv: srv,
-		svc: svc,
-		cfg: cfg,
+		cfg:      cfg,
+		browsers: newBrowserCheck(),
+		queues:   &scraper.Queues{},
+	}
+
+	ans.profiles = newProfileStore(cfg.DataFolder, cfg.S3Uploader, cfg.S3Bucket)
+
+	// without -autotune the jobs run one at a time
+	ans.slots = newSlots(func() int { return 1 })
+
+	if cfg.Autotune {
+		ans.tuner = autotune.New(cfg.AutotuneJobs, cfg.AutotuneConcurrency)
+		ans.slots = newSlots(ans.tuner.Jobs)
+	}
+
+	if cfg.RedisURL != "" {
+		ans.queue, err = redisqueue.New(cfg.RedisURL, redisqueue.WithMaxDeliveries(int64(cfg.MaxAttempts)))
+		if err != nil {
+			return nil, err
+		}
+
+		svcOpts = append(svcOpts, web.WithQueue(ans.queue))
+	}
+
+	if store, ok := cfg.S3Uploader.(web.FileStore); ok && cfg.S3Bucket != "" {
+		svcOpts = append(svcOpts, web.WithFileStore(store, cfg.S3Bucket))
+	}
+
+	svcOpts = append(svcOpts, web.WithCapacity(ans.slots.limit), web.WithStaleJobTimeout(cfg.StaleJobTimeout))
+
+	if deliveries, ok := repo.(web.DeliveryRepository); ok {
+		svcOpts = append(svcOpts, web.WithDeliveries(deliveries))
+	}
+
+	if shares, ok := repo.(web.ShareRepository); ok {
+		svcOpts = append(svcOpts, web.WithShares(shares))
+	}
+
+	ans.svc = web.NewService(repo, cfg.DataFolder, svcOpts...)
+
+	var notifiers []notify.Notifier
+
+	if cfg.TelegramToken != "" {
+		ans.bot, err = telegram.New(cfg.TelegramToken, cfg.TelegramChats, ans.svc)
+		if err != nil {
+			return nil, err
+		}
+
+		notifiers = append(notifiers, ans.bot)
+	}
+
+	ans.notifier, err = notifier(cfg, notifiers...)
+	if err != nil {
+		return nil, err
+	}
+
+	footerLinks, err := web.ParseFooterLinks(cfg.BrandFooterLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	branding := web.Branding{
+		Name:         cfg.BrandName,
+		LogoURL:      cfg.BrandLogoURL,
+		PrimaryColor: cfg.BrandColor,
+		FooterLinks:  footerLinks,
+	}
+
+	if err := branding.Validate(); err != nil {
+		return nil, err
+	}
+
+	srvOpts := []web.ServerOption{
+		web.WithAdminToken(cfg.AdminToken),
+		web.WithPlanner(ans.plan),
+		web.WithSyncScraper(ans.scrapeSync),
+		web.WithReplayer(ans.replayDelivery),
+		web.WithHealthChecks(ans.healthChecks()...),
+		web.WithEmbedOrigins(cfg.EmbedOrigins),
+		web.WithBranding(branding),
+	}
+
+	if len(cfg.AllowedOrigins) > 0 {
+		var cors func(http.Handler) http.Handler
+
+		cors, err = middleware.CORS(cfg.AllowedOrigins)
+		if err != nil {
+			return nil, err
+		}
+
+		srvOpts = append(srvOpts, web.WithMiddleware(cors))
+	}
+
+	rateLimit, err := ans.rateLimit()
+	if err != nil {
+		return nil, err
+	}
+
+	if rateLimit != nil {
+		srvOpts = append(srvOpts, web.WithMiddleware(rateLimit))
+	}
+
+	ans.srv, err = web.New(ans.svc, cfg.Addr, srvOpts...)
+	if err != nil {
+		return nil, err
 	}
 
 	return &ans, nil
 }
 
+// rateLimit returns the rate limit middleware, or nil when no limit is set.
+// The buckets are kept in Redis when a redis url is configured.
+func (w *webrunner) rateLimit() (func(http.Handler) http.Handler, error) {
+	create, err := middleware.ParseLimit(w.cfg.RateLimitCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	read, err := middleware.ParseLimit(w.cfg.RateLimitRead)
+	if err != nil {
+		return nil, err
+	}
+
+	if create.Burst == 0 && read.Burst == 0 {
+		return nil, nil
+	}
+
+	trusted, err := middleware.ParseTrustedProxies(w.cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	w.limits = middleware.NewMemoryStore()
+
+	if w.cfg.RedisURL != "" {
+		w.limits, err = middleware.NewRedisStore(w.cfg.RedisURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return middleware.RateLimit(w.limits, create, read, trusted), nil
+}
+
 func (w *webrunner) Run(ctx context.Context) error {
+	// running jobs are not canceled with ctx, they get the drain
+	// timeout to finish when the runner shuts down.
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
 	egroup, ctx := errgroup.WithContext(ctx)
 
+	go w.browsers.install(w.cfg.BrowsersOffline)
+
 	egroup.Go(func() error {
-		return w.work(ctx)
+		defer cancelJobs()
+
+		// the jobs are taken once the browsers are installed, like
+		// the readiness of the server
+		if err := w.browsers.wait(ctx); err != nil {
+			return nil
+		}
+
+		return w.work(ctx, jobCtx)
 	})
 
 	egroup.Go(func() error {
 		return w.srv.Start(ctx)
 	})
 
+	egroup.Go(func() error {
+		<-ctx.Done()
+
+		w.drain(jobCtx, cancelJobs)
+
+		return nil
+	})
+
+	if w.cfg.StaleJobTimeout > 0 {
+		egroup.Go(func() error {
+			return w.reap(ctx)
+		})
+	}
+
+	if w.bot != nil {
+		egroup.Go(func() error {
+			return w.bot.Run(ctx)
+		})
+	}
+
 	return egroup.Wait()
 }
 
+// drain waits for the running job to finish within the drain timeout
+// and cancels it afterwards.
+func (w *webrunner) drain(jobCtx context.Context, cancelJobs context.CancelFunc) {
+	log.Printf("waiting up to %s for running jobs to finish", w.cfg.DrainTimeout)
+
+	timer := time.NewTimer(w.cfg.DrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-jobCtx.Done():
+	case <-timer.C:
+		log.Printf("drain timeout expired, interrupting running jobs")
+
+		cancelJobs()
+	}
+}
+
 func (w *webrunner) Close(context.Context) error {
+	if c, ok := w.limits.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Printf("failed to close the rate limit store: %v", err)
+		}
+	}
+
+	if w.queue != nil {
+		return w.queue.Close()
+	}
+
 	return nil
 }
 
-func (w *webrunner) work(ctx context.Context) error {
+// work runs the pending jobs until ctx is done. The jobs themselves
+// run with jobCtx.
+func (w *webrunner) work(ctx, jobCtx context.Context) error {
+	// the running jobs finish before the runner stops
+	defer w.slots.wait()
+
+	if w.tuner != nil {
+		go w.tuner.Run(ctx)
+	}
+
+	if w.queue != nil {
+		return w.consume(ctx, jobCtx)
+	}
+
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
@@ -97,48 +354,290 @@ func (w *webrunner) work(ctx context.Context) error {
 			}
 
 			for i := range jobs {
-				select {
-				case <-ctx.Done():
+				if !w.waitForMemory(ctx) || !w.waitForWriters(ctx) {
 					return nil
-				default:
-					t0 := time.Now().UTC()
-					if err := w.scrapeJob(ctx, &jobs[i]); err != nil {
-						params := map[string]any{
-							"job_count": len(jobs[i].Data.Keywords),
-							"duration":  time.Now().UTC().Sub(t0).String(),
-							"error":     err.Error(),
-						}
-
-						evt := tlmt.NewEvent("web_runner", params)
-
-						_ = runner.Telemetry().Send(ctx, evt)
-
-						log.Printf("error scraping job %s: %v", jobs[i].ID, err)
-					} else {
-						params := map[string]any{
-							"job_count": len(jobs[i].Data.Keywords),
-							"duration":  time.Now().UTC().Sub(t0).String(),
-						}
-
-						_ = runner.Telemetry().Send(ctx, tlmt.NewEvent("web_runner", params))
-
-						log.Printf("job %s scraped successfully", jobs[i].ID)
-					}
 				}
+
+				w.start(ctx, jobCtx, jobs[i], nil)
+			}
+		}
+	}
+}
+
+// consume runs the jobs dispatched through the queue. The pending jobs
+// of the repository are published on startup so that jobs created while
+// the queue was unavailable are not lost. Duplicates are skipped since
+// only pending jobs are scraped.
+func (w *webrunner) consume(ctx, jobCtx context.Context) error {
+	pending, err := w.svc.AllPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range pending {
+		if err := w.queue.Publish(ctx, pending[i].ID); err != nil {
+			return err
+		}
+	}
+
+	for {
+		msg, err := w.queue.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		job, err := w.svc.Get(ctx, msg.JobID)
+
+		// the message is acknowledged once the job ends
+		ack := func() {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err := w.queue.Ack(ctx, msg); err != nil {
+				log.Printf("failed to ack job %s: %v", msg.JobID, err)
+			}
+		}
+
+		switch {
+		case err != nil:
+			// the job may belong to another instance, the message is not
+			// acknowledged so that it is delivered again after the claim
+			// idle time, up to the max deliveries
+			log.Printf("failed to get job %s from queue: %v", msg.JobID, err)
+
+			continue
+		case job.Status == web.StatusPending && w.waitForMemory(ctx) && w.waitForWriters(ctx):
+			if w.start(ctx, jobCtx, job, ack) {
+				continue
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		ack()
+	}
+}
+
+// start runs the job in the background once a slot is free and calls done
+// when it ends. It returns false when ctx is done or the job already runs.
+func (w *webrunner) start(ctx, jobCtx context.Context, job web.Job, done func()) bool {
+	if !w.slots.acquire(ctx, job.ID) {
+		return false
+	}
+
+	go func() {
+		w.runJob(jobCtx, &job)
+
+		if done != nil {
+			done()
+		}
+	}()
+
+	return true
+}
+
+// concurrency returns the concurrency of a job that starts.
+func (w *webrunner) concurrency() int {
+	if w.tuner != nil {
+		return w.tuner.Concurrency()
+	}
+
+	return w.cfg.Concurrency
+}
+
+// waitForMemory waits until the memory used by the scraper and its browsers
+// is below -max-memory. It returns false when ctx is done.
+func (w *webrunner) waitForMemory(ctx context.Context) bool {
+	if w.cfg.MaxMemory == 0 {
+		return ctx.Err() == nil
+	}
+
+	var waiting bool
+
+	for {
+		usage, err := resource.Sample(ctx)
+		if err != nil || usage.MiB() <= w.cfg.MaxMemory {
+			return ctx.Err() == nil
+		}
+
+		if !waiting {
+			log.Printf("%d MiB used, above the limit of %d MiB, waiting to start the next job", usage.MiB(), w.cfg.MaxMemory)
+
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(resource.SampleInterval):
+		}
+	}
+}
+
+// waitForWriters waits until the queues of the writers of the running jobs
+// are below the high watermark, so that slow writers are not flooded by
+// more jobs. It returns false when ctx is done.
+func (w *webrunner) waitForWriters(ctx context.Context) bool {
+	const (
+		highWatermark = 0.8
+		interval      = time.Second
+	)
+
+	var waiting bool
+
+	for {
+		if w.queues.Full() < highWatermark {
+			return ctx.Err() == nil
+		}
+
+		if !waiting {
+			log.Printf("the queues of the writers are above %.0f%%, waiting to start the next job", highWatermark*100)
+
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (w *webrunner) runJob(ctx context.Context, job *web.Job) {
+	t0 := time.Now().UTC()
+
+	err := w.scrapeJob(ctx, job)
+
+	var browserDataSize int64
+
+	// the browsers of the job are closed, the profiles are removed when
+	// no other job uses them
+	w.slots.release(job.ID, func() {
+		var cleanErr error
+
+		browserDataSize, cleanErr = installplaywright.CleanTempDir(w.cfg.BrowserDataDir)
+		if cleanErr != nil {
+			log.Printf("could not clean the browser data of job %s: %v", job.ID, cleanErr)
+		}
+	})
+
+	if err != nil {
+		params := map[string]any{
+			"job_count":         len(job.Data.Keywords),
+			"duration":          time.Now().UTC().Sub(t0).String(),
+			"error":             err.Error(),
+			"browser_data_size": browserDataSize,
+		}
+
+		evt := tlmt.NewEvent("web_runner", params)
+
+		_ = runner.Telemetry().Send(ctx, evt)
+
+		log.Printf("error scraping job %s: %v", job.ID, err)
+	} else {
+		params := map[string]any{
+			"job_count":         len(job.Data.Keywords),
+			"duration":          time.Now().UTC().Sub(t0).String(),
+			"browser_data_size": browserDataSize,
+		}
+
+		_ = runner.Telemetry().Send(ctx, tlmt.NewEvent("web_runner", params))
+
+		log.Printf("job %s scraped successfully", job.ID)
+	}
+
+	if job.Status == web.StatusOK || job.Status == web.StatusFailed {
+		w.notify(ctx, job)
+	}
+}
+
+// reap recovers the jobs that stay in working because the process
+// that scraped them died. Jobs whose heartbeat is older than the stale
+// timeout are set back to pending, or to failed after MaxAttempts.
+func (w *webrunner) reap(ctx context.Context) error {
+	ticker := time.NewTicker(min(time.Minute, w.cfg.StaleJobTimeout/2))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		jobs, err := w.svc.SelectStale(ctx, time.Now().UTC().Add(-w.cfg.StaleJobTimeout))
+		if err != nil {
+			log.Printf("failed to select stale jobs: %v", err)
+
+			continue
+		}
+
+		for i := range jobs {
+			if jobs[i].Attempts >= w.cfg.MaxAttempts {
+				_, err = w.svc.ForceFail(ctx, jobs[i].ID)
+			} else {
+				_, err = w.svc.Requeue(ctx, jobs[i].ID)
+			}
+
+			if err != nil {
+				log.Printf("failed to recover stale job %s: %v", jobs[i].ID, err)
+
+				continue
+			}
+
+			log.Printf("recovered stale job %s after %d attempts", jobs[i].ID, jobs[i].Attempts)
+		}
+	}
+}
+
+// heartbeat keeps the heartbeat of the job fresh until ctx is done.
+func (w *webrunner) heartbeat(ctx context.Context, jobID string) {
+	ticker := time.NewTicker(max(time.Second, w.cfg.StaleJobTimeout/3))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.svc.Heartbeat(ctx, jobID); err != nil {
+				log.Printf("failed to update heartbeat of job %s: %v", jobID, err)
 			}
 		}
 	}
 }
 
 func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
+	started := time.Now()
+
 	job.Status = web.StatusWorking
+	job.Attempts++
+	job.HeartbeatAt = time.Now().UTC()
+	job.StartedAt = job.HeartbeatAt
 
 	err := w.svc.Update(ctx, job)
 	if err != nil {
 		return err
 	}
 
-	if len(job.Data.Keywords) == 0 {
+	w.notify(ctx, job)
+
+	if w.cfg.StaleJobTimeout > 0 {
+		hbCtx, hbCancel := context.WithCancel(ctx)
+		defer hbCancel()
+
+		go w.heartbeat(hbCtx, job.ID)
+	}
+
+	if len(job.Data.Keywords) == 0 && len(job.Data.Places) == 0 {
 		job.Status = web.StatusFailed
 
 		return w.svc.Update(ctx, job)
@@ -155,7 +654,27 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 		_ = outfile.Close()
 	}()
 
-	mate, err := w.setupMate(ctx, outfile, job)
+	var (
+		tables  []scrapemate.ResultWriter
+		peak    resource.Usage
+		limited bool
+		timing  *eta.Timing
+	)
+
+	for _, table := range job.Data.Tables() {
+		f, err := os.Create(filepath.Join(w.cfg.DataFolder, runner.TableFile(job.ID+".csv", table.Name)))
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		tables = append(tables, runner.NewTableWriter(f, table))
+	}
+
+	stats := newStatsWriter()
+
+	mate, err := w.setupMate(ctx, outfile, stats, job, tables...)
 	if err != nil {
 		job.Status = web.StatusFailed
 
@@ -169,32 +688,10 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 
 	defer mate.Close()
 
-	var coords string
-	if job.Data.Lat != "" && job.Data.Lon != "" {
-		coords = job.Data.Lat + "," + job.Data.Lon
-	}
-
 	dedup := deduper.New()
 	exitMonitor := exiter.New()
 
-	seedJobs, err := runner.CreateSeedJobs(
-		job.Data.FastMode,
-		job.Data.Lang,
-		strings.NewReader(strings.Join(job.Data.Keywords, "\n")),
-		job.Data.Depth,
-		job.Data.Email,
-		coords,
-		job.Data.Zoom,
-		func() float64 {
-			if job.Data.Radius <= 0 {
-				return 10000 // 10 km
-			}
-
-			return float64(job.Data.Radius)
-		}(),
-		dedup,
-		exitMonitor,
-	)
+	seedJobs, err := createSeedJobs(&job.Data, w.cfg, dedup, exitMonitor)
 	if err != nil {
 		err2 := w.svc.Update(ctx, job)
 		if err2 != nil {
@@ -204,18 +701,35 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 		return err
 	}
 
-	if len(seedJobs) > 0 {
-		exitMonitor.SetSeedCount(len(seedJobs))
+	var session *gmaps.Session
 
-		allowedSeconds := max(60, len(seedJobs)*10*job.Data.Depth/50+120)
+	if job.Data.Profile != "" && !job.Data.FastMode {
+		session, err = w.profiles.load(ctx, job.Data.Profile)
+		if err != nil {
+			log.Printf("failed to load profile %s of job %s: %v", job.Data.Profile, job.ID, err)
+		}
 
-		if job.Data.MaxTime > 0 {
-			if job.Data.MaxTime.Seconds() < 180 {
-				allowedSeconds = 180
-			} else {
-				allowedSeconds = int(job.Data.MaxTime.Seconds())
+		for _, seed := range seedJobs {
+			switch v := seed.(type) {
+			case *gmaps.GmapJob:
+				v.Session = session
+			case *gmaps.PlaceJob:
+				v.Session = session
 			}
 		}
+	}
+
+	if len(seedJobs) > 0 {
+		if len(job.Data.Places) > 0 {
+			// the place jobs are the seeds
+			exitMonitor.IncrPlacesFound(len(seedJobs))
+		} else {
+			exitMonitor.SetSeedCount(len(seedJobs))
+		}
+
+		exitMonitor.SetMaxPlaces(job.Data.MaxResults)
+
+		allowedSeconds := w.allowedSeconds(ctx, job, len(seedJobs))
 
 		log.Printf("running job %s with %d seed jobs and %d allowed seconds", job.ID, len(seedJobs), allowedSeconds)
 
@@ -226,6 +740,19 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 
 		go exitMonitor.Run(mateCtx)
 
+		// a job over its memory budget ends with the places found so far.
+		// The memory of the process can't be split between the jobs, so it
+		// is only measured while the job runs alone.
+		memMonitor := resource.NewMonitor(uint64(w.cfg.JobMemoryLimit)<<20, func() { //nolint:gosec // validated on startup
+			log.Printf("job %s exceeded the memory limit of %d MiB, closing its browsers", job.ID, w.cfg.JobMemoryLimit)
+
+			cancel()
+		}, resource.WithAlone(func() bool { return w.slots.count() == 1 }))
+
+		go memMonitor.Run(mateCtx)
+
+		mateStarted := time.Now()
+
 		err = mate.Start(mateCtx, seedJobs...)
 		if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
 			cancel()
@@ -239,18 +766,123 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 		}
 
 		cancel()
+
+		peak, limited = memMonitor.Peak()
+		timing = jobTiming(job, len(seedJobs), stats, exitMonitor, mateStarted, limited || errors.Is(mateCtx.Err(), context.DeadlineExceeded))
+
+		if w.tuner != nil {
+			retried, failed := exitMonitor.SeedRetries()
+			w.tuner.Observe(retried+failed, len(seedJobs)+retried)
+		}
+
+		log.Printf("job %s used at most %d MiB with %d browser processes", job.ID, peak.MiB(), peak.Browsers)
 	}
 
 	mate.Close()
 
+	if session != nil && session.Changed() {
+		if err := w.profiles.save(context.WithoutCancel(ctx), job.Data.Profile, session); err != nil {
+			log.Printf("failed to save profile %s of job %s: %v", job.Data.Profile, job.ID, err)
+		}
+	}
+
+	if ctx.Err() != nil {
+		// interrupted by shutdown, the job runs again on the next start
+		job.Status = web.StatusPending
+		job.Attempts--
+
+		if err := w.svc.Update(context.WithoutCancel(ctx), job); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("job %s interrupted by shutdown and set back to pending", job.ID)
+	}
+
+	_ = outfile.Close()
+
+	if err := w.svc.StoreCSV(ctx, job); err != nil {
+		log.Printf("failed to upload results of job %s: %v", job.ID, err)
+	}
+
 	job.Status = web.StatusOK
+	job.Stats = stats.result(time.Since(started))
+	job.Stats.SeedRetries, job.Stats.SeedsFailed = exitMonitor.SeedRetries()
+	job.Stats.PeakMemoryMB = peak.MiB()
+	job.Stats.PeakBrowsers = peak.Browsers
+	job.Stats.MemoryLimited = limited
+	job.Stats.Timing = timing
 
 	return w.svc.Update(ctx, job)
 }
 
-func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job) (*scrapemateapp.ScrapemateApp, error) {
+// allowedSeconds returns how long the job may run: its max time, or twice
+// its duration estimated from the last completed jobs, at least 3 minutes.
+// The jobs cut at their allowed time have no timing, so the estimate never
+// goes below the time the depth of the searches needs.
+func (w *webrunner) allowedSeconds(ctx context.Context, job *web.Job, seeds int) int {
+	const minAllowedSeconds = 180
+
+	if job.Data.MaxTime > 0 {
+		return max(minAllowedSeconds, int(job.Data.MaxTime.Seconds()))
+	}
+
+	m, err := w.svc.Model(ctx)
+	if err != nil {
+		log.Printf("failed to load the timings of the completed jobs: %v", err)
+
+		m = eta.New(nil)
+	}
+
+	var estimate time.Duration
+
+	if len(job.Data.Places) > 0 {
+		estimate = m.EstimateSeeds(0, 0, seeds, 0)
+	} else {
+		estimate = m.EstimateSeeds(float64(seeds), job.Data.Depth, 0, job.Data.MaxResults)
+	}
+
+	byDepth := seeds*10*job.Data.Depth/50 + 120
+
+	return max(minAllowedSeconds, byDepth, int(2*estimate.Seconds()))
+}
+
+// jobTiming returns the timing of the job, nil when it was cut short and
+// would skew the estimates of the next jobs.
+func jobTiming(job *web.Job, seeds int, stats *statsWriter, exitMonitor exiter.Exiter, started time.Time, cut bool) *eta.Timing {
+	searchDone := exitMonitor.SearchDoneAt()
+
+	if cut || (len(job.Data.Places) == 0 && searchDone.IsZero()) {
+		return nil
+	}
+
+	elapsed := time.Since(started)
+	s := stats.result(elapsed)
+	places := s.PlacesFound + s.FilteredOut
+
+	var search time.Duration
+
+	// the places of a refresh are its seeds, there is no search
+	if len(job.Data.Places) > 0 {
+		seeds = 0
+	} else {
+		search = searchDone.Sub(started)
+	}
+
+	ans := eta.NewTiming(len(job.Data.Keywords), seeds, job.Data.Depth, places, search, elapsed)
+
+	return &ans
+}
+
+func (w *webrunner) setupMate(
+	_ context.Context,
+	writer io.Writer,
+	stats *statsWriter,
+	job *web.Job,
+	tables ...scrapemate.ResultWriter,
+) (*scrapemateapp.ScrapemateApp, error) {
 	opts := []func(*scrapemateapp.Config) error{
-		scrapemateapp.WithConcurrency(w.cfg.Concurrency),
+		scrapemateapp.WithConcurrency(w.concurrency()),
+		scrapemateapp.WithProvider(gmaps.NewRetryProvider(nil)),
 		scrapemateapp.WithExitOnInactivity(time.Minute * 3),
 	}
 
@@ -285,9 +917,32 @@ func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job)
 
 	log.Printf("job %s has proxy: %v", job.ID, hasProxy)
 
-	csvWriter := csvwriter.NewCsvWriter(csv.NewWriter(writer))
+	lat, lon := runner.ParseCoordinates(job.Data.Lat + "," + job.Data.Lon)
+
+	// the results api reads the csv file, so it returns the sorted order
+	csvWriter := scraper.NewSortWriter(job.Data.OrderBy, lat, lon, csvwriter.NewCsvWriter(csv.NewWriter(writer)))
+
+	writers := append([]scrapemate.ResultWriter{csvWriter, stats}, tables...)
+
+	externalWriters, err := runner.ExternalWriters(w.cfg, job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	writers = append(writers, w.deliveryWriters(job.ID, externalWriters)...)
+
+	filterOpts := job.Data.FilterOptions()
+	filterOpts.GeocodeURL = w.cfg.GeocodeURL
 
-	writers := []scrapemate.ResultWriter{csvWriter}
+	filterFn, err := filterOpts.EntryFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	if filterFn != nil {
+		stats.filter = scraper.NewHookWriter(filterFn, nil, writers...)
+		writers = []scrapemate.ResultWriter{stats.filter}
+	}
 
 	matecfg, err := scrapemateapp.NewConfig(
 		writers,
@@ -299,3 +954,49 @@ func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job)
 
 	return scrapemateapp.NewScrapeMateApp(matecfg)
 }
+
+func (w *webrunner) plan(_ context.Context, data *web.JobData) (*dryrun.Plan, error) {
+	seedJobs, err := createSeedJobs(data, w.cfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return dryrun.New(seedJobs, dryrun.Params{
+		FastMode:    data.FastMode,
+		Depth:       data.Depth,
+		Email:       data.Email,
+		MaxResults:  data.MaxResults,
+		Concurrency: w.cfg.Concurrency,
+	}), nil
+}
+
+func createSeedJobs(data *web.JobData, cfg *runner.Config, dedup deduper.Deduper, exitMonitor exiter.Exiter) ([]scrapemate.IJob, error) {
+	if len(data.Places) > 0 {
+		return runner.CreatePlaceJobs(data.Lang, data.Places, data.Email, cfg.EmailSitemap(), exitMonitor)
+	}
+
+	var coords string
+	if data.Lat != "" && data.Lon != "" {
+		coords = data.Lat + "," + data.Lon
+	}
+
+	return seeds.New(strings.NewReader(strings.Join(data.Keywords, "\n")), &seeds.Options{
+		FastMode:         data.FastMode,
+		LangCode:         data.Lang,
+		MaxDepth:         data.Depth,
+		Email:            data.Email,
+		EmailSitemap:     cfg.EmailSitemap(),
+		GeoCoordinates:   coords,
+		Zoom:             data.Zoom,
+		Radius:           float64(data.Radius),
+		Tiles:            data.Tiles,
+		Localize:         data.Localize,
+		ResultsLang:      data.ResultsLang,
+		ResultsEnglish:   data.ResultsEnglish,
+		Country:          data.Country,
+		ExcludeSponsored: data.ExcludeSponsored,
+		SeedRetries:      cfg.SeedRetries,
+		Dedup:            dedup,
+		ExitMonitor:      exitMonitor,
+	})
+}
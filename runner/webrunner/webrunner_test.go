@@ -0,0 +1,80 @@
+package webrunner_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/runner/webrunner"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_CreateSeedJobsRadius(t *testing.T) {
+	// the values the web form posts when only the keywords are filled in
+	formDefaults := func() *web.JobData {
+		return &web.JobData{
+			Keywords: []string{"cafe in berlin", "cafe in munich"},
+			Lang:     "en",
+			Zoom:     15,
+			Lat:      "0",
+			Lon:      "0",
+			Radius:   10000,
+			Depth:    10,
+		}
+	}
+
+	centers := func(data *web.JobData) []gmaps.MapLocation {
+		jobs, err := webrunner.CreateSeedJobs(data, &runner.Config{}, nil, nil)
+		require.NoError(t, err)
+
+		ans := make([]gmaps.MapLocation, 0, len(jobs))
+
+		for _, job := range jobs {
+			gjob, ok := job.(*gmaps.GmapJob)
+			require.True(t, ok)
+
+			ans = append(ans, gjob.Center)
+		}
+
+		return ans
+	}
+
+	// 0,0 is no location, the places are not dropped
+	got := centers(formDefaults())
+	require.Len(t, got, 2)
+
+	for _, c := range got {
+		require.Zero(t, c.Radius)
+	}
+
+	// a location without a radius keeps the places far away
+	data := formDefaults()
+	data.Lat, data.Lon, data.Radius = "52.52", "13.40", 0
+
+	for _, c := range centers(data) {
+		require.Zero(t, c.Radius)
+	}
+
+	// an explicit radius drops the places farther away
+	data.Radius = 5000
+
+	got = centers(data)
+	require.Len(t, got, 2)
+
+	for _, c := range got {
+		require.InDelta(t, 5000, c.Radius, 0)
+		require.InDelta(t, 52.52, c.Lat, 1e-9)
+	}
+
+	// the tiles are one search per tile of the radius
+	data.Tiles = true
+
+	got = centers(data)
+	require.Greater(t, len(got), 2)
+
+	for _, c := range got {
+		require.InDelta(t, 5000, c.Radius, 0)
+	}
+}
@@ -0,0 +1,205 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/bigquery"
+	"github.com/gosom/google-maps-scraper/elasticsearch"
+	"github.com/gosom/google-maps-scraper/fieldmap"
+	"github.com/gosom/google-maps-scraper/filter"
+	"github.com/gosom/google-maps-scraper/kafka"
+	"github.com/gosom/google-maps-scraper/mongodb"
+	"github.com/gosom/google-maps-scraper/nats"
+	"github.com/gosom/google-maps-scraper/pkg/scraper"
+	"github.com/gosom/google-maps-scraper/sheets"
+)
+
+// FilterOptions returns the filtering options of the configuration.
+func (c *Config) FilterOptions() *filter.Options {
+	return &filter.Options{
+		Expr:              c.Filter,
+		IncludeCategories: c.IncludeCategories,
+		ExcludeCategories: c.ExcludeCategories,
+		Quality: filter.Quality{
+			RequirePhone:   c.RequirePhone,
+			RequireWebsite: c.RequireWebsite,
+			MinRating:      c.MinRating,
+			MinReviews:     c.MinReviews,
+		},
+		VerifyAddress: c.VerifyAddress,
+		GeocodeURL:    c.GeocodeURL,
+		MaxResults:    c.MaxResults,
+	}
+}
+
+// SortWriter wraps the writer of the results file, so that the places
+// are written in the order set with -order-by.
+func (c *Config) SortWriter(w scrapemate.ResultWriter) scrapemate.ResultWriter {
+	lat, lon := ParseCoordinates(c.GeoCoordinates)
+
+	return scraper.NewSortWriter(c.OrderBy, lat, lon, w)
+}
+
+// ParseCoordinates returns the latitude and longitude of coordinates in
+// the format lat,lon. It returns zeros when they are invalid.
+func ParseCoordinates(coordinates string) (lat, lon float64) {
+	latStr, lonStr, ok := strings.Cut(coordinates, ",")
+	if !ok {
+		return 0, 0
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return 0, 0
+	}
+
+	lon, err = strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil {
+		return 0, 0
+	}
+
+	return lat, lon
+}
+
+// FilterWriters wraps writers so that the places that do not pass opts
+// are dropped before they are written.
+func FilterWriters(opts *filter.Options, writers []scrapemate.ResultWriter) ([]scrapemate.ResultWriter, error) {
+	fn, err := opts.EntryFunc()
+	if err != nil || fn == nil {
+		return writers, err
+	}
+
+	return []scrapemate.ResultWriter{scraper.NewHookWriter(fn, nil, writers...)}, nil
+}
+
+// ExternalWriters returns the result writers for the external sinks enabled
+// in the configuration. They are used in addition to the runner's own writer.
+// jobID is set by the web runner and is empty otherwise.
+func ExternalWriters(cfg *Config, jobID string) ([]scrapemate.ResultWriter, error) {
+	var writers []scrapemate.ResultWriter
+
+	// the file is read for every job, so that a change applies to the next
+	// jobs of the web server
+	var mappings fieldmap.Config
+
+	if cfg.FieldMapping != "" {
+		var err error
+
+		mappings, err = fieldmap.Load(cfg.FieldMapping)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.ElasticURL != "" {
+		esWriter, err := elasticsearch.NewResultWriter(
+			cfg.ElasticURL,
+			elasticsearch.WithIndex(cfg.ElasticIndex),
+			elasticsearch.WithAPIKey(cfg.ElasticAPIKey),
+			elasticsearch.WithJobID(jobID),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		writers = append(writers, esWriter)
+	}
+
+	if cfg.BigQueryDataset != "" {
+		bqWriter, err := bigquery.NewResultWriter(bigquery.Config{
+			CredentialsFile: cfg.BigQueryCredentials,
+			ProjectID:       cfg.BigQueryProject,
+			DatasetID:       cfg.BigQueryDataset,
+			TableID:         cfg.BigQueryTable,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		writers = append(writers, bqWriter)
+	}
+
+	if cfg.SheetsID != "" {
+		sheetsWriter, err := sheets.NewResultWriter(sheets.Config{
+			CredentialsFile: cfg.SheetsCredentials,
+			SpreadsheetID:   cfg.SheetsID,
+			Range:           cfg.SheetsRange,
+			BatchSize:       cfg.SheetsBatchSize,
+			Mapping:         mappings["sheets"],
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		writers = append(writers, sheetsWriter)
+	}
+
+	if cfg.KafkaRestURL != "" {
+		kafkaWriter, err := kafka.NewResultWriter(
+			cfg.KafkaRestURL,
+			cfg.KafkaTopic,
+			kafka.WithFormat(cfg.KafkaFormat),
+			kafka.WithMapping(mappings["kafka"]),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		writers = append(writers, kafkaWriter)
+	}
+
+	if cfg.NatsURL != "" {
+		natsWriter, err := nats.NewResultWriter(
+			cfg.NatsURL,
+			cfg.NatsSubject,
+			nats.WithMapping(mappings["nats"]),
+			nats.WithCredentials(cfg.NatsCreds),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		writers = append(writers, natsWriter)
+	}
+
+	if cfg.MongoURI != "" {
+		mongoWriter, err := mongodb.NewResultWriter(
+			cfg.MongoURI,
+			mongodb.WithDatabase(cfg.MongoDatabase),
+			mongodb.WithCollection(cfg.MongoCollection),
+			mongodb.WithJobID(jobID),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		writers = append(writers, mongoWriter)
+	}
+
+	return writers, nil
+}
+
+// QueueWriters wraps the writers with bounded queues of the
+// -writer-queue-size, tracked by queues. A slow writer then slows down the
+// scraping when its queue is full instead of blocking the other writers
+// right away or buffering without limit.
+func QueueWriters(cfg *Config, queues *scraper.Queues, writers []scrapemate.ResultWriter) []scrapemate.ResultWriter {
+	ans := make([]scrapemate.ResultWriter, 0, len(writers))
+
+	for _, w := range writers {
+		ans = append(ans, queues.Wrap(WriterName(w), cfg.WriterQueueSize, w))
+	}
+
+	return ans
+}
+
+// WriterName returns the name of the package of the writer, e.g. kafka.
+func WriterName(w scrapemate.ResultWriter) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(fmt.Sprintf("%T", w), "*"), ".")
+
+	return name
+}
@@ -2,19 +2,78 @@ package s3uploader
 
 import (
 	"context"
+	"errors"
 	"io"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
+const (
+	// SignatureV4 signs the payload of the requests (default).
+	SignatureV4 = "v4"
+	// SignatureV4UnsignedPayload signs the headers only. Some S3-compatible
+	// services require it for streamed uploads over https.
+	SignatureV4UnsignedPayload = "v4-unsigned-payload"
+
+	// defaultRegion is used for S3-compatible services that ignore the region.
+	defaultRegion = "us-east-1"
+)
+
+type Option func(*options)
+
+type options struct {
+	endpoint  string
+	pathStyle bool
+	signature string
+}
+
+// WithEndpoint sets a custom endpoint for S3-compatible services
+// like MinIO, Cloudflare R2 or Backblaze B2.
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithPathStyle uses path-style addressing (https://host/bucket/key)
+// instead of virtual-hosted-style addressing (https://bucket.host/key).
+func WithPathStyle(v bool) Option {
+	return func(o *options) {
+		o.pathStyle = v
+	}
+}
+
+func WithSignature(signature string) Option {
+	return func(o *options) {
+		if signature != "" {
+			o.signature = signature
+		}
+	}
+}
+
 type Uploader struct {
 	client *s3.Client
 }
 
-func New(accessKey, secretKey, region string) *Uploader {
+func New(accessKey, secretKey, region string, opts ...Option) *Uploader {
+	o := options{
+		signature: SignatureV4,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if region == "" && o.endpoint != "" {
+		region = defaultRegion
+	}
+
 	creds := credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")
 
 	cfg, err := config.LoadDefaultConfig(context.Background(),
@@ -25,7 +84,17 @@ func New(accessKey, secretKey, region string) *Uploader {
 		return nil
 	}
 
-	client := s3.NewFromConfig(cfg)
+	client := s3.NewFromConfig(cfg, func(so *s3.Options) {
+		if o.endpoint != "" {
+			so.BaseEndpoint = aws.String(o.endpoint)
+		}
+
+		so.UsePathStyle = o.pathStyle
+
+		if o.signature == SignatureV4UnsignedPayload {
+			so.APIOptions = append(so.APIOptions, v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware)
+		}
+	})
 
 	return &Uploader{
 		client: client,
@@ -46,3 +115,61 @@ func (u *Uploader) Upload(ctx context.Context, bucketName, key string, body io.R
 
 	return nil
 }
+
+// PresignGet returns a time-limited url to download the object. It uses the
+// same endpoint and addressing style as the uploads.
+func (u *Uploader) PresignGet(ctx context.Context, bucketName, key string, expires time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(u.client)
+
+	req, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+
+	return req.URL, nil
+}
+
+// Delete removes the object. Deleting an object that does not exist is not
+// an error.
+func (u *Uploader) Delete(ctx context.Context, bucketName, key string) error {
+	_, err := u.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+
+	return err
+}
+
+// ErrNotFound is returned by Download when the object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Download returns the body of the object, the caller closes it.
+func (u *Uploader) Download(ctx context.Context, bucketName, key string) (io.ReadCloser, error) {
+	out, err := u.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return out.Body, nil
+}
+
+// HeadBucket checks that the bucket exists and can be accessed with the
+// credentials of the uploader.
+func (u *Uploader) HeadBucket(ctx context.Context, bucketName string) error {
+	_, err := u.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+
+	return err
+}
@@ -0,0 +1,2 @@
+DROP TABLE results;
+DROP TABLE gmaps_jobs;
@@ -0,0 +1,14 @@
+CREATE TABLE gmaps_jobs(
+    id CHAR(36) PRIMARY KEY,
+    priority SMALLINT NOT NULL,
+    payload_type VARCHAR(32) NOT NULL,
+    payload LONGBLOB NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    INDEX idx_gmaps_jobs_status_priority_created (status, priority, created_at)
+);
+
+CREATE TABLE results(
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    data JSON NOT NULL
+);
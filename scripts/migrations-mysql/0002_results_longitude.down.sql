@@ -0,0 +1,3 @@
+UPDATE results
+    SET data = JSON_REMOVE(JSON_SET(data, '$.longtitude', JSON_EXTRACT(data, '$.longitude')), '$.longitude')
+    WHERE JSON_CONTAINS_PATH(data, 'one', '$.longitude');
@@ -0,0 +1,3 @@
+UPDATE results
+    SET data = JSON_REMOVE(JSON_SET(data, '$.longitude', JSON_EXTRACT(data, '$.longtitude')), '$.longtitude')
+    WHERE JSON_CONTAINS_PATH(data, 'one', '$.longtitude');
@@ -0,0 +1,7 @@
+ALTER TABLE results
+    ADD COLUMN street VARCHAR(255) GENERATED ALWAYS AS (data->>'$.complete_address.street') STORED,
+    ADD COLUMN city VARCHAR(255) GENERATED ALWAYS AS (data->>'$.complete_address.city') STORED,
+    ADD COLUMN postal_code VARCHAR(32) GENERATED ALWAYS AS (data->>'$.complete_address.postal_code') STORED,
+    ADD COLUMN state VARCHAR(255) GENERATED ALWAYS AS (data->>'$.complete_address.state') STORED,
+    ADD COLUMN country VARCHAR(8) GENERATED ALWAYS AS (data->>'$.complete_address.country') STORED,
+    ADD COLUMN address_check VARCHAR(16) GENERATED ALWAYS AS (data->>'$.address_check') STORED;
@@ -0,0 +1,3 @@
+ALTER TABLE results
+    DROP INDEX idx_results_scraped_at,
+    DROP COLUMN scraped_at;
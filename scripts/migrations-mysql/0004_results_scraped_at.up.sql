@@ -0,0 +1,3 @@
+ALTER TABLE results
+    ADD COLUMN scraped_at DATETIME(6) NULL,
+    ADD INDEX idx_results_scraped_at (scraped_at);
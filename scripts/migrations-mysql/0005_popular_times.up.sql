@@ -0,0 +1,7 @@
+CREATE TABLE popular_times(
+    cid VARCHAR(32) NOT NULL,
+    day VARCHAR(16) NOT NULL,
+    hour TINYINT NOT NULL,
+    busyness TINYINT NOT NULL,
+    PRIMARY KEY (cid, day, hour)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE reviews(
+    cid VARCHAR(32) NOT NULL,
+    review_id VARCHAR(128) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    rating TINYINT NOT NULL,
+    date VARCHAR(16) NOT NULL,
+    text TEXT NOT NULL,
+    owner_reply TEXT NOT NULL,
+    PRIMARY KEY (cid, review_id)
+);
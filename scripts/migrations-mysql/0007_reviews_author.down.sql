@@ -0,0 +1,5 @@
+ALTER TABLE reviews
+    DROP COLUMN local_guide,
+    DROP COLUMN local_guide_level,
+    DROP COLUMN author_reviews,
+    DROP COLUMN author_photos;
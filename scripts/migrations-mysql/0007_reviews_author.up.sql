@@ -0,0 +1,5 @@
+ALTER TABLE reviews
+    ADD COLUMN local_guide BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN local_guide_level TINYINT NOT NULL DEFAULT 0,
+    ADD COLUMN author_reviews INT NOT NULL DEFAULT 0,
+    ADD COLUMN author_photos INT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_gmaps_jobs_status_priority_created;
+
+COMMIT;
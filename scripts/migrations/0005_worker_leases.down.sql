@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_gmaps_jobs_worker_id;
+
+ALTER TABLE gmaps_jobs
+    DROP COLUMN worker_id,
+    DROP COLUMN lease_expires_at,
+    DROP COLUMN attempts;
+
+DROP TABLE gmaps_workers;
+
+COMMIT;
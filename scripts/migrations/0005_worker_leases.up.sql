@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE gmaps_workers(
+    id TEXT PRIMARY KEY,
+    hostname TEXT NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_heartbeat TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+ALTER TABLE gmaps_jobs
+    ADD COLUMN worker_id TEXT,
+    ADD COLUMN lease_expires_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN attempts SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_gmaps_jobs_worker_id ON gmaps_jobs(worker_id) WHERE worker_id IS NOT NULL;
+
+COMMIT;
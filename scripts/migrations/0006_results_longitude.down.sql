@@ -0,0 +1,7 @@
+BEGIN;
+
+UPDATE results
+    SET data = (data - 'longitude') || jsonb_build_object('longtitude', data->'longitude')
+    WHERE data ? 'longitude';
+
+COMMIT;
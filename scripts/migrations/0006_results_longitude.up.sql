@@ -0,0 +1,7 @@
+BEGIN;
+
+UPDATE results
+    SET data = (data - 'longtitude') || jsonb_build_object('longitude', data->'longtitude')
+    WHERE data ? 'longtitude';
+
+COMMIT;
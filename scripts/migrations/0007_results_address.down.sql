@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE results
+    DROP COLUMN street,
+    DROP COLUMN city,
+    DROP COLUMN postal_code,
+    DROP COLUMN state,
+    DROP COLUMN country,
+    DROP COLUMN address_check;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE results
+    ADD COLUMN street TEXT GENERATED ALWAYS AS (data->'complete_address'->>'street') STORED,
+    ADD COLUMN city TEXT GENERATED ALWAYS AS (data->'complete_address'->>'city') STORED,
+    ADD COLUMN postal_code TEXT GENERATED ALWAYS AS (data->'complete_address'->>'postal_code') STORED,
+    ADD COLUMN state TEXT GENERATED ALWAYS AS (data->'complete_address'->>'state') STORED,
+    ADD COLUMN country TEXT GENERATED ALWAYS AS (data->'complete_address'->>'country') STORED,
+    ADD COLUMN address_check TEXT GENERATED ALWAYS AS (data->>'address_check') STORED;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_results_scraped_at;
+
+ALTER TABLE results
+    DROP COLUMN scraped_at;
+
+COMMIT;
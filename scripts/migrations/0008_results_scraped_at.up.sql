@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE results
+    ADD COLUMN scraped_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_results_scraped_at ON results(scraped_at);
+
+COMMIT;
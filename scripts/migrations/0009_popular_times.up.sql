@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE popular_times(
+    cid TEXT NOT NULL,
+    day TEXT NOT NULL,
+    hour SMALLINT NOT NULL,
+    busyness SMALLINT NOT NULL,
+    PRIMARY KEY (cid, day, hour)
+);
+
+COMMIT;
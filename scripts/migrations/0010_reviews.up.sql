@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE reviews(
+    cid TEXT NOT NULL,
+    review_id TEXT NOT NULL,
+    author TEXT NOT NULL,
+    rating SMALLINT NOT NULL,
+    date TEXT NOT NULL,
+    text TEXT NOT NULL,
+    owner_reply TEXT NOT NULL,
+    PRIMARY KEY (cid, review_id)
+);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE reviews
+    DROP COLUMN local_guide,
+    DROP COLUMN local_guide_level,
+    DROP COLUMN author_reviews,
+    DROP COLUMN author_photos;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE outbox(
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    destination TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_outbox_status_next_attempt ON outbox(status, next_attempt_at);
+
+COMMIT;
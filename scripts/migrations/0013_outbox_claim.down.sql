@@ -0,0 +1,7 @@
+BEGIN;
+
+UPDATE outbox SET status = 'pending' WHERE status = 'delivering';
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS locked_until;
+
+COMMIT;
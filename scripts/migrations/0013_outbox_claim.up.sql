@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE outbox ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+COMMIT;
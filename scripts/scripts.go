@@ -0,0 +1,47 @@
+// Package scripts embeds the database migrations, so that the binary knows
+// the schema version it expects.
+package scripts
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// Postgres are the migrations of the postgres database provider.
+//
+//go:embed migrations/*.sql
+var Postgres embed.FS
+
+// MySQL are the migrations of the MySQL/MariaDB database provider.
+//
+//go:embed migrations-mysql/*.sql
+var MySQL embed.FS
+
+// LatestVersion returns the version of the last migration of a directory,
+// the number the names of the migrations start with.
+func LatestVersion(fsys fs.FS, dir string) (int, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int
+
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			continue
+		}
+
+		latest = max(latest, version)
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,199 @@
+// Package secrets encrypts the credentials that are persisted, like the
+// proxies of the jobs, with envelope encryption: every value is encrypted
+// with its own random data key and the data key with a key of the keyring.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const prefix = "enc:v1:"
+
+var (
+	// ErrUnknownKey is returned when a value was encrypted with a key that
+	// is not in the keyring.
+	ErrUnknownKey = errors.New("secrets: value encrypted with an unknown key")
+	// ErrMalformed is returned when a value looks encrypted but is not
+	// valid.
+	ErrMalformed = errors.New("secrets: malformed encrypted value")
+)
+
+// Keyring holds the keys that encrypt the data keys. The first key encrypts
+// new values, the others only decrypt the values encrypted before a
+// rotation.
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// ParseKeyring parses a comma separated list of keys in the format
+// <id>:<base64 of 32 bytes>, the current key first. Rotate the keys by
+// prepending a new key and keeping the old ones until the values are
+// encrypted again.
+func ParseKeyring(s string) (*Keyring, error) {
+	ans := Keyring{keys: map[string]cipher.AEAD{}}
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(item, ":")
+		if !ok || !validID(id) {
+			return nil, fmt.Errorf("secrets: invalid key %q: it must be <id>:<base64 key>", id)
+		}
+
+		if _, ok := ans.keys[id]; ok {
+			return nil, fmt.Errorf("secrets: duplicate key id %q", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("secrets: key %q must be 32 bytes encoded in base64", id)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if ans.current == "" {
+			ans.current = id
+		}
+
+		ans.keys[id] = aead
+	}
+
+	if ans.current == "" {
+		return nil, errors.New("secrets: no keys")
+	}
+
+	return &ans, nil
+}
+
+// IsEncrypted reports whether s is an encrypted value.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+// Current reports whether s is encrypted with the current key.
+func (k *Keyring) Current(s string) bool {
+	id, _, ok := strings.Cut(strings.TrimPrefix(s, prefix), ":")
+
+	return IsEncrypted(s) && ok && id == k.current
+}
+
+// Encrypt encrypts s with a new data key, which is encrypted with the
+// current key. The result is enc:v1:<key id>:<data key>:<value>.
+func (k *Keyring) Encrypt(s string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := seal(aead, []byte(s))
+	if err != nil {
+		return "", err
+	}
+
+	wrapped, err := seal(k.keys[k.current], dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + k.current + ":" + wrapped + ":" + value, nil
+}
+
+// Decrypt decrypts a value of Encrypt. Values that are not encrypted are
+// returned as they are, they were stored before the keyring was set.
+func (k *Keyring) Decrypt(s string) (string, error) {
+	if !IsEncrypted(s) {
+		return s, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, prefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformed
+	}
+
+	key, ok := k.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, parts[0])
+	}
+
+	dataKey, err := open(key, parts[1])
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := open(aead, parts[2])
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal returns the base64 of the nonce followed by the ciphertext.
+func seal(aead cipher.AEAD, plaintext []byte) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawStdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+func open(aead cipher.AEAD, s string) ([]byte, error) {
+	b, err := base64.RawStdEncoding.DecodeString(s)
+	if err != nil || len(b) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+
+	ans, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return ans, nil
+}
+
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	for _, c := range id {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,232 @@
+// Package seeds creates the seed jobs of the search queries, the jobs a
+// scrape starts with.
+package seeds
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/localize"
+)
+
+// Options are the options of the seed jobs of the search queries.
+type Options struct {
+	FastMode bool
+	LangCode string
+	MaxDepth int
+	Email    bool
+	// EmailSitemap crawls the sitemaps of the websites for the emails.
+	EmailSitemap gmaps.SitemapOptions
+	// GeoCoordinates is "lat,lon", required in fast mode.
+	GeoCoordinates string
+	Zoom           int
+	// Radius in meters around GeoCoordinates. Normal mode drops the places
+	// farther away only when it is set, fast mode defaults to
+	// DefaultFastModeRadius.
+	Radius float64
+	// Tiles splits the search into map tiles that cover the radius.
+	Tiles bool
+	// Localize translates the category term of the queries to LangCode.
+	Localize         bool
+	ResultsLang      string
+	ResultsEnglish   bool
+	Country          string
+	ExcludeSponsored bool
+	SeedRetries      int
+	Dedup            deduper.Deduper
+	ExitMonitor      exiter.Exiter
+}
+
+// DefaultFastModeRadius is the radius in meters of the fast mode searches
+// when none is set.
+const DefaultFastModeRadius = 10000
+
+// New creates a job for every query of r, one per line. The text after
+// "#!#" in a line is the id of its job.
+func New(r io.Reader, opts *Options) (jobs []scrapemate.IJob, err error) {
+	var lat, lon float64
+
+	if opts.FastMode && opts.GeoCoordinates == "" {
+		return nil, fmt.Errorf("geo coordinates are required in fast mode")
+	}
+
+	if opts.GeoCoordinates != "" {
+		parts := strings.Split(opts.GeoCoordinates, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid geo coordinates: %s", opts.GeoCoordinates)
+		}
+
+		lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid latitude: %w", err)
+		}
+
+		lon, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid longitude: %w", err)
+		}
+
+		if lat < -90 || lat > 90 {
+			return nil, fmt.Errorf("invalid latitude: %f", lat)
+		}
+
+		if lon < -180 || lon > 180 {
+			return nil, fmt.Errorf("invalid longitude: %f", lon)
+		}
+	}
+
+	if opts.FastMode && (opts.Zoom < 1 || opts.Zoom > 21) {
+		return nil, fmt.Errorf("invalid zoom level: %d", opts.Zoom)
+	}
+
+	if opts.Radius < 0 {
+		return nil, fmt.Errorf("invalid radius: %f", opts.Radius)
+	}
+
+	radius := opts.Radius
+	if opts.FastMode && radius == 0 {
+		radius = DefaultFastModeRadius
+	}
+
+	// the places farther than the radius are dropped in normal mode only
+	// when there is a location, 0,0 is the default of the web form
+	hasLocation := opts.GeoCoordinates != "" && (lat != 0 || lon != 0)
+
+	if opts.Tiles && (!hasLocation || radius == 0) {
+		return nil, fmt.Errorf("tiles require geo coordinates and a radius")
+	}
+
+	scanner := bufio.NewScanner(r)
+	index := 0
+
+	for scanner.Scan() {
+		query := strings.TrimSpace(scanner.Text())
+		if query == "" {
+			continue
+		}
+
+		var id string
+
+		if before, after, ok := strings.Cut(query, "#!#"); ok {
+			query = strings.TrimSpace(before)
+			id = strings.TrimSpace(after)
+		}
+
+		index++
+		keyword := query
+
+		if opts.Localize {
+			query = localize.Query(query, opts.LangCode)
+		}
+
+		if !opts.FastMode {
+			jopts := []gmaps.GmapJobOptions{
+				gmaps.WithInput(keyword, index),
+			}
+
+			if opts.Dedup != nil {
+				jopts = append(jopts, gmaps.WithDeduper(opts.Dedup))
+			}
+
+			if opts.ExitMonitor != nil {
+				jopts = append(jopts, gmaps.WithExitMonitor(opts.ExitMonitor))
+			}
+
+			if opts.Country != "" {
+				jopts = append(jopts, gmaps.WithCountry(opts.Country))
+			}
+
+			if opts.ExcludeSponsored {
+				jopts = append(jopts, gmaps.WithExcludeSponsored())
+			}
+
+			if opts.EmailSitemap.MaxPages > 0 {
+				jopts = append(jopts, gmaps.WithEmailSitemap(opts.EmailSitemap))
+			}
+
+			if opts.SeedRetries > 0 {
+				jopts = append(jopts, gmaps.WithRetries(opts.SeedRetries))
+			}
+
+			if opts.ResultsLang != "" || opts.ResultsEnglish {
+				jopts = append(jopts, gmaps.WithResultsLang(opts.ResultsLang, opts.ResultsEnglish))
+			}
+
+			// the search is split into tiles of the radius, so that the
+			// places far away are not scraped only to be dropped
+			if opts.Tiles {
+				area := gmaps.MapLocation{Lat: lat, Lon: lon, ZoomLvl: float64(opts.Zoom), Radius: radius}
+
+				for _, job := range gmaps.NewTiledGmapJobs(id, opts.LangCode, query, opts.MaxDepth, opts.Email, area, jopts...) {
+					jobs = append(jobs, job)
+				}
+
+				continue
+			}
+
+			if hasLocation && radius > 0 {
+				jopts = append(jopts, gmaps.WithRadius(lat, lon, radius))
+			}
+
+			jobs = append(jobs, gmaps.NewGmapJob(id, opts.LangCode, query, opts.MaxDepth, opts.Email, opts.GeoCoordinates, opts.Zoom, jopts...))
+		} else {
+			jparams := gmaps.MapSearchParams{
+				Location: gmaps.MapLocation{
+					Lat:     lat,
+					Lon:     lon,
+					ZoomLvl: float64(opts.Zoom),
+					Radius:  radius,
+				},
+				Query:     query,
+				ViewportW: 1920,
+				ViewportH: 450,
+				Hl:        opts.LangCode,
+				Country:   opts.Country,
+			}
+
+			if opts.ResultsLang != "" {
+				jparams.Hl = opts.ResultsLang
+			}
+
+			jopts := []gmaps.SearchJobOptions{
+				gmaps.WithSearchJobInput(keyword, index),
+			}
+
+			if opts.Email {
+				jopts = append(jopts, gmaps.WithSearchJobExtractEmail(), gmaps.WithSearchJobEmailSitemap(opts.EmailSitemap))
+			}
+
+			if opts.Dedup != nil {
+				jopts = append(jopts, gmaps.WithSearchJobDeduper(opts.Dedup))
+			}
+
+			if opts.SeedRetries > 0 {
+				jopts = append(jopts, gmaps.WithSearchJobRetries(opts.SeedRetries))
+			}
+
+			if opts.ExitMonitor != nil {
+				jopts = append(jopts, gmaps.WithSearchJobExitMonitor(opts.ExitMonitor))
+			}
+
+			if !opts.Tiles {
+				jobs = append(jobs, gmaps.NewSearchJob(&jparams, jopts...))
+
+				continue
+			}
+
+			for _, params := range jparams.Split() {
+				jobs = append(jobs, gmaps.NewSearchJob(params, jopts...))
+			}
+		}
+	}
+
+	return jobs, scanner.Err()
+}
@@ -0,0 +1,55 @@
+package seeds_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/seeds"
+)
+
+func Test_New(t *testing.T) {
+	input := "cafe in berlin #!# berlin\n\n  bar in munich  \n"
+
+	jobs, err := seeds.New(strings.NewReader(input), &seeds.Options{LangCode: "en", MaxDepth: 1, Zoom: 15, SeedRetries: 2})
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+
+	first, ok := jobs[0].(*gmaps.GmapJob)
+	require.True(t, ok)
+	require.Equal(t, "berlin", first.GetID())
+	require.Equal(t, "cafe in berlin", first.InputKeyword)
+	require.Equal(t, 2, first.Retries)
+
+	second, ok := jobs[1].(*gmaps.GmapJob)
+	require.True(t, ok)
+	require.Equal(t, "bar in munich", second.InputKeyword)
+	require.Equal(t, 2, second.InputIndex)
+
+	// fast mode searches within the default radius
+	jobs, err = seeds.New(strings.NewReader("cafe"), &seeds.Options{FastMode: true, GeoCoordinates: "52.52,13.40", Zoom: 15})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+
+	_, ok = jobs[0].(*gmaps.SearchJob)
+	require.True(t, ok)
+
+	tests := []struct {
+		name string
+		opts seeds.Options
+	}{
+		{"fast mode without coordinates", seeds.Options{FastMode: true, Zoom: 15}},
+		{"invalid coordinates", seeds.Options{GeoCoordinates: "91,0"}},
+		{"invalid zoom", seeds.Options{FastMode: true, GeoCoordinates: "52.52,13.40", Zoom: 22}},
+		{"tiles without radius", seeds.Options{GeoCoordinates: "52.52,13.40", Zoom: 15, Tiles: true}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := seeds.New(strings.NewReader("cafe"), &tc.opts)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+//go:build darwin
+
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const label = "com.github.gosom." + Name
+
+var plistTemplate = template.Must(template.New("plist").Funcs(template.FuncMap{
+	"xml": func(s string) (string, error) {
+		var b strings.Builder
+
+		err := xml.EscapeText(&b, []byte(s))
+
+		return b.String(), err
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{ .Label }}</string>
+	<key>ProgramArguments</key>
+	<array>
+{{- range .Args }}
+		<string>{{ xml . }}</string>
+{{- end }}
+	</array>
+	<key>WorkingDirectory</key>
+	<string>{{ xml .Dir }}</string>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>{{ xml .Log }}</string>
+	<key>StandardErrorPath</key>
+	<string>{{ xml .Log }}</string>
+</dict>
+</plist>
+`))
+
+// install writes a launchd plist and loads it. As root it is a daemon,
+// otherwise an agent of the current user. The logs are written to
+// google-maps-scraper.log in the working directory.
+func install(exe, dir string, args []string) error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = plistTemplate.Execute(f, map[string]any{
+		"Label": label,
+		"Args":  append([]string{exe}, args...),
+		"Dir":   dir,
+		"Log":   filepath.Join(dir, Name+".log"),
+	})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := run("launchctl", "load", "-w", path); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "installed %s\n", path)
+
+	return nil
+}
+
+func uninstall() error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+
+	if err := run("launchctl", "unload", "-w", path); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func plistPath() (string, error) {
+	if os.Geteuid() == 0 {
+		return "/Library/LaunchDaemons/" + label + ".plist", nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, "Library", "LaunchAgents", label+".plist"), nil
+}
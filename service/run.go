@@ -0,0 +1,12 @@
+//go:build !windows
+
+package service
+
+import "context"
+
+// Run reports the state of the process to the Windows service manager when
+// it runs as a service. On the other platforms the service managers stop
+// the process with a signal, so there is nothing to do.
+func Run(context.CancelFunc) (stopped func()) {
+	return func() {}
+}
@@ -0,0 +1,76 @@
+// Package service installs the web runner as a background service managed
+// by the operating system, so it is started on boot and restarted when it
+// exits: a systemd unit on Linux, a launchd job on macOS and a Windows service.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	Name        = "google-maps-scraper"
+	description = "Google Maps Scraper web runner"
+)
+
+var ErrUnsupported = errors.New("service: not supported on this platform")
+
+// Command runs the service subcommand. args are the arguments that follow
+// "service": either "install" followed by the flags the web runner is
+// started with, or "uninstall".
+func Command(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: service install [flags] | service uninstall")
+	}
+
+	switch args[0] {
+	case "install":
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+
+		exe, err = filepath.EvalSymlinks(exe)
+		if err != nil {
+			return err
+		}
+
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		return install(exe, dir, runnerArgs(args[1:]))
+	case "uninstall":
+		return uninstall()
+	default:
+		return fmt.Errorf("unknown service command %q", args[0])
+	}
+}
+
+// runnerArgs makes sure that the service starts the web runner.
+func runnerArgs(args []string) []string {
+	for _, arg := range args {
+		if arg == "-web" || arg == "--web" || strings.HasPrefix(arg, "-web=") || strings.HasPrefix(arg, "--web=") {
+			return args
+		}
+	}
+
+	return append([]string{"-web"}, args...)
+}
+
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+//go:build linux
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var unitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description={{ .Description }}
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart={{ .ExecStart }}
+WorkingDirectory={{ .Dir }}
+Restart=always
+RestartSec=5
+
+[Install]
+WantedBy={{ .WantedBy }}
+`))
+
+// install writes a systemd unit and starts it. As root it is a system unit,
+// otherwise a user unit.
+func install(exe, dir string, args []string) error {
+	path, systemctl, wantedBy, err := unitLocation()
+	if err != nil {
+		return err
+	}
+
+	execStart := make([]string, 0, len(args)+1)
+
+	for _, arg := range append([]string{exe}, args...) {
+		execStart = append(execStart, systemdQuote(arg))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = unitTemplate.Execute(f, map[string]string{
+		"Description": description,
+		"ExecStart":   strings.Join(execStart, " "),
+		"Dir":         strings.ReplaceAll(dir, "%", "%%"),
+		"WantedBy":    wantedBy,
+	})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := run("systemctl", append(systemctl, "daemon-reload")...); err != nil {
+		return err
+	}
+
+	if err := run("systemctl", append(systemctl, "enable", "--now", Name)...); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "installed %s\n", path)
+
+	return nil
+}
+
+func uninstall() error {
+	path, systemctl, _, err := unitLocation()
+	if err != nil {
+		return err
+	}
+
+	if err := run("systemctl", append(systemctl, "disable", "--now", Name)...); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	return run("systemctl", append(systemctl, "daemon-reload")...)
+}
+
+//nolint:gocritic // it contains about unnamed results
+func unitLocation() (string, []string, string, error) {
+	if os.Geteuid() == 0 {
+		return "/etc/systemd/system/" + Name + ".service", nil, "multi-user.target", nil
+	}
+
+	cfgDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	return filepath.Join(cfgDir, "systemd", "user", Name+".service"), []string{"--user"}, "default.target", nil
+}
+
+// systemdQuote quotes a word of a unit file. % and $ are escaped so that
+// systemd does not expand them.
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	s = strings.ReplaceAll(s, "$", "$$")
+
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
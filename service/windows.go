@@ -0,0 +1,146 @@
+//go:build windows
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// install creates a Windows service that starts automatically and is
+// restarted when it exits. Services start in the system directory, so the
+// data folder is set to the working directory unless it is given.
+func install(exe, dir string, args []string) error {
+	if !hasFlag(args, "data-folder") {
+		args = append(args, "-data-folder", filepath.Join(dir, "webdata"))
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+
+	defer m.Disconnect() //nolint:errcheck // nothing to do on error
+
+	s, err := m.CreateService(Name, exe, mgr.Config{
+		DisplayName: "Google Maps Scraper",
+		Description: description,
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return err
+	}
+
+	defer s.Close()
+
+	err = s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+	}, uint32((24 * time.Hour).Seconds()))
+	if err != nil {
+		return err
+	}
+
+	if err := s.Start(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "installed service %s\n", Name)
+
+	return nil
+}
+
+func uninstall() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+
+	defer m.Disconnect() //nolint:errcheck // nothing to do on error
+
+	s, err := m.OpenService(Name)
+	if err != nil {
+		return err
+	}
+
+	defer s.Close()
+
+	_, _ = s.Control(svc.Stop)
+
+	return s.Delete()
+}
+
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		arg = strings.TrimLeft(arg, "-")
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Run reports the state of the process to the Windows service manager when
+// it runs as a service and calls cancel when the service is stopped. The
+// returned function must be called once the runner has exited.
+func Run(cancel context.CancelFunc) (stopped func()) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return func() {}
+	}
+
+	h := handler{
+		cancel: cancel,
+		exited: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(h.done)
+
+		_ = svc.Run(Name, &h)
+	}()
+
+	return func() {
+		close(h.exited)
+		<-h.done
+	}
+}
+
+type handler struct {
+	cancel context.CancelFunc
+	exited chan struct{}
+	done   chan struct{}
+}
+
+//nolint:gocritic // the signature is defined by svc.Handler
+func (h *handler) Execute(_ []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown
+
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for {
+		select {
+		case <-h.exited:
+			changes <- svc.Status{State: svc.StopPending}
+
+			return false, 0
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Interrogate:
+				changes <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+
+				h.cancel()
+			}
+		}
+	}
+}
@@ -0,0 +1,471 @@
+// Package sheets appends the results to a Google Sheets spreadsheet while
+// the scraping runs.
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/fieldmap"
+	"github.com/gosom/google-maps-scraper/gcpauth"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	scope            = "https://www.googleapis.com/auth/spreadsheets"
+	baseURL          = "https://sheets.googleapis.com/v4/spreadsheets"
+	defaultBatchSize = 500
+	flushInterval    = 10 * time.Second
+	// the Sheets API allows 60 write requests a minute per user, the
+	// requests are spaced so that a writer stays below
+	minRequestInterval = 1100 * time.Millisecond
+	maxRetries         = 6
+	maxBackoff         = time.Minute
+	// the columns read to find the header of an existing table
+	headerColumns = 256
+)
+
+type Config struct {
+	// CredentialsFile is the path to the service account JSON key. When
+	// empty GOOGLE_APPLICATION_CREDENTIALS or the metadata server is used.
+	// The spreadsheet must be shared with the service account.
+	CredentialsFile string
+	SpreadsheetID   string
+	// Range is the tab the results are appended to, e.g. Leads, optionally
+	// with the cell where the table starts, e.g. Leads!B3. The tab is
+	// created if it does not exist. It defaults to the first tab.
+	Range string
+	// BatchSize is the number of rows of an append request, 500 by default.
+	BatchSize int
+	// Mapping sets the columns of the table instead of the columns of the
+	// csv results.
+	Mapping fieldmap.Mapping
+}
+
+// NewResultWriter creates a writer that appends the entries to a table of
+// the spreadsheet in batches, while the job runs. The header row is written
+// when the table is empty; otherwise the columns of the existing header are
+// filled, in its order, so the columns can be reordered or removed.
+func NewResultWriter(cfg Config) (scrapemate.ResultWriter, error) {
+	if cfg.SpreadsheetID == "" {
+		return nil, errors.New("sheets spreadsheet id is required")
+	}
+
+	target, err := parseRange(cfg.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+
+	ts, err := gcpauth.Default(cfg.CredentialsFile, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := resultWriter{
+		cfg:    cfg,
+		target: target,
+		ts:     ts,
+		client: &http.Client{Timeout: time.Minute},
+	}
+
+	return &ans, nil
+}
+
+type resultWriter struct {
+	cfg    Config
+	target target
+	ts     *gcpauth.TokenSource
+	client *http.Client
+
+	// columns are the indexes of the values of a row, see headers, in the
+	// order of the header of the table, -1 for an unknown column
+	columns     []int
+	lastRequest time.Time
+}
+
+func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	if err := r.ensureTab(ctx); err != nil {
+		return err
+	}
+
+	if err := r.ensureHeader(ctx); err != nil {
+		return err
+	}
+
+	buff := make([][]string, 0, r.cfg.BatchSize)
+	lastSave := time.Now().UTC()
+
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = []*gmaps.Entry{data}
+		case []*gmaps.Entry:
+			entries = data
+		default:
+			return errors.New("invalid data type")
+		}
+
+		for _, entry := range entries {
+			row, err := r.row(entry)
+			if err != nil {
+				return err
+			}
+
+			buff = append(buff, row)
+		}
+
+		if len(buff) >= r.cfg.BatchSize || time.Now().UTC().Sub(lastSave) >= flushInterval {
+			if err := r.append(ctx, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastSave = time.Now().UTC()
+		}
+	}
+
+	return r.append(ctx, buff)
+}
+
+func (r *resultWriter) row(entry *gmaps.Entry) ([]string, error) {
+	values := entry.CsvRow()
+
+	if r.cfg.Mapping != nil {
+		var err error
+
+		values, err = r.cfg.Mapping.Strings(entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ans := make([]string, len(r.columns))
+
+	for i, idx := range r.columns {
+		if idx >= 0 && idx < len(values) {
+			ans[i] = values[idx]
+		}
+	}
+
+	return ans, nil
+}
+
+// headers returns the columns of the rows: the columns of the mapping or
+// of the csv results.
+func (r *resultWriter) headers() []string {
+	if r.cfg.Mapping != nil {
+		return r.cfg.Mapping.Columns()
+	}
+
+	return (&gmaps.Entry{}).CsvHeaders()
+}
+
+// ensureTab adds the tab of the range when the spreadsheet does not have it.
+func (r *resultWriter) ensureTab(ctx context.Context) error {
+	if r.target.tab == "" {
+		return nil
+	}
+
+	var spreadsheet struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+
+	if err := r.do(ctx, http.MethodGet, "?fields=sheets.properties.title", nil, &spreadsheet); err != nil {
+		return err
+	}
+
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties.Title == r.target.tab {
+			return nil
+		}
+	}
+
+	payload := map[string]any{
+		"requests": []any{
+			map[string]any{
+				"addSheet": map[string]any{
+					"properties": map[string]any{"title": r.target.tab},
+				},
+			},
+		},
+	}
+
+	return r.do(ctx, http.MethodPost, ":batchUpdate", payload, nil)
+}
+
+// ensureHeader reads the header row of the table and writes it when the
+// table is empty.
+func (r *resultWriter) ensureHeader(ctx context.Context) error {
+	headers := r.headers()
+
+	var existing struct {
+		Values [][]string `json:"values"`
+	}
+
+	headerRange := r.target.a1(r.target.col, r.target.col+headerColumns-1)
+
+	if err := r.do(ctx, http.MethodGet, "/values/"+url.PathEscape(headerRange), nil, &existing); err != nil {
+		return err
+	}
+
+	if len(existing.Values) == 0 || len(existing.Values[0]) == 0 {
+		writeRange := r.target.a1(r.target.col, r.target.col+len(headers)-1)
+
+		payload := map[string]any{
+			"range":  writeRange,
+			"values": [][]string{headers},
+		}
+
+		path := "/values/" + url.PathEscape(writeRange) + "?valueInputOption=RAW"
+
+		if err := r.do(ctx, http.MethodPut, path, payload, nil); err != nil {
+			return err
+		}
+
+		existing.Values = [][]string{headers}
+	}
+
+	index := make(map[string]int, len(headers))
+
+	for i, h := range headers {
+		index[h] = i
+	}
+
+	r.columns = make([]int, len(existing.Values[0]))
+	matched := false
+
+	for i, h := range existing.Values[0] {
+		idx, ok := index[strings.TrimSpace(h)]
+		if !ok {
+			idx = -1
+		}
+
+		r.columns[i] = idx
+		matched = matched || ok
+	}
+
+	if !matched {
+		return fmt.Errorf("the header of %s has none of the result columns", headerRange)
+	}
+
+	return nil
+}
+
+// append adds the rows after the last row of the table.
+func (r *resultWriter) append(ctx context.Context, rows [][]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	tableRange := r.target.a1(r.target.col, r.target.col+len(r.columns)-1)
+
+	// RAW, so that a value starting with = is not run as a formula
+	path := "/values/" + url.PathEscape(tableRange) + ":append?valueInputOption=RAW&insertDataOption=INSERT_ROWS"
+
+	payload := map[string]any{
+		"range":  tableRange,
+		"values": rows,
+	}
+
+	return r.do(ctx, http.MethodPost, path, payload, nil)
+}
+
+// do sends a request to the spreadsheet and decodes the response into out.
+// The requests are spaced to stay within the write quota. The rate limited
+// and failed requests are retried with a backoff, and a rejected token is
+// fetched again, so that the export resumes where it stopped.
+func (r *resultWriter) do(ctx context.Context, method, path string, payload, out any) error {
+	var body []byte
+
+	if payload != nil {
+		var err error
+
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	backoff := time.Second
+
+	for attempt := 1; ; attempt++ {
+		if err := r.wait(ctx); err != nil {
+			return err
+		}
+
+		resp, err := r.send(ctx, method, path, body)
+		if err != nil {
+			if attempt >= maxRetries || ctx.Err() != nil {
+				return err
+			}
+		} else {
+			status, retryAfter := resp.StatusCode, resp.Header.Get("Retry-After")
+
+			if status < http.StatusMultipleChoices {
+				return decode(resp, out)
+			}
+
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+
+			err = fmt.Errorf("sheets request failed with status %d: %s", status, string(msg))
+
+			if !retryable(status) || attempt >= maxRetries {
+				return err
+			}
+
+			if status == http.StatusUnauthorized {
+				r.ts.Invalidate()
+			}
+
+			if seconds, convErr := strconv.Atoi(retryAfter); convErr == nil && seconds > 0 {
+				backoff = time.Duration(seconds) * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func decode(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (r *resultWriter) send(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+"/"+url.PathEscape(r.cfg.SpreadsheetID)+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := r.ts.Authorize(req); err != nil {
+		return nil, err
+	}
+
+	return r.client.Do(req)
+}
+
+// wait spaces the requests by minRequestInterval.
+func (r *resultWriter) wait(ctx context.Context) error {
+	if d := time.Until(r.lastRequest.Add(minRequestInterval)); d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+
+	r.lastRequest = time.Now()
+
+	return nil
+}
+
+func retryable(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// target is the start of the table: the tab and the cell of its header.
+type target struct {
+	tab string
+	// col is 0 based, row is 1 based as in A1 notation
+	col int
+	row int
+}
+
+var cellRe = regexp.MustCompile(`^([A-Za-z]{1,3})([1-9][0-9]*)$`)
+
+// parseRange parses a tab name, a tab and a cell (Leads!B3 or 'My leads'!B3)
+// or a cell of the first tab.
+func parseRange(s string) (target, error) {
+	ans := target{row: 1}
+
+	tab, cell := s, ""
+
+	if i := strings.LastIndex(s, "!"); i >= 0 {
+		tab, cell = s[:i], s[i+1:]
+	} else if cellRe.MatchString(s) {
+		tab, cell = "", s
+	}
+
+	if len(tab) >= 2 && strings.HasPrefix(tab, "'") && strings.HasSuffix(tab, "'") {
+		tab = strings.ReplaceAll(tab[1:len(tab)-1], "''", "'")
+	}
+
+	ans.tab = tab
+
+	if cell == "" {
+		return ans, nil
+	}
+
+	match := cellRe.FindStringSubmatch(cell)
+	if match == nil {
+		return target{}, fmt.Errorf("invalid sheets range %q, expected a tab, a cell like B3 or both like Leads!B3", s)
+	}
+
+	for _, c := range strings.ToUpper(match[1]) {
+		ans.col = ans.col*26 + int(c-'A') + 1
+	}
+
+	ans.col--
+
+	ans.row, _ = strconv.Atoi(match[2])
+
+	return ans, nil
+}
+
+// a1 returns the range of the header row from the column from to the
+// column to, in A1 notation.
+func (t target) a1(from, to int) string {
+	ans := fmt.Sprintf("%s%d:%s%d", columnName(from), t.row, columnName(to), t.row)
+
+	if t.tab == "" {
+		return ans
+	}
+
+	return "'" + strings.ReplaceAll(t.tab, "'", "''") + "'!" + ans
+}
+
+func columnName(col int) string {
+	var ans []byte
+
+	for col++; col > 0; col = (col - 1) / 26 {
+		ans = append([]byte{byte('A' + (col-1)%26)}, ans...)
+	}
+
+	return string(ans)
+}
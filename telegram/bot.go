@@ -0,0 +1,393 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gosom/google-maps-scraper/notify"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+const (
+	apiURL      = "https://api.telegram.org/bot"
+	pollTimeout = 30
+	// bots can upload files up to 50 MB
+	maxDocumentSize = 50 << 20
+	recentJobs      = 5
+)
+
+const helpText = `Commands:
+/scrape <query> [depth=10] [lang=en] [zoom=15] [max_time=10m] [max_results=0] [email]
+  separate several queries with ;
+/status [job id] - status of a job, or of your last jobs
+/help - this message`
+
+var _ notify.Notifier = (*Bot)(nil)
+
+// Bot lets the authorized Telegram chats create jobs and follow them.
+// The results are sent to the chat that created the job when it is done.
+type Bot struct {
+	token  string
+	chats  map[int64]bool
+	svc    *web.Service
+	client *http.Client
+	offset int64
+}
+
+func New(token string, chats []int64, svc *web.Service) (*Bot, error) {
+	if token == "" {
+		return nil, errors.New("telegram bot token is required")
+	}
+
+	if len(chats) == 0 {
+		return nil, errors.New("at least one authorized telegram chat id is required")
+	}
+
+	ans := Bot{
+		token:  token,
+		chats:  make(map[int64]bool, len(chats)),
+		svc:    svc,
+		client: &http.Client{Timeout: (pollTimeout + 30) * time.Second},
+	}
+
+	for _, id := range chats {
+		ans.chats[id] = true
+	}
+
+	return &ans, nil
+}
+
+type update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *message `json:"message"`
+}
+
+type message struct {
+	Chat struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+	Text string `json:"text"`
+}
+
+// Run polls the updates of the bot until ctx is done.
+func (b *Bot) Run(ctx context.Context) error {
+	for {
+		updates, err := b.getUpdates(ctx)
+
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			log.Printf("telegram: failed to get updates: %v", err)
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(5 * time.Second):
+			}
+
+			continue
+		}
+
+		for i := range updates {
+			b.offset = updates[i].UpdateID + 1
+
+			if updates[i].Message == nil {
+				continue
+			}
+
+			if err := b.handle(ctx, updates[i].Message); err != nil {
+				log.Printf("telegram: %v", err)
+			}
+		}
+	}
+}
+
+func (b *Bot) handle(ctx context.Context, msg *message) error {
+	chat := msg.Chat.ID
+
+	if !b.chats[chat] {
+		return b.send(ctx, chat, "This chat is not authorized to use the scraper.")
+	}
+
+	cmd, args, _ := strings.Cut(strings.TrimSpace(msg.Text), " ")
+
+	// commands of group chats are suffixed with the bot name
+	cmd, _, _ = strings.Cut(cmd, "@")
+
+	switch cmd {
+	case "/scrape":
+		return b.scrape(ctx, chat, args)
+	case "/status":
+		return b.status(ctx, chat, strings.TrimSpace(args))
+	default:
+		return b.send(ctx, chat, helpText)
+	}
+}
+
+func (b *Bot) scrape(ctx context.Context, chat int64, args string) error {
+	job, err := parseScrape(args)
+	if err != nil {
+		return b.send(ctx, chat, err.Error()+"\n\n"+helpText)
+	}
+
+	job.Data.NotifyTelegram = chat
+
+	if err := job.Validate(); err != nil {
+		return b.send(ctx, chat, "Invalid job: "+err.Error())
+	}
+
+	if err := b.svc.Create(ctx, &job); err != nil {
+		return b.send(ctx, chat, "Failed to create the job: "+err.Error())
+	}
+
+	return b.send(ctx, chat, fmt.Sprintf("Job %s created. The results are sent here when it is done.", job.ID))
+}
+
+// parseScrape creates a job from the arguments of /scrape: the queries are
+// separated by ";" and the key=value words are the options of the job.
+func parseScrape(args string) (web.Job, error) {
+	job := web.Job{
+		ID:     uuid.New().String(),
+		Date:   time.Now().UTC(),
+		Status: web.StatusPending,
+		Data: web.JobData{
+			Lang:    "en",
+			Zoom:    15,
+			Depth:   10,
+			MaxTime: 10 * time.Minute,
+		},
+	}
+
+	for _, part := range strings.Split(args, ";") {
+		var words []string
+
+		for _, word := range strings.Fields(part) {
+			key, value, ok := strings.Cut(word, "=")
+
+			var err error
+
+			switch {
+			case word == "email":
+				job.Data.Email = true
+			case !ok:
+				words = append(words, word)
+			case key == "depth":
+				job.Data.Depth, err = strconv.Atoi(value)
+			case key == "lang":
+				job.Data.Lang = value
+			case key == "zoom":
+				job.Data.Zoom, err = strconv.Atoi(value)
+			case key == "max_results":
+				job.Data.MaxResults, err = strconv.Atoi(value)
+			case key == "max_time":
+				job.Data.MaxTime, err = time.ParseDuration(value)
+			default:
+				words = append(words, word)
+			}
+
+			if err != nil {
+				return web.Job{}, fmt.Errorf("invalid %s: %s", key, value)
+			}
+		}
+
+		if len(words) > 0 {
+			job.Data.Keywords = append(job.Data.Keywords, strings.Join(words, " "))
+		}
+	}
+
+	if len(job.Data.Keywords) == 0 {
+		return web.Job{}, errors.New("a query is required")
+	}
+
+	job.Name = job.Data.Keywords[0]
+
+	return job, nil
+}
+
+func (b *Bot) status(ctx context.Context, chat int64, id string) error {
+	if id != "" {
+		job, err := b.svc.Get(ctx, id)
+		if err != nil || job.Data.NotifyTelegram != chat {
+			return b.send(ctx, chat, "Job not found.")
+		}
+
+		return b.send(ctx, chat, notify.Summary(notify.Event{Job: job}))
+	}
+
+	jobs, err := b.svc.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+
+	for i := range jobs {
+		if jobs[i].Data.NotifyTelegram != chat {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %s: %s", jobs[i].ID, jobs[i].Name, jobs[i].Status))
+
+		if len(lines) == recentJobs {
+			break
+		}
+	}
+
+	if len(lines) == 0 {
+		return b.send(ctx, chat, "You have no jobs yet.")
+	}
+
+	return b.send(ctx, chat, strings.Join(lines, "\n"))
+}
+
+// Notify sends the outcome of a job to the chat that created it, with the
+// results file when the job finished.
+func (b *Bot) Notify(ctx context.Context, evt notify.Event) error {
+	chat := evt.Job.Data.NotifyTelegram
+	if !b.chats[chat] || !evt.Done() {
+		return nil
+	}
+
+	if err := b.send(ctx, chat, notify.Summary(evt)); err != nil {
+		return err
+	}
+
+	if evt.Job.Status != web.StatusOK || evt.Job.Data.File != nil {
+		return nil
+	}
+
+	path, err := b.svc.GetCSV(ctx, evt.Job.ID)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Size() > maxDocumentSize {
+		return b.send(ctx, chat, "The results file is too large for Telegram, use the download link.")
+	}
+
+	return b.sendDocument(ctx, chat, path, evt.Job.ID+".csv")
+}
+
+func (b *Bot) getUpdates(ctx context.Context) ([]update, error) {
+	var updates []update
+
+	err := b.call(ctx, "getUpdates", map[string]any{
+		"offset":          b.offset,
+		"timeout":         pollTimeout,
+		"allowed_updates": []string{"message"},
+	}, &updates)
+
+	return updates, err
+}
+
+func (b *Bot) send(ctx context.Context, chat int64, text string) error {
+	return b.call(ctx, "sendMessage", map[string]any{
+		"chat_id": chat,
+		"text":    text,
+	}, nil)
+}
+
+func (b *Bot) sendDocument(ctx context.Context, chat int64, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+
+	if err := mw.WriteField("chat_id", strconv.FormatInt(chat, 10)); err != nil {
+		return err
+	}
+
+	part, err := mw.CreateFormFile("document", name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(part, f); err != nil {
+		return err
+	}
+
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+b.token+"/sendDocument", &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return b.do(req, nil)
+}
+
+func (b *Bot) call(ctx context.Context, method string, params, result any) error {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+b.token+"/"+method, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return b.do(req, result)
+}
+
+func (b *Bot) do(req *http.Request, result any) error {
+	resp, err := b.client.Do(req)
+	if err != nil {
+		// the error contains the url, which contains the token
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return uerr.Err
+		}
+
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	var ans struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&ans); err != nil {
+		return fmt.Errorf("invalid telegram response with status %d: %w", resp.StatusCode, err)
+	}
+
+	if !ans.OK {
+		return fmt.Errorf("telegram api error: %s", ans.Description)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(ans.Result, result)
+}
@@ -0,0 +1,133 @@
+// Package tmplwriter renders the results with a user provided Go
+// text/template, for output formats like the import files of a CRM.
+package tmplwriter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// Funcs are the functions available to the templates, in addition to the
+// builtin ones of text/template.
+var Funcs = template.FuncMap{
+	"join":      func(list []string, sep string) string { return strings.Join(list, sep) },
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"trim":      strings.TrimSpace,
+	"replace":   func(s, old, replacement string) string { return strings.ReplaceAll(s, old, replacement) },
+	"stripPlus": func(s string) string { return strings.ReplaceAll(s, "+", "") },
+	"csv":       csvField,
+	"json":      jsonValue,
+	"default": func(def, s string) string {
+		if s == "" {
+			return def
+		}
+
+		return s
+	},
+}
+
+// Parse reads a template file. The file is rendered for every entry, with
+// the entry as dot. The optional header and footer templates, defined with
+// {{define "header"}}...{{end}}, are rendered once before and after the
+// entries.
+func Parse(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(filepath.Base(path), string(data))
+}
+
+// New parses a template, see Parse.
+func New(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(Funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	return t, nil
+}
+
+// NewResultWriter returns a writer that renders the entries with t. The
+// output is flushed after every result.
+func NewResultWriter(w io.Writer, t *template.Template) scrapemate.ResultWriter {
+	return &resultWriter{w: bufio.NewWriter(w), t: t}
+}
+
+type resultWriter struct {
+	w *bufio.Writer
+	t *template.Template
+}
+
+func (r *resultWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	if err := r.section("header"); err != nil {
+		return err
+	}
+
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = append(entries, data)
+		case []*gmaps.Entry:
+			entries = data
+		default:
+			return errors.New("invalid data type")
+		}
+
+		for i := range entries {
+			if err := r.t.Execute(r.w, entries[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := r.w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if err := r.section("footer"); err != nil {
+		return err
+	}
+
+	return r.w.Flush()
+}
+
+func (r *resultWriter) section(name string) error {
+	if r.t.Lookup(name) == nil {
+		return nil
+	}
+
+	return r.t.ExecuteTemplate(r.w, name, nil)
+}
+
+// csvField quotes a value of a CSV line when it has a comma, a quote or a
+// line break.
+func csvField(s string) string {
+	if !strings.ContainsAny(s, ",\"\r\n") {
+		return s
+	}
+
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func jsonValue(v any) (string, error) {
+	data, err := json.Marshal(v)
+
+	return string(data), err
+}
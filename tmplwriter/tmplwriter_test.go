@@ -0,0 +1,42 @@
+package tmplwriter_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/tmplwriter"
+)
+
+func Test_ResultWriter(t *testing.T) {
+	const text = `{{define "header"}}Company,Phone,Categories
+{{end}}{{csv .Title}},{{stripPlus .Phone}},{{csv (join .Categories ", ")}}
+{{define "footer"}}# end
+{{end}}`
+
+	tmpl, err := tmplwriter.New("crm", text)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: `Joe's "Diner"`, Phone: "+30 123", Categories: []string{"Diner"}}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "Cafe", Categories: []string{"Cafe", "Bakery"}}}}
+
+	close(in)
+
+	require.NoError(t, tmplwriter.NewResultWriter(&buf, tmpl).Run(context.Background(), in))
+	require.Equal(t, "Company,Phone,Categories\n"+
+		`"Joe's ""Diner""",30 123,Diner`+"\n"+
+		`Cafe,,"Cafe, Bakery"`+"\n"+
+		"# end\n", buf.String())
+}
+
+func Test_New(t *testing.T) {
+	_, err := tmplwriter.New("bad", "{{.Title")
+	require.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultStatsDays = 14
+
+// adminOnly allows only requests with the admin token and the given method.
+// The admin API is disabled when no token is configured.
+func (s *Server) adminOnly(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			renderJSON(w, http.StatusNotFound, apiError{
+				Code:    http.StatusNotFound,
+				Message: http.StatusText(http.StatusNotFound),
+			})
+
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			renderJSON(w, http.StatusUnauthorized, apiError{
+				Code:    http.StatusUnauthorized,
+				Message: http.StatusText(http.StatusUnauthorized),
+			})
+
+			return
+		}
+
+		if r.Method != method {
+			renderJSON(w, http.StatusMethodNotAllowed, apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			})
+
+			return
+		}
+
+		next(w, requestWithID(r))
+	}
+}
+
+func (s *Server) adminStats(w http.ResponseWriter, r *http.Request) {
+	days := defaultStatsDays
+
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			renderJSON(w, http.StatusUnprocessableEntity, apiError{
+				Code:    http.StatusUnprocessableEntity,
+				Message: "days must be between 1 and 365",
+			})
+
+			return
+		}
+
+		days = n
+	}
+
+	ans, err := s.svc.Stats(r.Context(), days)
+	if err != nil {
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	renderJSON(w, http.StatusOK, ans)
+}
+
+func (s *Server) adminRequeueJob(w http.ResponseWriter, r *http.Request) {
+	s.adminUpdateJob(w, r, s.svc.Requeue)
+}
+
+func (s *Server) adminFailJob(w http.ResponseWriter, r *http.Request) {
+	s.adminUpdateJob(w, r, s.svc.ForceFail)
+}
+
+func (s *Server) adminUpdateJob(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, id string) (Job, error)) {
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Invalid ID",
+		})
+
+		return
+	}
+
+	job, err := fn(r.Context(), id.String())
+
+	switch {
+	case errors.Is(err, ErrInvalidStatus):
+		renderJSON(w, http.StatusConflict, apiError{
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	case errors.Is(err, sql.ErrNoRows):
+		renderJSON(w, http.StatusNotFound, apiError{
+			Code:    http.StatusNotFound,
+			Message: http.StatusText(http.StatusNotFound),
+		})
+	case err != nil:
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	default:
+		renderJSON(w, http.StatusOK, job)
+	}
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const defaultProductName = "Google Maps Scraper"
+
+var colorRe = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Branding is the look of the pages of the web UI, for the self-hosters that
+// present it to their own clients. The zero value is the default look.
+type Branding struct {
+	// Name replaces the product name in the titles and headers.
+	Name string
+	// LogoURL is shown before the name: an absolute http(s) url or a path
+	// of the server.
+	LogoURL string
+	// PrimaryColor is a hex color like #0b5fff.
+	PrimaryColor string
+	FooterLinks  []Link
+}
+
+// Link is a link of the footer.
+type Link struct {
+	Label string
+	URL   string
+}
+
+// WithBranding sets the look of the web UI, see Branding.Validate.
+func WithBranding(b Branding) ServerOption {
+	return func(s *Server) {
+		s.branding = b
+	}
+}
+
+// ParseFooterLinks parses comma separated links like
+// Privacy|https://example.com/privacy,Support|https://example.com/support.
+func ParseFooterLinks(s string) ([]Link, error) {
+	var ans []Link
+
+	for _, item := range splitList(s) {
+		label, u, ok := strings.Cut(item, "|")
+		if !ok {
+			return nil, fmt.Errorf("invalid footer link %q, expected label|url", item)
+		}
+
+		ans = append(ans, Link{Label: strings.TrimSpace(label), URL: strings.TrimSpace(u)})
+	}
+
+	return ans, nil
+}
+
+// Validate checks the color and the urls.
+func (b *Branding) Validate() error {
+	if b.PrimaryColor != "" && !colorRe.MatchString(b.PrimaryColor) {
+		return fmt.Errorf("invalid primary color %q, expected a hex color like #0b5fff", b.PrimaryColor)
+	}
+
+	if b.LogoURL != "" && !strings.HasPrefix(b.LogoURL, "/") && !isHTTPURL(b.LogoURL) {
+		return fmt.Errorf("invalid logo url %q, expected an http(s) url or a path", b.LogoURL)
+	}
+
+	for _, link := range b.FooterLinks {
+		if link.Label == "" {
+			return errors.New("a footer link has no label")
+		}
+
+		if !isHTTPURL(link.URL) && !strings.HasPrefix(link.URL, "mailto:") {
+			return fmt.Errorf("invalid url %q of the footer link %s", link.URL, link.Label)
+		}
+	}
+
+	return nil
+}
+
+// ProductName returns the name of the product in the pages.
+//
+//nolint:gocritic // this is used in template
+func (b Branding) ProductName() string {
+	if b.Name == "" {
+		return defaultProductName
+	}
+
+	return b.Name
+}
+
+// Custom reports whether the look was changed, the links to the project
+// are not shown then.
+//
+//nolint:gocritic // this is used in template
+func (b Branding) Custom() bool {
+	return b.Name != "" || b.LogoURL != "" || b.PrimaryColor != "" || len(b.FooterLinks) > 0
+}
+
+// logoSource returns the origin of the logo for the Content-Security-Policy
+// of the pages, empty when the logo is served by the server.
+func (b *Branding) logoSource() string {
+	if !isHTTPURL(b.LogoURL) {
+		return ""
+	}
+
+	u, err := url.Parse(b.LogoURL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Scheme + "://" + u.Host
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,69 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_Branding(t *testing.T) {
+	links, err := web.ParseFooterLinks("Privacy|https://acme.example.com/privacy, Support|mailto:help@acme.example.com")
+	require.NoError(t, err)
+	require.Equal(t, []web.Link{
+		{Label: "Privacy", URL: "https://acme.example.com/privacy"},
+		{Label: "Support", URL: "mailto:help@acme.example.com"},
+	}, links)
+
+	_, err = web.ParseFooterLinks("https://acme.example.com/privacy")
+	require.Error(t, err)
+
+	branding := web.Branding{
+		Name:         "Acme Leads",
+		LogoURL:      "https://cdn.acme.example.com/logo.png",
+		PrimaryColor: "#0b5fff",
+		FooterLinks:  links,
+	}
+	require.NoError(t, branding.Validate())
+
+	for _, invalid := range []web.Branding{
+		{PrimaryColor: "red; background: url(x)"},
+		{LogoURL: "javascript:alert(1)"},
+		{FooterLinks: []web.Link{{Label: "Home", URL: "ftp://acme.example.com"}}},
+	} {
+		require.Error(t, invalid.Validate())
+	}
+
+	get := func(srv *web.Server) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+		return rec
+	}
+
+	srv, err := web.New(web.NewService(&fakeRepo{}, t.TempDir()), ":0", web.WithBranding(branding))
+	require.NoError(t, err)
+
+	rec := get(srv)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	require.Contains(t, body, "<title>Acme Leads</title>")
+	require.Contains(t, body, `<img src="https://cdn.acme.example.com/logo.png"`)
+	require.Contains(t, body, "--color-primary: #0b5fff")
+	require.Contains(t, body, `href="https://acme.example.com/privacy"`)
+	require.NotContains(t, body, "Sponsor on GitHub")
+	require.Contains(t, rec.Header().Get("Content-Security-Policy"), "https://cdn.acme.example.com")
+
+	// the default look
+	srv, err = web.New(web.NewService(&fakeRepo{}, t.TempDir()), ":0")
+	require.NoError(t, err)
+
+	body = get(srv).Body.String()
+	require.Contains(t, body, "<title>Google Maps Scraper</title>")
+	require.Contains(t, body, "Sponsor on GitHub")
+	require.NotContains(t, body, "brand-footer")
+}
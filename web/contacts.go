@@ -0,0 +1,117 @@
+package web
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/gosom/google-maps-scraper/contacts"
+)
+
+// downloadContacts sends the places of a job as contacts to import in an
+// address book, see contacts.Formats.
+func (s *Server) downloadContacts(w http.ResponseWriter, r *http.Request, job *Job, format string) {
+	if !slices.Contains(contacts.Formats, format) {
+		http.Error(w, fmt.Sprintf("unknown format %s", format), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	f, ok := s.openResults(w, r, job)
+	if !ok {
+		return
+	}
+
+	defer f.Close()
+
+	cw, err := contacts.NewWriter(w, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s%s", job.ID, format, contacts.Extension(format)))
+	w.Header().Set("Content-Type", contacts.ContentType(format))
+
+	err = eachRecord(f, func(record map[string]string) error {
+		c := contacts.FromRecord(record)
+
+		return cw.Write(&c)
+	})
+	if err == nil {
+		err = cw.Flush()
+	}
+
+	if err != nil {
+		// the response has started, the error can only be logged
+		log.Printf("failed to send the contacts of job %s: %v", job.ID, err)
+	}
+}
+
+// openResults opens the results CSV file of a job, or sends the error.
+func (s *Server) openResults(w http.ResponseWriter, r *http.Request, job *Job) (*os.File, bool) {
+	if job.Data.File != nil {
+		http.Error(w, "the results of the job were moved to the file store, download the csv file", http.StatusUnprocessableEntity)
+
+		return nil, false
+	}
+
+	filePath, err := s.svc.GetCSV(r.Context(), job.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return nil, false
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		http.Error(w, "Failed to open file", http.StatusInternalServerError)
+
+		return nil, false
+	}
+
+	return f, true
+}
+
+// eachRecord calls fn with every row of a results CSV file, keyed by the
+// column names.
+func eachRecord(results io.Reader, fn func(record map[string]string) error) error {
+	reader := csv.NewReader(results)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		row := make(map[string]string, len(header))
+
+		for i := range header {
+			if i < len(record) {
+				row[header[i]] = record[i]
+			}
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	csrfCookie = "csrf_token"
+	csrfHeader = "X-CSRF-Token"
+	csrfField  = "csrf_token"
+)
+
+// csrfToken returns the csrf token of the browser. It is set in a cookie on
+// the first visit and embedded in the pages, which send it back in the
+// X-CSRF-Token header of their requests.
+func csrfToken(w http.ResponseWriter, r *http.Request) string {
+	if c, err := r.Cookie(csrfCookie); err == nil && len(c.Value) == 64 {
+		return c.Value
+	}
+
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+
+	token := hex.EncodeToString(b)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https"),
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	return token
+}
+
+// csrfProtect rejects the requests of the HTML forms that change data when
+// they come from another site or their token does not match the cookie.
+// The JSON API is not protected, it does not use cookies.
+func csrfProtect(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next(w, r)
+
+			return
+		}
+
+		if r.Header.Get("Sec-Fetch-Site") == "cross-site" {
+			http.Error(w, "cross-site request rejected", http.StatusForbidden)
+
+			return
+		}
+
+		cookie, err := r.Cookie(csrfCookie)
+		if err != nil || cookie.Value == "" {
+			http.Error(w, "missing csrf token, reload the page", http.StatusForbidden)
+
+			return
+		}
+
+		token := r.Header.Get(csrfHeader)
+		if token == "" {
+			token = r.FormValue(csrfField)
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(cookie.Value)) != 1 {
+			http.Error(w, "invalid csrf token, reload the page", http.StatusForbidden)
+
+			return
+		}
+
+		next(w, r)
+	}
+}
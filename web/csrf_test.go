@@ -0,0 +1,56 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_CSRF(t *testing.T) {
+	srv, err := web.New(web.NewService(&fakeRepo{}, t.TempDir()), ":0")
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+	require.True(t, cookies[0].HttpOnly)
+	require.Contains(t, rec.Body.String(), cookies[0].Value)
+
+	scrape := func(token string, header http.Header) int {
+		form := url.Values{"name": {"test"}, "maxtime": {"invalid"}}
+
+		req := httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookies[0])
+
+		for k := range header {
+			req.Header.Set(k, header.Get(k))
+		}
+
+		if token != "" {
+			req.Header.Set("X-CSRF-Token", token)
+		}
+
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusForbidden, scrape("", nil))
+	require.Equal(t, http.StatusForbidden, scrape("other", nil))
+	require.Equal(t, http.StatusForbidden, scrape(cookies[0].Value, http.Header{"Sec-Fetch-Site": {"cross-site"}}))
+
+	// past the csrf check the form is validated
+	require.Equal(t, http.StatusUnprocessableEntity, scrape(cookies[0].Value, nil))
+}
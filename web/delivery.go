@@ -0,0 +1,237 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var (
+	// ErrAlreadyReplayed is returned when a failed delivery was delivered
+	// by an earlier replay.
+	ErrAlreadyReplayed = errors.New("the delivery was already replayed")
+	// ErrReplayFailed is returned when the destination failed again.
+	ErrReplayFailed = errors.New("replay failed")
+)
+
+// DeliveryFailure keeps the places that a destination of a job, like
+// kafka or mongodb, did not receive, so that they can be delivered again
+// without scraping them again.
+type DeliveryFailure struct {
+	ID          string     `json:"id"`
+	JobID       string     `json:"job_id"`
+	Destination string     `json:"destination"`
+	Error       string     `json:"error"`
+	Entries     int        `json:"entries"`
+	Attempts    int        `json:"attempts"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReplayedAt  *time.Time `json:"replayed_at,omitempty"`
+	// Payload are the places, as a JSON array.
+	Payload []byte `json:"-"`
+}
+
+// DeliveryRepository stores the failed deliveries.
+type DeliveryRepository interface {
+	CreateDeliveryFailure(context.Context, *DeliveryFailure) error
+	GetDeliveryFailure(context.Context, string) (DeliveryFailure, error)
+	// SelectDeliveryFailures returns the failures that were not replayed,
+	// of the job unless jobID is empty.
+	SelectDeliveryFailures(ctx context.Context, jobID string) ([]DeliveryFailure, error)
+	UpdateDeliveryFailure(context.Context, *DeliveryFailure) error
+}
+
+// WithDeliveries keeps the deliveries that failed in repo.
+func WithDeliveries(repo DeliveryRepository) ServiceOption {
+	return func(s *Service) {
+		s.deliveries = repo
+	}
+}
+
+// Replayer delivers the places of a failed delivery to its destination
+// again.
+type Replayer func(ctx context.Context, failure *DeliveryFailure, entries []*gmaps.Entry) error
+
+// WithReplayer enables the replay of the failed deliveries.
+func WithReplayer(replayer Replayer) ServerOption {
+	return func(s *Server) {
+		s.replayer = replayer
+	}
+}
+
+// RecordDeliveryFailure keeps the entries that destination did not receive
+// for the job.
+func (s *Service) RecordDeliveryFailure(ctx context.Context, jobID, destination string, entries []*gmaps.Entry, deliveryErr error) error {
+	if s.deliveries == nil {
+		return deliveryErr
+	}
+
+	payload, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	failure := DeliveryFailure{
+		ID:          uuid.New().String(),
+		JobID:       jobID,
+		Destination: destination,
+		Error:       deliveryErr.Error(),
+		Entries:     len(entries),
+		Attempts:    1,
+		CreatedAt:   time.Now().UTC(),
+		Payload:     payload,
+	}
+
+	return s.deliveries.CreateDeliveryFailure(ctx, &failure)
+}
+
+// DeliveryFailures returns the failed deliveries that were not replayed,
+// of the job unless jobID is empty.
+func (s *Service) DeliveryFailures(ctx context.Context, jobID string) ([]DeliveryFailure, error) {
+	return s.deliveries.SelectDeliveryFailures(ctx, jobID)
+}
+
+// ReplayDelivery delivers the places of a failed delivery again with
+// replay. The failure is marked as replayed on success and keeps the error
+// of the last attempt otherwise.
+func (s *Service) ReplayDelivery(ctx context.Context, id string, replay Replayer) (DeliveryFailure, error) {
+	failure, err := s.deliveries.GetDeliveryFailure(ctx, id)
+	if err != nil {
+		return DeliveryFailure{}, err
+	}
+
+	if failure.ReplayedAt != nil {
+		return failure, ErrAlreadyReplayed
+	}
+
+	var entries []*gmaps.Entry
+
+	if err := json.Unmarshal(failure.Payload, &entries); err != nil {
+		return failure, err
+	}
+
+	replayErr := replay(ctx, &failure, entries)
+
+	failure.Attempts++
+
+	if replayErr == nil {
+		now := time.Now().UTC()
+		failure.ReplayedAt = &now
+	} else {
+		failure.Error = replayErr.Error()
+	}
+
+	if err := s.deliveries.UpdateDeliveryFailure(ctx, &failure); err != nil {
+		return failure, err
+	}
+
+	if replayErr != nil {
+		return failure, fmt.Errorf("%w: %w", ErrReplayFailed, replayErr)
+	}
+
+	return failure, nil
+}
+
+type apiDeliveryFailures struct {
+	Deliveries []DeliveryFailure `json:"deliveries"`
+}
+
+func (s *Server) deliveriesEnabled(w http.ResponseWriter) bool {
+	if s.svc.deliveries != nil && s.replayer != nil {
+		return true
+	}
+
+	renderJSON(w, http.StatusNotImplemented, apiError{
+		Code:    http.StatusNotImplemented,
+		Message: "the failed deliveries are not kept",
+	})
+
+	return false
+}
+
+// apiFailedDeliveries lists the deliveries to the external destinations
+// that failed and were not replayed.
+func (s *Server) apiFailedDeliveries(w http.ResponseWriter, r *http.Request) {
+	if !s.deliveriesEnabled(w) {
+		return
+	}
+
+	jobID := r.URL.Query().Get("job_id")
+	if jobID != "" {
+		if _, err := uuid.Parse(jobID); err != nil {
+			renderJSON(w, http.StatusUnprocessableEntity, apiError{
+				Code:    http.StatusUnprocessableEntity,
+				Message: "Invalid job_id",
+			})
+
+			return
+		}
+	}
+
+	failures, err := s.svc.DeliveryFailures(r.Context(), jobID)
+	if err != nil {
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if failures == nil {
+		failures = []DeliveryFailure{}
+	}
+
+	renderJSON(w, http.StatusOK, apiDeliveryFailures{Deliveries: failures})
+}
+
+// apiReplayDelivery delivers the places of a failed delivery again.
+func (s *Server) apiReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	if !s.deliveriesEnabled(w) {
+		return
+	}
+
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Invalid ID",
+		})
+
+		return
+	}
+
+	failure, err := s.svc.ReplayDelivery(r.Context(), id.String(), s.replayer)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		renderJSON(w, http.StatusNotFound, apiError{
+			Code:    http.StatusNotFound,
+			Message: http.StatusText(http.StatusNotFound),
+		})
+	case errors.Is(err, ErrAlreadyReplayed):
+		renderJSON(w, http.StatusConflict, apiError{
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	case errors.Is(err, ErrReplayFailed):
+		renderJSON(w, http.StatusBadGateway, apiError{
+			Code:    http.StatusBadGateway,
+			Message: err.Error(),
+		})
+	case err != nil:
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	default:
+		renderJSON(w, http.StatusOK, failure)
+	}
+}
@@ -0,0 +1,128 @@
+package web_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+type fakeDeliveries struct {
+	failures map[string]web.DeliveryFailure
+}
+
+func (f *fakeDeliveries) CreateDeliveryFailure(_ context.Context, failure *web.DeliveryFailure) error {
+	f.failures[failure.ID] = *failure
+
+	return nil
+}
+
+func (f *fakeDeliveries) GetDeliveryFailure(_ context.Context, id string) (web.DeliveryFailure, error) {
+	failure, ok := f.failures[id]
+	if !ok {
+		return web.DeliveryFailure{}, sql.ErrNoRows
+	}
+
+	return failure, nil
+}
+
+func (f *fakeDeliveries) SelectDeliveryFailures(_ context.Context, jobID string) ([]web.DeliveryFailure, error) {
+	var ans []web.DeliveryFailure
+
+	for _, failure := range f.failures {
+		if failure.ReplayedAt == nil && (jobID == "" || failure.JobID == jobID) {
+			ans = append(ans, failure)
+		}
+	}
+
+	return ans, nil
+}
+
+func (f *fakeDeliveries) UpdateDeliveryFailure(_ context.Context, failure *web.DeliveryFailure) error {
+	f.failures[failure.ID] = *failure
+
+	return nil
+}
+
+func Test_ReplayDelivery(t *testing.T) {
+	const jobID = "9c1a2b4e-6f5d-4c3b-8a2e-1d0f9e8c7b6a"
+
+	deliveries := &fakeDeliveries{failures: map[string]web.DeliveryFailure{}}
+	svc := web.NewService(&fakeRepo{}, t.TempDir(), web.WithDeliveries(deliveries))
+
+	entries := []*gmaps.Entry{{Title: "a"}, {Title: "b"}}
+	require.NoError(t, svc.RecordDeliveryFailure(context.Background(), jobID, "kafka", entries, errors.New("connection refused")))
+
+	var delivered []*gmaps.Entry
+
+	down := true
+
+	replayer := func(_ context.Context, failure *web.DeliveryFailure, entries []*gmaps.Entry) error {
+		require.Equal(t, "kafka", failure.Destination)
+
+		if down {
+			return errors.New("still down")
+		}
+
+		delivered = entries
+
+		return nil
+	}
+
+	srv, err := web.New(svc, ":0", web.WithReplayer(replayer))
+	require.NoError(t, err)
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(method, target, http.NoBody))
+
+		return rec
+	}
+
+	var list struct {
+		Deliveries []web.DeliveryFailure `json:"deliveries"`
+	}
+
+	rec := do(http.MethodGet, "/api/v1/deliveries/failed?job_id="+jobID)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	require.Len(t, list.Deliveries, 1)
+	require.Equal(t, 2, list.Deliveries[0].Entries)
+	require.Equal(t, "connection refused", list.Deliveries[0].Error)
+
+	replay := "/api/v1/deliveries/failed/" + list.Deliveries[0].ID + "/replay"
+
+	rec = do(http.MethodPost, replay)
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.Contains(t, rec.Body.String(), "still down")
+
+	down = false
+
+	rec = do(http.MethodPost, replay)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, delivered, 2)
+
+	var failure web.DeliveryFailure
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&failure))
+	require.NotNil(t, failure.ReplayedAt)
+	require.Equal(t, 3, failure.Attempts)
+
+	rec = do(http.MethodPost, replay)
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = do(http.MethodGet, "/api/v1/deliveries/failed")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"deliveries": []}`, rec.Body.String())
+
+	rec = do(http.MethodPost, "/api/v1/deliveries/failed/"+jobID+"/replay")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,173 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var errUnknownColumn = errors.New("unknown column")
+
+// the columns of the widget when the url does not set them
+var defaultEmbedColumns = []string{"title", "category", "address", "phone", "website", "review_rating", "review_count"}
+
+// WithEmbedOrigins sets the origins of the pages that can embed the results
+// widget in an iframe, e.g. https://portal.example.com or
+// https://*.example.com. Any page can embed it when empty.
+func WithEmbedOrigins(origins []string) ServerOption {
+	return func(s *Server) {
+		s.embedOrigins = origins
+	}
+}
+
+type embedMarker struct {
+	Title string  `json:"title"`
+	Lat   float64 `json:"lat"`
+	Lng   float64 `json:"lng"`
+}
+
+type embedPage struct {
+	Name    string
+	Columns []string
+	Rows    [][]embedCell
+	Total   int
+	Markers []embedMarker
+	ShowMap bool
+	Brand   Branding
+}
+
+type embedCell struct {
+	Text string
+	// Link is set for the url columns.
+	Link string
+}
+
+// embedResults renders the places of a shared job as a widget for an
+// iframe: a table of the columns of ?columns= and a map of the places,
+// hidden with ?map=0.
+func (s *Server) embedResults(w http.ResponseWriter, r *http.Request) {
+	job, _, ok := s.sharedJob(w, r)
+	if !ok {
+		return
+	}
+
+	ancestors := "*"
+	if len(s.embedOrigins) > 0 {
+		ancestors = strings.Join(s.embedOrigins, " ")
+	}
+
+	// the widget is framed by other sites, unlike the other pages
+	w.Header().Del("X-Frame-Options")
+	w.Header().Set("Content-Security-Policy",
+		"default-src 'self'; "+
+			"script-src 'self' cdnjs.cloudflare.com 'unsafe-inline'; "+
+			"style-src 'self' 'unsafe-inline' cdnjs.cloudflare.com; "+
+			"img-src 'self' data: *.tile.openstreetmap.org cdnjs.cloudflare.com; "+
+			"connect-src 'self'; "+
+			"frame-ancestors "+ancestors)
+
+	if job.Data.File != nil {
+		http.Error(w, "the results of the job were moved to the file store, use the download link", http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	tmpl, ok := s.tmpl["static/templates/embed.html"]
+	if !ok {
+		http.Error(w, "missing tpl", http.StatusInternalServerError)
+
+		return
+	}
+
+	page := embedPage{
+		Name:    job.Name,
+		Columns: splitList(r.URL.Query().Get("columns")),
+		ShowMap: r.URL.Query().Get("map") != "0",
+		Brand:   s.branding,
+	}
+
+	explicit := len(page.Columns) > 0
+
+	filePath, err := s.svc.GetCSV(r.Context(), job.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		http.Error(w, "Failed to open file", http.StatusInternalServerError)
+
+		return
+	}
+
+	defer f.Close()
+
+	err = eachRecord(f, func(record map[string]string) error {
+		if page.Total == 0 && explicit {
+			for _, c := range page.Columns {
+				if _, ok := record[c]; !ok {
+					return fmt.Errorf("%w %s", errUnknownColumn, c)
+				}
+			}
+		} else if page.Total == 0 {
+			// the default columns that the results have, the older
+			// results files have fewer columns
+			for _, c := range defaultEmbedColumns {
+				if _, ok := record[c]; ok {
+					page.Columns = append(page.Columns, c)
+				}
+			}
+		}
+
+		page.Total++
+
+		if len(page.Rows) >= sharedPageRows {
+			return nil
+		}
+
+		row := make([]embedCell, len(page.Columns))
+
+		for i, c := range page.Columns {
+			row[i].Text = record[c]
+
+			if slices.Contains([]string{"website", "link", "reviews_link", "menu"}, c) {
+				row[i].Link = record[c]
+			}
+		}
+
+		page.Rows = append(page.Rows, row)
+
+		lat, latErr := strconv.ParseFloat(record["latitude"], 64)
+		lng, lngErr := strconv.ParseFloat(record["longitude"], 64)
+
+		if latErr == nil && lngErr == nil && (lat != 0 || lng != 0) {
+			page.Markers = append(page.Markers, embedMarker{Title: record["title"], Lat: lat, Lng: lng})
+		}
+
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, errUnknownColumn):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+
+		return
+	case err != nil:
+		http.Error(w, "Failed to read the results", http.StatusInternalServerError)
+
+		return
+	}
+
+	page.ShowMap = page.ShowMap && len(page.Markers) > 0
+
+	if err := tmpl.Execute(w, page); err != nil {
+		log.Printf("failed to render the widget of job %s: %v", job.ID, err)
+	}
+}
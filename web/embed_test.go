@@ -0,0 +1,67 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_Embed(t *testing.T) {
+	const jobID = "9c1a2b4e-6f5d-4c3b-8a2e-1d0f9e8c7b6a"
+
+	dataFolder := t.TempDir()
+
+	results := "title,phone,website,latitude,longitude\nJoe's,+1 555,https://joes.example.com,39.78,-89.65\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dataFolder, jobID+".csv"), []byte(results), 0o600))
+
+	repo := &jobsRepo{fakeRepo{jobs: []web.Job{{ID: jobID, Name: "Diners", Status: web.StatusOK}}}}
+	svc := web.NewService(repo, dataFolder, web.WithShares(&fakeShares{shares: map[string]web.Share{}}))
+
+	srv, err := web.New(svc, ":0", web.WithEmbedOrigins([]string{"https://portal.example.com"}))
+	require.NoError(t, err)
+
+	get := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(method, path, http.NoBody))
+
+		return rec
+	}
+
+	rec := get(http.MethodPost, "/api/v1/jobs/"+jobID+"/shares")
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var share web.Share
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &share))
+
+	token := strings.TrimPrefix(share.URL, "http://example.com/shared/")
+
+	rec = get(http.MethodGet, "/embed/"+token+"?columns=title,phone")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get("X-Frame-Options"))
+	require.Contains(t, rec.Header().Get("Content-Security-Policy"), "frame-ancestors https://portal.example.com")
+	require.Contains(t, rec.Body.String(), "<th>phone</th>")
+	require.NotContains(t, rec.Body.String(), "<th>website</th>")
+	require.Contains(t, rec.Body.String(), `"lat":39.78`)
+
+	rec = get(http.MethodGet, "/embed/"+token+"?columns=title,secret")
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = get(http.MethodGet, "/embed/"+token+"?map=0")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotContains(t, rec.Body.String(), "leaflet")
+
+	// the other pages cannot be framed
+	rec = get(http.MethodGet, "/shared/"+token)
+	require.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+
+	rec = get(http.MethodGet, "/embed/not-a-token")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
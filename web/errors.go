@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrNotFound      = errors.New("not found")
 	ErrAlreadyExists = errors.New("already exists")
+	ErrInvalidStatus = errors.New("invalid job status")
 )
@@ -0,0 +1,157 @@
+package web
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/eta"
+)
+
+// WithCapacity sets the number of jobs that run at the same time, which
+// the queue positions are estimated with. It is 1 by default.
+func WithCapacity(capacity func() int) ServiceOption {
+	return func(s *Service) {
+		s.capacity = capacity
+	}
+}
+
+type estimate struct {
+	position   int
+	start      time.Time
+	completion time.Time
+}
+
+// Model returns the model of the durations of the jobs, the rolling
+// average of the timings of the last completed jobs.
+func (s *Service) Model(ctx context.Context) (eta.Model, error) {
+	all, err := s.All(ctx)
+	if err != nil {
+		return eta.Model{}, err
+	}
+
+	return model(all), nil
+}
+
+// Estimate sets the queue position and the estimated start and completion
+// times of the pending and working jobs. The pending jobs start in the
+// order they were created once a job slot is free, and the duration of a
+// job is estimated with the model of the last completed jobs.
+func (s *Service) Estimate(ctx context.Context, jobs []Job) error {
+	all, err := s.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	etas := estimateQueue(all, s.capacity(), time.Now().UTC())
+
+	for i := range jobs {
+		e, ok := etas[jobs[i].ID]
+		if !ok {
+			continue
+		}
+
+		jobs[i].QueuePosition = e.position
+		jobs[i].EstimatedStartAt = &e.start
+		jobs[i].EstimatedCompletionAt = &e.completion
+	}
+
+	return nil
+}
+
+// estimateQueue simulates the slots: each pending job takes the slot that
+// frees up first.
+func estimateQueue(all []Job, capacity int, now time.Time) map[string]estimate {
+	m := model(all)
+
+	duration := func(job *Job) time.Duration {
+		ans := m.Estimate(len(job.Data.Keywords), job.Data.Depth, len(job.Data.Places), job.Data.MaxResults)
+
+		if job.Data.MaxTime > 0 {
+			ans = min(ans, job.Data.MaxTime)
+		}
+
+		return ans
+	}
+
+	var (
+		ans     = map[string]estimate{}
+		free    []time.Time
+		pending []*Job
+	)
+
+	for i := range all {
+		job := &all[i]
+
+		switch job.Status {
+		case StatusWorking:
+			started := job.StartedAt
+			if started.IsZero() {
+				started = now
+			}
+
+			// a job that runs longer than estimated ends any moment
+			completion := started.Add(duration(job))
+			if completion.Before(now) {
+				completion = now
+			}
+
+			ans[job.ID] = estimate{start: started, completion: completion}
+			free = append(free, completion)
+		case StatusPending:
+			pending = append(pending, job)
+		}
+	}
+
+	capacity = max(capacity, 1)
+
+	slices.SortFunc(free, func(a, b time.Time) int { return a.Compare(b) })
+
+	// with more working jobs than slots a slot frees up when enough of
+	// them complete
+	if len(free) > capacity {
+		free = free[len(free)-capacity:]
+	}
+
+	for len(free) < capacity {
+		free = append(free, now)
+	}
+
+	slices.SortStableFunc(pending, func(a, b *Job) int { return a.Date.Compare(b.Date) })
+
+	for i, job := range pending {
+		slot := 0
+
+		for j := range free {
+			if free[j].Before(free[slot]) {
+				slot = j
+			}
+		}
+
+		start := free[slot]
+		completion := start.Add(duration(job))
+		free[slot] = completion
+
+		ans[job.ID] = estimate{position: i + 1, start: start, completion: completion}
+	}
+
+	return ans
+}
+
+// model returns the model of the timings of the completed jobs of all,
+// the most recent first.
+func model(all []Job) eta.Model {
+	var timings []eta.Timing
+
+	for i := range all {
+		if len(timings) == eta.Window {
+			break
+		}
+
+		if all[i].Status == StatusOK && all[i].Stats != nil && all[i].Stats.Timing != nil {
+			timings = append(timings, *all[i].Stats.Timing)
+		}
+	}
+
+	return eta.New(timings)
+}
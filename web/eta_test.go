@@ -0,0 +1,77 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/eta"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_Estimate(t *testing.T) {
+	now := time.Now().UTC()
+	timing := eta.NewTiming(2, 2, 10, 0, 200*time.Second, 200*time.Second)
+
+	repo := &fakeRepo{jobs: []web.Job{
+		{
+			ID:     "done",
+			Status: web.StatusOK,
+			Data:   web.JobData{Keywords: []string{"a", "b"}},
+			Stats:  &web.JobStats{Timing: &timing},
+		},
+		{
+			ID:        "working",
+			Status:    web.StatusWorking,
+			StartedAt: now.Add(-50 * time.Second),
+			Data:      web.JobData{Keywords: []string{"a"}},
+		},
+		{
+			ID:     "second",
+			Status: web.StatusPending,
+			Date:   now.Add(-time.Second),
+			Data:   web.JobData{Keywords: []string{"a"}, MaxTime: time.Minute},
+		},
+		{
+			ID:     "first",
+			Status: web.StatusPending,
+			Date:   now.Add(-time.Minute),
+			Data:   web.JobData{Keywords: []string{"a", "b"}},
+		},
+	}}
+
+	svc := web.NewService(repo, t.TempDir())
+
+	jobs, err := svc.All(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, svc.Estimate(context.Background(), jobs))
+
+	byID := map[string]web.Job{}
+	for _, job := range jobs {
+		byID[job.ID] = job
+	}
+
+	require.Zero(t, byID["done"].QueuePosition)
+	require.Nil(t, byID["done"].EstimatedCompletionAt)
+
+	// the jobs take 100 seconds per keyword and run one at a time
+	require.Zero(t, byID["working"].QueuePosition)
+	require.WithinDuration(t, now.Add(50*time.Second), *byID["working"].EstimatedCompletionAt, time.Second)
+
+	require.Equal(t, 1, byID["first"].QueuePosition)
+	require.WithinDuration(t, now.Add(50*time.Second), *byID["first"].EstimatedStartAt, time.Second)
+	require.WithinDuration(t, now.Add(250*time.Second), *byID["first"].EstimatedCompletionAt, time.Second)
+
+	// the max time caps the duration
+	require.Equal(t, 2, byID["second"].QueuePosition)
+	require.WithinDuration(t, now.Add(250*time.Second), *byID["second"].EstimatedStartAt, time.Second)
+	require.WithinDuration(t, now.Add(310*time.Second), *byID["second"].EstimatedCompletionAt, time.Second)
+
+	// with two slots the first pending job starts now
+	svc = web.NewService(repo, t.TempDir(), web.WithCapacity(func() int { return 2 }))
+
+	require.NoError(t, svc.Estimate(context.Background(), jobs))
+	require.WithinDuration(t, now, *jobs[3].EstimatedStartAt, time.Second)
+}
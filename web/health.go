@@ -0,0 +1,231 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The probes of the health checks. A failed liveness check means that the
+// server must be restarted, a failed readiness check that it should not
+// receive jobs for now.
+const (
+	ProbeLiveness  = "liveness"
+	ProbeReadiness = "readiness"
+)
+
+const healthCheckTimeout = 10 * time.Second
+
+// HealthCheck checks a dependency of the server. Check returns details of
+// the dependency, e.g. the depth of a queue, and an error when it fails.
+type HealthCheck struct {
+	Name  string
+	Probe string
+	Check func(ctx context.Context) (any, error)
+}
+
+// HealthStatus is the result of a HealthCheck.
+type HealthStatus struct {
+	Status  string `json:"status"`
+	Probe   string `json:"probe"`
+	Details any    `json:"details,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Health is the response of the health endpoint. Live is false when a
+// liveness check fails and Ready when any check fails.
+type Health struct {
+	Status string                  `json:"status"`
+	Live   bool                    `json:"live"`
+	Ready  bool                    `json:"ready"`
+	Checks map[string]HealthStatus `json:"checks"`
+}
+
+// WithHealthChecks adds checks to the health endpoint, besides the ones
+// of the database and the jobs.
+func WithHealthChecks(checks ...HealthCheck) ServerOption {
+	return func(s *Server) {
+		s.healthChecks = append(s.healthChecks, checks...)
+	}
+}
+
+// JobsHealth are the details of the jobs health check.
+type JobsHealth struct {
+	Pending       int        `json:"pending"`
+	Working       int        `json:"working"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+}
+
+// Health returns the number of pending and working jobs and the time the
+// last successful job completed.
+func (s *Service) Health(ctx context.Context) (JobsHealth, error) {
+	var ans JobsHealth
+
+	pending, err := s.repo.Select(ctx, SelectParams{Status: StatusPending})
+	if err != nil {
+		return ans, err
+	}
+
+	working, err := s.repo.Select(ctx, SelectParams{Status: StatusWorking})
+	if err != nil {
+		return ans, err
+	}
+
+	ans.Pending = len(pending)
+	ans.Working = len(working)
+
+	last, err := s.repo.Select(ctx, SelectParams{Status: StatusOK, Limit: 1})
+	if err != nil {
+		return ans, err
+	}
+
+	// the heartbeat of a finished job is the last one before it completed
+	if len(last) > 0 && !last[0].HeartbeatAt.IsZero() {
+		ans.LastSuccessAt = &last[0].HeartbeatAt
+	}
+
+	return ans, nil
+}
+
+func (s *Server) checks() []HealthCheck {
+	return append([]HealthCheck{
+		{
+			Name:  "database",
+			Probe: ProbeLiveness,
+			Check: func(ctx context.Context) (any, error) {
+				_, err := s.svc.repo.Select(ctx, SelectParams{Limit: 1})
+
+				return nil, err
+			},
+		},
+		{
+			Name:  "jobs",
+			Probe: ProbeReadiness,
+			Check: func(ctx context.Context) (any, error) {
+				return s.svc.Health(ctx)
+			},
+		},
+	}, s.healthChecks...)
+}
+
+// checkHealth runs the checks of the probe, or all of them when the probe
+// is empty, in parallel.
+func (s *Server) checkHealth(ctx context.Context, probe string) Health {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	ans := Health{
+		Status: "ok",
+		Live:   true,
+		Ready:  true,
+		Checks: map[string]HealthStatus{},
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, check := range s.checks() {
+		if probe != "" && check.Probe != probe {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			details, err := check.Check(ctx)
+
+			status := HealthStatus{Status: "ok", Probe: check.Probe, Details: details}
+			if err != nil {
+				status.Status = "error"
+				status.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			ans.Checks[check.Name] = status
+
+			if err != nil {
+				ans.Ready = false
+				ans.Live = ans.Live && check.Probe != ProbeLiveness
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	switch {
+	case !ans.Live:
+		ans.Status = "down"
+	case !ans.Ready:
+		ans.Status = "not_ready"
+	}
+
+	return ans
+}
+
+// health responds 503 when the server is not live and 200 otherwise, with
+// the status of every check.
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	ans := s.checkHealth(r.Context(), "")
+
+	code := http.StatusOK
+	if !ans.Live {
+		code = http.StatusServiceUnavailable
+	}
+
+	renderJSON(w, code, ans)
+}
+
+// healthz is the liveness probe, it responds 200 as long as the process
+// serves requests.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	renderJSON(w, http.StatusOK, Health{
+		Status: "ok",
+		Live:   true,
+		Checks: map[string]HealthStatus{},
+	})
+}
+
+// readyz is the readiness probe, it responds 503 until every check passes,
+// e.g. while the browsers are installed.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	ans := s.checkHealth(r.Context(), "")
+
+	code := http.StatusOK
+	if !ans.Ready {
+		code = http.StatusServiceUnavailable
+	}
+
+	renderJSON(w, code, ans)
+}
+
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet {
+		return true
+	}
+
+	renderJSON(w, http.StatusMethodNotAllowed, apiError{
+		Code:    http.StatusMethodNotAllowed,
+		Message: "Method not allowed",
+	})
+
+	return false
+}
@@ -0,0 +1,102 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+type fakeRepo struct {
+	jobs []web.Job
+	err  error
+}
+
+func (f *fakeRepo) Get(context.Context, string) (web.Job, error) { return web.Job{}, f.err }
+func (f *fakeRepo) Create(context.Context, *web.Job) error       { return f.err }
+func (f *fakeRepo) Delete(context.Context, string) error         { return f.err }
+func (f *fakeRepo) Update(context.Context, *web.Job) error       { return f.err }
+func (f *fakeRepo) Heartbeat(context.Context, string) error      { return f.err }
+
+func (f *fakeRepo) Select(_ context.Context, params web.SelectParams) ([]web.Job, error) {
+	var ans []web.Job
+
+	for _, job := range f.jobs {
+		if params.Status == "" || job.Status == params.Status {
+			ans = append(ans, job)
+		}
+	}
+
+	return ans, f.err
+}
+
+func Test_Health(t *testing.T) {
+	finished := time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	repo := &fakeRepo{jobs: []web.Job{
+		{ID: "1", Status: web.StatusPending},
+		{ID: "2", Status: web.StatusPending},
+		{ID: "3", Status: web.StatusWorking},
+		{ID: "4", Status: web.StatusOK, HeartbeatAt: finished},
+	}}
+
+	browserErr := errors.New("browser not installed")
+
+	srv, err := web.New(web.NewService(repo, t.TempDir()), ":0", web.WithHealthChecks(web.HealthCheck{
+		Name:  "browser",
+		Probe: web.ProbeReadiness,
+		Check: func(context.Context) (any, error) { return nil, browserErr },
+	}))
+	require.NoError(t, err)
+
+	get := func(path string) (int, web.Health) {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+
+		var health web.Health
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+
+		return rec.Code, health
+	}
+
+	// a failed readiness check keeps the server live
+	code, health := get("/health")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "not_ready", health.Status)
+	require.True(t, health.Live)
+	require.False(t, health.Ready)
+	require.Equal(t, "ok", health.Checks["database"].Status)
+	require.Equal(t, browserErr.Error(), health.Checks["browser"].Error)
+
+	jobs, err := json.Marshal(health.Checks["jobs"].Details)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"pending":2,"working":1,"last_success_at":"2025-03-07T12:00:00Z"}`, string(jobs))
+
+	code, health = get("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "not_ready", health.Status)
+
+	browserErr = nil
+
+	code, _ = get("/readyz")
+	require.Equal(t, http.StatusOK, code)
+
+	repo.err = errors.New("database is locked")
+
+	code, health = get("/health")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "down", health.Status)
+	require.False(t, health.Live)
+
+	// the liveness probe only needs the process to be up
+	code, health = get("/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, health.Live)
+}
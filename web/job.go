@@ -2,8 +2,11 @@ package web
 
 import (
 	"context"
-	"errors"
 	"time"
+
+	"github.com/gosom/google-maps-scraper/eta"
+	"github.com/gosom/google-maps-scraper/filter"
+	"github.com/gosom/google-maps-scraper/gmaps"
 )
 
 var jobs []Job
@@ -16,8 +19,12 @@ const (
 )
 
 type SelectParams struct {
-	Status string
-	Limit  int
+	Status          string
+	Limit           int
+	HeartbeatBefore time.Time
+	// OldestFirst orders the jobs by creation date ascending instead of
+	// descending.
+	OldestFirst bool
 }
 
 type JobRepository interface {
@@ -26,78 +33,149 @@ type JobRepository interface {
 	Delete(context.Context, string) error
 	Select(context.Context, SelectParams) ([]Job, error)
 	Update(context.Context, *Job) error
+	Heartbeat(context.Context, string) error
 }
 
 type Job struct {
-	ID     string
-	Name   string
-	Date   time.Time
-	Status string
-	Data   JobData
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Date        time.Time `json:"date"`
+	Status      string    `json:"status"`
+	Data        JobData   `json:"data"`
+	Attempts    int       `json:"attempts"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+	StartedAt   time.Time `json:"started_at"`
+	Stats       *JobStats `json:"stats,omitempty"`
+	// QueuePosition, EstimatedStartAt and EstimatedCompletionAt are set
+	// for the pending and working jobs by Service.Estimate.
+	QueuePosition         int        `json:"queue_position,omitempty"`
+	EstimatedStartAt      *time.Time `json:"estimated_start_at,omitempty"`
+	EstimatedCompletionAt *time.Time `json:"estimated_completion_at,omitempty"`
 }
 
-func (j *Job) Validate() error {
-	if j.ID == "" {
-		return errors.New("missing id")
-	}
+// JobStats summarize the results of a job. They are computed when
+// the job completes. FilteredOut are the places dropped by the filters,
+// SeedRetries the retries of the failed searches and SeedsFailed the
+// searches that failed without retries left.
+type JobStats struct {
+	PlacesFound     int            `json:"places_found"`
+	UniquePlaces    int            `json:"unique_places"`
+	EmailsFound     int            `json:"emails_found"`
+	ReviewsFetched  int            `json:"reviews_fetched"`
+	ImagesFetched   int            `json:"images_fetched"`
+	AverageRating   float64        `json:"average_rating"`
+	KeywordResults  map[string]int `json:"keyword_results"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	FilteredOut     int            `json:"filtered_out"`
+	SeedRetries     int            `json:"seed_retries"`
+	SeedsFailed     int            `json:"seeds_failed"`
+	PeakMemoryMB    int            `json:"peak_memory_mb"`
+	PeakBrowsers    int            `json:"peak_browsers"`
+	MemoryLimited   bool           `json:"memory_limited,omitempty"`
+	Timing          *eta.Timing    `json:"timing,omitempty"`
+}
 
-	if j.Name == "" {
-		return errors.New("missing name")
+// FilterOptions returns the options that drop places before they are
+// written.
+func (d *JobData) FilterOptions() *filter.Options {
+	return &filter.Options{
+		Expr:              d.Filter,
+		IncludeCategories: d.IncludeCategories,
+		ExcludeCategories: d.ExcludeCategories,
+		Quality: filter.Quality{
+			RequirePhone:   d.RequirePhone,
+			RequireWebsite: d.RequireWebsite,
+			MinRating:      d.MinRating,
+			MinReviews:     d.MinReviews,
+		},
+		VerifyAddress: d.VerifyAddress,
+		MaxResults:    d.MaxResults,
 	}
+}
 
-	if j.Status == "" {
-		return errors.New("missing status")
-	}
+// Tables returns the secondary outputs of the job.
+func (d *JobData) Tables() []gmaps.Table {
+	var tables []gmaps.Table
 
-	if j.Date.IsZero() {
-		return errors.New("missing date")
+	if d.PopularTimesNormalized {
+		tables = append(tables, gmaps.PopularTimesTable)
 	}
 
-	if err := j.Data.Validate(); err != nil {
-		return err
+	if d.ReviewsNormalized {
+		tables = append(tables, gmaps.ReviewsTable)
 	}
 
-	return nil
+	return tables
 }
 
-type JobData struct {
-	Keywords []string      `json:"keywords"`
-	Lang     string        `json:"lang"`
-	Zoom     int           `json:"zoom"`
-	Lat      string        `json:"lat"`
-	Lon      string        `json:"lon"`
-	FastMode bool          `json:"fast_mode"`
-	Radius   int           `json:"radius"`
-	Depth    int           `json:"depth"`
-	Email    bool          `json:"email"`
-	MaxTime  time.Duration `json:"max_time"`
-	Proxies  []string      `json:"proxies"`
-}
+func (j *Job) Validate() error {
+	var verr ValidationError
 
-func (d *JobData) Validate() error {
-	if len(d.Keywords) == 0 {
-		return errors.New("missing keywords")
+	if j.ID == "" {
+		verr.add("id", "is required")
 	}
 
-	if d.Lang == "" {
-		return errors.New("missing lang")
+	if j.Name == "" {
+		verr.add("name", "is required")
 	}
 
-	if len(d.Lang) != 2 {
-		return errors.New("invalid lang")
+	if j.Status == "" {
+		verr.add("status", "is required")
 	}
 
-	if d.Depth == 0 {
-		return errors.New("missing depth")
+	if j.Date.IsZero() {
+		verr.add("date", "is required")
 	}
 
-	if d.MaxTime == 0 {
-		return errors.New("missing max time")
-	}
+	j.Data.validate(&verr)
 
-	if d.FastMode && (d.Lat == "" || d.Lon == "") {
-		return errors.New("missing geo coordinates")
-	}
+	return verr.errOrNil()
+}
+
+type JobData struct {
+	Keywords               []string      `json:"keywords"`
+	Places                 []string      `json:"places,omitempty"`
+	Lang                   string        `json:"lang"`
+	Zoom                   int           `json:"zoom"`
+	Lat                    string        `json:"lat"`
+	Lon                    string        `json:"lon"`
+	FastMode               bool          `json:"fast_mode"`
+	Radius                 int           `json:"radius"`
+	Tiles                  bool          `json:"tiles,omitempty"`
+	Depth                  int           `json:"depth"`
+	Email                  bool          `json:"email"`
+	MaxTime                time.Duration `json:"max_time"`
+	Proxies                []string      `json:"proxies"`
+	MaxResults             int           `json:"max_results"`
+	Localize               bool          `json:"localize"`
+	ResultsLang            string        `json:"results_lang"`
+	ResultsEnglish         bool          `json:"results_english"`
+	Country                string        `json:"country"`
+	ExcludeSponsored       bool          `json:"exclude_sponsored"`
+	Filter                 string        `json:"filter"`
+	IncludeCategories      []string      `json:"include_categories"`
+	ExcludeCategories      []string      `json:"exclude_categories"`
+	RequirePhone           bool          `json:"require_phone"`
+	RequireWebsite         bool          `json:"require_website"`
+	MinRating              float64       `json:"min_rating"`
+	MinReviews             int           `json:"min_reviews"`
+	VerifyAddress          bool          `json:"verify_address"`
+	OrderBy                string        `json:"order_by,omitempty"`
+	PopularTimesNormalized bool          `json:"popular_times_normalized,omitempty"`
+	ReviewsNormalized      bool          `json:"reviews_normalized,omitempty"`
+	NotifyEmail            string        `json:"notify_email"`
+	NotifyTelegram         int64         `json:"notify_telegram,omitempty"`
+	Profile                string        `json:"profile,omitempty"`
+	File                   *JobFile      `json:"file,omitempty"`
+	// Revision counts the rewrites of the results file, e.g. by
+	// Service.PurgePlace. The result cursors of an older revision skip
+	// the rows instead of seeking to their offset.
+	Revision int `json:"revision,omitempty"`
+}
 
-	return nil
+// JobFile describes a results file that was moved to the file store.
+type JobFile struct {
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
 }
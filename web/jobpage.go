@@ -0,0 +1,185 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+)
+
+// the places of the preview of the job page
+const previewRows = 10
+
+var errEnoughRecords = errors.New("enough records")
+
+type jobEvent struct {
+	At    time.Time
+	Text  string
+	Error bool
+}
+
+type jobPageData struct {
+	Job     Job
+	Events  []jobEvent
+	Preview []sharedPlace
+	CSRF    string
+	Brand   Branding
+}
+
+// jobPage renders the page of a job: its parameters, statistics, events, a
+// preview of the results and the downloads.
+func (s *Server) jobPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		http.Error(w, "Invalid ID", http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	tmpl, ok := s.tmpl["static/templates/job.html"]
+	if !ok {
+		http.Error(w, "missing tpl", http.StatusInternalServerError)
+
+		return
+	}
+
+	job, err := s.svc.Get(r.Context(), id.String())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	jobs := []Job{job}
+
+	if err := s.svc.Estimate(r.Context(), jobs); err != nil {
+		log.Printf("failed to estimate the queue: %v", err)
+	}
+
+	data := jobPageData{
+		Job:    jobs[0],
+		Events: s.jobEvents(r, &jobs[0]),
+		CSRF:   csrfToken(w, r),
+		Brand:  s.branding,
+	}
+
+	if job.Status == StatusOK && job.Data.File == nil {
+		data.Preview, err = s.preview(r, job.ID)
+		if err != nil {
+			log.Printf("failed to read the preview of job %s: %v", job.ID, err)
+		}
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Printf("failed to render the page of job %s: %v", job.ID, err)
+	}
+}
+
+// jobEvents returns what happened to the job, in order: the times it was
+// created, started and finished, and the deliveries that failed.
+func (s *Server) jobEvents(r *http.Request, job *Job) []jobEvent {
+	ans := []jobEvent{{At: job.Date, Text: "Created"}}
+
+	if !job.StartedAt.IsZero() {
+		text := "Started"
+		if job.Attempts > 1 {
+			text = fmt.Sprintf("Started, attempt %d", job.Attempts)
+		}
+
+		ans = append(ans, jobEvent{At: job.StartedAt, Text: text})
+	}
+
+	switch job.Status {
+	case StatusWorking:
+		if !job.HeartbeatAt.IsZero() {
+			ans = append(ans, jobEvent{At: job.HeartbeatAt, Text: "Last heartbeat"})
+		}
+	case StatusOK, StatusFailed:
+		at := job.HeartbeatAt
+		if job.Stats != nil && !job.StartedAt.IsZero() {
+			at = job.StartedAt.Add(time.Duration(job.Stats.DurationSeconds * float64(time.Second)))
+		}
+
+		if job.Status == StatusOK {
+			text := "Finished"
+			if job.Stats != nil {
+				text = fmt.Sprintf("Finished with %d places", job.Stats.UniquePlaces)
+			}
+
+			ans = append(ans, jobEvent{At: at, Text: text})
+		} else {
+			ans = append(ans, jobEvent{At: at, Text: "Failed", Error: true})
+		}
+	}
+
+	if s.svc.deliveries != nil {
+		failures, err := s.svc.DeliveryFailures(r.Context(), job.ID)
+		if err != nil {
+			log.Printf("failed to read the failed deliveries of job %s: %v", job.ID, err)
+		}
+
+		for i := range failures {
+			ans = append(ans, jobEvent{
+				At:    failures[i].CreatedAt,
+				Text:  fmt.Sprintf("Delivery of %d places to %s failed: %s", failures[i].Entries, failures[i].Destination, failures[i].Error),
+				Error: true,
+			})
+		}
+	}
+
+	sort.SliceStable(ans, func(i, j int) bool {
+		return ans[i].At.Before(ans[j].At)
+	})
+
+	return ans
+}
+
+// preview returns the first places of the results of a job.
+func (s *Server) preview(r *http.Request, jobID string) ([]sharedPlace, error) {
+	filePath, err := s.svc.GetCSV(r.Context(), jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var ans []sharedPlace
+
+	err = eachRecord(f, func(record map[string]string) error {
+		ans = append(ans, sharedPlace{
+			Title:    record["title"],
+			Category: record["category"],
+			Address:  record["address"],
+			Phone:    record["phone"],
+			Website:  record["website"],
+			Rating:   record["review_rating"],
+			Reviews:  record["review_count"],
+			Link:     record["link"],
+		})
+
+		if len(ans) == previewRows {
+			return errEnoughRecords
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEnoughRecords) {
+		return nil, err
+	}
+
+	return ans, nil
+}
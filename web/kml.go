@@ -0,0 +1,48 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gosom/google-maps-scraper/kml"
+)
+
+// downloadKML sends the places of a job as a KML document or a KMZ archive,
+// to load in Google Earth or Google My Maps.
+func (s *Server) downloadKML(w http.ResponseWriter, r *http.Request, job *Job, format string) {
+	f, ok := s.openResults(w, r, job)
+	if !ok {
+		return
+	}
+
+	defer f.Close()
+
+	kw, err := kml.NewWriter(w, format, job.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	err = eachRecord(f, func(record map[string]string) error {
+		p := kml.FromRecord(record)
+
+		kw.Add(&p)
+
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "Failed to read the results", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s%s", job.ID, kml.Extension(format)))
+	w.Header().Set("Content-Type", kml.ContentType(format))
+
+	if err := kw.Close(); err != nil {
+		// the response has started, the error can only be logged
+		log.Printf("failed to send the map of job %s: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	corsMethods = "GET, POST, DELETE, OPTIONS"
+	corsHeaders = "Authorization, Content-Type, X-API-Key, X-CSRF-Token"
+	corsMaxAge  = "600"
+)
+
+// origin is an allowed origin. A wildcard origin matches the subdomains of
+// host, at any depth, but not host itself.
+type origin struct {
+	scheme   string
+	host     string
+	wildcard bool
+}
+
+func (o origin) match(scheme, host string) bool {
+	if scheme != o.scheme {
+		return false
+	}
+
+	if o.wildcard {
+		return strings.HasSuffix(host, "."+o.host)
+	}
+
+	return host == o.host
+}
+
+// parseOrigin parses an allowed origin in the format scheme://host[:port].
+// The host may start with *. to allow its subdomains, e.g.
+// https://*.example.com.
+func parseOrigin(s string) (origin, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return origin{}, fmt.Errorf("invalid origin %q: it must be scheme://host[:port]", s)
+	}
+
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return origin{}, fmt.Errorf("invalid origin %q: it must be scheme://host[:port]", s)
+	}
+
+	ans := origin{scheme: u.Scheme, host: strings.ToLower(u.Host)}
+
+	if rest, ok := strings.CutPrefix(ans.host, "*."); ok {
+		ans.host = rest
+		ans.wildcard = true
+	}
+
+	if ans.host == "" || strings.Contains(ans.host, "*") {
+		return origin{}, fmt.Errorf("invalid origin %q: only a leading *. wildcard is allowed", s)
+	}
+
+	return ans, nil
+}
+
+// CORS allows the browsers of the origins to call the API with
+// credentials. The allowed origin is echoed back, never *, and the requests
+// of other origins get no CORS headers, so the browsers deny them.
+// Preflight requests of other origins are rejected.
+func CORS(origins []string) (func(http.Handler) http.Handler, error) {
+	allowed := make([]origin, 0, len(origins))
+
+	for _, s := range origins {
+		o, err := parseOrigin(s)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed = append(allowed, o)
+	}
+
+	isAllowed := func(s string) bool {
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			return false
+		}
+
+		host := strings.ToLower(u.Host)
+
+		for _, o := range allowed {
+			if o.match(u.Scheme, host) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestOrigin := r.Header.Get("Origin")
+			if requestOrigin == "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if !isAllowed(requestOrigin) {
+				if preflight {
+					http.Error(w, "origin not allowed", http.StatusForbidden)
+
+					return
+				}
+
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", requestOrigin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+
+			if !preflight {
+				w.Header().Set("Access-Control-Expose-Headers", "Retry-After, Content-Disposition")
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+			w.Header().Set("Access-Control-Allow-Headers", corsHeaders)
+			w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}, nil
+}
@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web/middleware"
+)
+
+func Test_CORS(t *testing.T) {
+	_, err := middleware.CORS([]string{"*"})
+	require.Error(t, err)
+
+	_, err = middleware.CORS([]string{"https://app.*.com"})
+	require.Error(t, err)
+
+	cors, err := middleware.CORS([]string{"https://app.example.com", "https://*.example.org", "http://localhost:3000"})
+	require.NoError(t, err)
+
+	handler := cors(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/jobs", http.NoBody)
+		req.Header.Set("Origin", origin)
+
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for _, origin := range []string{"https://app.example.com", "https://a.b.example.org", "http://localhost:3000"} {
+		w := do(http.MethodGet, origin)
+		require.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+		w = do(http.MethodOptions, origin)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.NotEmpty(t, w.Header().Get("Access-Control-Allow-Methods"))
+	}
+
+	for _, origin := range []string{"http://app.example.com", "https://example.org", "https://evil-example.org", "http://localhost:3001", "null"} {
+		w := do(http.MethodGet, origin)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+
+		w = do(http.MethodOptions, origin)
+		require.Equal(t, http.StatusForbidden, w.Code, origin)
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import "time"
+
+// NewMemoryStoreAt returns a MemoryStore whose clock is now.
+func NewMemoryStoreAt(now func() time.Time) *MemoryStore {
+	ans := NewMemoryStore()
+	ans.now = now
+	ans.lastSweep = now()
+
+	return ans
+}
+
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.buckets)
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// sweepInterval is how often the buckets that are full again are removed.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps the token buckets in memory. Use the RedisStore
+// when several instances serve the API.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// bucket is full again, and can be removed, once per has passed since
+// the last take.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	per    time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (m *MemoryStore) Take(_ *http.Request, key string, limit Limit) (ok bool, retryAfter time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	m.sweep(now)
+
+	b, exists := m.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.rate())
+	b.last = now
+	b.per = limit.Per
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / limit.rate() * float64(time.Second)), nil
+	}
+
+	b.tokens--
+
+	return true, 0, nil
+}
+
+// sweep removes the buckets that are full again so the map does not grow
+// with every client ever seen. Every bucket expires after the period of
+// its own limit.
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+
+	for k, b := range m.buckets {
+		if now.Sub(b.last) > b.per {
+			delete(m.buckets, k)
+		}
+	}
+
+	m.lastSweep = now
+}
@@ -0,0 +1,209 @@
+// Package middleware contains HTTP middlewares of the web server.
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket: Burst tokens that refill at Burst per Per.
+type Limit struct {
+	Burst int
+	Per   time.Duration
+}
+
+func (l Limit) enabled() bool {
+	return l.Burst > 0 && l.Per > 0
+}
+
+// rate returns the refill rate in tokens per second.
+func (l Limit) rate() float64 {
+	return float64(l.Burst) / l.Per.Seconds()
+}
+
+// ParseLimit parses limits in the format <count>/<s|m|h>, e.g. 10/m.
+// An empty string disables the limit.
+func ParseLimit(s string) (Limit, error) {
+	if s == "" {
+		return Limit{}, nil
+	}
+
+	count, unit, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q", s)
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q", s)
+	}
+
+	ans := Limit{Burst: n}
+
+	switch unit {
+	case "s":
+		ans.Per = time.Second
+	case "m":
+		ans.Per = time.Minute
+	case "h":
+		ans.Per = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("invalid rate limit unit %q", unit)
+	}
+
+	return ans, nil
+}
+
+// Store keeps the token buckets. Take removes a token from the bucket with
+// the given key and returns how long to wait when the bucket is empty.
+type Store interface {
+	Take(r *http.Request, key string, limit Limit) (ok bool, retryAfter time.Duration, err error)
+}
+
+// ParseTrustedProxies parses a comma separated list of IPs and CIDRs, e.g.
+// 10.0.0.0/8,127.0.0.1.
+func ParseTrustedProxies(s string) ([]netip.Prefix, error) {
+	var ans []netip.Prefix
+
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", v)
+			}
+
+			ans = append(ans, netip.PrefixFrom(addr, addr.BitLen()))
+
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", v)
+		}
+
+		ans = append(ans, prefix.Masked())
+	}
+
+	return ans, nil
+}
+
+// RateLimit limits the API requests per client. Job creation and reads have
+// separate buckets. Clients are identified by their IP. The X-Forwarded-For
+// header is only used when the request comes from one of the trusted
+// proxies.
+func RateLimit(store Store, create, read Limit, trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			kind, limit := classify(r, create, read)
+			if !limit.enabled() {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			ok, retryAfter, err := store.Take(r, kind+":ip:"+clientIP(r, trusted), limit)
+			if err != nil {
+				// do not block the API when the store is unavailable
+				log.Printf("rate limit store error: %v", err)
+
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if !ok {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+
+				w.Header().Set("Retry-After", strconv.Itoa(max(1, seconds)))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"code":    http.StatusTooManyRequests,
+					"message": http.StatusText(http.StatusTooManyRequests),
+				})
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func classify(r *http.Request, create, read Limit) (string, Limit) {
+	switch {
+	case r.Method == http.MethodPost && (r.URL.Path == "/api/v1/jobs" || r.URL.Path == "/scrape"):
+		return "create", create
+	case strings.HasPrefix(r.URL.Path, "/api/v1/"):
+		return "read", read
+	default:
+		return "", Limit{}
+	}
+}
+
+// clientIP returns the IP of the client of r. The addresses of the
+// X-Forwarded-For header are read from the right, the last one that is not
+// a trusted proxy is the client.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !isTrusted(host, trusted) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+
+		host = hop
+
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+
+	return host
+}
+
+func isTrusted(host string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
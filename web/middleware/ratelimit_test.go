@@ -0,0 +1,113 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web/middleware"
+)
+
+func Test_RateLimit(t *testing.T) {
+	trusted, err := middleware.ParseTrustedProxies("10.0.0.0/8, 127.0.0.1")
+	require.NoError(t, err)
+
+	_, err = middleware.ParseTrustedProxies("10.0.0.0/33")
+	require.Error(t, err)
+
+	limit := middleware.Limit{Burst: 2, Per: time.Minute}
+
+	handler := middleware.RateLimit(middleware.NewMemoryStore(), limit, limit, trusted)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	do := func(remote string, headers map[string]string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs", http.NoBody)
+		req.RemoteAddr = remote
+
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	// a new api key on every request does not get a new bucket
+	for i := range 2 {
+		require.Equal(t, http.StatusOK, do("192.0.2.1:1234", map[string]string{"X-API-Key": strconv.Itoa(i)}))
+	}
+
+	require.Equal(t, http.StatusTooManyRequests, do("192.0.2.1:1234", map[string]string{"X-API-Key": "2"}))
+
+	// nor does a forged X-Forwarded-For of a client that is not a proxy
+	require.Equal(t, http.StatusTooManyRequests, do("192.0.2.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.7"}))
+
+	// behind a trusted proxy the client is the last untrusted address
+	for range 2 {
+		require.Equal(t, http.StatusOK, do("10.0.0.2:80", map[string]string{"X-Forwarded-For": "192.0.2.9, 127.0.0.1"}))
+	}
+
+	require.Equal(t, http.StatusTooManyRequests, do("10.0.0.2:80", map[string]string{"X-Forwarded-For": "198.51.100.7, 192.0.2.9"}))
+	require.Equal(t, http.StatusOK, do("10.0.0.3:80", map[string]string{"X-Forwarded-For": "192.0.2.10"}))
+}
+
+func Test_MemoryStoreSweep(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		now = time.Now()
+	)
+
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return now
+	}
+
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now = now.Add(d)
+	}
+
+	store := middleware.NewMemoryStoreAt(clock)
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+
+	create := middleware.Limit{Burst: 1, Per: time.Hour}
+	read := middleware.Limit{Burst: 100, Per: time.Minute}
+
+	ok, _, err := store.Take(req, "create:ip:192.0.2.1", create)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the reads of another client do not reset the bucket of the hourly limit
+	advance(2 * time.Minute)
+
+	ok, _, err = store.Take(req, "read:ip:192.0.2.2", read)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, retryAfter, err := store.Take(req, "create:ip:192.0.2.1", create)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Greater(t, retryAfter, 50*time.Minute)
+
+	// the buckets are removed once full again
+	advance(2 * time.Hour)
+
+	ok, _, err = store.Take(req, "read:ip:192.0.2.3", read)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, store.Len())
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ Store = (*RedisStore)(nil)
+
+var errInvalidStoreResponse = errors.New("invalid rate limit store response")
+
+// takeScript refills and takes from the bucket atomically. It returns
+// 1 and 0 when a token was taken, or 0 and the milliseconds until the
+// next token otherwise.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1]) or burst
+local last = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + (now - last) / 1000 * rate)
+
+local ok = 0
+local wait = 0
+
+if tokens >= 1 then
+	tokens = tokens - 1
+	ok = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", now)
+redis.call("PEXPIRE", KEYS[1], ttl)
+
+return {ok, wait}
+`)
+
+// RedisStore keeps the token buckets in Redis so that the limits are
+// shared by all the instances of the API.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisStore(redisURL string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	ans := RedisStore{
+		client: redis.NewClient(opts),
+		prefix: "gmaps:ratelimit:",
+	}
+
+	return &ans, nil
+}
+
+func (s *RedisStore) Take(r *http.Request, key string, limit Limit) (ok bool, retryAfter time.Duration, err error) {
+	res, err := takeScript.Run(r.Context(), s.client, []string{s.prefix + key},
+		limit.Burst,
+		limit.rate(),
+		time.Now().UnixMilli(),
+		limit.Per.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+
+	if len(res) != 2 {
+		return false, 0, errInvalidStoreResponse
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
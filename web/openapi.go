@@ -0,0 +1,191 @@
+package web
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gosom/google-maps-scraper/dryrun"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// specSchemas are the component schemas of the OpenAPI document. They are
+// generated from the request and response types of the handlers so the
+// documentation cannot drift from the code.
+var specSchemas = map[string]reflect.Type{
+	"ApiError":             reflect.TypeOf(apiError{}),
+	"ApiPlacesResponse":    reflect.TypeOf(apiPlacesResponse{}),
+	"ApiRefreshRequest":    reflect.TypeOf(apiRefreshRequest{}),
+	"ApiScrapeRequest":     reflect.TypeOf(apiScrapeRequest{}),
+	"ApiScrapeResponse":    reflect.TypeOf(apiScrapeResponse{}),
+	"ApiSyncScrapeRequest": reflect.TypeOf(apiSyncScrapeRequest{}),
+	"ApiDeliveryFailures":  reflect.TypeOf(apiDeliveryFailures{}),
+	"ApiCreateShare":       reflect.TypeOf(apiCreateShareRequest{}),
+	"ApiShares":            reflect.TypeOf(apiShares{}),
+	"DailyStats":           reflect.TypeOf(DailyStats{}),
+	"DeliveryFailure":      reflect.TypeOf(DeliveryFailure{}),
+	"DownloadResponse":     reflect.TypeOf(downloadResponse{}),
+	"Entry":                reflect.TypeOf(gmaps.Entry{}),
+	"FieldError":           reflect.TypeOf(FieldError{}),
+	"Health":               reflect.TypeOf(Health{}),
+	"HealthStatus":         reflect.TypeOf(HealthStatus{}),
+	"Job":                  reflect.TypeOf(Job{}),
+	"JobData":              reflect.TypeOf(JobData{}),
+	"JobFile":              reflect.TypeOf(JobFile{}),
+	"JobStats":             reflect.TypeOf(JobStats{}),
+	"Plan":                 reflect.TypeOf(dryrun.Plan{}),
+	"PlanSeed":             reflect.TypeOf(dryrun.Seed{}),
+	"PurgeResult":          reflect.TypeOf(PurgeResult{}),
+	"ResultsPage":          reflect.TypeOf(ResultsPage{}),
+	"Share":                reflect.TypeOf(Share{}),
+	"Stats":                reflect.TypeOf(Stats{}),
+	"ValidationError":      reflect.TypeOf(ValidationError{}),
+}
+
+var (
+	specOnce sync.Once
+	specDoc  map[string]any
+	specErr  error
+)
+
+// OpenAPISpec returns the OpenAPI document: the paths of static/spec/spec.yaml
+// and the schemas generated from the Go types.
+func OpenAPISpec() (map[string]any, error) {
+	specOnce.Do(func() {
+		specDoc, specErr = buildSpec()
+	})
+
+	return specDoc, specErr
+}
+
+func buildSpec() (map[string]any, error) {
+	data, err := static.ReadFile("static/spec/spec.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]any, len(specSchemas))
+
+	for name, t := range specSchemas {
+		schemas[name] = schemaOf(t, false)
+	}
+
+	doc["components"] = map[string]any{"schemas": schemas}
+
+	if err := checkRefs(doc, schemas); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func schemaOf(t reflect.Type, allowRef bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if allowRef {
+		for name, st := range specSchemas {
+			if st == t {
+				return map[string]any{"$ref": "#/components/schemas/" + name}
+			}
+		}
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": "integer"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), true)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), true)}
+	case reflect.Struct:
+		properties := map[string]any{}
+
+		addProperties(t, properties)
+
+		return map[string]any{"type": "object", "properties": properties}
+	default:
+		return map[string]any{}
+	}
+}
+
+func addProperties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			addProperties(f.Type, properties)
+
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+
+		properties[name] = schemaOf(f.Type, true)
+	}
+}
+
+// checkRefs makes sure that every schema the paths refer to is generated.
+func checkRefs(v any, schemas map[string]any) error {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if ref, ok := item.(string); ok && k == "$ref" {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				if _, ok := schemas[name]; !ok {
+					return fmt.Errorf("openapi: unknown schema %s", ref)
+				}
+
+				continue
+			}
+
+			if err := checkRefs(item, schemas); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range val {
+			if err := checkRefs(item, schemas); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_OpenAPISpec(t *testing.T) {
+	spec, err := web.OpenAPISpec()
+	require.NoError(t, err)
+
+	paths, ok := spec["paths"].(map[string]any)
+	require.True(t, ok)
+	require.Contains(t, paths, "/api/v1/jobs")
+
+	components, ok := spec["components"].(map[string]any)
+	require.True(t, ok)
+
+	schemas, ok := components["schemas"].(map[string]any)
+	require.True(t, ok)
+
+	jobData, ok := schemas["JobData"].(map[string]any)
+	require.True(t, ok)
+
+	properties, ok := jobData["properties"].(map[string]any)
+	require.True(t, ok)
+	require.Contains(t, properties, "keywords")
+	require.Contains(t, properties, "max_time")
+
+	request, ok := schemas["ApiScrapeRequest"].(map[string]any)
+	require.True(t, ok)
+	require.Contains(t, request["properties"], "name")
+	require.Contains(t, request["properties"], "keywords")
+}
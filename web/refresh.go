@@ -0,0 +1,104 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxSyncRefresh is the number of places up to which a refresh
+	// responds with the places instead of a job.
+	maxSyncRefresh = 10
+	refreshTimeout = 60 * time.Second
+)
+
+type apiRefreshRequest struct {
+	Name   string   `json:"name"`
+	Places []string `json:"places"`
+	Lang   string   `json:"lang"`
+	Email  bool     `json:"email"`
+}
+
+// apiRefreshPlaces scrapes the places given as cids or urls again, without
+// searching them. Up to maxSyncRefresh places are returned in the response,
+// larger lists are scraped by a job.
+func (s *Server) apiRefreshPlaces(w http.ResponseWriter, r *http.Request) {
+	var req apiRefreshRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if len(req.Places) == 0 {
+		var verr ValidationError
+
+		verr.add("places", "at least one place is required")
+		renderJSON(w, http.StatusUnprocessableEntity, &verr)
+
+		return
+	}
+
+	if req.Name == "" {
+		req.Name = "refresh"
+	}
+
+	if req.Lang == "" {
+		req.Lang = "en"
+	}
+
+	newJob := Job{
+		ID:     uuid.New().String(),
+		Name:   req.Name,
+		Date:   time.Now().UTC(),
+		Status: StatusPending,
+		Data: JobData{
+			Places:  req.Places,
+			Lang:    req.Lang,
+			Email:   req.Email,
+			Depth:   1,
+			MaxTime: max(minMaxTime, time.Duration(len(req.Places))*5*time.Second),
+		},
+	}
+
+	if err := newJob.Validate(); err != nil {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			renderJSON(w, http.StatusUnprocessableEntity, verr)
+
+			return
+		}
+
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if s.syncScraper != nil && len(newJob.Data.Places) <= maxSyncRefresh {
+		s.scrapeSync(w, r, &newJob.Data, refreshTimeout)
+
+		return
+	}
+
+	if err := s.svc.Create(r.Context(), &newJob); err != nil {
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	renderJSON(w, http.StatusAccepted, apiScrapeResponse{ID: newJob.ID})
+}
@@ -0,0 +1,61 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_RefreshPlaces(t *testing.T) {
+	var refreshed []string
+
+	refresher := func(_ context.Context, data *web.JobData) ([]*gmaps.Entry, error) {
+		refreshed = data.Places
+
+		return []*gmaps.Entry{{Cid: data.Places[0], Title: "Kipriakon"}}, nil
+	}
+
+	srv, err := web.New(web.NewService(&fakeRepo{}, t.TempDir()), ":0", web.WithSyncScraper(refresher))
+	require.NoError(t, err)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/places/refresh", strings.NewReader(body)))
+
+		return rec
+	}
+
+	rec := post(`{"places": ["16519582940102929223"]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []string{"16519582940102929223"}, refreshed)
+
+	var resp struct {
+		Places []gmaps.Entry `json:"places"`
+	}
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Places, 1)
+	require.Equal(t, "Kipriakon", resp.Places[0].Title)
+
+	// the large lists are scraped by a job
+	places := make([]string, 11)
+	for i := range places {
+		places[i] = `"` + strconv.Itoa(i+1) + `"`
+	}
+
+	rec = post(`{"places": [` + strings.Join(places, ",") + `]}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Contains(t, rec.Body.String(), `"id"`)
+
+	require.Equal(t, http.StatusUnprocessableEntity, post(`{"places": []}`).Code)
+	require.Equal(t, http.StatusUnprocessableEntity, post(`{"places": ["https://example.com"]}`).Code)
+}
@@ -0,0 +1,387 @@
+package web
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultResultsLimit = 100
+	maxResultsLimit     = 1000
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ResultsPage is a page of results. NextCursor is empty on the last page.
+type ResultsPage struct {
+	Results    []map[string]string `json:"results"`
+	NextCursor string              `json:"next_cursor"`
+}
+
+// cursor points to the next row of the results: the job, the index of the
+// row and its byte offset in the csv file. Seeking to the offset makes every
+// page of a local file equally fast, no matter how deep it is. The offset is
+// only valid for the revision of the file it was read from, after the file
+// is rewritten the rows are skipped by their index instead.
+type cursor struct {
+	jobID    string
+	revision int
+	row      int
+	offset   int64
+}
+
+func (c cursor) String() string {
+	raw := fmt.Sprintf("%s:%d:%d:%d", c.jobID, c.revision, c.row, c.offset)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func parseCursor(s string) (cursor, error) {
+	if s == "" {
+		return cursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 4 || parts[0] == "" {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	ans := cursor{jobID: parts[0]}
+
+	ans.revision, err = strconv.Atoi(parts[1])
+	if err != nil || ans.revision < 0 {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	ans.row, err = strconv.Atoi(parts[2])
+	if err != nil || ans.row < 0 {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	ans.offset, err = strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || ans.offset < 0 {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	return ans, nil
+}
+
+// JobResults returns a page of the results of a job.
+func (s *Service) JobResults(ctx context.Context, jobID, cursorStr string, limit int, fields []string) (ResultsPage, error) {
+	c, err := parseCursor(cursorStr)
+	if err != nil {
+		return ResultsPage{}, err
+	}
+
+	if c.jobID != "" && c.jobID != jobID {
+		return ResultsPage{}, ErrInvalidCursor
+	}
+
+	job, err := s.Get(ctx, jobID)
+	if err != nil {
+		return ResultsPage{}, err
+	}
+
+	c.jobID = jobID
+
+	return s.results(ctx, []Job{job}, c, limit, fields)
+}
+
+// AllResults returns a page of the results of all jobs, newest jobs first.
+func (s *Service) AllResults(ctx context.Context, cursorStr string, limit int, fields []string) (ResultsPage, error) {
+	c, err := parseCursor(cursorStr)
+	if err != nil {
+		return ResultsPage{}, err
+	}
+
+	all, err := s.All(ctx)
+	if err != nil {
+		return ResultsPage{}, err
+	}
+
+	var jobs []Job
+
+	for i := range all {
+		if all[i].Status == StatusOK {
+			jobs = append(jobs, all[i])
+		}
+	}
+
+	if c.jobID != "" {
+		idx := -1
+
+		for i := range jobs {
+			if jobs[i].ID == c.jobID {
+				idx = i
+
+				break
+			}
+		}
+
+		if idx == -1 {
+			return ResultsPage{}, ErrInvalidCursor
+		}
+
+		jobs = jobs[idx:]
+	}
+
+	return s.results(ctx, jobs, c, limit, fields)
+}
+
+func (s *Service) results(ctx context.Context, jobs []Job, c cursor, limit int, fields []string) (ResultsPage, error) {
+	if limit <= 0 {
+		limit = defaultResultsLimit
+	}
+
+	limit = min(limit, maxResultsLimit)
+
+	ans := ResultsPage{
+		Results: []map[string]string{},
+	}
+
+	for i := range jobs {
+		if i > 0 || c.jobID == "" {
+			c = cursor{jobID: jobs[i].ID}
+		}
+
+		if c.revision != jobs[i].Data.Revision {
+			// the file was rewritten since the cursor was created
+			c.revision, c.offset = jobs[i].Data.Revision, 0
+		}
+
+		f, err := s.openResults(ctx, &jobs[i])
+		if err != nil {
+			if len(jobs) == 1 {
+				return ResultsPage{}, err
+			}
+
+			continue
+		}
+
+		next, more, err := readCSVPage(f, c, limit-len(ans.Results), fields, &ans.Results)
+
+		_ = f.Close()
+
+		if err != nil {
+			return ResultsPage{}, err
+		}
+
+		if more {
+			ans.NextCursor = next.String()
+
+			return ans, nil
+		}
+	}
+
+	return ans, nil
+}
+
+// openResults opens the results file of the job, in the data folder or in
+// the file store.
+func (s *Service) openResults(ctx context.Context, job *Job) (io.ReadCloser, error) {
+	if job.Data.File != nil {
+		if s.store == nil {
+			return nil, fmt.Errorf("results file of job %s is in the file store, which is not configured", job.ID)
+		}
+
+		return s.store.Download(ctx, s.bucket, job.Data.File.Key)
+	}
+
+	datapath, err := s.GetCSV(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(datapath)
+}
+
+// readCSVPage appends up to limit rows starting at the cursor to rows and
+// returns the cursor of the next row. more is false when the end of the file
+// was reached. Files that can seek are read from the offset of the cursor,
+// the others from the start.
+func readCSVPage(f io.Reader, c cursor, limit int, fields []string, rows *[]map[string]string) (next cursor, more bool, err error) {
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return cursor{}, false, nil
+	} else if err != nil {
+		return cursor{}, false, err
+	}
+
+	// base is the offset of the reader in the file
+	var base int64
+
+	seeker, ok := f.(io.Seeker)
+
+	switch {
+	case c.offset > 0 && ok:
+		if _, err := seeker.Seek(c.offset, io.SeekStart); err != nil {
+			return cursor{}, false, err
+		}
+
+		r = csv.NewReader(f)
+		r.FieldsPerRecord = -1
+		base = c.offset
+	default:
+		for n := 0; n < c.row; n++ {
+			if _, err := r.Read(); errors.Is(err, io.EOF) {
+				return cursor{}, false, nil
+			} else if err != nil {
+				return cursor{}, false, err
+			}
+		}
+	}
+
+	columns := make([]int, 0, len(header))
+
+	for i := range header {
+		if len(fields) == 0 || contains(fields, header[i]) {
+			columns = append(columns, i)
+		}
+	}
+
+	next = c
+
+	for n := 0; n < limit; n++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return cursor{}, false, nil
+		} else if err != nil {
+			return cursor{}, false, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+		}
+
+		row := make(map[string]string, len(columns))
+
+		for _, idx := range columns {
+			if idx < len(record) {
+				row[header[idx]] = record[idx]
+			}
+		}
+
+		*rows = append(*rows, row)
+		next.row++
+	}
+
+	next.offset = base + r.InputOffset()
+
+	// peek to avoid returning a cursor to an empty page
+	if _, err := r.Read(); errors.Is(err, io.EOF) {
+		return cursor{}, false, nil
+	}
+
+	return next, true, nil
+}
+
+func contains(items []string, v string) bool {
+	for i := range items {
+		if items[i] == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseResultsQuery reads the limit, cursor and fields query parameters.
+func parseResultsQuery(r *http.Request) (limit int, cursorStr string, fields []string, err error) {
+	q := r.URL.Query()
+
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return 0, "", nil, errors.New("invalid limit")
+		}
+	}
+
+	if v := q.Get("fields"); v != "" {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				fields = append(fields, f)
+			}
+		}
+	}
+
+	return limit, q.Get("cursor"), fields, nil
+}
+
+func (s *Server) apiJobResults(w http.ResponseWriter, r *http.Request) {
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Invalid ID",
+		})
+
+		return
+	}
+
+	limit, cursorStr, fields, err := parseResultsQuery(r)
+	if err != nil {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	job, err := s.svc.Get(r.Context(), id.String())
+	if err != nil {
+		renderJSON(w, http.StatusNotFound, apiError{
+			Code:    http.StatusNotFound,
+			Message: http.StatusText(http.StatusNotFound),
+		})
+
+		return
+	}
+
+	ans, err := s.svc.JobResults(r.Context(), job.ID, cursorStr, limit, fields)
+	s.renderResults(w, ans, err)
+}
+
+func (s *Server) apiResults(w http.ResponseWriter, r *http.Request) {
+	limit, cursorStr, fields, err := parseResultsQuery(r)
+	if err != nil {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ans, err := s.svc.AllResults(r.Context(), cursorStr, limit, fields)
+	s.renderResults(w, ans, err)
+}
+
+func (s *Server) renderResults(w http.ResponseWriter, ans ResultsPage, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidCursor):
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+	case err != nil:
+		renderJSON(w, http.StatusNotFound, apiError{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+	default:
+		renderJSON(w, http.StatusOK, ans)
+	}
+}
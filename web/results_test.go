@@ -0,0 +1,151 @@
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+type fakeStore struct {
+	objects map[string][]byte
+}
+
+func (f *fakeStore) Upload(_ context.Context, _, key string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	f.objects[key] = data
+
+	return nil
+}
+
+func (f *fakeStore) PresignGet(_ context.Context, _, key string, _ time.Duration) (string, error) {
+	return "https://bucket.example.com/" + key, nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, _, key string) error {
+	delete(f.objects, key)
+
+	return nil
+}
+
+func (f *fakeStore) Download(_ context.Context, _, key string) (io.ReadCloser, error) {
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func titles(page web.ResultsPage) []string {
+	ans := make([]string, 0, len(page.Results))
+
+	for _, row := range page.Results {
+		ans = append(ans, row["title"])
+	}
+
+	return ans
+}
+
+func Test_Results(t *testing.T) {
+	const (
+		localID  = "9c1a2b4e-6f5d-4c3b-8a2e-1d0f9e8c7b6a"
+		storedID = "0b6e0f2c-3a4d-4e5f-9a8b-7c6d5e4f3a2b"
+	)
+
+	ctx := context.Background()
+	dataFolder := t.TempDir()
+
+	local := "title,cid\nfirst place with a long name,1\nsecond,2\nthird,3\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dataFolder, localID+".csv"), []byte(local), 0o600))
+
+	store := &fakeStore{objects: map[string][]byte{
+		storedID + ".csv": []byte("title,cid\nfourth,4\nfifth,5\n"),
+	}}
+
+	repo := &jobsRepo{fakeRepo{jobs: []web.Job{
+		{ID: localID, Status: web.StatusOK},
+		{ID: storedID, Status: web.StatusOK, Data: web.JobData{File: &web.JobFile{Key: storedID + ".csv"}}},
+	}}}
+
+	svc := web.NewService(repo, dataFolder, web.WithFileStore(store, "bucket"))
+
+	t.Run("all results span the local and the stored files", func(t *testing.T) {
+		var (
+			got    []string
+			cursor string
+		)
+
+		for {
+			page, err := svc.AllResults(ctx, cursor, 2, []string{"title"})
+			require.NoError(t, err)
+
+			got = append(got, titles(page)...)
+
+			if page.NextCursor == "" {
+				break
+			}
+
+			cursor = page.NextCursor
+		}
+
+		require.Equal(t, []string{"first place with a long name", "second", "third", "fourth", "fifth"}, got)
+	})
+
+	t.Run("job results are read from the file store", func(t *testing.T) {
+		page, err := svc.JobResults(ctx, storedID, "", 1, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"fourth"}, titles(page))
+
+		page, err = svc.JobResults(ctx, storedID, page.NextCursor, 1, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"fifth"}, titles(page))
+		require.Empty(t, page.NextCursor)
+	})
+
+	t.Run("invalid cursors are rejected", func(t *testing.T) {
+		_, err := svc.JobResults(ctx, localID, "not a cursor", 1, nil)
+		require.ErrorIs(t, err, web.ErrInvalidCursor)
+
+		page, err := svc.JobResults(ctx, storedID, "", 1, nil)
+		require.NoError(t, err)
+
+		_, err = svc.JobResults(ctx, localID, page.NextCursor, 1, nil)
+		require.ErrorIs(t, err, web.ErrInvalidCursor)
+	})
+
+	t.Run("cursors skip the rows of a rewritten file", func(t *testing.T) {
+		page, err := svc.JobResults(ctx, localID, "", 2, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"first place with a long name", "second"}, titles(page))
+
+		// the byte offset of the cursor is past the end of the purged file
+		purged, err := svc.PurgePlace(ctx, "1")
+		require.NoError(t, err)
+		require.Equal(t, []string{localID}, purged.Jobs)
+
+		page, err = svc.JobResults(ctx, localID, page.NextCursor, 2, nil)
+		require.NoError(t, err)
+		require.Empty(t, page.Results)
+		require.Empty(t, page.NextCursor)
+
+		page, err = svc.JobResults(ctx, localID, "", 1, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"second"}, titles(page))
+
+		page, err = svc.JobResults(ctx, localID, page.NextCursor, 1, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"third"}, titles(page))
+	})
+}
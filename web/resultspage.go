@@ -0,0 +1,177 @@
+package web
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+)
+
+// the columns of the results page that are shown before the user picks any
+var defaultResultsColumns = []string{"title", "category", "address", "phone", "website", "review_rating", "review_count"}
+
+type resultsPageData struct {
+	Job      Job
+	Columns  []string
+	Selected []string
+	CSRF     string
+	Brand    Branding
+}
+
+// IsSelected reports whether a column is shown when the page loads.
+func (d *resultsPageData) IsSelected(column string) bool {
+	return contains(d.Selected, column)
+}
+
+// resultsPage renders the results of a job as a table that loads its rows
+// from the results API and sorts and filters them in the browser.
+func (s *Server) resultsPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		http.Error(w, "Invalid ID", http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	tmpl, ok := s.tmpl["static/templates/results.html"]
+	if !ok {
+		http.Error(w, "missing tpl", http.StatusInternalServerError)
+
+		return
+	}
+
+	job, err := s.svc.Get(r.Context(), id.String())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	data := resultsPageData{
+		Job:   job,
+		CSRF:  csrfToken(w, r),
+		Brand: s.branding,
+	}
+
+	// the results in the file store are only downloaded, the page says so
+	if job.Status == StatusOK && job.Data.File == nil {
+		filePath, err := s.svc.GetCSV(r.Context(), job.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		data.Columns, err = csvHeader(filePath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		for _, c := range defaultResultsColumns {
+			if contains(data.Columns, c) {
+				data.Selected = append(data.Selected, c)
+			}
+		}
+	}
+
+	if err := tmpl.Execute(w, &data); err != nil {
+		log.Printf("failed to render the results of job %s: %v", job.ID, err)
+	}
+}
+
+// csvHeader returns the columns of a CSV file.
+func csvHeader(filePath string) ([]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	header, err := csv.NewReader(f).Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	return header, err
+}
+
+// downloadColumns sends a CSV file with only the given columns, in the given
+// order.
+func downloadColumns(w http.ResponseWriter, file io.Reader, fileName string, columns []string) {
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+
+		return
+	}
+
+	indexes := make([]int, len(columns))
+
+	for i, c := range columns {
+		indexes[i] = -1
+
+		for j := range header {
+			if header[j] == c {
+				indexes[i] = j
+
+				break
+			}
+		}
+
+		if indexes[i] == -1 {
+			http.Error(w, fmt.Sprintf("%s %s", errUnknownColumn, c), http.StatusUnprocessableEntity)
+
+			return
+		}
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	w.Header().Set("Content-Type", "text/csv")
+
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(columns); err != nil {
+		return
+	}
+
+	row := make([]string, len(columns))
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			log.Printf("failed to read %s: %v", fileName, err)
+
+			return
+		}
+
+		for i, idx := range indexes {
+			row[i] = ""
+			if idx < len(record) {
+				row[i] = record[idx]
+			}
+		}
+
+		if err := cw.Write(row); err != nil {
+			return
+		}
+	}
+
+	cw.Flush()
+}
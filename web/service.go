@@ -2,26 +2,97 @@ package web
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/queue"
 )
 
+const downloadURLExpiry = 15 * time.Minute
+
+// FileStore stores the results files outside of the data folder
+// and hands out time-limited download urls for them.
+type FileStore interface {
+	Upload(ctx context.Context, bucketName, key string, body io.Reader) error
+	PresignGet(ctx context.Context, bucketName, key string, expires time.Duration) (string, error)
+	Delete(ctx context.Context, bucketName, key string) error
+	Download(ctx context.Context, bucketName, key string) (io.ReadCloser, error)
+}
+
 type Service struct {
 	repo       JobRepository
 	dataFolder string
+	queue      queue.Provider
+	store      FileStore
+	bucket     string
+	capacity   func() int
+	deliveries DeliveryRepository
+	shares     ShareRepository
+	// staleAfter is the time without heartbeat after which the runner
+	// of a working job is considered gone, 0 when there are no heartbeats.
+	staleAfter time.Duration
+}
+
+type ServiceOption func(*Service)
+
+// WithQueue makes the service publish the ids of the created jobs
+// to q instead of letting the runner poll the repository.
+func WithQueue(q queue.Provider) ServiceOption {
+	return func(s *Service) {
+		s.queue = q
+	}
+}
+
+// WithFileStore moves the results files of the finished jobs to bucket.
+// Downloads are then served with presigned urls.
+func WithFileStore(store FileStore, bucket string) ServiceOption {
+	return func(s *Service) {
+		s.store = store
+		s.bucket = bucket
+	}
+}
+
+// WithStaleJobTimeout lets Requeue take over the working jobs without
+// a heartbeat for d. Without it working jobs cannot be requeued.
+func WithStaleJobTimeout(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.staleAfter = d
+	}
 }
 
-func NewService(repo JobRepository, dataFolder string) *Service {
-	return &Service{
+func NewService(repo JobRepository, dataFolder string, opts ...ServiceOption) *Service {
+	ans := Service{
 		repo:       repo,
 		dataFolder: dataFolder,
+		capacity:   func() int { return 1 },
 	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	return &ans
 }
 
 func (s *Service) Create(ctx context.Context, job *Job) error {
-	return s.repo.Create(ctx, job)
+	if err := s.repo.Create(ctx, job); err != nil {
+		return err
+	}
+
+	if s.queue != nil {
+		return s.queue.Publish(ctx, job.ID)
+	}
+
+	return nil
 }
 
 func (s *Service) All(ctx context.Context) ([]Job, error) {
@@ -47,6 +118,25 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
+	for name := range gmaps.Tables {
+		if err := os.Remove(filepath.Join(s.dataFolder, id+"."+name+".csv")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if s.store != nil {
+		job, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if job.Data.File != nil {
+			if err := s.store.Delete(ctx, s.bucket, job.Data.File.Key); err != nil {
+				return fmt.Errorf("failed to delete the results file of job %s: %w", id, err)
+			}
+		}
+	}
+
 	return s.repo.Delete(ctx, id)
 }
 
@@ -54,8 +144,22 @@ func (s *Service) Update(ctx context.Context, job *Job) error {
 	return s.repo.Update(ctx, job)
 }
 
+// SelectPending returns the oldest pending job.
 func (s *Service) SelectPending(ctx context.Context) ([]Job, error) {
-	return s.repo.Select(ctx, SelectParams{Status: StatusPending, Limit: 1})
+	return s.repo.Select(ctx, SelectParams{Status: StatusPending, Limit: 1, OldestFirst: true})
+}
+
+func (s *Service) AllPending(ctx context.Context) ([]Job, error) {
+	return s.repo.Select(ctx, SelectParams{Status: StatusPending})
+}
+
+// SelectStale returns the working jobs whose last heartbeat is older than before.
+func (s *Service) SelectStale(ctx context.Context, before time.Time) ([]Job, error) {
+	return s.repo.Select(ctx, SelectParams{Status: StatusWorking, HeartbeatBefore: before})
+}
+
+func (s *Service) Heartbeat(ctx context.Context, id string) error {
+	return s.repo.Heartbeat(ctx, id)
 }
 
 func (s *Service) GetCSV(_ context.Context, id string) (string, error) {
@@ -71,3 +175,444 @@ func (s *Service) GetCSV(_ context.Context, id string) (string, error) {
 
 	return datapath, nil
 }
+
+// GetTableCSV returns the path of the csv file of a table of the job
+// (see JobData.Tables). The tables stay in the data folder when the
+// results are moved to the file store.
+func (s *Service) GetTableCSV(_ context.Context, id, table string) (string, error) {
+	if strings.Contains(id, "/") || strings.Contains(id, "\\") || strings.Contains(id, "..") {
+		return "", fmt.Errorf("invalid file name")
+	}
+
+	if _, ok := gmaps.Tables[table]; !ok {
+		return "", fmt.Errorf("unknown table %s", table)
+	}
+
+	datapath := filepath.Join(s.dataFolder, id+"."+table+".csv")
+
+	if _, err := os.Stat(datapath); os.IsNotExist(err) {
+		return "", fmt.Errorf("%s file not found for job %s", table, id)
+	}
+
+	return datapath, nil
+}
+
+// StoreCSV uploads the results file of the job to the file store and removes
+// the local copy. It is a no-op when no file store is configured.
+func (s *Service) StoreCSV(ctx context.Context, job *Job) error {
+	if s.store == nil {
+		return nil
+	}
+
+	datapath, err := s.GetCSV(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(datapath)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	h := sha256.New()
+
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := job.ID + ".csv"
+
+	if err := s.store.Upload(ctx, s.bucket, key, f); err != nil {
+		return err
+	}
+
+	job.Data.File = &JobFile{
+		Key:    key,
+		Size:   size,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}
+
+	_ = f.Close()
+
+	return os.Remove(datapath)
+}
+
+// DownloadURL returns a presigned url for the results file of the job
+// if it lives in the file store.
+func (s *Service) DownloadURL(ctx context.Context, job *Job) (string, time.Time, error) {
+	if s.store == nil || job.Data.File == nil {
+		return "", time.Time{}, fmt.Errorf("results file of job %s is not in the file store", job.ID)
+	}
+
+	expiresAt := time.Now().UTC().Add(downloadURLExpiry)
+
+	u, err := s.store.PresignGet(ctx, s.bucket, job.Data.File.Key, downloadURLExpiry)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return u, expiresAt, nil
+}
+
+// PurgeResult reports what PurgePlace removed. Skipped are the jobs that
+// were not purged because they are pending or running, they have to be
+// purged again once they finished.
+type PurgeResult struct {
+	Removed int      `json:"removed"`
+	Jobs    []string `json:"jobs"`
+	Skipped []string `json:"skipped"`
+}
+
+// PurgePlace removes the rows of the place with the given cid from the
+// results of all jobs: the results file, in the data folder or in the file
+// store, and the tables of the job.
+func (s *Service) PurgePlace(ctx context.Context, cid string) (PurgeResult, error) {
+	ans := PurgeResult{
+		Jobs:    []string{},
+		Skipped: []string{},
+	}
+
+	jobs, err := s.All(ctx)
+	if err != nil {
+		return ans, err
+	}
+
+	for i := range jobs {
+		// the runner writes the files of the job
+		if jobs[i].Status == StatusPending || jobs[i].Status == StatusWorking {
+			ans.Skipped = append(ans.Skipped, jobs[i].ID)
+
+			continue
+		}
+
+		n, err := s.purgeJob(ctx, &jobs[i], cid)
+		if err != nil {
+			return ans, fmt.Errorf("failed to purge job %s: %w", jobs[i].ID, err)
+		}
+
+		if n > 0 {
+			jobs[i].Data.Revision++
+
+			if err := s.Update(ctx, &jobs[i]); err != nil {
+				return ans, fmt.Errorf("failed to update job %s: %w", jobs[i].ID, err)
+			}
+
+			ans.Removed += n
+			ans.Jobs = append(ans.Jobs, jobs[i].ID)
+		}
+	}
+
+	return ans, nil
+}
+
+// purgeJob removes the rows of the place from the files of the job and
+// returns the number of removed rows.
+func (s *Service) purgeJob(ctx context.Context, job *Job, cid string) (int, error) {
+	var removed int
+
+	if job.Data.File != nil {
+		n, err := s.purgeStoredCSV(ctx, job, cid)
+		if err != nil {
+			return 0, err
+		}
+
+		removed += n
+	} else if datapath, err := s.GetCSV(ctx, job.ID); err == nil {
+		n, err := purgeCSVFile(datapath, "cid", cid)
+		if err != nil {
+			return 0, err
+		}
+
+		removed += n
+	}
+
+	for name := range gmaps.Tables {
+		datapath := filepath.Join(s.dataFolder, job.ID+"."+name+".csv")
+
+		if _, err := os.Stat(datapath); err != nil {
+			continue
+		}
+
+		n, err := purgeCSVFile(datapath, "cid", cid)
+		if err != nil {
+			return 0, err
+		}
+
+		removed += n
+	}
+
+	return removed, nil
+}
+
+// purgeStoredCSV rewrites the results file of the job in the file store
+// without the rows of the place.
+func (s *Service) purgeStoredCSV(ctx context.Context, job *Job, cid string) (int, error) {
+	if s.store == nil {
+		return 0, fmt.Errorf("results file of job %s is in the file store, which is not configured", job.ID)
+	}
+
+	body, err := s.store.Download(ctx, s.bucket, job.Data.File.Key)
+	if err != nil {
+		return 0, err
+	}
+
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(s.dataFolder, ".purge-*.csv")
+	if err != nil {
+		return 0, err
+	}
+
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+
+	removed, err := purgeCSV(body, io.MultiWriter(tmp, h), "cid", cid)
+	if err != nil || removed == 0 {
+		return 0, err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	if err := s.store.Upload(ctx, s.bucket, job.Data.File.Key, tmp); err != nil {
+		return 0, err
+	}
+
+	job.Data.File.Size = size
+	job.Data.File.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	return removed, nil
+}
+
+// purgeCSVFile rewrites the csv file at datapath without the rows whose
+// column equals value and returns the number of removed rows.
+func purgeCSVFile(datapath, column, value string) (int, error) {
+	f, err := os.Open(datapath)
+	if err != nil {
+		return 0, err
+	}
+
+	defer f.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(datapath), ".purge-*.csv")
+	if err != nil {
+		return 0, err
+	}
+
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	removed, err := purgeCSV(f, tmp, column, value)
+	if err != nil || removed == 0 {
+		return 0, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+
+	_ = f.Close()
+
+	return removed, os.Rename(tmp.Name(), datapath)
+}
+
+// purgeCSV copies the csv from r to w without the rows whose column equals
+// value and returns the number of removed rows. Nothing is written when the
+// csv has no such column.
+func purgeCSV(r io.Reader, w io.Writer, column, value string) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	idx := -1
+
+	for i := range header {
+		if header[i] == column {
+			idx = i
+
+			break
+		}
+	}
+
+	if idx == -1 {
+		return 0, nil
+	}
+
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+
+	var removed int
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+
+		if idx < len(record) && record[idx] == value {
+			removed++
+
+			continue
+		}
+
+		if err := cw.Write(record); err != nil {
+			return 0, err
+		}
+	}
+
+	cw.Flush()
+
+	return removed, cw.Error()
+}
+
+// Stats are the system-wide job statistics of the admin API.
+type Stats struct {
+	Jobs       map[string]int `json:"jobs"`
+	QueueDepth int            `json:"queue_depth"`
+	Daily      []DailyStats   `json:"daily"`
+}
+
+type DailyStats struct {
+	Date      string  `json:"date"`
+	Total     int     `json:"total"`
+	Failed    int     `json:"failed"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// Stats returns the number of jobs per status, the number of pending jobs
+// and the failure rate of the jobs created in each of the last days.
+func (s *Service) Stats(ctx context.Context, days int) (Stats, error) {
+	jobs, err := s.All(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	ans := Stats{
+		Jobs: map[string]int{
+			StatusPending: 0,
+			StatusWorking: 0,
+			StatusOK:      0,
+			StatusFailed:  0,
+		},
+		Daily: make([]DailyStats, days),
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	index := make(map[string]int, days)
+
+	for i := range ans.Daily {
+		date := today.AddDate(0, 0, i-days+1).Format(time.DateOnly)
+		ans.Daily[i].Date = date
+		index[date] = i
+	}
+
+	for i := range jobs {
+		ans.Jobs[jobs[i].Status]++
+
+		if jobs[i].Status == StatusPending {
+			ans.QueueDepth++
+		}
+
+		idx, ok := index[jobs[i].Date.UTC().Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+
+		ans.Daily[idx].Total++
+
+		if jobs[i].Status == StatusFailed {
+			ans.Daily[idx].Failed++
+		}
+	}
+
+	for i := range ans.Daily {
+		if ans.Daily[i].Total > 0 {
+			ans.Daily[i].ErrorRate = float64(ans.Daily[i].Failed) / float64(ans.Daily[i].Total)
+		}
+	}
+
+	return ans, nil
+}
+
+// Requeue sets a failed or stale working job back to pending so it is
+// scraped again. A working job with a recent heartbeat is rejected, as
+// its scrape is still running and would race with the next one.
+func (s *Service) Requeue(ctx context.Context, id string) (Job, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return Job{}, err
+	}
+
+	switch {
+	case job.Status == StatusWorking && !s.isStale(&job):
+		return Job{}, fmt.Errorf("%w: %s with a recent heartbeat, fail it or wait for the stale job timeout", ErrInvalidStatus, job.Status)
+	case job.Status != StatusWorking && job.Status != StatusFailed:
+		return Job{}, fmt.Errorf("%w: %s", ErrInvalidStatus, job.Status)
+	}
+
+	job.Status = StatusPending
+
+	if err := s.Update(ctx, &job); err != nil {
+		return Job{}, err
+	}
+
+	if s.queue != nil {
+		if err := s.queue.Publish(ctx, job.ID); err != nil {
+			return Job{}, err
+		}
+	}
+
+	return job, nil
+}
+
+// isStale reports whether the runner of the working job stopped sending
+// heartbeats.
+func (s *Service) isStale(job *Job) bool {
+	return s.staleAfter > 0 && time.Since(job.HeartbeatAt) >= s.staleAfter
+}
+
+// ForceFail marks a working job as failed.
+func (s *Service) ForceFail(ctx context.Context, id string) (Job, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return Job{}, err
+	}
+
+	if job.Status != StatusWorking {
+		return Job{}, fmt.Errorf("%w: %s", ErrInvalidStatus, job.Status)
+	}
+
+	job.Status = StatusFailed
+
+	if err := s.Update(ctx, &job); err != nil {
+		return Job{}, err
+	}
+
+	return job, nil
+}
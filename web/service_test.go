@@ -0,0 +1,102 @@
+package web_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_PurgePlace(t *testing.T) {
+	const (
+		localID   = "9c1a2b4e-6f5d-4c3b-8a2e-1d0f9e8c7b6a"
+		storedID  = "0b6e0f2c-3a4d-4e5f-9a8b-7c6d5e4f3a2b"
+		workingID = "5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a"
+	)
+
+	dataFolder := t.TempDir()
+
+	write := func(name, data string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dataFolder, name), []byte(data), 0o600))
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dataFolder, name))
+		require.NoError(t, err)
+
+		return string(data)
+	}
+
+	write(localID+".csv", "title,cid\nfirst,1\nsecond,2\n")
+	write(localID+".reviews.csv", "cid,review_id\n1,a\n1,b\n2,c\n")
+	write(workingID+".csv", "title,cid\nfirst,1\n")
+
+	store := &fakeStore{objects: map[string][]byte{
+		storedID + ".csv": []byte("title,cid\nfirst,1\nthird,3\n"),
+	}}
+
+	repo := &jobsRepo{fakeRepo{jobs: []web.Job{
+		{ID: localID, Status: web.StatusOK},
+		{ID: storedID, Status: web.StatusOK, Data: web.JobData{File: &web.JobFile{Key: storedID + ".csv", Size: 26}}},
+		{ID: workingID, Status: web.StatusWorking},
+	}}}
+
+	svc := web.NewService(repo, dataFolder, web.WithFileStore(store, "bucket"))
+
+	ans, err := svc.PurgePlace(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, 4, ans.Removed)
+	require.Equal(t, []string{localID, storedID}, ans.Jobs)
+	require.Equal(t, []string{workingID}, ans.Skipped)
+
+	require.Equal(t, "title,cid\nsecond,2\n", read(localID+".csv"))
+	require.Equal(t, "cid,review_id\n2,c\n", read(localID+".reviews.csv"))
+	require.Equal(t, "title,cid\nfirst,1\n", read(workingID+".csv"))
+	require.Equal(t, "title,cid\nthird,3\n", string(store.objects[storedID+".csv"]))
+
+	for _, job := range repo.jobs[:2] {
+		require.Equal(t, 1, job.Data.Revision)
+	}
+
+	require.Equal(t, int64(len("title,cid\nthird,3\n")), repo.jobs[1].Data.File.Size)
+}
+
+func Test_Requeue(t *testing.T) {
+	const (
+		runningID = "9c1a2b4e-6f5d-4c3b-8a2e-1d0f9e8c7b6a"
+		staleID   = "0b6e0f2c-3a4d-4e5f-9a8b-7c6d5e4f3a2b"
+		failedID  = "5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a"
+	)
+
+	now := time.Now().UTC()
+
+	repo := &jobsRepo{fakeRepo{jobs: []web.Job{
+		{ID: runningID, Status: web.StatusWorking, HeartbeatAt: now},
+		{ID: staleID, Status: web.StatusWorking, HeartbeatAt: now.Add(-time.Hour)},
+		{ID: failedID, Status: web.StatusFailed},
+	}}}
+
+	// without heartbeats a working job may still be running
+	svc := web.NewService(repo, t.TempDir())
+
+	_, err := svc.Requeue(context.Background(), staleID)
+	require.ErrorIs(t, err, web.ErrInvalidStatus)
+
+	svc = web.NewService(repo, t.TempDir(), web.WithStaleJobTimeout(10*time.Minute))
+
+	_, err = svc.Requeue(context.Background(), runningID)
+	require.ErrorIs(t, err, web.ErrInvalidStatus)
+
+	for _, id := range []string{staleID, failedID} {
+		job, err := svc.Requeue(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, web.StatusPending, job.Status)
+	}
+
+	require.Equal(t, web.StatusWorking, repo.jobs[0].Status)
+}
@@ -0,0 +1,500 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultShareHours = 7 * 24
+	maxShareHours     = 90 * 24
+	// the rows of the shared page, the csv download has all of them
+	sharedPageRows = 500
+)
+
+// ErrShareNotFound is returned for a share token that does not exist, was
+// revoked or expired. The reasons are not told apart, so that the page of
+// a revoked link does not tell that it existed.
+var ErrShareNotFound = errors.New("the shared link does not exist or expired")
+
+// Share is a public, read-only link to the results of a job, for people
+// without access to the server.
+type Share struct {
+	ID    string `json:"id"`
+	JobID string `json:"job_id"`
+	// URL is the link, only returned when the share is created: the
+	// token is not stored, only its hash.
+	URL       string     `json:"url,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	TokenHash string     `json:"-"`
+}
+
+// Active reports whether the link can be opened at now.
+func (s *Share) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// ShareRepository stores the shares.
+type ShareRepository interface {
+	CreateShare(context.Context, *Share) error
+	GetShare(ctx context.Context, id string) (Share, error)
+	GetShareByTokenHash(ctx context.Context, tokenHash string) (Share, error)
+	// SelectShares returns the shares of the job, the latest first.
+	SelectShares(ctx context.Context, jobID string) ([]Share, error)
+	UpdateShare(context.Context, *Share) error
+}
+
+// WithShares enables the share links of the job results, stored in repo.
+func WithShares(repo ShareRepository) ServiceOption {
+	return func(s *Service) {
+		s.shares = repo
+	}
+}
+
+// CreateShare creates a link to the results of the job that expires after
+// ttl. It returns the share and its token.
+func (s *Service) CreateShare(ctx context.Context, jobID string, ttl time.Duration) (Share, string, error) {
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(secret); err != nil {
+		return Share{}, "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	now := time.Now().UTC()
+
+	share := Share{
+		ID:        uuid.New().String(),
+		JobID:     jobID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		TokenHash: hashToken(token),
+	}
+
+	if err := s.shares.CreateShare(ctx, &share); err != nil {
+		return Share{}, "", err
+	}
+
+	return share, token, nil
+}
+
+// Shares returns the shares of the job, the latest first.
+func (s *Service) Shares(ctx context.Context, jobID string) ([]Share, error) {
+	return s.shares.SelectShares(ctx, jobID)
+}
+
+// RevokeShare disables a link. Revoking a revoked link keeps the first
+// revocation time.
+func (s *Service) RevokeShare(ctx context.Context, id string) (Share, error) {
+	share, err := s.shares.GetShare(ctx, id)
+	if err != nil {
+		return Share{}, err
+	}
+
+	if share.RevokedAt != nil {
+		return share, nil
+	}
+
+	now := time.Now().UTC()
+	share.RevokedAt = &now
+
+	if err := s.shares.UpdateShare(ctx, &share); err != nil {
+		return Share{}, err
+	}
+
+	return share, nil
+}
+
+// SharedJob returns the job of an active share token.
+func (s *Service) SharedJob(ctx context.Context, token string) (Job, Share, error) {
+	share, err := s.shares.GetShareByTokenHash(ctx, hashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, Share{}, ErrShareNotFound
+	} else if err != nil {
+		return Job{}, Share{}, err
+	}
+
+	if !share.Active(time.Now().UTC()) {
+		return Job{}, Share{}, ErrShareNotFound
+	}
+
+	job, err := s.repo.Get(ctx, share.JobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, Share{}, ErrShareNotFound
+	} else if err != nil {
+		return Job{}, Share{}, err
+	}
+
+	return job, share, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Server) sharesEnabled(w http.ResponseWriter) bool {
+	if s.svc.shares != nil {
+		return true
+	}
+
+	renderJSON(w, http.StatusNotImplemented, apiError{
+		Code:    http.StatusNotImplemented,
+		Message: "the share links are not enabled",
+	})
+
+	return false
+}
+
+type apiCreateShareRequest struct {
+	// ExpiresInHours is the lifetime of the link, 7 days by default and 90
+	// days at most.
+	ExpiresInHours int `json:"expires_in_hours"`
+}
+
+type apiShares struct {
+	Shares []Share `json:"shares"`
+}
+
+// apiCreateShare creates a public link to the results of a finished job.
+func (s *Server) apiCreateShare(w http.ResponseWriter, r *http.Request) {
+	if !s.sharesEnabled(w) {
+		return
+	}
+
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Invalid ID",
+		})
+
+		return
+	}
+
+	var req apiCreateShareRequest
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			renderJSON(w, http.StatusUnprocessableEntity, apiError{
+				Code:    http.StatusUnprocessableEntity,
+				Message: err.Error(),
+			})
+
+			return
+		}
+	}
+
+	if req.ExpiresInHours == 0 {
+		req.ExpiresInHours = defaultShareHours
+	}
+
+	if req.ExpiresInHours < 1 || req.ExpiresInHours > maxShareHours {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("expires_in_hours must be between 1 and %d", maxShareHours),
+		})
+
+		return
+	}
+
+	job, err := s.svc.Get(r.Context(), id.String())
+	if err != nil {
+		renderJSON(w, http.StatusNotFound, apiError{
+			Code:    http.StatusNotFound,
+			Message: http.StatusText(http.StatusNotFound),
+		})
+
+		return
+	}
+
+	if job.Status != StatusOK {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "only the results of the finished jobs can be shared",
+		})
+
+		return
+	}
+
+	share, token, err := s.svc.CreateShare(r.Context(), job.ID, time.Duration(req.ExpiresInHours)*time.Hour)
+	if err != nil {
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	share.URL = baseURL(r) + "/shared/" + token
+
+	renderJSON(w, http.StatusCreated, share)
+}
+
+// apiJobShares lists the links of a job, including the expired and revoked
+// ones.
+func (s *Server) apiJobShares(w http.ResponseWriter, r *http.Request) {
+	if !s.sharesEnabled(w) {
+		return
+	}
+
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Invalid ID",
+		})
+
+		return
+	}
+
+	shares, err := s.svc.Shares(r.Context(), id.String())
+	if err != nil {
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if shares == nil {
+		shares = []Share{}
+	}
+
+	renderJSON(w, http.StatusOK, apiShares{Shares: shares})
+}
+
+// apiRevokeShare disables a link.
+func (s *Server) apiRevokeShare(w http.ResponseWriter, r *http.Request) {
+	if !s.sharesEnabled(w) {
+		return
+	}
+
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Invalid ID",
+		})
+
+		return
+	}
+
+	share, err := s.svc.RevokeShare(r.Context(), id.String())
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		renderJSON(w, http.StatusNotFound, apiError{
+			Code:    http.StatusNotFound,
+			Message: http.StatusText(http.StatusNotFound),
+		})
+	case err != nil:
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	default:
+		renderJSON(w, http.StatusOK, share)
+	}
+}
+
+// baseURL returns the scheme and host the request was sent to.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
+
+type sharedPlace struct {
+	Title    string
+	Category string
+	Address  string
+	Phone    string
+	Website  string
+	Rating   string
+	Reviews  string
+	Link     string
+}
+
+type sharedPage struct {
+	Name        string
+	ExpiresAt   time.Time
+	DownloadURL string
+	Places      []sharedPlace
+	Total       int
+	Brand       Branding
+	// Moved is set when the results are in the file store, they can only
+	// be downloaded.
+	Moved bool
+}
+
+// sharedJob returns the job of the token of the path, or sends the error.
+// The shared pages are not cached and do not send the token to the sites
+// they link to.
+func (s *Server) sharedJob(w http.ResponseWriter, r *http.Request) (Job, Share, bool) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return Job{}, Share{}, false
+	}
+
+	if s.svc.shares == nil {
+		http.NotFound(w, r)
+
+		return Job{}, Share{}, false
+	}
+
+	job, share, err := s.svc.SharedJob(r.Context(), r.PathValue("token"))
+	if errors.Is(err, ErrShareNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return Job{}, Share{}, false
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return Job{}, Share{}, false
+	}
+
+	return job, share, true
+}
+
+// sharedResults renders the places of a shared job as a read-only table.
+func (s *Server) sharedResults(w http.ResponseWriter, r *http.Request) {
+	job, share, ok := s.sharedJob(w, r)
+	if !ok {
+		return
+	}
+
+	tmpl, ok := s.tmpl["static/templates/shared.html"]
+	if !ok {
+		http.Error(w, "missing tpl", http.StatusInternalServerError)
+
+		return
+	}
+
+	page := sharedPage{
+		Name:        job.Name,
+		ExpiresAt:   share.ExpiresAt,
+		DownloadURL: "/shared/" + url.PathEscape(r.PathValue("token")) + "/download",
+		Moved:       job.Data.File != nil,
+		Brand:       s.branding,
+	}
+
+	if !page.Moved {
+		filePath, err := s.svc.GetCSV(r.Context(), job.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		f, err := os.Open(filePath)
+		if err != nil {
+			http.Error(w, "Failed to open file", http.StatusInternalServerError)
+
+			return
+		}
+
+		defer f.Close()
+
+		err = eachRecord(f, func(record map[string]string) error {
+			page.Total++
+
+			if len(page.Places) < sharedPageRows {
+				page.Places = append(page.Places, sharedPlace{
+					Title:    record["title"],
+					Category: record["category"],
+					Address:  record["address"],
+					Phone:    record["phone"],
+					Website:  record["website"],
+					Rating:   record["review_rating"],
+					Reviews:  record["review_count"],
+					Link:     record["link"],
+				})
+			}
+
+			return nil
+		})
+		if err != nil {
+			http.Error(w, "Failed to read the results", http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	if err := tmpl.Execute(w, page); err != nil {
+		log.Printf("failed to render the shared results of job %s: %v", job.ID, err)
+	}
+}
+
+// sharedDownload sends the results file of a shared job.
+func (s *Server) sharedDownload(w http.ResponseWriter, r *http.Request) {
+	job, _, ok := s.sharedJob(w, r)
+	if !ok {
+		return
+	}
+
+	if job.Data.File != nil {
+		u, _, err := s.svc.DownloadURL(r.Context(), &job)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		http.Redirect(w, r, u, http.StatusTemporaryRedirect)
+
+		return
+	}
+
+	filePath, err := s.svc.GetCSV(r.Context(), job.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		http.Error(w, "Failed to open file", http.StatusInternalServerError)
+
+		return
+	}
+
+	defer file.Close()
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", job.ID))
+	w.Header().Set("Content-Type", "text/csv")
+
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("failed to send the shared results of job %s: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,163 @@
+package web_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+type fakeShares struct {
+	shares map[string]web.Share
+}
+
+func (f *fakeShares) CreateShare(_ context.Context, share *web.Share) error {
+	f.shares[share.ID] = *share
+
+	return nil
+}
+
+func (f *fakeShares) GetShare(_ context.Context, id string) (web.Share, error) {
+	share, ok := f.shares[id]
+	if !ok {
+		return web.Share{}, sql.ErrNoRows
+	}
+
+	return share, nil
+}
+
+func (f *fakeShares) GetShareByTokenHash(_ context.Context, tokenHash string) (web.Share, error) {
+	for _, share := range f.shares {
+		if share.TokenHash == tokenHash {
+			return share, nil
+		}
+	}
+
+	return web.Share{}, sql.ErrNoRows
+}
+
+func (f *fakeShares) SelectShares(_ context.Context, jobID string) ([]web.Share, error) {
+	var ans []web.Share
+
+	for _, share := range f.shares {
+		if share.JobID == jobID {
+			ans = append(ans, share)
+		}
+	}
+
+	return ans, nil
+}
+
+func (f *fakeShares) UpdateShare(_ context.Context, share *web.Share) error {
+	f.shares[share.ID] = *share
+
+	return nil
+}
+
+// jobsRepo returns and updates its jobs by id.
+type jobsRepo struct {
+	fakeRepo
+}
+
+func (f *jobsRepo) Get(_ context.Context, id string) (web.Job, error) {
+	for _, job := range f.jobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+
+	return web.Job{}, sql.ErrNoRows
+}
+
+func (f *jobsRepo) Update(_ context.Context, job *web.Job) error {
+	for i := range f.jobs {
+		if f.jobs[i].ID == job.ID {
+			f.jobs[i] = *job
+		}
+	}
+
+	return nil
+}
+
+func Test_Share(t *testing.T) {
+	const (
+		jobID     = "9c1a2b4e-6f5d-4c3b-8a2e-1d0f9e8c7b6a"
+		workingID = "0b6e0f2c-3a4d-4e5f-9a8b-7c6d5e4f3a2b"
+	)
+
+	dataFolder := t.TempDir()
+
+	results := "title,category,review_rating,website\n<b>Joe's</b>,Diner,4.5,https://joes.example.com\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dataFolder, jobID+".csv"), []byte(results), 0o600))
+
+	repo := &jobsRepo{fakeRepo{jobs: []web.Job{
+		{ID: jobID, Name: "Diners", Status: web.StatusOK},
+		{ID: workingID, Name: "Cafes", Status: web.StatusWorking},
+	}}}
+
+	shares := &fakeShares{shares: map[string]web.Share{}}
+
+	srv, err := web.New(web.NewService(repo, dataFolder, web.WithShares(shares)), ":0")
+	require.NoError(t, err)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/v1/jobs/"+workingID+"/shares", "")
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = do(http.MethodPost, "/api/v1/jobs/"+jobID+"/shares", `{"expires_in_hours": 10000}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = do(http.MethodPost, "/api/v1/jobs/"+jobID+"/shares", `{"expires_in_hours": 24}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var share web.Share
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &share))
+	require.Equal(t, jobID, share.JobID)
+	require.True(t, strings.HasPrefix(share.URL, "http://example.com/shared/"))
+
+	// only the hash of the token is stored
+	token := strings.TrimPrefix(share.URL, "http://example.com/shared/")
+	require.NotContains(t, shares.shares[share.ID].TokenHash, token)
+
+	rec = do(http.MethodGet, "/shared/"+token, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "&lt;b&gt;Joe&#39;s&lt;/b&gt;")
+	require.Contains(t, rec.Body.String(), `href="https://joes.example.com"`)
+	require.Equal(t, "no-referrer", rec.Header().Get("Referrer-Policy"))
+
+	rec = do(http.MethodGet, "/shared/"+token+"/download", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, results, rec.Body.String())
+
+	rec = do(http.MethodGet, "/shared/not-a-token", "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = do(http.MethodGet, "/api/v1/jobs/"+jobID+"/shares", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), share.ID)
+	require.NotContains(t, rec.Body.String(), token)
+
+	rec = do(http.MethodDelete, "/api/v1/shares/"+share.ID, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = do(http.MethodGet, "/shared/"+token, "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = do(http.MethodGet, "/shared/"+token+"/download", "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
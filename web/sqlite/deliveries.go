@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+var _ web.DeliveryRepository = (*repo)(nil)
+
+const deliveryColumns = `id, job_id, destination, error, payload, entries, attempts, created_at, replayed_at`
+
+func (repo *repo) CreateDeliveryFailure(ctx context.Context, failure *web.DeliveryFailure) error {
+	const q = `INSERT INTO delivery_failures (` + deliveryColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := repo.db.ExecContext(ctx, q,
+		failure.ID, failure.JobID, failure.Destination, failure.Error, string(failure.Payload),
+		failure.Entries, failure.Attempts, failure.CreatedAt.UTC().Unix(), unixOrZero(failure.ReplayedAt),
+	)
+
+	return err
+}
+
+func (repo *repo) GetDeliveryFailure(ctx context.Context, id string) (web.DeliveryFailure, error) {
+	const q = `SELECT ` + deliveryColumns + ` FROM delivery_failures WHERE id = ?`
+
+	return scanDeliveryFailure(repo.db.QueryRowContext(ctx, q, id))
+}
+
+func (repo *repo) SelectDeliveryFailures(ctx context.Context, jobID string) ([]web.DeliveryFailure, error) {
+	q := `SELECT ` + deliveryColumns + ` FROM delivery_failures WHERE replayed_at = 0`
+
+	var args []any
+
+	if jobID != "" {
+		q += ` AND job_id = ?`
+
+		args = append(args, jobID)
+	}
+
+	q += ` ORDER BY created_at`
+
+	rows, err := repo.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ans []web.DeliveryFailure
+
+	for rows.Next() {
+		failure, err := scanDeliveryFailure(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, failure)
+	}
+
+	return ans, rows.Err()
+}
+
+func (repo *repo) UpdateDeliveryFailure(ctx context.Context, failure *web.DeliveryFailure) error {
+	const q = `UPDATE delivery_failures SET error = ?, attempts = ?, replayed_at = ? WHERE id = ?`
+
+	_, err := repo.db.ExecContext(ctx, q, failure.Error, failure.Attempts, unixOrZero(failure.ReplayedAt), failure.ID)
+
+	return err
+}
+
+func scanDeliveryFailure(row scannable) (web.DeliveryFailure, error) {
+	var (
+		ans        web.DeliveryFailure
+		payload    string
+		createdAt  int64
+		replayedAt int64
+	)
+
+	err := row.Scan(&ans.ID, &ans.JobID, &ans.Destination, &ans.Error, &payload,
+		&ans.Entries, &ans.Attempts, &createdAt, &replayedAt)
+	if err != nil {
+		return web.DeliveryFailure{}, err
+	}
+
+	ans.Payload = []byte(payload)
+	ans.CreatedAt = time.Unix(createdAt, 0).UTC()
+
+	if replayedAt > 0 {
+		t := time.Unix(replayedAt, 0).UTC()
+		ans.ReplayedAt = &t
+	}
+
+	return ans, nil
+}
+
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+
+	return t.UTC().Unix()
+}
+
+func createDeliverySchema(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS delivery_failures (
+			id TEXT PRIMARY KEY,
+			job_id TEXT NOT NULL,
+			destination TEXT NOT NULL,
+			error TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			entries INT NOT NULL,
+			attempts INT NOT NULL,
+			created_at INT NOT NULL,
+			replayed_at INT NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS delivery_failures_job_id ON delivery_failures (job_id)
+	`)
+
+	return err
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+var _ web.ShareRepository = (*repo)(nil)
+
+const shareColumns = `id, job_id, token_hash, created_at, expires_at, revoked_at`
+
+func (repo *repo) CreateShare(ctx context.Context, share *web.Share) error {
+	const q = `INSERT INTO shares (` + shareColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := repo.db.ExecContext(ctx, q,
+		share.ID, share.JobID, share.TokenHash,
+		share.CreatedAt.UTC().Unix(), share.ExpiresAt.UTC().Unix(), unixOrZero(share.RevokedAt),
+	)
+
+	return err
+}
+
+func (repo *repo) GetShare(ctx context.Context, id string) (web.Share, error) {
+	const q = `SELECT ` + shareColumns + ` FROM shares WHERE id = ?`
+
+	return scanShare(repo.db.QueryRowContext(ctx, q, id))
+}
+
+func (repo *repo) GetShareByTokenHash(ctx context.Context, tokenHash string) (web.Share, error) {
+	const q = `SELECT ` + shareColumns + ` FROM shares WHERE token_hash = ?`
+
+	return scanShare(repo.db.QueryRowContext(ctx, q, tokenHash))
+}
+
+func (repo *repo) SelectShares(ctx context.Context, jobID string) ([]web.Share, error) {
+	const q = `SELECT ` + shareColumns + ` FROM shares WHERE job_id = ? ORDER BY created_at DESC`
+
+	rows, err := repo.db.QueryContext(ctx, q, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ans []web.Share
+
+	for rows.Next() {
+		share, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, share)
+	}
+
+	return ans, rows.Err()
+}
+
+func (repo *repo) UpdateShare(ctx context.Context, share *web.Share) error {
+	const q = `UPDATE shares SET expires_at = ?, revoked_at = ? WHERE id = ?`
+
+	_, err := repo.db.ExecContext(ctx, q, share.ExpiresAt.UTC().Unix(), unixOrZero(share.RevokedAt), share.ID)
+
+	return err
+}
+
+func scanShare(row scannable) (web.Share, error) {
+	var (
+		ans       web.Share
+		createdAt int64
+		expiresAt int64
+		revokedAt int64
+	)
+
+	if err := row.Scan(&ans.ID, &ans.JobID, &ans.TokenHash, &createdAt, &expiresAt, &revokedAt); err != nil {
+		return web.Share{}, err
+	}
+
+	ans.CreatedAt = time.Unix(createdAt, 0).UTC()
+	ans.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+
+	if revokedAt > 0 {
+		t := time.Unix(revokedAt, 0).UTC()
+		ans.RevokedAt = &t
+	}
+
+	return ans, nil
+}
+
+func createShareSchema(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS shares (
+			id TEXT PRIMARY KEY,
+			job_id TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			created_at INT NOT NULL,
+			expires_at INT NOT NULL,
+			revoked_at INT NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS shares_job_id ON shares (job_id)
+	`)
+
+	return err
+}
@@ -4,43 +4,77 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite" // sqlite driver
 
+	"github.com/gosom/google-maps-scraper/secrets"
 	"github.com/gosom/google-maps-scraper/web"
 )
 
 type repo struct {
-	db *sql.DB
+	db      *sql.DB
+	keyring *secrets.Keyring
 }
 
-func New(path string) (web.JobRepository, error) {
+// Option configures the repository.
+type Option func(*repo)
+
+// WithKeyring encrypts the proxies of the jobs, which may contain
+// credentials, with the keyring. The proxies stored in plaintext or with
+// an older key of the keyring are encrypted with the current key on
+// startup.
+func WithKeyring(keyring *secrets.Keyring) Option {
+	return func(r *repo) {
+		r.keyring = keyring
+	}
+}
+
+func New(path string, opts ...Option) (web.JobRepository, error) {
 	db, err := initDatabase(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return &repo{db: db}, nil
+	ans := repo{db: db}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	if ans.keyring != nil {
+		if err := ans.reencrypt(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ans, nil
 }
 
+const columns = `id, name, status, data, created_at, updated_at, attempts, heartbeat_at, stats, started_at`
+
 func (repo *repo) Get(ctx context.Context, id string) (web.Job, error) {
-	const q = `SELECT * from jobs WHERE id = ?`
+	const q = `SELECT ` + columns + ` from jobs WHERE id = ?`
 
 	row := repo.db.QueryRowContext(ctx, q, id)
 
-	return rowToJob(row)
+	return repo.rowToJob(row)
 }
 
 func (repo *repo) Create(ctx context.Context, job *web.Job) error {
-	item, err := jobToRow(job)
+	item, err := repo.jobToRow(job)
 	if err != nil {
 		return err
 	}
 
-	const q = `INSERT INTO jobs (id, name, status, data, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+	const q = `INSERT INTO jobs (` + columns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err = repo.db.ExecContext(ctx, q, item.ID, item.Name, item.Status, item.Data, item.CreatedAt, item.UpdatedAt)
+	_, err = repo.db.ExecContext(ctx, q,
+		item.ID, item.Name, item.Status, item.Data, item.CreatedAt, item.UpdatedAt, item.Attempts, item.HeartbeatAt, item.Stats, item.StartedAt,
+	)
 	if err != nil {
 		return err
 	}
@@ -57,17 +91,32 @@ func (repo *repo) Delete(ctx context.Context, id string) error {
 }
 
 func (repo *repo) Select(ctx context.Context, params web.SelectParams) ([]web.Job, error) {
-	q := `SELECT * from jobs`
+	q := `SELECT ` + columns + ` from jobs`
 
-	var args []any
+	var (
+		where []string
+		args  []any
+	)
 
 	if params.Status != "" {
-		q += ` WHERE status = ?`
-
+		where = append(where, `status = ?`)
 		args = append(args, params.Status)
 	}
 
-	q += " ORDER BY created_at DESC"
+	if !params.HeartbeatBefore.IsZero() {
+		where = append(where, `heartbeat_at < ?`)
+		args = append(args, params.HeartbeatBefore.Unix())
+	}
+
+	if len(where) > 0 {
+		q += ` WHERE ` + strings.Join(where, " AND ")
+	}
+
+	if params.OldestFirst {
+		q += " ORDER BY created_at ASC"
+	} else {
+		q += " ORDER BY created_at DESC"
+	}
 
 	if params.Limit > 0 {
 		q += " LIMIT ?"
@@ -85,7 +134,7 @@ func (repo *repo) Select(ctx context.Context, params web.SelectParams) ([]web.Jo
 	var ans []web.Job
 
 	for rows.Next() {
-		job, err := rowToJob(rows)
+		job, err := repo.rowToJob(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -101,14 +150,24 @@ func (repo *repo) Select(ctx context.Context, params web.SelectParams) ([]web.Jo
 }
 
 func (repo *repo) Update(ctx context.Context, job *web.Job) error {
-	item, err := jobToRow(job)
+	item, err := repo.jobToRow(job)
 	if err != nil {
 		return err
 	}
 
-	const q = `UPDATE jobs SET name = ?, status = ?, data = ?, updated_at = ? WHERE id = ?`
+	const q = `UPDATE jobs SET name = ?, status = ?, data = ?, updated_at = ?, attempts = ?, heartbeat_at = ?, stats = ?, started_at = ? WHERE id = ?`
 
-	_, err = repo.db.ExecContext(ctx, q, item.Name, item.Status, item.Data, item.UpdatedAt, item.ID)
+	_, err = repo.db.ExecContext(ctx, q,
+		item.Name, item.Status, item.Data, item.UpdatedAt, item.Attempts, item.HeartbeatAt, item.Stats, item.StartedAt, item.ID,
+	)
+
+	return err
+}
+
+func (repo *repo) Heartbeat(ctx context.Context, id string) error {
+	const q = `UPDATE jobs SET heartbeat_at = ? WHERE id = ?`
+
+	_, err := repo.db.ExecContext(ctx, q, time.Now().UTC().Unix(), id)
 
 	return err
 }
@@ -117,19 +176,28 @@ type scannable interface {
 	Scan(dest ...any) error
 }
 
-func rowToJob(row scannable) (web.Job, error) {
+func (repo *repo) rowToJob(row scannable) (web.Job, error) {
 	var j job
 
-	err := row.Scan(&j.ID, &j.Name, &j.Status, &j.Data, &j.CreatedAt, &j.UpdatedAt)
+	err := row.Scan(&j.ID, &j.Name, &j.Status, &j.Data, &j.CreatedAt, &j.UpdatedAt, &j.Attempts, &j.HeartbeatAt, &j.Stats, &j.StartedAt)
 	if err != nil {
 		return web.Job{}, err
 	}
 
 	ans := web.Job{
-		ID:     j.ID,
-		Name:   j.Name,
-		Status: j.Status,
-		Date:   time.Unix(j.CreatedAt, 0).UTC(),
+		ID:       j.ID,
+		Name:     j.Name,
+		Status:   j.Status,
+		Date:     time.Unix(j.CreatedAt, 0).UTC(),
+		Attempts: j.Attempts,
+	}
+
+	if j.HeartbeatAt > 0 {
+		ans.HeartbeatAt = time.Unix(j.HeartbeatAt, 0).UTC()
+	}
+
+	if j.StartedAt > 0 {
+		ans.StartedAt = time.Unix(j.StartedAt, 0).UTC()
 	}
 
 	err = json.Unmarshal([]byte(j.Data), &ans.Data)
@@ -137,32 +205,173 @@ func rowToJob(row scannable) (web.Job, error) {
 		return web.Job{}, err
 	}
 
+	ans.Data.Proxies, err = repo.decryptProxies(ans.Data.Proxies)
+	if err != nil {
+		return web.Job{}, fmt.Errorf("job %s: %w", ans.ID, err)
+	}
+
+	if j.Stats != "" {
+		ans.Stats = new(web.JobStats)
+
+		if err := json.Unmarshal([]byte(j.Stats), ans.Stats); err != nil {
+			return web.Job{}, err
+		}
+	}
+
 	return ans, nil
 }
 
-func jobToRow(item *web.Job) (job, error) {
-	data, err := json.Marshal(item.Data)
+func (repo *repo) jobToRow(item *web.Job) (job, error) {
+	data, err := repo.marshalData(item.Data)
 	if err != nil {
 		return job{}, err
 	}
 
-	return job{
+	ans := job{
 		ID:        item.ID,
 		Name:      item.Name,
 		Status:    item.Status,
 		Data:      string(data),
 		CreatedAt: item.Date.Unix(),
 		UpdatedAt: time.Now().UTC().Unix(),
-	}, nil
+		Attempts:  item.Attempts,
+	}
+
+	if !item.HeartbeatAt.IsZero() {
+		ans.HeartbeatAt = item.HeartbeatAt.Unix()
+	}
+
+	if !item.StartedAt.IsZero() {
+		ans.StartedAt = item.StartedAt.Unix()
+	}
+
+	if item.Stats != nil {
+		stats, err := json.Marshal(item.Stats)
+		if err != nil {
+			return job{}, err
+		}
+
+		ans.Stats = string(stats)
+	}
+
+	return ans, nil
+}
+
+// marshalData returns the json of the data with the proxies encrypted.
+func (repo *repo) marshalData(data web.JobData) ([]byte, error) {
+	if repo.keyring != nil && len(data.Proxies) > 0 {
+		proxies := make([]string, len(data.Proxies))
+
+		for i, p := range data.Proxies {
+			encrypted, err := repo.keyring.Encrypt(p)
+			if err != nil {
+				return nil, err
+			}
+
+			proxies[i] = encrypted
+		}
+
+		data.Proxies = proxies
+	}
+
+	return json.Marshal(data)
+}
+
+func (repo *repo) decryptProxies(proxies []string) ([]string, error) {
+	for i, p := range proxies {
+		if !secrets.IsEncrypted(p) {
+			continue
+		}
+
+		if repo.keyring == nil {
+			return nil, errors.New("the proxies are encrypted and no encryption keys are set")
+		}
+
+		decrypted, err := repo.keyring.Decrypt(p)
+		if err != nil {
+			return nil, err
+		}
+
+		proxies[i] = decrypted
+	}
+
+	return proxies, nil
+}
+
+// reencrypt encrypts the proxies that are not encrypted with the current
+// key of the keyring.
+func (repo *repo) reencrypt(ctx context.Context) error {
+	rows, err := repo.db.QueryContext(ctx, `SELECT id, data FROM jobs`)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	updates := map[string][]byte{}
+
+	for rows.Next() {
+		var id, raw string
+
+		if err := rows.Scan(&id, &raw); err != nil {
+			return err
+		}
+
+		var data web.JobData
+
+		if err := json.Unmarshal([]byte(raw), &data); err != nil {
+			return err
+		}
+
+		stale := false
+
+		for _, p := range data.Proxies {
+			if !repo.keyring.Current(p) {
+				stale = true
+
+				break
+			}
+		}
+
+		if !stale {
+			continue
+		}
+
+		data.Proxies, err = repo.decryptProxies(data.Proxies)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", id, err)
+		}
+
+		updates[id], err = repo.marshalData(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, data := range updates {
+		if _, err := repo.db.ExecContext(ctx, `UPDATE jobs SET data = ? WHERE id = ?`, string(data), id); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 type job struct {
-	ID        string
-	Name      string
-	Status    string
-	Data      string
-	CreatedAt int64
-	UpdatedAt int64
+	ID          string
+	Name        string
+	Status      string
+	Data        string
+	CreatedAt   int64
+	UpdatedAt   int64
+	Attempts    int
+	HeartbeatAt int64
+	Stats       string
+	StartedAt   int64
 }
 
 func initDatabase(path string) (*sql.DB, error) {
@@ -190,6 +399,73 @@ func createSchema(db *sql.DB) error {
 			updated_at INT NOT NULL
 		)
 	`)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := migrateSchema(db); err != nil {
+		return err
+	}
+
+	if err := createDeliverySchema(db); err != nil {
+		return err
+	}
+
+	return createShareSchema(db)
+}
+
+// migrateSchema adds the columns that were introduced after the first
+// release to existing databases.
+func migrateSchema(db *sql.DB) error {
+	rows, err := db.Query(`PRAGMA table_info(jobs)`)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	existing := map[string]bool{}
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			ctype     string
+			notnull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+
+		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dfltValue, &pk); err != nil {
+			return err
+		}
+
+		existing[name] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	newColumns := []struct {
+		name string
+		def  string
+	}{
+		{"attempts", "INT NOT NULL DEFAULT 0"},
+		{"heartbeat_at", "INT NOT NULL DEFAULT 0"},
+		{"stats", "TEXT NOT NULL DEFAULT ''"},
+		{"started_at", "INT NOT NULL DEFAULT 0"},
+	}
+
+	for _, c := range newColumns {
+		if existing[c.name] {
+			continue
+		}
+
+		if _, err := db.Exec(`ALTER TABLE jobs ADD COLUMN ` + c.name + ` ` + c.def); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
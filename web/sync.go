@@ -0,0 +1,185 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	defaultSyncTimeout    = 60
+	maxSyncTimeout        = 90
+	defaultSyncMaxResults = 20
+	maxSyncMaxResults     = 100
+	maxSyncDepth          = 3
+)
+
+// SyncScraper scrapes the keywords or the places of data until ctx is done
+// and returns the places found so far, without creating a job.
+type SyncScraper func(ctx context.Context, data *JobData) ([]*gmaps.Entry, error)
+
+// WithSyncScraper enables the endpoints that respond with the places
+// instead of a job: the quick scrape and the refresh of small lists of
+// places.
+func WithSyncScraper(scraper SyncScraper) ServerOption {
+	return func(s *Server) {
+		s.syncScraper = scraper
+	}
+}
+
+type apiSyncScrapeRequest struct {
+	Keyword    string `json:"keyword"`
+	Lang       string `json:"lang"`
+	Depth      int    `json:"depth"`
+	MaxResults int    `json:"max_results"`
+	Email      bool   `json:"email"`
+	FastMode   bool   `json:"fast_mode"`
+	Lat        string `json:"lat"`
+	Lon        string `json:"lon"`
+	Zoom       int    `json:"zoom"`
+	Radius     int    `json:"radius"`
+	// Timeout is the budget of the scrape in seconds, up to 90.
+	Timeout int `json:"timeout"`
+}
+
+// apiPlacesResponse are the places of a synchronous scrape. Partial is set
+// when the budget ran out before the scrape completed.
+type apiPlacesResponse struct {
+	Places  []*gmaps.Entry `json:"places"`
+	Partial bool           `json:"partial"`
+}
+
+// apiScrapeSync scrapes one keyword within a budget of seconds and responds
+// with the places, for the clients that cannot poll a job.
+func (s *Server) apiScrapeSync(w http.ResponseWriter, r *http.Request) {
+	if s.syncScraper == nil {
+		renderJSON(w, http.StatusNotImplemented, apiError{
+			Code:    http.StatusNotImplemented,
+			Message: "synchronous scrapes are not supported",
+		})
+
+		return
+	}
+
+	var req apiSyncScrapeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if req.Lang == "" {
+		req.Lang = "en"
+	}
+
+	if req.Depth == 0 {
+		req.Depth = 1
+	}
+
+	if req.MaxResults == 0 {
+		req.MaxResults = defaultSyncMaxResults
+	}
+
+	if req.Timeout == 0 {
+		req.Timeout = defaultSyncTimeout
+	}
+
+	data := JobData{
+		Keywords:   []string{strings.TrimSpace(req.Keyword)},
+		Lang:       req.Lang,
+		Depth:      req.Depth,
+		MaxResults: req.MaxResults,
+		Email:      req.Email,
+		FastMode:   req.FastMode,
+		Lat:        req.Lat,
+		Lon:        req.Lon,
+		Zoom:       req.Zoom,
+		Radius:     req.Radius,
+		MaxTime:    minMaxTime,
+	}
+
+	var verr ValidationError
+
+	if data.Keywords[0] == "" {
+		verr.add("keyword", "is required")
+	}
+
+	if req.Depth > maxSyncDepth {
+		verr.add("depth", "must be 1-3")
+	}
+
+	if req.MaxResults > maxSyncMaxResults {
+		verr.add("max_results", "must be 1-100")
+	}
+
+	if req.Timeout < 1 || req.Timeout > maxSyncTimeout {
+		verr.add("timeout", "must be 1-90 seconds")
+	}
+
+	data.validate(&verr)
+
+	if err := verr.errOrNil(); err != nil {
+		renderJSON(w, http.StatusUnprocessableEntity, err)
+
+		return
+	}
+
+	s.scrapeSync(w, r, &data, time.Duration(req.Timeout)*time.Second)
+}
+
+// scrapeSync runs the synchronous scraper with the budget and renders the
+// places found.
+func (s *Server) scrapeSync(w http.ResponseWriter, r *http.Request, data *JobData, budget time.Duration) {
+	// the response is written after the budget, later than the write
+	// timeout of the server allows
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(budget + 30*time.Second)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("could not extend the write deadline of %s: %v", r.URL.Path, err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), budget)
+	defer cancel()
+
+	entries, err := s.syncScraper(ctx, data)
+
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		renderJSON(w, http.StatusGatewayTimeout, apiError{
+			Code:    http.StatusGatewayTimeout,
+			Message: err.Error(),
+		})
+
+		return
+	case err != nil:
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if data.MaxResults > 0 && len(entries) > data.MaxResults {
+		entries = entries[:data.MaxResults]
+	}
+
+	ans := apiPlacesResponse{
+		Places:  entries,
+		Partial: ctx.Err() != nil,
+	}
+
+	if ans.Places == nil {
+		ans.Places = []*gmaps.Entry{}
+	}
+
+	renderJSON(w, http.StatusOK, ans)
+}
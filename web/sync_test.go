@@ -0,0 +1,58 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_ScrapeSync(t *testing.T) {
+	scraper := func(ctx context.Context, data *web.JobData) ([]*gmaps.Entry, error) {
+		if data.Keywords[0] == "slow" {
+			<-ctx.Done()
+		}
+
+		return []*gmaps.Entry{{Title: "a"}, {Title: "b"}, {Title: "c"}}, nil
+	}
+
+	srv, err := web.New(web.NewService(&fakeRepo{}, t.TempDir()), ":0", web.WithSyncScraper(scraper))
+	require.NoError(t, err)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/scrape/sync", strings.NewReader(body)))
+
+		return rec
+	}
+
+	var resp struct {
+		Places  []gmaps.Entry `json:"places"`
+		Partial bool          `json:"partial"`
+	}
+
+	rec := post(`{"keyword": "coffee in ilion", "max_results": 2}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Places, 2)
+	require.False(t, resp.Partial)
+
+	rec = post(`{"keyword": "slow", "timeout": 1}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Places, 3)
+	require.True(t, resp.Partial)
+
+	rec = post(`{"keyword": "", "depth": 4, "timeout": 120}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	require.Contains(t, rec.Body.String(), `"keyword"`)
+	require.Contains(t, rec.Body.String(), `"depth"`)
+	require.Contains(t, rec.Body.String(), `"timeout"`)
+}
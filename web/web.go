@@ -4,6 +4,7 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -17,18 +18,58 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/gosom/google-maps-scraper/dryrun"
+	"github.com/gosom/google-maps-scraper/kml"
 )
 
 //go:embed static
 var static embed.FS
 
 type Server struct {
-	tmpl map[string]*template.Template
-	srv  *http.Server
-	svc  *Service
+	tmpl         map[string]*template.Template
+	srv          *http.Server
+	svc          *Service
+	adminToken   string
+	middlewares  []func(http.Handler) http.Handler
+	planner      Planner
+	syncScraper  SyncScraper
+	healthChecks []HealthCheck
+	replayer     Replayer
+	embedOrigins []string
+	branding     Branding
+}
+
+type ServerOption func(*Server)
+
+// Planner creates the seed jobs of a job and estimates its size
+// without scraping.
+type Planner func(ctx context.Context, data *JobData) (*dryrun.Plan, error)
+
+// WithPlanner enables dry runs of the job creation endpoint.
+func WithPlanner(p Planner) ServerOption {
+	return func(s *Server) {
+		s.planner = p
+	}
+}
+
+// WithAdminToken enables the admin API. Requests to it must send the token
+// in the Authorization header as a bearer token.
+func WithAdminToken(token string) ServerOption {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+// WithMiddleware wraps the routes with mw. Middlewares run in the order
+// they are added.
+func WithMiddleware(mw func(http.Handler) http.Handler) ServerOption {
+	return func(s *Server) {
+		s.middlewares = append(s.middlewares, mw)
+	}
 }
 
-func New(svc *Service, addr string) (*Server, error) {
+func New(svc *Service, addr string, opts ...ServerOption) (*Server, error) {
 	ans := Server{
 		svc:  svc,
 		tmpl: make(map[string]*template.Template),
@@ -42,6 +83,10 @@ func New(svc *Service, addr string) (*Server, error) {
 		},
 	}
 
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
 	staticFS, err := fs.Sub(static, "static")
 	if err != nil {
 		return nil, err
@@ -51,22 +96,37 @@ func New(svc *Service, addr string) (*Server, error) {
 	mux := http.NewServeMux()
 
 	mux.Handle("/static/", http.StripPrefix("/static/", fileServer))
-	mux.HandleFunc("/scrape", ans.scrape)
+	mux.HandleFunc("/scrape", csrfProtect(ans.scrape))
 	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
 		r = requestWithID(r)
 
 		ans.download(w, r)
 	})
-	mux.HandleFunc("/delete", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/delete", csrfProtect(func(w http.ResponseWriter, r *http.Request) {
 		r = requestWithID(r)
 
 		ans.delete(w, r)
-	})
+	}))
 	mux.HandleFunc("/jobs", ans.getJobs)
+	mux.HandleFunc("/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		r = requestWithID(r)
+
+		ans.jobPage(w, r)
+	})
+	mux.HandleFunc("/jobs/{id}/results", func(w http.ResponseWriter, r *http.Request) {
+		r = requestWithID(r)
+
+		ans.resultsPage(w, r)
+	})
+	// the shared results are public, the token of the path is the access
+	mux.HandleFunc("/shared/{token}", ans.sharedResults)
+	mux.HandleFunc("/shared/{token}/download", ans.sharedDownload)
+	mux.HandleFunc("/embed/{token}", ans.embedResults)
 	mux.HandleFunc("/", ans.index)
 
 	// api routes
 	mux.HandleFunc("/api/docs", ans.redocHandler)
+	mux.HandleFunc("/api/openapi.json", ans.openAPIHandler)
 	mux.HandleFunc("/api/v1/jobs", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
@@ -118,18 +178,200 @@ func New(svc *Service, addr string) (*Server, error) {
 		ans.download(w, r)
 	})
 
-	handler := securityHeaders(mux)
-	ans.srv.Handler = handler
+	mux.HandleFunc("/api/v1/jobs/{id}/results", func(w http.ResponseWriter, r *http.Request) {
+		r = requestWithID(r)
+
+		if r.Method != http.MethodGet {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiJobResults(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/jobs/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
+		r = requestWithID(r)
+
+		if r.Method != http.MethodGet {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiJobStats(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/jobs/{id}/shares", func(w http.ResponseWriter, r *http.Request) {
+		r = requestWithID(r)
+
+		switch r.Method {
+		case http.MethodPost:
+			ans.apiCreateShare(w, r)
+		case http.MethodGet:
+			ans.apiJobShares(w, r)
+		default:
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+		}
+	})
+
+	mux.HandleFunc("/api/v1/shares/{id}", func(w http.ResponseWriter, r *http.Request) {
+		r = requestWithID(r)
+
+		if r.Method != http.MethodDelete {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiRevokeShare(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/results", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiResults(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scrape/sync", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiScrapeSync(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/places/refresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiRefreshPlaces(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/places/{cid}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiPurgePlace(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/deliveries/failed", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiFailedDeliveries(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/deliveries/failed/{id}/replay", func(w http.ResponseWriter, r *http.Request) {
+		r = requestWithID(r)
+
+		if r.Method != http.MethodPost {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiReplayDelivery(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/admin/stats", ans.adminOnly(http.MethodGet, ans.adminStats))
+	mux.HandleFunc("/api/v1/admin/jobs/{id}/requeue", ans.adminOnly(http.MethodPost, ans.adminRequeueJob))
+	mux.HandleFunc("/api/v1/admin/jobs/{id}/fail", ans.adminOnly(http.MethodPost, ans.adminFailJob))
+
+	var handler http.Handler = mux
+
+	for i := len(ans.middlewares) - 1; i >= 0; i-- {
+		handler = ans.middlewares[i](handler)
+	}
+
+	// the health checks are not rate limited
+	root := http.NewServeMux()
+	root.HandleFunc("/health", ans.health)
+	root.HandleFunc("/healthz", ans.healthz)
+	root.HandleFunc("/readyz", ans.readyz)
+	root.Handle("/", handler)
+
+	ans.srv.Handler = securityHeaders(root, ans.branding.logoSource())
 
 	tmplsKeys := []string{
 		"static/templates/index.html",
 		"static/templates/job_rows.html",
 		"static/templates/job_row.html",
 		"static/templates/redoc.html",
+		"static/templates/shared.html",
+		"static/templates/embed.html",
+		"static/templates/job.html",
+		"static/templates/results.html",
 	}
 
 	for _, key := range tmplsKeys {
-		tmp, err := template.ParseFS(static, key)
+		// layout.html has the parts shared by the pages
+		tmp, err := template.ParseFS(static, key, "static/templates/layout.html")
 		if err != nil {
 			return nil, err
 		}
@@ -140,6 +382,11 @@ func New(svc *Service, addr string) (*Server, error) {
 	return &ans, nil
 }
 
+// Handler returns the handler of the routes of the server.
+func (s *Server) Handler() http.Handler {
+	return s.srv.Handler
+}
+
 func (s *Server) Start(ctx context.Context) error {
 	go func() {
 		<-ctx.Done()
@@ -177,6 +424,9 @@ type formData struct {
 	Depth    int
 	Email    bool
 	Proxies  []string
+	// CSRF is the token the requests of the page send back.
+	CSRF  string
+	Brand Branding
 }
 
 type ctxKey string
@@ -234,11 +484,12 @@ func (s *Server) index(w http.ResponseWriter, r *http.Request) {
 		Language: "en",
 		Zoom:     15,
 		FastMode: false,
-		Radius:   10000,
 		Lat:      "0",
 		Lon:      "0",
 		Depth:    10,
 		Email:    false,
+		CSRF:     csrfToken(w, r),
+		Brand:    s.branding,
 	}
 
 	_ = tmpl.Execute(w, data)
@@ -275,13 +526,16 @@ func (s *Server) scrape(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if maxTime < time.Minute*3 {
-		http.Error(w, "max time must be more than 3m", http.StatusUnprocessableEntity)
+	newJob.Data.MaxTime = maxTime
 
-		return
-	}
+	if v := r.Form.Get("maxresults"); v != "" {
+		newJob.Data.MaxResults, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid max results", http.StatusUnprocessableEntity)
 
-	newJob.Data.MaxTime = maxTime
+			return
+		}
+	}
 
 	keywordsStr, ok := r.Form["keywords"]
 	if !ok {
@@ -313,13 +567,18 @@ func (s *Server) scrape(w http.ResponseWriter, r *http.Request) {
 		newJob.Data.FastMode = true
 	}
 
-	newJob.Data.Radius, err = strconv.Atoi(r.Form.Get("radius"))
-	if err != nil {
-		http.Error(w, "invalid radius", http.StatusUnprocessableEntity)
+	// an empty radius keeps the places far away in normal mode
+	if v := r.Form.Get("radius"); v != "" {
+		newJob.Data.Radius, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid radius", http.StatusUnprocessableEntity)
 
-		return
+			return
+		}
 	}
 
+	newJob.Data.Tiles = r.Form.Get("tiles") == "on"
+
 	newJob.Data.Lat = r.Form.Get("latitude")
 	newJob.Data.Lon = r.Form.Get("longitude")
 
@@ -331,6 +590,40 @@ func (s *Server) scrape(w http.ResponseWriter, r *http.Request) {
 	}
 
 	newJob.Data.Email = r.Form.Get("email") == "on"
+	newJob.Data.Localize = r.Form.Get("localize") == "on"
+	newJob.Data.ResultsLang = strings.TrimSpace(r.Form.Get("results_lang"))
+	newJob.Data.ResultsEnglish = r.Form.Get("results_english") == "on"
+	newJob.Data.Country = strings.TrimSpace(r.Form.Get("country"))
+	newJob.Data.ExcludeSponsored = r.Form.Get("exclude_sponsored") == "on"
+	newJob.Data.Filter = strings.TrimSpace(r.Form.Get("filter"))
+	newJob.Data.IncludeCategories = splitList(r.Form.Get("include_categories"))
+	newJob.Data.ExcludeCategories = splitList(r.Form.Get("exclude_categories"))
+	newJob.Data.RequirePhone = r.Form.Get("require_phone") == "on"
+	newJob.Data.RequireWebsite = r.Form.Get("require_website") == "on"
+	newJob.Data.VerifyAddress = r.Form.Get("verify_address") == "on"
+	newJob.Data.OrderBy = r.Form.Get("order_by")
+	newJob.Data.PopularTimesNormalized = r.Form.Get("popular_times_normalized") == "on"
+	newJob.Data.ReviewsNormalized = r.Form.Get("reviews_normalized") == "on"
+
+	if v := r.Form.Get("min_rating"); v != "" {
+		newJob.Data.MinRating, err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			http.Error(w, "invalid min rating", http.StatusUnprocessableEntity)
+
+			return
+		}
+	}
+
+	if v := r.Form.Get("min_reviews"); v != "" {
+		newJob.Data.MinReviews, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid min reviews", http.StatusUnprocessableEntity)
+
+			return
+		}
+	}
+	newJob.Data.NotifyEmail = strings.TrimSpace(r.Form.Get("notify_email"))
+	newJob.Data.Profile = strings.TrimSpace(r.Form.Get("profile"))
 
 	proxies := strings.Split(r.Form.Get("proxies"), "\n")
 	if len(proxies) > 0 {
@@ -388,6 +681,10 @@ func (s *Server) getJobs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := s.svc.Estimate(r.Context(), jobs); err != nil {
+		log.Printf("failed to estimate the queue: %v", err)
+	}
+
 	_ = tmpl.Execute(w, jobs)
 }
 
@@ -407,7 +704,38 @@ func (s *Server) download(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	filePath, err := s.svc.GetCSV(ctx, id.String())
+	job, err := s.svc.Get(ctx, id.String())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	// ?format=vcard downloads the places as contacts, ?format=kml as a map
+	if format := r.URL.Query().Get("format"); format != "" && format != "csv" {
+		switch format {
+		case kml.FormatKML, kml.FormatKMZ:
+			s.downloadKML(w, r, &job, format)
+		default:
+			s.downloadContacts(w, r, &job, format)
+		}
+
+		return
+	}
+
+	var filePath string
+
+	// ?table=popular_times downloads a table of the job instead
+	if table := r.URL.Query().Get("table"); table != "" {
+		filePath, err = s.svc.GetTableCSV(ctx, id.String(), table)
+	} else if job.Data.File != nil {
+		s.redirectDownload(w, r, &job)
+
+		return
+	} else {
+		filePath, err = s.svc.GetCSV(ctx, id.String())
+	}
+
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -421,6 +749,14 @@ func (s *Server) download(w http.ResponseWriter, r *http.Request) {
 	defer file.Close()
 
 	fileName := filepath.Base(filePath)
+
+	// ?columns=title,phone downloads only these columns
+	if columns := splitList(r.URL.Query().Get("columns")); len(columns) > 0 {
+		downloadColumns(w, file, fileName, columns)
+
+		return
+	}
+
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
 	w.Header().Set("Content-Type", "text/csv")
 
@@ -431,6 +767,43 @@ func (s *Server) download(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+type downloadResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+}
+
+// redirectDownload sends the client to a presigned url of the results file
+// instead of streaming it through the server. Clients that accept JSON get
+// the url together with the size and checksum of the file.
+func (s *Server) redirectDownload(w http.ResponseWriter, r *http.Request, job *Job) {
+	u, expiresAt, err := s.svc.DownloadURL(r.Context(), job)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		ans := downloadResponse{
+			URL:       u,
+			ExpiresAt: expiresAt,
+			Size:      job.Data.File.Size,
+			SHA256:    job.Data.File.SHA256,
+		}
+
+		renderJSON(w, http.StatusOK, ans)
+
+		return
+	}
+
+	w.Header().Set("X-File-Size", strconv.FormatInt(job.Data.File.Size, 10))
+	w.Header().Set("X-File-Sha256", job.Data.File.SHA256)
+
+	http.Redirect(w, r, u, http.StatusTemporaryRedirect)
+}
+
 func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -461,7 +834,7 @@ type apiError struct {
 }
 
 type apiScrapeRequest struct {
-	Name string
+	Name string `json:"name"`
 	JobData
 }
 
@@ -480,6 +853,20 @@ func (s *Server) redocHandler(w http.ResponseWriter, _ *http.Request) {
 	_ = tmpl.Execute(w, nil)
 }
 
+func (s *Server) openAPIHandler(w http.ResponseWriter, _ *http.Request) {
+	spec, err := OpenAPISpec()
+	if err != nil {
+		renderJSON(w, http.StatusInternalServerError, apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	renderJSON(w, http.StatusOK, spec)
+}
+
 func (s *Server) apiScrape(w http.ResponseWriter, r *http.Request) {
 	var req apiScrapeRequest
 
@@ -503,11 +890,20 @@ func (s *Server) apiScrape(w http.ResponseWriter, r *http.Request) {
 		Data:   req.JobData,
 	}
 
+	newJob.Data.File = nil
+
 	// convert to seconds
 	newJob.Data.MaxTime *= time.Second
 
 	err = newJob.Validate()
 	if err != nil {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			renderJSON(w, http.StatusUnprocessableEntity, verr)
+
+			return
+		}
+
 		ans := apiError{
 			Code:    http.StatusUnprocessableEntity,
 			Message: err.Error(),
@@ -518,6 +914,12 @@ func (s *Server) apiScrape(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dryRun {
+		s.apiPlan(w, r, &newJob)
+
+		return
+	}
+
 	err = s.svc.Create(r.Context(), &newJob)
 	if err != nil {
 		ans := apiError{
@@ -537,6 +939,33 @@ func (s *Server) apiScrape(w http.ResponseWriter, r *http.Request) {
 	renderJSON(w, http.StatusCreated, ans)
 }
 
+func (s *Server) apiPlan(w http.ResponseWriter, r *http.Request, job *Job) {
+	if s.planner == nil {
+		ans := apiError{
+			Code:    http.StatusNotImplemented,
+			Message: "dry runs are not supported",
+		}
+
+		renderJSON(w, http.StatusNotImplemented, ans)
+
+		return
+	}
+
+	plan, err := s.planner(r.Context(), &job.Data)
+	if err != nil {
+		ans := apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+
+		renderJSON(w, http.StatusUnprocessableEntity, ans)
+
+		return
+	}
+
+	renderJSON(w, http.StatusOK, plan)
+}
+
 func (s *Server) apiGetJobs(w http.ResponseWriter, r *http.Request) {
 	jobs, err := s.svc.All(r.Context())
 	if err != nil {
@@ -550,6 +979,10 @@ func (s *Server) apiGetJobs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := s.svc.Estimate(r.Context(), jobs); err != nil {
+		log.Printf("failed to estimate the queue: %v", err)
+	}
+
 	renderJSON(w, http.StatusOK, jobs)
 }
 
@@ -578,7 +1011,80 @@ func (s *Server) apiGetJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	renderJSON(w, http.StatusOK, job)
+	jobs := []Job{job}
+
+	if err := s.svc.Estimate(r.Context(), jobs); err != nil {
+		log.Printf("failed to estimate the queue: %v", err)
+	}
+
+	renderJSON(w, http.StatusOK, jobs[0])
+}
+
+func (s *Server) apiJobStats(w http.ResponseWriter, r *http.Request) {
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		apiError := apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Invalid ID",
+		}
+
+		renderJSON(w, http.StatusUnprocessableEntity, apiError)
+
+		return
+	}
+
+	job, err := s.svc.Get(r.Context(), id.String())
+	if err != nil {
+		apiError := apiError{
+			Code:    http.StatusNotFound,
+			Message: http.StatusText(http.StatusNotFound),
+		}
+
+		renderJSON(w, http.StatusNotFound, apiError)
+
+		return
+	}
+
+	if job.Stats == nil {
+		apiError := apiError{
+			Code:    http.StatusNotFound,
+			Message: "the stats are computed when the job completes",
+		}
+
+		renderJSON(w, http.StatusNotFound, apiError)
+
+		return
+	}
+
+	renderJSON(w, http.StatusOK, job.Stats)
+}
+
+func (s *Server) apiPurgePlace(w http.ResponseWriter, r *http.Request) {
+	cid := r.PathValue("cid")
+	if _, err := strconv.ParseUint(cid, 10, 64); err != nil {
+		apiError := apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Invalid CID",
+		}
+
+		renderJSON(w, http.StatusUnprocessableEntity, apiError)
+
+		return
+	}
+
+	ans, err := s.svc.PurgePlace(r.Context(), cid)
+	if err != nil {
+		apiError := apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+
+		renderJSON(w, http.StatusInternalServerError, apiError)
+
+		return
+	}
+
+	renderJSON(w, http.StatusOK, ans)
 }
 
 func (s *Server) apiDeleteJob(w http.ResponseWriter, r *http.Request) {
@@ -620,7 +1126,14 @@ func formatDate(t time.Time) string {
 	return t.Format("Jan 02, 2006 15:04:05")
 }
 
-func securityHeaders(next http.Handler) http.Handler {
+// securityHeaders sets the headers of the responses. imgSource is an
+// additional origin of the images, e.g. of the logo, or empty.
+func securityHeaders(next http.Handler, imgSource string) http.Handler {
+	imgSrc := "img-src 'self' data: cdn.redoc.ly"
+	if imgSource != "" {
+		imgSrc += " " + imgSource
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-Content-Type-Options", "nosniff")
 		w.Header().Set("X-Frame-Options", "DENY")
@@ -630,10 +1143,23 @@ func securityHeaders(next http.Handler) http.Handler {
 				"script-src 'self' cdn.redoc.ly cdnjs.cloudflare.com 'unsafe-inline' 'unsafe-eval'; "+
 				"worker-src 'self' blob:; "+
 				"style-src 'self' 'unsafe-inline' fonts.googleapis.com; "+
-				"img-src 'self' data: cdn.redoc.ly; "+
+				imgSrc+"; "+
 				"font-src 'self' fonts.gstatic.com; "+
 				"connect-src 'self'")
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// splitList splits the comma separated values of a form field.
+func splitList(s string) []string {
+	var ans []string
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ans = append(ans, item)
+		}
+	}
+
+	return ans
+}